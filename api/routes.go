@@ -9,6 +9,7 @@ import (
 
 	"github.com/OdyseeTeam/odysee-api/app/asynquery"
 	"github.com/OdyseeTeam/odysee-api/app/auth"
+	"github.com/OdyseeTeam/odysee-api/app/entitlements"
 	"github.com/OdyseeTeam/odysee-api/app/geopublish"
 	gpmetrics "github.com/OdyseeTeam/odysee-api/app/geopublish/metrics"
 	"github.com/OdyseeTeam/odysee-api/app/proxy"
@@ -24,20 +25,25 @@ import (
 	"github.com/OdyseeTeam/odysee-api/internal/monitor"
 	"github.com/OdyseeTeam/odysee-api/internal/status"
 	"github.com/OdyseeTeam/odysee-api/internal/storage"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/iprate"
 	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
 	"github.com/OdyseeTeam/odysee-api/pkg/redislocker"
 	"github.com/OdyseeTeam/odysee-api/pkg/sturdycache"
 	"github.com/OdyseeTeam/player-server/pkg/paid"
 
 	sentryhttp "github.com/getsentry/sentry-go/http"
 	"github.com/gorilla/mux"
+	"github.com/hibiken/asynq"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/tus/tusd/v2/pkg/filestore"
 	tusd "github.com/tus/tusd/v2/pkg/handler"
 	"github.com/tus/tusd/v2/pkg/prometheuscollector"
+	"golang.org/x/time/rate"
 )
 
 const preflightDuration = 86400
@@ -90,10 +96,19 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router, opts *RoutesOptio
 	v1Router.HandleFunc("/metric/ui", emptyHandler).Methods(http.MethodOptions)
 
 	v1Router.HandleFunc("/paid/pubkey", paid.HandlePublicKeyRequest).Methods(http.MethodGet)
+	if kr := query.PaidTokenKeyring(); kr != nil {
+		v1Router.HandleFunc("/paid/jwks", kr.JWKSHandler).Methods(http.MethodGet)
+	}
 
 	internalRouter := r.PathPrefix("/internal").Subrouter()
 	internalRouter.Handle("/metrics", promhttp.Handler())
 
+	if token := config.GetEntitlementsConfig().WebhookToken; token != "" {
+		er := internalRouter.PathPrefix("/entitlements").Subrouter()
+		er.Use(admin.SimpleAdminAuthMiddleware(token, iprate.NewLimiter(rate.Limit(0.05), 1, iprate.WithCleanupInterval(time.Hour))))
+		entitlements.InstallRoutes(er, query.EntitlementCache())
+	}
+
 	if opts.EnableProfiling {
 		pr := internalRouter.PathPrefix("/pprof").Subrouter()
 		pr.HandleFunc("/symbol", pprof.Symbol).Methods(http.MethodPost)
@@ -165,7 +180,7 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router, opts *RoutesOptio
 		}
 	}
 
-	keyfob, err := keybox.KeyfobFromString(config.GetUploadTokenPrivateKey())
+	keyring, err := uploadTokenKeyring()
 	if err != nil {
 		panic(err)
 	}
@@ -177,9 +192,10 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router, opts *RoutesOptio
 	launcher := asynquery.NewLauncher(
 		asynquery.WithRequestsConnOpts(asynqueryBusOpts),
 		asynquery.WithLogger(zapadapter.NewKV(nil)),
-		asynquery.WithPrivateKey(keyfob.PrivateKey()),
+		asynquery.WithKeyring(keyring),
 		asynquery.WithDB(storage.DB),
 		asynquery.WithUploadServiceURL(config.GetUploadServiceURL()),
+		asynquery.WithQueueOptions(config.GetAsynqueryQueueConfig().Options()...),
 	)
 
 	err = launcher.InstallRoutes(v1Router)
@@ -193,10 +209,12 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router, opts *RoutesOptio
 	if err := admin.InstallRoutes(v1AdminRouter); err != nil {
 		panic(err)
 	}
+	admin.InstallDeadLetterRoutes(v1AdminRouter, deadLetterQueues(asynqueryBusOpts, launcher.HandleRequeue))
 
 	onceMetrics.Do(func() {
 		gpmetrics.RegisterMetrics(nil)
 		redislocker.RegisterMetrics(nil)
+		queue.RegisterMetrics(nil)
 		if !opts.EnableV3Publish {
 			tus2metrics := prometheuscollector.New(tusHandler.Metrics)
 			prometheus.MustRegister(tus2metrics)
@@ -207,6 +225,49 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router, opts *RoutesOptio
 	})
 }
 
+// uploadTokenKeyring loads keys upload tokens are signed with from the keyring file,
+// falling back to a single UploadTokenPrivateKey.
+func uploadTokenKeyring() (*keybox.Keyring, error) {
+	kc := config.GetUploadTokenKeyringConfig()
+	if kc.File != "" {
+		return keybox.LoadKeyring(kc.File, keybox.WithGracePeriod(kc.GracePeriod))
+	}
+	keyfob, err := keybox.KeyfobFromString(config.GetUploadTokenPrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	return keybox.NewKeyringFromKeyfob(keyfob, keybox.WithGracePeriod(kc.GracePeriod))
+}
+
+// deadLetterQueues connects to queues which failed tasks should be available to operators for.
+// Queries failed by dead tasks are reopened by reopenQuery when those tasks are requeued.
+func deadLetterQueues(asynqueryBusOpts asynq.RedisConnOpt, reopenQuery queue.RequeueHandler) map[string]*queue.Queue {
+	queues := map[string]*queue.Queue{}
+	aq, err := queue.New(queue.WithRequestsConnOpts(asynqueryBusOpts))
+	if err != nil {
+		panic(err)
+	}
+	aq.AddRequeueHandler(tasks.AsynqueryIncomingQuery, reopenQuery)
+	aq.AddRequeueHandler(tasks.ForkliftUploadDone, reopenQuery)
+	queues["asynquery"] = aq
+
+	forkliftOpts, err := config.GetForkliftRequestsConnOpts()
+	if err != nil {
+		panic(err)
+	}
+	if forkliftOpts != nil {
+		fq, err := queue.New(queue.WithRequestsConnOpts(forkliftOpts))
+		if err != nil {
+			logger.Log().WithError(err).Error("forklift queue is unavailable, its dead letters won't be accessible")
+		} else {
+			fq.AddRequeueHandler(tasks.ForkliftUploadIncoming, reopenQuery)
+			fq.AddRequeueHandler(tasks.ForkliftURLIncoming, reopenQuery)
+			queues["forklift"] = fq
+		}
+	}
+	return queues
+}
+
 func defaultMiddlewares(oauthAuther auth.Authenticator, legacyProvider auth.Provider, router *sdkrouter.Router) mux.MiddlewareFunc {
 	store, err := sturdycache.NewReplicatedCache(
 		config.GetSturdyCacheMaster(),
@@ -218,6 +279,10 @@ func defaultMiddlewares(oauthAuther auth.Authenticator, legacyProvider auth.Prov
 	}
 	cache := query.NewQueryCache(store)
 	logger.Log().Infof("cache configured: master=%s, replicas=%s", config.GetSturdyCacheMaster(), config.GetSturdyCacheReplicas())
+	if config.GetArfleetEnabled() {
+		query.SetArfleetResolver(query.NewArfleetResolver(store))
+	}
+	query.SetEntitlementCache(query.NewEntitlementCache(store))
 
 	defaultHeaders := []string{
 		wallet.LegacyTokenHeader, wallet.AuthorizationHeader, "X-Requested-With", "Content-Type", "Accept",
@@ -1,6 +1,7 @@
 package arweave
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -8,7 +9,8 @@ import (
 	"github.com/tidwall/sjson"
 )
 
-func ReplaceAssetUrls(baseUrl string, structure any, collPath, itemPath string) (any, error) {
+// ReplaceAssetUrls substitutes URLs at itemPath of each item in the collection at collPath with Arfleet ones.
+func (c *ArfleetResolver) ReplaceAssetUrls(ctx context.Context, structure any, collPath, itemPath string) (any, error) {
 	var origUrls []string
 	urlPaths := map[string][]string{}
 
@@ -30,8 +32,7 @@ func ReplaceAssetUrls(baseUrl string, structure any, collPath, itemPath string)
 		return true
 	})
 
-	resolver := NewArfleetResolver(baseUrl)
-	subsUrls, err := resolver.ResolveUrls(origUrls)
+	subsUrls, err := c.ResolveUrls(ctx, origUrls)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +47,8 @@ func ReplaceAssetUrls(baseUrl string, structure any, collPath, itemPath string)
 	return d, json.Unmarshal(jsonData, &d)
 }
 
-func ReplaceAssetUrl(baseUrl string, structure any, path string) (any, error) {
+// ReplaceAssetUrl substitutes the URL at path with an Arfleet one.
+func (c *ArfleetResolver) ReplaceAssetUrl(ctx context.Context, structure any, path string) (any, error) {
 	jsonData, err := json.Marshal(structure)
 	if err != nil {
 		return nil, err
@@ -54,8 +56,7 @@ func ReplaceAssetUrl(baseUrl string, structure any, path string) (any, error) {
 
 	origUrl := gjson.GetBytes(jsonData, path).String()
 
-	resolver := NewArfleetResolver(baseUrl)
-	subsUrls, err := resolver.ResolveUrls([]string{origUrl})
+	subsUrls, err := c.ResolveUrls(ctx, []string{origUrl})
 
 	if err != nil {
 		return nil, err
@@ -71,9 +72,9 @@ func ReplaceAssetUrl(baseUrl string, structure any, path string) (any, error) {
 	return d, json.Unmarshal(jsonData, &d)
 }
 
-func GetClaimUrl(baseUrl, claim_id string) (string, error) {
-	resolver := NewArfleetResolver(baseUrl)
-	r, err := resolver.ResolveClaims([]string{claim_id})
+// GetClaimUrl returns the Arfleet stream URL for the claim, empty if it doesn't have one.
+func (c *ArfleetResolver) GetClaimUrl(ctx context.Context, claim_id string) (string, error) {
+	r, err := c.ResolveClaims(ctx, []string{claim_id})
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package arweave
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -23,7 +24,7 @@ func TestReplaceAssetUrls(t *testing.T) {
 	require.NoError(err)
 	var resp jsonrpc.RPCResponse
 	require.NoError(json.Unmarshal(f, &resp))
-	result, err := ReplaceAssetUrls("http://odycdn.com", resp.Result, "items", "value.thumbnail.url")
+	result, err := NewArfleetResolver("http://odycdn.com").ReplaceAssetUrls(context.Background(), resp.Result, "items", "value.thumbnail.url")
 	require.NoError(err)
 
 	out, err := json.MarshalIndent(result, "", "  ")
@@ -44,7 +45,7 @@ func TestReplaceAssetUrl(t *testing.T) {
 	require.NoError(err)
 	var resp jsonrpc.RPCResponse
 	require.NoError(json.Unmarshal(f, &resp))
-	result, err := ReplaceAssetUrl("http://odycdn.com", resp.Result.(map[string]any)["lbry://@MySillyReactions#d1ae6a9097b44691d318a5bfc6dc1240311c75e2"], "value.thumbnail.url")
+	result, err := NewArfleetResolver("http://odycdn.com").ReplaceAssetUrl(context.Background(), resp.Result.(map[string]any)["lbry://@MySillyReactions#d1ae6a9097b44691d318a5bfc6dc1240311c75e2"], "value.thumbnail.url")
 	require.NoError(err)
 
 	out, err := json.MarshalIndent(result, "", "  ")
@@ -57,7 +58,7 @@ func TestGetClaimUrl(t *testing.T) {
 
 	require := require.New(t)
 	assert := assert.New(t)
-	url, err := GetClaimUrl("https://cdnhost.com", "91e8caf6d1e740aaa6235d4eb81b21ec21cb2652")
+	url, err := NewArfleetResolver("https://cdnhost.com").GetClaimUrl(context.Background(), "91e8caf6d1e740aaa6235d4eb81b21ec21cb2652")
 	require.NoError(err)
 	assert.Regexp(`^https://cdnhost.com/explore/\w+\?data_item_id=\w+&filename=\w+.mp4$`, url)
 }
@@ -2,31 +2,136 @@ package arweave
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/eko/gocache/lib/v4/cache"
+	"github.com/eko/gocache/lib/v4/store"
 )
 
 const (
-	batchResolverUrl      = "https://migrator.arfleet.zephyrdev.xyz/batch-resolve"
-	batchClaimResolverUrl = "https://migrator.arfleet.zephyrdev.xyz/claims/batch-resolve"
-	resolverTimeout       = 10 * time.Second
+	DefaultBatchResolverUrl      = "https://migrator.arfleet.zephyrdev.xyz/batch-resolve"
+	DefaultBatchClaimResolverUrl = "https://migrator.arfleet.zephyrdev.xyz/claims/batch-resolve"
+
+	defaultTimeout          = time.Second
+	defaultCacheTTL         = time.Hour
+	defaultNegativeCacheTTL = 5 * time.Minute
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	cacheWriteTimeout       = 5 * time.Second
 
 	paramDataItemId = "data_item_id"
+
+	kindUrl   = "url"
+	kindClaim = "claim"
 )
 
+// ErrUnavailable is returned instead of making a request while the resolver circuit is open.
+var ErrUnavailable = errors.New("arfleet resolver is unavailable")
+
 type HttpDoer interface {
 	Do(req *http.Request) (res *http.Response, err error)
 }
 
+// ArfleetResolver looks up Arfleet substitutes for asset URLs and claims.
+//
+// Lookup results are cached, with a shorter TTL for URLs and claims which aren't on Arfleet,
+// in a cache which can be shared between instances. Concurrent lookups of the same URL or claim make a single request.
+// Each lookup has a time budget, and after a number of consecutive failed requests the resolver
+// stops making them for a cooldown period, returning ErrUnavailable.
 type ArfleetResolver struct {
 	baseUrl               string
 	batchResolverUrl      string
 	batchClaimResolverUrl string
 	client                HttpDoer
+	timeout               time.Duration
+
+	cache            cache.CacheInterface[any]
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]*lookup
+
+	breaker *breaker
+	logger  logging.KVLogger
+}
+
+// lookup is a request for a batch of URLs or claims other lookups can wait for.
+type lookup struct {
+	done    chan struct{}
+	results map[string]string
+	err     error
+}
+
+type ResolverOption func(r *ArfleetResolver)
+
+// WithEndpoints sets URLs of batch resolve endpoints for asset URLs and claims, empty values keep defaults.
+func WithEndpoints(batchResolverUrl, batchClaimResolverUrl string) ResolverOption {
+	return func(r *ArfleetResolver) {
+		if batchResolverUrl != "" {
+			r.batchResolverUrl = batchResolverUrl
+		}
+		if batchClaimResolverUrl != "" {
+			r.batchClaimResolverUrl = batchClaimResolverUrl
+		}
+	}
+}
+
+// WithCache enables caching of lookup results. Zero TTLs keep defaults.
+func WithCache(c cache.CacheInterface[any], ttl, negativeTTL time.Duration) ResolverOption {
+	return func(r *ArfleetResolver) {
+		r.cache = c
+		if ttl > 0 {
+			r.cacheTTL = ttl
+		}
+		if negativeTTL > 0 {
+			r.negativeCacheTTL = negativeTTL
+		}
+	}
+}
+
+// WithTimeout sets the time budget for a single lookup, including cache access. Zero keeps the default.
+func WithTimeout(d time.Duration) ResolverOption {
+	return func(r *ArfleetResolver) {
+		if d > 0 {
+			r.timeout = d
+		}
+	}
+}
+
+// WithBreaker sets the number of consecutive failed requests after which the resolver
+// stops making them for cooldown. Zero values keep defaults.
+func WithBreaker(threshold int, cooldown time.Duration) ResolverOption {
+	return func(r *ArfleetResolver) {
+		if threshold > 0 {
+			r.breaker.threshold = threshold
+		}
+		if cooldown > 0 {
+			r.breaker.cooldown = cooldown
+		}
+	}
+}
+
+func WithHttpClient(client HttpDoer) ResolverOption {
+	return func(r *ArfleetResolver) {
+		r.client = client
+	}
+}
+
+func WithLogger(logger logging.KVLogger) ResolverOption {
+	return func(r *ArfleetResolver) {
+		r.logger = logger
+	}
 }
 
 type BatchResolveUrlResponse map[string]ResolveUrlResponse
@@ -48,27 +153,228 @@ type ResolveClaimResponse struct {
 	Resolved   bool   `json:"resolved"`
 }
 
-func NewArfleetResolver(baseUrl string) *ArfleetResolver {
+// NewArfleetResolver creates a resolver returning substitutes on the baseUrl host.
+// It's meant to be long-lived, so that cached results and circuit state are kept between lookups.
+func NewArfleetResolver(baseUrl string, options ...ResolverOption) *ArfleetResolver {
 	r := &ArfleetResolver{
 		baseUrl:               baseUrl,
-		batchResolverUrl:      batchResolverUrl,
-		batchClaimResolverUrl: batchClaimResolverUrl,
-		client: &http.Client{
-			Timeout: resolverTimeout,
-		},
+		batchResolverUrl:      DefaultBatchResolverUrl,
+		batchClaimResolverUrl: DefaultBatchClaimResolverUrl,
+		client:                &http.Client{},
+		timeout:               defaultTimeout,
+		cacheTTL:              defaultCacheTTL,
+		negativeCacheTTL:      defaultNegativeCacheTTL,
+		inflight:              map[string]*lookup{},
+		breaker:               &breaker{threshold: defaultBreakerThreshold, cooldown: defaultBreakerCooldown},
+		logger:                logging.NoopKVLogger{},
+	}
+	for _, o := range options {
+		o(r)
 	}
 	return r
 }
 
-func (c *ArfleetResolver) ResolveUrls(urls []string) (map[string]string, error) {
+// ResolveUrls returns Arfleet substitutes for asset URLs which have them.
+func (c *ArfleetResolver) ResolveUrls(ctx context.Context, urls []string) (map[string]string, error) {
+	return c.resolve(ctx, kindUrl, urls, c.fetchUrls)
+}
+
+// ResolveClaims returns Arfleet stream URLs for claims which have them.
+func (c *ArfleetResolver) ResolveClaims(ctx context.Context, claimIds []string) (map[string]string, error) {
+	return c.resolve(ctx, kindClaim, claimIds, c.fetchClaims)
+}
+
+// resolve returns substitutes for keys from the cache, waiting for lookups already in progress
+// and fetching the rest. Results are paths relative to baseUrl until they're returned.
+func (c *ArfleetResolver) resolve(
+	ctx context.Context, kind string, keys []string,
+	fetch func(ctx context.Context, keys []string) (map[string]string, error),
+) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	paths := map[string]string{}
+	misses := c.getCached(ctx, kind, keys, paths)
+	if len(misses) > 0 {
+		owned, waiting := c.startLookup(kind, misses)
+		if owned != nil {
+			if !c.breaker.allow() {
+				c.finishLookup(kind, owned, nil, ErrUnavailable)
+				return nil, ErrUnavailable
+			}
+			go c.runLookup(ctx, kind, owned, fetch)
+			for _, k := range owned.keys {
+				waiting[k] = owned.lookup
+			}
+		}
+		for _, l := range waiting {
+			select {
+			case <-l.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if l.err != nil {
+				return nil, l.err
+			}
+		}
+		for k, l := range waiting {
+			if p, ok := l.results[k]; ok {
+				paths[k] = p
+			}
+		}
+	}
+
 	substitutes := map[string]string{}
+	for k, p := range paths {
+		if p != "" {
+			substitutes[k] = c.baseUrl + p
+		}
+	}
+	return substitutes, nil
+}
 
+// ownedLookup is a lookup started by the caller, which has to fetch keys.
+type ownedLookup struct {
+	*lookup
+	keys []string
+}
+
+// startLookup registers a lookup for keys nobody is looking up yet and returns it along with
+// lookups already in progress for the rest of keys.
+func (c *ArfleetResolver) startLookup(kind string, keys []string) (*ownedLookup, map[string]*lookup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var owned *ownedLookup
+	waiting := map[string]*lookup{}
+	for _, k := range keys {
+		id := kind + ":" + k
+		if l, ok := c.inflight[id]; ok {
+			waiting[k] = l
+			continue
+		}
+		if owned == nil {
+			owned = &ownedLookup{lookup: &lookup{done: make(chan struct{})}}
+		}
+		owned.keys = append(owned.keys, k)
+		c.inflight[id] = owned.lookup
+	}
+	return owned, waiting
+}
+
+// runLookup fetches keys of the lookup and caches results. Other lookups may be waiting for it,
+// so the request is made under a context detached from the caller and bounded by the lookup time budget.
+func (c *ArfleetResolver) runLookup(
+	ctx context.Context, kind string, l *ownedLookup,
+	fetch func(ctx context.Context, keys []string) (map[string]string, error),
+) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+	results, err := fetch(ctx, l.keys)
+	c.breaker.record(err, c.logger)
+	c.finishLookup(kind, l, results, err)
+	if err == nil {
+		c.setCached(kind, l.keys, results)
+	}
+}
+
+func (c *ArfleetResolver) finishLookup(kind string, l *ownedLookup, results map[string]string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range l.keys {
+		delete(c.inflight, kind+":"+k)
+	}
+	l.results, l.err = results, err
+	close(l.done)
+}
+
+func cacheKey(kind, key string) string {
+	return "arfleet:" + kind + ":" + key
+}
+
+// multiGetter is implemented by caches which can read many keys in a single request, like sturdycache.ReplicatedCache.
+type multiGetter interface {
+	MGet(ctx context.Context, keys ...string) ([]any, error)
+}
+
+// getCached puts cached paths into paths, empty for keys known not to be on Arfleet, and returns uncached keys.
+// Cache errors are treated as misses.
+func (c *ArfleetResolver) getCached(ctx context.Context, kind string, keys []string, paths map[string]string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, k)
+	}
+	if c.cache == nil || len(unique) == 0 {
+		return unique
+	}
+
+	values := make([]any, len(unique))
+	if mg, ok := c.cache.(multiGetter); ok {
+		cacheKeys := make([]string, len(unique))
+		for i, k := range unique {
+			cacheKeys[i] = cacheKey(kind, k)
+		}
+		found, err := mg.MGet(ctx, cacheKeys...)
+		if err != nil {
+			c.logger.Warn("arfleet cache read failed", "err", err)
+			return unique
+		}
+		copy(values, found)
+	} else {
+		for i, k := range unique {
+			v, err := c.cache.Get(ctx, cacheKey(kind, k))
+			if err != nil {
+				if !errors.Is(err, &store.NotFound{}) {
+					c.logger.Warn("arfleet cache read failed", "err", err)
+				}
+				continue
+			}
+			values[i] = v
+		}
+	}
+
+	var misses []string
+	for i, k := range unique {
+		p, ok := values[i].(string)
+		if !ok {
+			misses = append(misses, k)
+			continue
+		}
+		paths[k] = p
+	}
+	return misses
+}
+
+// setCached stores fetched paths and empty values for keys which weren't resolved.
+func (c *ArfleetResolver) setCached(kind string, keys []string, results map[string]string) {
+	if c.cache == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheWriteTimeout)
+	defer cancel()
+	for _, k := range keys {
+		p, ttl := results[k], c.cacheTTL
+		if p == "" {
+			ttl = c.negativeCacheTTL
+		}
+		if err := c.cache.Set(ctx, cacheKey(kind, k), p, store.WithExpiration(ttl)); err != nil {
+			c.logger.Warn("arfleet cache write failed", "err", err)
+			return
+		}
+	}
+}
+
+func (c *ArfleetResolver) fetchUrls(ctx context.Context, urls []string) (map[string]string, error) {
 	jsonData, err := json.Marshal(map[string][]string{"urls": urls})
 	if err != nil {
 		return nil, err
 	}
 
-	jsonResponse, err := c.makeRequest(http.MethodPost, c.batchResolverUrl, jsonData)
+	jsonResponse, err := c.makeRequest(ctx, http.MethodPost, c.batchResolverUrl, jsonData)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +384,7 @@ func (c *ArfleetResolver) ResolveUrls(urls []string) (map[string]string, error)
 		return nil, fmt.Errorf("error parsing json: %w", err)
 	}
 
+	paths := map[string]string{}
 	for url, resolved := range resolvedList {
 		if !resolved.Resolved {
 			continue
@@ -86,20 +393,18 @@ func (c *ArfleetResolver) ResolveUrls(urls []string) (map[string]string, error)
 		if err != nil {
 			continue
 		}
-		substitutes[url] = c.baseUrl + u
+		paths[url] = u
 	}
-	return substitutes, nil
+	return paths, nil
 }
 
-func (c *ArfleetResolver) ResolveClaims(claim_ids []string) (map[string]string, error) {
-	substitutes := map[string]string{}
-
-	jsonData, err := json.Marshal(map[string][]string{"claim_ids": claim_ids})
+func (c *ArfleetResolver) fetchClaims(ctx context.Context, claimIds []string) (map[string]string, error) {
+	jsonData, err := json.Marshal(map[string][]string{"claim_ids": claimIds})
 	if err != nil {
 		return nil, err
 	}
 
-	jsonResponse, err := c.makeRequest(http.MethodPost, c.batchClaimResolverUrl, jsonData)
+	jsonResponse, err := c.makeRequest(ctx, http.MethodPost, c.batchClaimResolverUrl, jsonData)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +414,7 @@ func (c *ArfleetResolver) ResolveClaims(claim_ids []string) (map[string]string,
 		return nil, fmt.Errorf("error parsing json: %w", err)
 	}
 
+	paths := map[string]string{}
 	for claim, resolved := range resolvedList {
 		if !resolved.Resolved {
 			continue
@@ -117,15 +423,15 @@ func (c *ArfleetResolver) ResolveClaims(claim_ids []string) (map[string]string,
 		if err != nil {
 			continue
 		}
-		substitutes[claim] = c.baseUrl + u
+		paths[claim] = u
 	}
-	return substitutes, nil
+	return paths, nil
 }
 
-func (c *ArfleetResolver) makeRequest(method, url string, jsonData []byte) ([]byte, error) {
+func (c *ArfleetResolver) makeRequest(ctx context.Context, method, url string, jsonData []byte) ([]byte, error) {
 	client := c.client
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -148,6 +454,49 @@ func (c *ArfleetResolver) makeRequest(method, url string, jsonData []byte) ([]by
 	return body, nil
 }
 
+// breaker stops requests to a failing service for cooldown after threshold consecutive failures.
+// Once cooldown passes a single request is let through, closing the circuit if it succeeds.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(err error, logger logging.KVLogger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		if b.failures >= b.threshold {
+			logger.Info("arfleet resolver recovered")
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		logger.Warn("arfleet resolver is failing, skipping lookups", "err", err, "failures", b.failures, "cooldown", b.cooldown)
+	}
+}
+
 func appendParams(baseUrl string, params map[string]string) (string, error) {
 	u, err := url.Parse(baseUrl)
 	if err != nil {
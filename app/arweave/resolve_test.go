@@ -0,0 +1,265 @@
+package arweave
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/sturdycache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc/v2"
+)
+
+// fakeArfleet serves batch resolve endpoints, resolving keys listed in known.
+type fakeArfleet struct {
+	*httptest.Server
+	known map[string]string
+
+	mu       sync.Mutex
+	requests [][]string
+	delay    time.Duration
+	failing  bool
+}
+
+func newFakeArfleet(t *testing.T, known map[string]string) *fakeArfleet {
+	f := &fakeArfleet{known: known}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeArfleet) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Urls     []string `json:"urls"`
+		ClaimIds []string `json:"claim_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	keys := append(req.Urls, req.ClaimIds...)
+	sort.Strings(keys)
+	f.mu.Lock()
+	f.requests = append(f.requests, keys)
+	delay, failing := f.delay, f.failing
+	f.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		return
+	}
+	if failing {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	resp := map[string]ResolveUrlResponse{}
+	for _, k := range keys {
+		arfleet, ok := f.known[k]
+		resp[k] = ResolveUrlResponse{Arfleet: arfleet, DataItemId: "item-" + arfleet, Resolved: ok}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeArfleet) set(delay time.Duration, failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay, f.failing = delay, failing
+}
+
+func (f *fakeArfleet) requested() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string{}, f.requests...)
+}
+
+func (f *fakeArfleet) resolver(options ...ResolverOption) *ArfleetResolver {
+	return NewArfleetResolver(
+		"https://cdn.test",
+		append([]ResolverOption{WithEndpoints(f.URL+"/batch-resolve", f.URL+"/claims/batch-resolve")}, options...)...,
+	)
+}
+
+func TestReplaceAssetUrlsFakeArfleet(t *testing.T) {
+	f := newFakeArfleet(t, map[string]string{
+		"https://thumbnails.lbry.com/NcFUGc3tSoE":                         "/explore/aaa",
+		"https://thumbs.odycdn.com/cb4f48c0e23a004db27514a52ff14445.webp": "/explore/bbb",
+	})
+	absPath, _ := filepath.Abs("./testdata/claim_search.json")
+	data, err := os.ReadFile(absPath)
+	require.NoError(t, err)
+	var resp jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(data, &resp))
+
+	result, err := f.resolver().ReplaceAssetUrls(context.Background(), resp.Result, "items", "value.thumbnail.url")
+	require.NoError(t, err)
+
+	thumb := func(i int) string {
+		item := result.(map[string]any)["items"].([]any)[i].(map[string]any)
+		return item["value"].(map[string]any)["thumbnail"].(map[string]any)["url"].(string)
+	}
+	assert.Equal(t, "https://cdn.test/explore/aaa?data_item_id=item-%2Fexplore%2Faaa", thumb(0))
+	assert.Equal(t, thumb(0), thumb(1))
+	assert.Equal(t, "https://thumbnails.lbry.com/x6XoL2c31k0", thumb(2))
+	assert.Equal(t, "https://cdn.test/explore/bbb?data_item_id=item-%2Fexplore%2Fbbb", thumb(8))
+
+	requests := f.requested()
+	require.Len(t, requests, 1)
+	assert.Len(t, requests[0], 19, "urls should be deduplicated")
+}
+
+func TestResolverCache(t *testing.T) {
+	store, master, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	f := newFakeArfleet(t, map[string]string{"claim1": "/explore/1"})
+	ctx := context.Background()
+
+	r := f.resolver(WithCache(store, time.Hour, time.Minute))
+	res, err := r.ResolveClaims(ctx, []string{"claim1", "claim2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"claim1": "https://cdn.test/explore/1?data_item_id=item-%2Fexplore%2F1"}, res)
+	require.Eventually(t, func() bool {
+		return master.Exists(cacheKey(kindClaim, "claim1")) && master.Exists(cacheKey(kindClaim, "claim2"))
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, time.Hour, master.TTL(cacheKey(kindClaim, "claim1")))
+	assert.Equal(t, time.Minute, master.TTL(cacheKey(kindClaim, "claim2")))
+
+	// Another instance sharing the cache doesn't make requests for either result.
+	r2 := f.resolver(WithCache(store, time.Hour, time.Minute))
+	res2, err := r2.ResolveClaims(ctx, []string{"claim1", "claim2"})
+	require.NoError(t, err)
+	assert.Equal(t, res, res2)
+	assert.Len(t, f.requested(), 1)
+
+	// Claims and asset URLs are cached separately.
+	_, err = r2.ResolveUrls(ctx, []string{"claim1"})
+	require.NoError(t, err)
+	assert.Len(t, f.requested(), 2)
+
+	// Negative results expire sooner.
+	master.FastForward(2 * time.Minute)
+	res, err = r2.ResolveClaims(ctx, []string{"claim1", "claim2"})
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+	requests := f.requested()
+	require.Len(t, requests, 3)
+	assert.Equal(t, []string{"claim2"}, requests[2])
+
+	require.Eventually(t, func() bool {
+		return master.Exists(cacheKey(kindClaim, "claim2")) && master.Exists(cacheKey(kindUrl, "claim1"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestResolverCoalescing(t *testing.T) {
+	f := newFakeArfleet(t, map[string]string{"a": "/explore/a", "b": "/explore/b", "c": "/explore/c"})
+	f.set(500*time.Millisecond, false)
+	r := f.resolver()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]map[string]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := r.ResolveUrls(ctx, []string{"a", "b"})
+			assert.NoError(t, err)
+			results[i] = res
+		}()
+	}
+	require.Eventually(t, func() bool { return len(f.requested()) > 0 }, time.Second, time.Millisecond)
+
+	// A lookup overlapping with the one in progress only requests what's missing.
+	res, err := r.ResolveUrls(ctx, []string{"b", "c"})
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+	wg.Wait()
+
+	for _, res := range results {
+		assert.Len(t, res, 2)
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, f.requested())
+}
+
+func TestResolverBreaker(t *testing.T) {
+	f := newFakeArfleet(t, map[string]string{"a": "/explore/a"})
+	f.set(0, true)
+	r := f.resolver(WithBreaker(2, 100*time.Millisecond))
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := r.ResolveUrls(ctx, []string{"a"})
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrUnavailable)
+	}
+	_, err := r.ResolveUrls(ctx, []string{"a"})
+	require.ErrorIs(t, err, ErrUnavailable)
+	assert.Len(t, f.requested(), 2)
+
+	// A failed probe after the cooldown opens the circuit again.
+	time.Sleep(150 * time.Millisecond)
+	_, err = r.ResolveUrls(ctx, []string{"a"})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrUnavailable)
+	_, err = r.ResolveUrls(ctx, []string{"a"})
+	require.ErrorIs(t, err, ErrUnavailable)
+	assert.Len(t, f.requested(), 3)
+
+	// A successful one closes it.
+	f.set(0, false)
+	time.Sleep(150 * time.Millisecond)
+	res, err := r.ResolveUrls(ctx, []string{"a"})
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+	_, err = r.ResolveUrls(ctx, []string{"a"})
+	require.NoError(t, err)
+	assert.Len(t, f.requested(), 5)
+}
+
+func TestResolverTimeout(t *testing.T) {
+	f := newFakeArfleet(t, map[string]string{"a": "/explore/a"})
+	f.set(5*time.Second, false)
+	r := f.resolver(WithTimeout(50*time.Millisecond), WithBreaker(1, time.Minute))
+
+	start := time.Now()
+	_, err := r.ResolveUrls(context.Background(), []string{"a"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Timeouts count as failures.
+	require.Eventually(t, func() bool {
+		_, err := r.ResolveUrls(context.Background(), []string{"a"})
+		return errors.Is(err, ErrUnavailable)
+	}, time.Second, 10*time.Millisecond)
+
+	// A caller going away doesn't fail lookups waiting for the same keys.
+	f.set(100*time.Millisecond, false)
+	r = f.resolver(WithTimeout(time.Second), WithBreaker(1, time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	waiter := make(chan error)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		res, err := r.ResolveUrls(context.Background(), []string{"a"})
+		assert.Len(t, res, 1)
+		waiter <- err
+	}()
+	_, err = r.ResolveUrls(ctx, []string{"a"})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, <-waiter)
+	_, err = r.ResolveUrls(context.Background(), []string{"a"})
+	require.NoError(t, err)
+}
@@ -68,12 +68,18 @@ type queryParams struct {
 	readyToRun bool
 }
 
-func NewCallManager(redisOpts asynq.RedisConnOpt, db *sql.DB, logger logging.KVLogger) (*CallManager, error) {
+// NewCallManager creates a call manager, queue options passed override default queues and routing.
+func NewCallManager(redisOpts asynq.RedisConnOpt, db *sql.DB, logger logging.KVLogger, queueOptions ...func(*queue.Options)) (*CallManager, error) {
 	m := CallManager{
 		logger: logger,
 		db:     db,
 	}
-	q, err := queue.New(queue.WithRequestsConnOpts(redisOpts), queue.WithConcurrency(10))
+	q, err := queue.New(append([]func(*queue.Options){
+		queue.WithRequestsConnOpts(redisOpts),
+		queue.WithConcurrency(10),
+		queue.WithQueues(tasks.AsynqueryQueues),
+		queue.WithRoutes(tasks.Routes),
+	}, queueOptions...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +96,9 @@ func (m *CallManager) Start() error {
 	onceMetrics.Do(registerMetrics)
 	m.queue.AddHandler(tasks.ForkliftUploadDone, m.HandleMerge)
 	m.queue.AddHandler(tasks.AsynqueryIncomingQuery, m.HandleQuery)
+	m.queue.AddHandler(tasks.ForkliftUploadFailed, m.HandleUploadFailure)
+	m.queue.AddDeadLetterHandler(tasks.AsynqueryIncomingQuery, m.HandleDeadQuery)
+	m.queue.AddDeadLetterHandler(tasks.ForkliftUploadDone, m.HandleDeadMerge)
 	return m.queue.ServeUntilShutdown()
 }
 
@@ -418,6 +427,133 @@ func (m *CallManager) HandleQuery(ctx context.Context, task *asynq.Task) error {
 	return nil
 }
 
+// HandleUploadFailure fails the query waiting for an upload that forklift has given up on.
+func (m *CallManager) HandleUploadFailure(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != tasks.ForkliftUploadFailed {
+		m.logger.Warn("cannot handle task", "type", task.Type())
+		return asynq.SkipRetry
+	}
+	var payload tasks.ForkliftUploadFailedPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		m.logger.Warn("message unmarshal failed", "err", err)
+		return asynq.SkipRetry
+	}
+	log := logging.TracedLogger(m.logger, payload)
+	log.Debug("task received")
+
+	return m.failPendingQuery(logging.AddToContext(ctx, log), queryParams{
+		uploadID: payload.UploadID, userID: int(payload.UserID),
+	}, payload.Error)
+}
+
+// HandleDeadQuery fails the query record once its task has run out of retries.
+func (m *CallManager) HandleDeadQuery(ctx context.Context, task *asynq.Task, taskErr error) {
+	var payload tasks.AsynqueryIncomingQueryPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		m.logger.Warn("message unmarshal failed", "err", err)
+		return
+	}
+	log := logging.TracedLogger(m.logger, payload)
+	err := m.failPendingQuery(logging.AddToContext(ctx, log), queryParams{
+		queryID: payload.QueryID, userID: payload.UserID,
+	}, fmt.Sprintf("query processing failed: %s", taskErr))
+	if err != nil {
+		log.Warn("failed to finalize dead query", "err", err)
+	}
+}
+
+// HandleDeadMerge fails the query for an upload once merging of its processing results has run out of retries.
+func (m *CallManager) HandleDeadMerge(ctx context.Context, task *asynq.Task, taskErr error) {
+	var payload tasks.ForkliftUploadDonePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		m.logger.Warn("message unmarshal failed", "err", err)
+		return
+	}
+	log := logging.TracedLogger(m.logger, payload)
+	err := m.failPendingQuery(logging.AddToContext(ctx, log), queryParams{
+		uploadID: payload.UploadID, userID: int(payload.UserID),
+	}, fmt.Sprintf("upload processing failed: %s", taskErr))
+	if err != nil {
+		log.Warn("failed to finalize dead query", "err", err)
+	}
+}
+
+// HandleRequeue reopens the query failed by a dead task that is about to be requeued,
+// so that the task is able to complete the query once it succeeds.
+func (m *CallManager) HandleRequeue(ctx context.Context, task *asynq.Task) error {
+	var params queryParams
+	switch task.Type() {
+	case tasks.AsynqueryIncomingQuery:
+		var payload tasks.AsynqueryIncomingQueryPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return fmt.Errorf("message unmarshal failed: %w", err)
+		}
+		params = queryParams{queryID: payload.QueryID, userID: payload.UserID}
+	case tasks.ForkliftUploadDone, tasks.ForkliftUploadIncoming, tasks.ForkliftURLIncoming:
+		var payload struct {
+			UploadID string `json:"upload_id"`
+			UserID   int32  `json:"user_id"`
+		}
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return fmt.Errorf("message unmarshal failed: %w", err)
+		}
+		params = queryParams{uploadID: payload.UploadID, userID: int(payload.UserID)}
+	default:
+		return nil
+	}
+	return m.reopenQuery(logging.AddToContext(ctx, m.logger), params)
+}
+
+// reopenQuery puts the latest matching query back to received status if it has failed.
+func (m *CallManager) reopenQuery(ctx context.Context, params queryParams) error {
+	log := logging.GetFromContext(ctx)
+	aq, err := m.getQueryRecord(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("no query found to reopen")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if aq.Status != models.AsynqueryStatusFailed {
+		return nil
+	}
+	aq.Status = models.AsynqueryStatusReceived
+	aq.Error = ""
+	aq.Response = null.JSON{}
+	aq.UpdatedAt = null.TimeFrom(time.Now())
+	_, err = aq.Update(m.db, boil.Whitelist(
+		models.AsynqueryColumns.Status,
+		models.AsynqueryColumns.Error,
+		models.AsynqueryColumns.Response,
+		models.AsynqueryColumns.UpdatedAt,
+	))
+	if err != nil {
+		InternalErrors.WithLabelValues(labelAreaDB).Inc()
+		return fmt.Errorf("error reopening async query record: %w", err)
+	}
+	log.Info("query reopened", "id", aq.ID)
+	return nil
+}
+
+// failPendingQuery marks the latest matching query as failed unless it has already been finalized.
+func (m *CallManager) failPendingQuery(ctx context.Context, params queryParams, reason string) error {
+	log := logging.GetFromContext(ctx)
+	aq, err := m.getQueryRecord(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("no pending query found")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if aq.Status != models.AsynqueryStatusReceived {
+		log.Debug("query already finalized", "id", aq.ID, "status", aq.Status)
+		return nil
+	}
+	QueriesFailed.Inc()
+	log.Info("failing query", "id", aq.ID, "reason", reason)
+	return m.finalizeQueryRecord(ctx, aq.ID, nil, reason)
+}
+
 func (m *CallManager) RetryDelay(n int, err error, t *asynq.Task) time.Duration {
 	d := 10 * time.Second
 	if errors.Is(err, sdkNetError) {
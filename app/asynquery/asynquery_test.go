@@ -1,16 +1,21 @@
 package asynquery
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/OdyseeTeam/odysee-api/app/query"
 	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
 	"github.com/OdyseeTeam/odysee-api/internal/e2etest"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
 	"github.com/OdyseeTeam/odysee-api/models"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 
 	"github.com/Pallinder/go-randomdata"
+	"github.com/hibiken/asynq"
 	"github.com/stretchr/testify/suite"
 	"github.com/ybbus/jsonrpc/v2"
 )
@@ -116,6 +121,24 @@ func (s *asynquerySuite) TestCall() {
 	s.Equal(params[FilePathParam], dparams[FilePathParam])
 }
 
+func (s *asynquerySuite) TestHandleRequeue() {
+	req := jsonrpc.NewRequest(query.MethodStreamCreate, map[string]any{"name": "requeue-test"})
+	aq, err := s.manager.Call(s.userHelper.UserID(), req)
+	s.Require().NoError(err)
+
+	payload, err := json.Marshal(tasks.AsynqueryIncomingQueryPayload{QueryID: aq.ID, UserID: s.userHelper.UserID()})
+	s.Require().NoError(err)
+	task := asynq.NewTask(tasks.AsynqueryIncomingQuery, payload)
+	s.manager.HandleDeadQuery(context.Background(), task, errors.New("sdk is down"))
+	s.Require().NoError(aq.Reload(s.userHelper.DB))
+	s.Equal(models.AsynqueryStatusFailed, aq.Status)
+
+	s.Require().NoError(s.manager.HandleRequeue(context.Background(), task))
+	s.Require().NoError(aq.Reload(s.userHelper.DB))
+	s.Equal(models.AsynqueryStatusReceived, aq.Status)
+	s.Empty(aq.Error)
+}
+
 func (s *asynquerySuite) SetupSuite() {
 	s.userHelper = &e2etest.UserTestHelper{}
 	s.Require().NoError(s.userHelper.Setup(s.T()))
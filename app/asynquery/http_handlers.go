@@ -31,15 +31,15 @@ const (
 type QueryHandler struct {
 	callManager      *CallManager
 	logger           logging.KVLogger
-	keyfob           *keybox.Keyfob
+	keyring          *keybox.Keyring
 	uploadServiceURL string
 }
 
-func NewHandler(callManager *CallManager, logger logging.KVLogger, keyfob *keybox.Keyfob, uploadServiceURL string) QueryHandler {
+func NewHandler(callManager *CallManager, logger logging.KVLogger, keyring *keybox.Keyring, uploadServiceURL string) QueryHandler {
 	return QueryHandler{
 		callManager:      callManager,
 		logger:           logger,
-		keyfob:           keyfob,
+		keyring:          keyring,
 		uploadServiceURL: uploadServiceURL,
 	}
 }
@@ -81,7 +81,7 @@ func (h QueryHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
-	token, err := h.keyfob.GenerateToken(int32(u.ID), time.Now().Add(48*time.Hour))
+	token, err := h.keyring.GenerateToken(int32(u.ID), time.Now().Add(48*time.Hour))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
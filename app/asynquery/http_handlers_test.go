@@ -2,6 +2,7 @@ package asynquery
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"database/sql"
 	"encoding/json"
@@ -49,6 +50,18 @@ func (s *asynqueryHandlerSuite) TestPublicKey() {
 	s.Require().IsType(&ecdsa.PublicKey{}, pubKey)
 }
 
+func (s *asynqueryHandlerSuite) TestJWKS() {
+	ts := httptest.NewServer(s.router)
+	defer ts.Close()
+
+	v, err := keybox.ValidatorFromJWKSURL(context.Background(), ts.URL+"/api/v1/asynqueries/auth/jwks", time.Hour)
+	s.Require().NoError(err)
+	token, err := s.launcher.keyring.GenerateToken(1, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	_, err = v.ParseToken(token)
+	s.Require().NoError(err)
+}
+
 func (s *asynqueryHandlerSuite) TestCreateUpload() {
 	ts := httptest.NewServer(s.router)
 	defer ts.Close()
@@ -186,12 +199,14 @@ func (s *asynqueryHandlerSuite) SetupSuite() {
 
 	kf, err := keybox.GenerateKeyfob()
 	s.Require().NoError(err)
+	kr, err := keybox.NewKeyringFromKeyfob(kf)
+	s.Require().NoError(err)
 
 	redisHelper := testdeps.NewRedisTestHelper(s.T())
 	s.launcher = NewLauncher(
 		WithRequestsConnOpts(redisHelper.AsynqOpts),
 		WithLogger(zapadapter.NewKV(nil)),
-		WithPrivateKey(kf.PrivateKey()),
+		WithKeyring(kr),
 		WithDB(s.userHelper.DB),
 		WithUploadServiceURL("https://uploads.odysee.com/v1/"),
 	)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
 
 	"github.com/gorilla/mux"
 	"github.com/hibiken/asynq"
@@ -20,8 +21,10 @@ type Launcher struct {
 	logger           logging.KVLogger
 	manager          *CallManager
 	privateKey       crypto.PrivateKey
+	keyring          *keybox.Keyring
 	readyCancel      context.CancelFunc
 	uploadServiceURL string
+	queueOptions     []func(*queue.Options)
 }
 
 type LauncherOption func(*Launcher)
@@ -32,6 +35,13 @@ func WithPrivateKey(privateKey crypto.PrivateKey) LauncherOption {
 	}
 }
 
+// WithKeyring sets keys upload tokens are signed with, it takes precedence over WithPrivateKey.
+func WithKeyring(keyring *keybox.Keyring) LauncherOption {
+	return func(l *Launcher) {
+		l.keyring = keyring
+	}
+}
+
 func WithDB(db *sql.DB) LauncherOption {
 	return func(l *Launcher) {
 		l.db = db
@@ -61,6 +71,13 @@ func WithUploadServiceURL(url string) LauncherOption {
 	}
 }
 
+// WithQueueOptions sets queue scheduling options, see queue.Config.
+func WithQueueOptions(options ...func(*queue.Options)) LauncherOption {
+	return func(l *Launcher) {
+		l.queueOptions = append(l.queueOptions, options...)
+	}
+}
+
 func NewLauncher(options ...LauncherOption) *Launcher {
 	launcher := &Launcher{
 		logger:           logging.NoopKVLogger{},
@@ -77,19 +94,27 @@ func (l *Launcher) InstallRoutes(r *mux.Router) error {
 	if l.requestsConnOpts == nil {
 		return errors.New("missing redis requests connection options")
 	}
-	keyfob, err := keybox.NewKeyfob(l.privateKey)
-	if err != nil {
-		return err
+	keyring := l.keyring
+	if keyring == nil {
+		keyfob, err := keybox.NewKeyfob(l.privateKey)
+		if err != nil {
+			return err
+		}
+		keyring, err = keybox.NewKeyringFromKeyfob(keyfob)
+		if err != nil {
+			return err
+		}
 	}
-	manager, err := NewCallManager(l.requestsConnOpts, l.db, l.logger)
+	manager, err := NewCallManager(l.requestsConnOpts, l.db, l.logger, l.queueOptions...)
 	if err != nil {
 		return err
 	}
 	l.manager = manager
-	handler := NewHandler(manager, l.logger, keyfob, l.uploadServiceURL)
+	handler := NewHandler(manager, l.logger, keyring, l.uploadServiceURL)
 	r = r.PathPrefix("/asynqueries").Subrouter()
 	r.PathPrefix("/").HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}).Methods(http.MethodOptions)
-	r.HandleFunc("/auth/pubkey", keyfob.PublicKeyHandler).Methods("GET")
+	r.HandleFunc("/auth/pubkey", keyring.PublicKeyHandler).Methods("GET")
+	r.HandleFunc("/auth/jwks", keyring.JWKSHandler).Methods("GET")
 	r.HandleFunc("/{type:(?:urls)|(?:uploads)}/", handler.CreateUpload).Methods("POST")
 	r.HandleFunc("/{id}", handler.Get).Methods("GET")
 	r.HandleFunc("/", handler.CreateQuery).Methods("POST")
@@ -97,6 +122,15 @@ func (l *Launcher) InstallRoutes(r *mux.Router) error {
 	return nil
 }
 
+// HandleRequeue reopens the query a dead task has failed before the task is requeued.
+// Routes must be installed first.
+func (l *Launcher) HandleRequeue(ctx context.Context, task *asynq.Task) error {
+	if l.manager == nil {
+		return errors.New("asynquery manager is not initialized")
+	}
+	return l.manager.HandleRequeue(ctx, task)
+}
+
 func (l *Launcher) Start() error {
 	err := l.manager.Start()
 	if err != nil {
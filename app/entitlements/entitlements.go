@@ -0,0 +1,216 @@
+// Package entitlements caches purchases, memberships and moderator status looked up in internal-apis.
+package entitlements
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/eko/gocache/lib/v4/cache"
+	"github.com/eko/gocache/lib/v4/store"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultTTL         = time.Minute
+	defaultNegativeTTL = 15 * time.Second
+
+	KindPurchase   = "purchase"
+	KindMembership = "membership"
+	KindModerator  = "moderator"
+)
+
+// ErrUnavailable wraps errors of lookups which internal-apis didn't answer.
+var ErrUnavailable = errors.New("entitlements are unavailable")
+
+// Unavailable marks err as a failure to reach internal-apis.
+func Unavailable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// Key identifies an entitlement of a user. ClaimID is empty for user-wide entitlements,
+// Variant distinguishes lookups of the same kind for the same claim.
+type Key struct {
+	Kind    string
+	UserID  int
+	ClaimID string
+	Variant string
+}
+
+func (k Key) String() string {
+	s := fmt.Sprintf("entitlements:%d:%s:%s", k.UserID, k.Kind, k.ClaimID)
+	if k.Variant != "" {
+		s += ":" + k.Variant
+	}
+	return s
+}
+
+func userTag(userID int) string {
+	return fmt.Sprintf("entitlements:%d", userID)
+}
+
+func claimTag(userID int, claimID string) string {
+	return fmt.Sprintf("entitlements:%d:%s", userID, claimID)
+}
+
+// Cache stores entitlements in a cache which can be shared between instances, so they can be invalidated
+// by a single call after internal-apis records a purchase. Concurrent lookups of the same entitlement
+// are collapsed into one. Entitlements which aren't granted are cached for a shorter time.
+type Cache struct {
+	store       cache.CacheInterface[any]
+	ttl         time.Duration
+	negativeTTL time.Duration
+	failOpen    map[string]bool
+
+	sf     singleflight.Group
+	logger logging.KVLogger
+}
+
+type Option func(c *Cache)
+
+// WithStore sets the cache entitlements are kept in, they are only looked up in internal-apis without it.
+func WithStore(s cache.CacheInterface[any]) Option {
+	return func(c *Cache) {
+		c.store = s
+	}
+}
+
+// WithTTL sets how long granted and not granted entitlements are cached, zero values keep defaults.
+func WithTTL(ttl, negativeTTL time.Duration) Option {
+	return func(c *Cache) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+		if negativeTTL > 0 {
+			c.negativeTTL = negativeTTL
+		}
+	}
+}
+
+// WithFailOpen sets access types which should be granted when their entitlements are unavailable.
+func WithFailOpen(accessTypes ...string) Option {
+	return func(c *Cache) {
+		for _, t := range accessTypes {
+			c.failOpen[t] = true
+		}
+	}
+}
+
+func WithLogger(logger logging.KVLogger) Option {
+	return func(c *Cache) {
+		c.logger = logger
+	}
+}
+
+func New(options ...Option) *Cache {
+	c := &Cache{
+		ttl:         defaultTTL,
+		negativeTTL: defaultNegativeTTL,
+		failOpen:    map[string]bool{},
+		logger:      logging.NoopKVLogger{},
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// FailOpen returns true if access of the type should be granted when entitlements are unavailable.
+func (c *Cache) FailOpen(accessType string) bool {
+	if c == nil {
+		return false
+	}
+	return c.failOpen[accessType]
+}
+
+// Lookup returns the entitlement, calling load if it's not cached. load reports whether the entitlement is granted.
+// Failed lookups aren't cached. A nil Cache calls load every time.
+func Lookup[T any](ctx context.Context, c *Cache, key Key, load func(context.Context) (T, bool, error)) (T, error) {
+	if c == nil {
+		v, _, err := load(ctx)
+		return v, err
+	}
+
+	var v T
+	if c.store != nil {
+		cached, err := c.store.Get(ctx, key.String())
+		if err == nil {
+			if err := decode(cached, &v); err == nil {
+				return v, nil
+			}
+			c.logger.Warn("invalid cached entitlement", "key", key.String(), "err", err)
+		} else if !errors.Is(err, &store.NotFound{}) {
+			c.logger.Warn("entitlement cache read failed", "key", key.String(), "err", err)
+		}
+	}
+
+	res, err, _ := c.sf.Do(key.String(), func() (any, error) {
+		v, granted, err := load(ctx)
+		if err != nil {
+			return v, err
+		}
+		c.set(ctx, key, v, granted)
+		return v, nil
+	})
+	if err != nil {
+		return v, err
+	}
+	return res.(T), nil
+}
+
+func (c *Cache) set(ctx context.Context, key Key, v any, granted bool) {
+	if c.store == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.logger.Warn("cannot encode entitlement", "key", key.String(), "err", err)
+		return
+	}
+	ttl := c.negativeTTL
+	if granted {
+		ttl = c.ttl
+	}
+	tags := []string{userTag(key.UserID)}
+	if key.ClaimID != "" {
+		tags = append(tags, claimTag(key.UserID, key.ClaimID))
+	}
+	// Tag sets must outlive every entry they list, whichever was set last.
+	err = c.store.Set(ctx, key.String(), string(data),
+		store.WithExpiration(ttl), store.WithTags(tags), store.WithTagsTTL(max(c.ttl, c.negativeTTL)))
+	if err != nil {
+		c.logger.Warn("entitlement cache write failed", "key", key.String(), "err", err)
+	}
+}
+
+// Invalidate removes cached entitlements of the user for the claim, or all of them if claimID is empty.
+func (c *Cache) Invalidate(ctx context.Context, userID int, claimID string) error {
+	if c == nil || c.store == nil {
+		return nil
+	}
+	tag := userTag(userID)
+	if claimID != "" {
+		tag = claimTag(userID, claimID)
+	}
+	return c.store.Invalidate(ctx, store.WithInvalidateTags([]string{tag}))
+}
+
+func decode(cached any, target any) error {
+	var data []byte
+	switch v := cached.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unexpected cached value type %T", cached)
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,208 @@
+package entitlements
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/sturdycache"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type purchase struct {
+	Type string `json:"type"`
+}
+
+// loader counts calls and returns p, a nil p is an entitlement which isn't granted.
+type loader struct {
+	calls atomic.Int32
+	p     *purchase
+	err   error
+	wait  chan struct{}
+}
+
+func (l *loader) load(context.Context) (*purchase, bool, error) {
+	l.calls.Add(1)
+	if l.wait != nil {
+		<-l.wait
+	}
+	return l.p, l.p != nil, l.err
+}
+
+func purchaseKey(userID int, claimID string) Key {
+	return Key{Kind: KindPurchase, UserID: userID, ClaimID: claimID}
+}
+
+func TestLookupCache(t *testing.T) {
+	store, master, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	c := New(WithStore(store), WithTTL(time.Minute, 10*time.Second))
+	ctx := context.Background()
+
+	granted := &loader{p: &purchase{Type: "rental"}}
+	for range 3 {
+		p, err := Lookup(ctx, c, purchaseKey(1, "claim1"), granted.load)
+		require.NoError(t, err)
+		assert.Equal(t, &purchase{Type: "rental"}, p)
+	}
+	assert.EqualValues(t, 1, granted.calls.Load())
+	assert.Equal(t, time.Minute, master.TTL(purchaseKey(1, "claim1").String()))
+
+	missing := &loader{}
+	for range 3 {
+		p, err := Lookup(ctx, c, purchaseKey(1, "claim2"), missing.load)
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	}
+	assert.EqualValues(t, 1, missing.calls.Load())
+	assert.Equal(t, 10*time.Second, master.TTL(purchaseKey(1, "claim2").String()))
+
+	master.FastForward(20 * time.Second)
+	_, err := Lookup(ctx, c, purchaseKey(1, "claim2"), missing.load)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, missing.calls.Load())
+
+	// Another instance sharing the store uses cached entitlements.
+	_, err = Lookup(ctx, New(WithStore(store)), purchaseKey(1, "claim1"), granted.load)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, granted.calls.Load())
+}
+
+func TestLookupErrors(t *testing.T) {
+	store, master, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	c := New(WithStore(store))
+	ctx := context.Background()
+
+	failing := &loader{err: Unavailable(errors.New("connection refused"))}
+	for range 2 {
+		_, err := Lookup(ctx, c, purchaseKey(1, "claim1"), failing.load)
+		require.ErrorIs(t, err, ErrUnavailable)
+		assert.EqualError(t, err, "entitlements are unavailable: connection refused")
+	}
+	assert.EqualValues(t, 2, failing.calls.Load())
+	assert.False(t, master.Exists(purchaseKey(1, "claim1").String()))
+
+	// Cache failures don't fail lookups.
+	master.SetError("server is down")
+	l := &loader{p: &purchase{}}
+	_, err := Lookup(ctx, c, purchaseKey(1, "claim1"), l.load)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, l.calls.Load())
+}
+
+func TestLookupCoalescing(t *testing.T) {
+	store, _, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	c := New(WithStore(store))
+	l := &loader{p: &purchase{Type: "purchase"}, wait: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := Lookup(context.Background(), c, purchaseKey(1, "claim1"), l.load)
+			assert.NoError(t, err)
+			assert.Equal(t, &purchase{Type: "purchase"}, p)
+		}()
+	}
+	require.Eventually(t, func() bool { return l.calls.Load() > 0 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(l.wait)
+	wg.Wait()
+	assert.EqualValues(t, 1, l.calls.Load())
+}
+
+func TestInvalidate(t *testing.T) {
+	store, master, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	c := New(WithStore(store))
+	ctx := context.Background()
+
+	keys := []Key{
+		purchaseKey(1, "claim1"),
+		{Kind: KindMembership, UserID: 1, ClaimID: "claim1", Variant: "Exclusive content"},
+		purchaseKey(1, "claim2"),
+		{Kind: KindModerator, UserID: 1},
+		purchaseKey(2, "claim1"),
+	}
+	l := &loader{p: &purchase{}}
+	for _, k := range keys {
+		_, err := Lookup(ctx, c, k, l.load)
+		require.NoError(t, err)
+		require.True(t, master.Exists(k.String()))
+	}
+
+	require.NoError(t, c.Invalidate(ctx, 1, "claim1"))
+	assert.False(t, master.Exists(keys[0].String()))
+	assert.False(t, master.Exists(keys[1].String()))
+	assert.True(t, master.Exists(keys[2].String()))
+	assert.True(t, master.Exists(keys[3].String()))
+
+	require.NoError(t, c.Invalidate(ctx, 1, ""))
+	assert.False(t, master.Exists(keys[2].String()))
+	assert.False(t, master.Exists(keys[3].String()))
+	assert.True(t, master.Exists(keys[4].String()))
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	l := &loader{p: &purchase{}}
+	for range 2 {
+		_, err := Lookup(context.Background(), c, purchaseKey(1, "claim1"), l.load)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, l.calls.Load())
+	assert.NoError(t, c.Invalidate(context.Background(), 1, ""))
+	assert.False(t, c.FailOpen("members-only"))
+}
+
+func TestFailOpen(t *testing.T) {
+	c := New(WithFailOpen("members-only", "rental"))
+	assert.True(t, c.FailOpen("members-only"))
+	assert.True(t, c.FailOpen("rental"))
+	assert.False(t, c.FailOpen("purchase"))
+	assert.False(t, New().FailOpen("members-only"))
+}
+
+func TestInvalidateHandler(t *testing.T) {
+	store, master, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	c := New(WithStore(store))
+	r := mux.NewRouter()
+	InstallRoutes(r, c)
+
+	key := purchaseKey(5, "claim1")
+	_, err := Lookup(context.Background(), c, key, (&loader{}).load)
+	require.NoError(t, err)
+	require.True(t, master.Exists(key.String()))
+
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"claim_id": "claim1"`, http.StatusBadRequest},
+		{`{"claim_id": "claim1"}`, http.StatusBadRequest},
+		{`{"user_id": 5, "claim_id": "claim1"}`, http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/invalidate", bytes.NewBufferString(tc.body)))
+		assert.Equal(t, tc.status, rr.Code, tc.body)
+	}
+	assert.False(t, master.Exists(key.String()))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/invalidate", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
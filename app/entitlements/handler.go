@@ -0,0 +1,44 @@
+package entitlements
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// InvalidateRequest is sent by internal-apis after it records a purchase or a membership change.
+// Entitlements of the user for the claim are invalidated, or all of them if ClaimID is empty.
+type InvalidateRequest struct {
+	UserID  int    `json:"user_id"`
+	ClaimID string `json:"claim_id"`
+}
+
+type handler struct {
+	cache *Cache
+}
+
+// InstallRoutes adds the invalidation webhook. Routes should be installed on a router protected
+// by an authentication middleware.
+func InstallRoutes(r *mux.Router, c *Cache) {
+	h := &handler{cache: c}
+	r.HandleFunc("/invalidate", h.invalidate).Methods(http.MethodPost)
+}
+
+func (h *handler) invalidate(w http.ResponseWriter, r *http.Request) {
+	var req InvalidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "cannot parse request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.UserID <= 0 {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.cache.Invalidate(r.Context(), req.UserID, req.ClaimID); err != nil {
+		h.cache.logger.Warn("entitlements invalidation failed", "user_id", req.UserID, "claim_id", req.ClaimID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -51,7 +51,7 @@ type UploadProcessResult struct {
 type Carriage struct {
 	blobsPath    string
 	analyzer     *fileanalyzer.Analyzer
-	store        *blobs.Store
+	store        blobs.Destination
 	resultWriter io.Writer
 	logger       logging.KVLogger
 }
@@ -66,13 +66,9 @@ func NewCarriage(blobsPath string, resultWriter io.Writer, reflectorConfig *vipe
 		logger = zapadapter.NewKV(nil)
 	}
 
-	destinations, err := blobs.CreateStoresFromConfig(reflectorConfig, "destinations")
+	store, err := blobs.NewDestinationFromConfig(reflectorConfig)
 	if err != nil {
-		return nil, fmt.Errorf("cannot initialize reflector destination stores: %w", err)
-	}
-	store, err := blobs.NewStore(reflectorConfig.GetString("databasedsn"), destinations)
-	if err != nil {
-		return nil, fmt.Errorf("cannot initialize reflector store: %w", err)
+		return nil, fmt.Errorf("cannot initialize blob destination: %w", err)
 	}
 
 	c := &Carriage{
@@ -136,7 +132,7 @@ func (c *Carriage) Process(p UploadProcessPayload) (*UploadProcessResult, error)
 	r := &UploadProcessResult{UploadID: p.UploadID, UserID: p.UserID}
 	log := c.logger.With("upload_id", p.UploadID, "user_id", p.UserID)
 
-	uploader := c.store.Uploader()
+	uploader := blobs.NewUploader(c.store, blobs.WithUploaderLogger(log))
 
 	t = time.Now()
 	info, err := c.analyzer.Analyze(context.Background(), p.Path, "")
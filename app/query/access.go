@@ -0,0 +1,428 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/app/auth"
+	"github.com/OdyseeTeam/odysee-api/app/entitlements"
+	"github.com/OdyseeTeam/odysee-api/internal/errors"
+	"github.com/OdyseeTeam/odysee-api/pkg/iapi"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+)
+
+// accessFact is a piece of data access rules base their decisions on.
+type accessFact string
+
+const (
+	factTime       accessFact = "time"
+	factPurchase   accessFact = "purchase"
+	factMembership accessFact = "membership"
+	factModerator  accessFact = "moderator"
+	factSignature  accessFact = "signature"
+)
+
+// Reasons are stable identifiers for access decisions, denial reasons are returned to clients.
+const (
+	reasonOwner              = "owner"
+	reasonModerator          = "moderator"
+	reasonPurchased          = "purchased"
+	reasonRented             = "rented"
+	reasonMember             = "member"
+	reasonSigned             = "signed"
+	reasonNotReleased        = "not_released"
+	reasonNotPurchased       = "not_purchased"
+	reasonWrongPurchaseType  = "wrong_purchase_type"
+	reasonRentalExpired      = "rental_expired"
+	reasonNotMember          = "not_member"
+	reasonMissingSignature   = "missing_signature"
+	reasonMissingSignatureTS = "missing_signature_ts"
+	reasonInvalidSignature   = "invalid_signature"
+	reasonFailOpen           = "entitlements_unavailable"
+)
+
+type accessEffect int
+
+const (
+	// accessAbstain passes the decision to the next rule.
+	accessAbstain accessEffect = iota
+	accessAllow
+	accessDeny
+)
+
+type accessDecision struct {
+	effect  accessEffect
+	rule    string
+	reason  string
+	message string
+}
+
+func allowAccess(reason string) accessDecision {
+	return accessDecision{effect: accessAllow, reason: reason}
+}
+
+func denyAccess(reason, message string) accessDecision {
+	return accessDecision{effect: accessDeny, reason: reason, message: message}
+}
+
+// AccessDeniedError explains to the client why a stream cannot be played.
+type AccessDeniedError struct {
+	Rule    string `json:"rule"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (e *AccessDeniedError) Error() string { return e.Message }
+
+// ErrorData is returned in the JSON-RPC error data.
+func (e *AccessDeniedError) ErrorData() any {
+	return map[string]any{"access_denied": e}
+}
+
+// accessRule grants or denies access to claims carrying any of its tags.
+type accessRule struct {
+	name        string
+	tags        []string
+	tagPrefixes []string
+	// applies narrows down the rule beyond tags, it is optional.
+	applies  func(r *accessRequest) (bool, error)
+	evaluate func(r *accessRequest) (accessDecision, error)
+}
+
+func (rule accessRule) matches(tag string) bool {
+	if sliceContains(rule.tags, tag) {
+		return true
+	}
+	for _, p := range rule.tagPrefixes {
+		if strings.HasPrefix(tag, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessExemptions are evaluated in order before the restricting rule, each either allows access or abstains.
+var accessExemptions = []accessRule{
+	{
+		name: "owner",
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			if r.claim.IsMyOutput {
+				return allowAccess(reasonOwner), nil
+			}
+			return accessDecision{}, nil
+		},
+	},
+	{
+		name: "moderator",
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			if r.isModerator() {
+				return allowAccess(reasonModerator), nil
+			}
+			return accessDecision{}, nil
+		},
+	},
+}
+
+// accessRules restrict access to claims, the rule matching the first of claim tags decides.
+var accessRules = []accessRule{
+	{
+		name: "scheduled",
+		tags: []string{ClaimTagScheduledHide, ClaimTagScheduledShow},
+		applies: func(r *accessRequest) (bool, error) {
+			return r.claim.Value.GetStream().ReleaseTime > r.now().Unix(), nil
+		},
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			return denyAccess(reasonNotReleased, "claim release time is in the future, not ready to be viewed yet"), nil
+		},
+	},
+	{
+		name:        "purchase",
+		tags:        []string{"c:purchase"},
+		tagPrefixes: []string{"purchase:"},
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			p, err := r.purchase()
+			if err != nil {
+				return accessDecision{}, err
+			}
+			if p == nil {
+				return denyAccess(reasonNotPurchased, "no access to paid content"), nil
+			}
+			// Any purchase grants access, rental terms are only enforced for claims tagged for rental.
+			return allowAccess(reasonPurchased), nil
+		},
+	},
+	{
+		name:        "rental",
+		tags:        []string{"c:rental"},
+		tagPrefixes: []string{"rental:"},
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			p, err := r.purchase()
+			if err != nil {
+				return accessDecision{}, err
+			}
+			if p == nil {
+				return denyAccess(reasonNotPurchased, "no access to paid content"), nil
+			}
+			if p.Type != "rental" {
+				return denyAccess(reasonWrongPurchaseType, "incorrect purchase type"), nil
+			}
+			if r.now().After(p.ValidThrough) {
+				return denyAccess(reasonRentalExpired, "rental expired"), nil
+			}
+			return allowAccess(reasonRented), nil
+		},
+	},
+	{
+		name: "members-only",
+		tags: []string{"c:members-only"},
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			ok, err := r.membership()
+			if err != nil {
+				return accessDecision{}, err
+			}
+			if !ok {
+				return denyAccess(reasonNotMember, "no access to members-only content"), nil
+			}
+			return allowAccess(reasonMember), nil
+		},
+	},
+	{
+		name: "unlisted",
+		tags: []string{ClaimTagUnlisted},
+		evaluate: func(r *accessRequest) (accessDecision, error) {
+			if _, ok := r.params["signature"]; !ok {
+				return denyAccess(reasonMissingSignature, "missing required signature param"), nil
+			}
+			if _, ok := r.params["signature_ts"]; !ok {
+				return denyAccess(reasonMissingSignatureTS, "missing required signature_ts param"), nil
+			}
+			if err := r.signature(); err != nil {
+				return denyAccess(reasonInvalidSignature, err.Error()), nil
+			}
+			return allowAccess(reasonSigned), nil
+		},
+	},
+}
+
+// accessFactSource looks up facts for access rules.
+type accessFactSource struct {
+	purchase   func(ctx context.Context, claimID, environ string) (*CustomerPurchase, error)
+	membership func(ctx context.Context, claimID, perkType, environ string) (bool, error)
+	moderator  func(ctx context.Context, environ string) bool
+	signature  func(claim *ljsonrpc.Claim, signature, signatureTS string) error
+	now        func() time.Time
+	// failOpen reports whether rules should grant access when facts they need are unavailable.
+	failOpen func(rule string) bool
+}
+
+var defaultAccessFacts = accessFactSource{
+	purchase:   lookupPurchase,
+	membership: lookupMembership,
+	moderator:  isUserAMod,
+	signature: func(claim *ljsonrpc.Claim, signature, signatureTS string) error {
+		return ValidateSignatureFromClaim(claim, signature, signatureTS, claim.ClaimID)
+	},
+	now:      func() time.Time { return timeSource.Now() },
+	failOpen: func(rule string) bool { return EntitlementCache().FailOpen(rule) },
+}
+
+// memo holds a lazily looked up value.
+type memo[T any] struct {
+	done  bool
+	value T
+	err   error
+}
+
+func (m *memo[T]) get(load func() (T, error)) (T, error) {
+	if !m.done {
+		m.value, m.err = load()
+		m.done = true
+	}
+	return m.value, m.err
+}
+
+// accessRequest is a single stream access check, facts are looked up at most once.
+type accessRequest struct {
+	ctx          context.Context
+	claim        *ljsonrpc.Claim
+	params       map[string]any
+	environ      string
+	isLivestream bool
+
+	source accessFactSource
+	// loaded records facts looked up so far.
+	loaded []accessFact
+	facts  struct {
+		now        memo[time.Time]
+		purchase   memo[*CustomerPurchase]
+		membership memo[bool]
+		moderator  memo[bool]
+		signature  memo[struct{}]
+	}
+}
+
+func newAccessRequest(ctx context.Context, claim *ljsonrpc.Claim, params map[string]any, source accessFactSource) *accessRequest {
+	r := &accessRequest{ctx: ctx, claim: claim, params: params, source: source}
+	_, r.isLivestream = params["base_streaming_url"]
+	if p, ok := params[iapi.ParamEnviron]; ok {
+		r.environ, _ = p.(string)
+	}
+	return r
+}
+
+func (r *accessRequest) record(f accessFact) {
+	if !sliceContains(r.loaded, f) {
+		r.loaded = append(r.loaded, f)
+	}
+}
+
+func (r *accessRequest) now() time.Time {
+	t, _ := r.facts.now.get(func() (time.Time, error) {
+		r.record(factTime)
+		return r.source.now(), nil
+	})
+	return t
+}
+
+func (r *accessRequest) purchase() (*CustomerPurchase, error) {
+	return r.facts.purchase.get(func() (*CustomerPurchase, error) {
+		r.record(factPurchase)
+		return r.source.purchase(r.ctx, r.claim.ClaimID, r.environ)
+	})
+}
+
+func (r *accessRequest) membership() (bool, error) {
+	return r.facts.membership.get(func() (bool, error) {
+		r.record(factMembership)
+		perkType := iapiTypeMembershipVod
+		if r.isLivestream {
+			perkType = iapiTypeMembershipLiveStream
+		}
+		return r.source.membership(r.ctx, r.claim.ClaimID, perkType, r.environ)
+	})
+}
+
+func (r *accessRequest) isModerator() bool {
+	ok, _ := r.facts.moderator.get(func() (bool, error) {
+		r.record(factModerator)
+		return r.source.moderator(r.ctx, r.environ), nil
+	})
+	return ok
+}
+
+// signature validates signature params, which must be present.
+func (r *accessRequest) signature() error {
+	_, err := r.facts.signature.get(func() (struct{}, error) {
+		r.record(factSignature)
+		signature, _ := r.params["signature"].(string)
+		signatureTS, _ := r.params["signature_ts"].(string)
+		return struct{}{}, r.source.signature(r.claim, signature, signatureTS)
+	})
+	return err
+}
+
+// ruleForTag returns the rule restricting access to claims carrying the tag, if any.
+func ruleForTag(tag string) *accessRule {
+	for i := range accessRules {
+		if accessRules[i].matches(tag) {
+			return &accessRules[i]
+		}
+	}
+	return nil
+}
+
+// evaluate finds the rule matching the first of claim tags which restricts access,
+// and lets exemptions and then the rule decide. A nil rule is returned for unrestricted claims.
+func (r *accessRequest) evaluate() (*accessRule, accessDecision, error) {
+	for _, tag := range r.claim.Value.Tags {
+		rule := ruleForTag(tag)
+		if rule == nil {
+			continue
+		}
+		if rule.applies != nil {
+			ok, err := rule.applies(r)
+			if err != nil {
+				return rule, accessDecision{}, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		for _, ex := range accessExemptions {
+			d, err := ex.evaluate(r)
+			if err != nil {
+				return rule, accessDecision{}, err
+			}
+			if d.effect != accessAbstain {
+				d.rule = ex.name
+				return rule, d, nil
+			}
+		}
+		d, err := rule.evaluate(r)
+		if errors.Is(err, entitlements.ErrUnavailable) && r.source.failOpen != nil && r.source.failOpen(rule.name) {
+			d, err = allowAccess(reasonFailOpen), nil
+		}
+		if err != nil {
+			return rule, accessDecision{}, err
+		}
+		d.rule = rule.name
+		return rule, d, nil
+	}
+	return nil, allowAccess(""), nil
+}
+
+// checkStreamAccess evaluates access rules for the claim. Access to restricted claims is granted
+// along with errNeedSignedUrl or errNeedSignedLivestreamUrl, denials are returned as AccessDeniedError.
+func checkStreamAccess(ctx context.Context, claim *ljsonrpc.Claim) (bool, error) {
+	r := newAccessRequest(ctx, claim, QueryFromContext(ctx).ParamsAsMap(), defaultAccessFacts)
+	logger := logging.GetFromContext(ctx).With("claim_id", claim.CanonicalURL)
+
+	rule, d, err := r.evaluate()
+	if rule == nil {
+		return true, nil
+	}
+	logger = logger.With("access_rule", rule.name, "facts", r.loaded)
+	if err != nil {
+		logger.Info("access check failed", "err", err)
+		return false, err
+	}
+	if d.effect != accessAllow {
+		logger.Info("access denied", "decided_by", d.rule, "reason", d.reason)
+		return false, &AccessDeniedError{Rule: d.rule, Reason: d.reason, Message: d.message}
+	}
+	if d.reason == reasonFailOpen {
+		logger.Warn("access granted without entitlements", "decided_by", d.rule)
+	} else {
+		logger.Debug("access granted", "decided_by", d.rule, "reason", d.reason)
+	}
+	if r.isLivestream {
+		return true, errNeedSignedLivestreamUrl
+	}
+	return true, errNeedSignedUrl
+}
+
+// checkMembershipPerk checks if the user has a membership perk for the claim.
+func checkMembershipPerk(ctx context.Context, claimID, perkType, environ string) (bool, error) {
+	cu, err := auth.GetCurrentUserData(ctx)
+	if err != nil {
+		return false, errors.Err("no user data in context: %w", err)
+	}
+
+	iac := cu.IAPIClient()
+	if iac == nil {
+		return false, errors.Err("authentication required")
+	}
+	if environ == iapi.EnvironTest {
+		iac = iac.Clone(iapi.WithEnvironment(iapi.EnvironTest))
+	}
+
+	resp := &iapi.MembershipPerkCheck{}
+	err = iac.Call(ctx, "membership_perk/check", map[string]string{"claim_id": claimID, "type": perkType}, resp)
+	if err != nil {
+		return false, iapiError(err)
+	}
+	return resp.Data.HasAccess, nil
+}
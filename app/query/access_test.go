@@ -0,0 +1,409 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/app/entitlements"
+	"github.com/OdyseeTeam/odysee-api/internal/errors"
+	"github.com/OdyseeTeam/odysee-api/pkg/iapi"
+	"github.com/OdyseeTeam/odysee-api/pkg/rpcerrors"
+
+	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+	pb "github.com/lbryio/types/v2/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc/v2"
+)
+
+var accessTestNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeAccessFacts struct {
+	purchase     *CustomerPurchase
+	purchaseErr  error
+	member       bool
+	memberErr    error
+	moderator    bool
+	signatureErr error
+	failOpen     []string
+
+	perkType string
+	lookups  map[accessFact]int
+}
+
+func (f *fakeAccessFacts) source() accessFactSource {
+	f.lookups = map[accessFact]int{}
+	return accessFactSource{
+		purchase: func(context.Context, string, string) (*CustomerPurchase, error) {
+			f.lookups[factPurchase]++
+			return f.purchase, f.purchaseErr
+		},
+		membership: func(_ context.Context, _, perkType, _ string) (bool, error) {
+			f.lookups[factMembership]++
+			f.perkType = perkType
+			return f.member, f.memberErr
+		},
+		moderator: func(context.Context, string) bool {
+			f.lookups[factModerator]++
+			return f.moderator
+		},
+		signature: func(*ljsonrpc.Claim, string, string) error {
+			f.lookups[factSignature]++
+			return f.signatureErr
+		},
+		now: func() time.Time {
+			f.lookups[factTime]++
+			return accessTestNow
+		},
+		failOpen: func(rule string) bool {
+			return sliceContains(f.failOpen, rule)
+		},
+	}
+}
+
+func testClaim(releaseTime time.Time, tags ...string) *ljsonrpc.Claim {
+	c := &ljsonrpc.Claim{ClaimID: "6769855a9aa43b67086f9ff3c1a5bacb5698a27a", CanonicalURL: "lbry://@test#1/test#2"}
+	c.Value.Tags = tags
+	c.Value.Type = &pb.Claim_Stream{Stream: &pb.Stream{ReleaseTime: releaseTime.Unix()}}
+	return c
+}
+
+type accessCase struct {
+	name   string
+	claim  *ljsonrpc.Claim
+	params map[string]any
+	facts  fakeAccessFacts
+
+	rule    string
+	allow   bool
+	reason  string
+	message string
+	err     string
+}
+
+// runAccessCases evaluates cases, checking that the rule decides and looks up each fact at most once.
+func runAccessCases(t *testing.T, rule string, cases []accessCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := tc.params
+			if params == nil {
+				params = map[string]any{}
+			}
+			r := newAccessRequest(context.Background(), tc.claim, params, tc.facts.source())
+			matched, d, err := r.evaluate()
+			require.NotNil(t, matched)
+			assert.Equal(t, rule, matched.name)
+
+			for f, n := range tc.facts.lookups {
+				assert.Equal(t, 1, n, "fact %s looked up more than once", f)
+			}
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			expRule := tc.rule
+			if expRule == "" {
+				expRule = rule
+			}
+			assert.Equal(t, expRule, d.rule)
+			assert.Equal(t, tc.allow, d.effect == accessAllow)
+			assert.Equal(t, tc.reason, d.reason)
+			assert.Equal(t, tc.message, d.message)
+		})
+	}
+}
+
+func TestAccessRulesOrder(t *testing.T) {
+	facts := fakeAccessFacts{}
+	r := newAccessRequest(context.Background(), testClaim(accessTestNow, "art", "c:private"), map[string]any{}, facts.source())
+	matched, d, err := r.evaluate()
+	require.NoError(t, err)
+	assert.Nil(t, matched)
+	assert.Equal(t, accessAllow, d.effect)
+	assert.Empty(t, facts.lookups)
+
+	runAccessCases(t, "unlisted", []accessCase{
+		{
+			name:    "first tag decides",
+			claim:   testClaim(accessTestNow, "art", ClaimTagUnlisted, "c:members-only", "c:purchase"),
+			reason:  reasonMissingSignature,
+			message: "missing required signature param",
+		},
+	})
+	runAccessCases(t, "purchase", []accessCase{
+		{
+			name:    "first tag decides",
+			claim:   testClaim(accessTestNow, "c:purchase", ClaimTagUnlisted, "c:members-only"),
+			reason:  reasonNotPurchased,
+			message: "no access to paid content",
+		},
+		{
+			name:    "released scheduled claim falls through",
+			claim:   testClaim(accessTestNow.Add(-time.Minute), ClaimTagScheduledHide, "purchase:1.0"),
+			reason:  reasonNotPurchased,
+			message: "no access to paid content",
+		},
+	})
+}
+
+func TestAccessExemptions(t *testing.T) {
+	owned := testClaim(accessTestNow.Add(time.Hour), ClaimTagScheduledShow)
+	owned.IsMyOutput = true
+	runAccessCases(t, "scheduled", []accessCase{
+		{
+			name:   "owner",
+			claim:  owned,
+			facts:  fakeAccessFacts{moderator: true},
+			rule:   "owner",
+			allow:  true,
+			reason: reasonOwner,
+		},
+		{
+			name:   "moderator",
+			claim:  testClaim(accessTestNow.Add(time.Hour), ClaimTagScheduledShow),
+			facts:  fakeAccessFacts{moderator: true},
+			rule:   "moderator",
+			allow:  true,
+			reason: reasonModerator,
+		},
+	})
+	runAccessCases(t, "members-only", []accessCase{
+		{
+			name:   "moderator skips membership lookup",
+			claim:  testClaim(accessTestNow, "c:members-only"),
+			facts:  fakeAccessFacts{moderator: true, memberErr: errors.Err("should not be called")},
+			rule:   "moderator",
+			allow:  true,
+			reason: reasonModerator,
+		},
+	})
+}
+
+func TestAccessRuleScheduled(t *testing.T) {
+	runAccessCases(t, "scheduled", []accessCase{
+		{
+			name:    "hidden until release",
+			claim:   testClaim(accessTestNow.Add(time.Second), ClaimTagScheduledHide),
+			reason:  reasonNotReleased,
+			message: "claim release time is in the future, not ready to be viewed yet",
+		},
+		{
+			name:    "shown until release",
+			claim:   testClaim(accessTestNow.Add(time.Hour), ClaimTagScheduledShow, "c:purchase"),
+			facts:   fakeAccessFacts{purchase: &CustomerPurchase{Type: "purchase"}},
+			reason:  reasonNotReleased,
+			message: "claim release time is in the future, not ready to be viewed yet",
+		},
+	})
+}
+
+func TestAccessRulePurchase(t *testing.T) {
+	runAccessCases(t, "purchase", []accessCase{
+		{
+			name:    "not purchased",
+			claim:   testClaim(accessTestNow, "purchase:2.5"),
+			reason:  reasonNotPurchased,
+			message: "no access to paid content",
+		},
+		{
+			name:   "purchased",
+			claim:  testClaim(accessTestNow, "c:purchase"),
+			facts:  fakeAccessFacts{purchase: &CustomerPurchase{Status: "confirmed", Type: "purchase"}},
+			allow:  true,
+			reason: reasonPurchased,
+		},
+		{
+			name:   "active rental",
+			claim:  testClaim(accessTestNow, "c:purchase", "c:rental"),
+			facts:  fakeAccessFacts{purchase: &CustomerPurchase{Type: "rental", ValidThrough: accessTestNow.Add(time.Hour)}},
+			allow:  true,
+			reason: reasonPurchased,
+		},
+		{
+			name:   "expired rental",
+			claim:  testClaim(accessTestNow, "c:purchase", "c:rental"),
+			facts:  fakeAccessFacts{purchase: &CustomerPurchase{Type: "rental", ValidThrough: accessTestNow.Add(-time.Hour)}},
+			allow:  true,
+			reason: reasonPurchased,
+		},
+		{
+			name:  "lookup error",
+			claim: testClaim(accessTestNow, "c:purchase"),
+			facts: fakeAccessFacts{purchaseErr: errors.Err("authentication required")},
+			err:   "authentication required",
+		},
+	})
+}
+
+func TestAccessRuleRental(t *testing.T) {
+	runAccessCases(t, "rental", []accessCase{
+		{
+			name:    "not rented",
+			claim:   testClaim(accessTestNow, "rental:1.0:3600"),
+			reason:  reasonNotPurchased,
+			message: "no access to paid content",
+		},
+		{
+			name:    "purchased instead",
+			claim:   testClaim(accessTestNow, "c:rental"),
+			facts:   fakeAccessFacts{purchase: &CustomerPurchase{Type: "purchase"}},
+			reason:  reasonWrongPurchaseType,
+			message: "incorrect purchase type",
+		},
+		{
+			name:    "expired",
+			claim:   testClaim(accessTestNow, "c:rental"),
+			facts:   fakeAccessFacts{purchase: &CustomerPurchase{Type: "rental", ValidThrough: accessTestNow.Add(-time.Second)}},
+			reason:  reasonRentalExpired,
+			message: "rental expired",
+		},
+		{
+			name:   "active",
+			claim:  testClaim(accessTestNow, "c:rental"),
+			facts:  fakeAccessFacts{purchase: &CustomerPurchase{Type: "rental", ValidThrough: accessTestNow.Add(time.Second)}},
+			allow:  true,
+			reason: reasonRented,
+		},
+		{
+			name:  "lookup error",
+			claim: testClaim(accessTestNow, "c:rental"),
+			facts: fakeAccessFacts{purchaseErr: errors.Err("authentication required")},
+			err:   "authentication required",
+		},
+	})
+}
+
+func TestAccessRuleMembersOnly(t *testing.T) {
+	runAccessCases(t, "members-only", []accessCase{
+		{
+			name:    "not a member",
+			claim:   testClaim(accessTestNow, "c:members-only"),
+			reason:  reasonNotMember,
+			message: "no access to members-only content",
+		},
+		{
+			name:   "member",
+			claim:  testClaim(accessTestNow, "c:members-only", ClaimTagUnlisted),
+			facts:  fakeAccessFacts{member: true},
+			allow:  true,
+			reason: reasonMember,
+		},
+		{
+			name:  "lookup error",
+			claim: testClaim(accessTestNow, "c:members-only"),
+			facts: fakeAccessFacts{memberErr: errors.Err("authentication required")},
+			err:   "authentication required",
+		},
+	})
+
+	for live, perkType := range map[bool]string{false: iapiTypeMembershipVod, true: iapiTypeMembershipLiveStream} {
+		facts := fakeAccessFacts{member: true}
+		p := map[string]any{}
+		if live {
+			p["base_streaming_url"] = "https://cloud.odysee.live/content/abc/master.m3u8"
+		}
+		r := newAccessRequest(context.Background(), testClaim(accessTestNow, "c:members-only"), p, facts.source())
+		_, _, err := r.evaluate()
+		require.NoError(t, err)
+		assert.Equal(t, perkType, facts.perkType)
+	}
+}
+
+func TestAccessRuleUnlisted(t *testing.T) {
+	signed := map[string]any{"signature": "abcd", "signature_ts": "1700000000"}
+	runAccessCases(t, "unlisted", []accessCase{
+		{
+			name:    "missing signature",
+			claim:   testClaim(accessTestNow, ClaimTagUnlisted),
+			params:  map[string]any{"signature_ts": "1700000000"},
+			reason:  reasonMissingSignature,
+			message: "missing required signature param",
+		},
+		{
+			name:    "missing signature_ts",
+			claim:   testClaim(accessTestNow, ClaimTagUnlisted),
+			params:  map[string]any{"signature": "abcd"},
+			reason:  reasonMissingSignatureTS,
+			message: "missing required signature_ts param",
+		},
+		{
+			name:    "invalid signature",
+			claim:   testClaim(accessTestNow, ClaimTagUnlisted),
+			params:  signed,
+			facts:   fakeAccessFacts{signatureErr: errors.Err("could not validate the signature")},
+			reason:  reasonInvalidSignature,
+			message: "could not validate the signature",
+		},
+		{
+			name:   "signed",
+			claim:  testClaim(accessTestNow, ClaimTagUnlisted),
+			params: signed,
+			allow:  true,
+			reason: reasonSigned,
+		},
+	})
+}
+
+func TestAccessFailOpen(t *testing.T) {
+	unavailable := entitlements.Unavailable(errors.Err("server returned non-OK status: 502"))
+	runAccessCases(t, "members-only", []accessCase{
+		{
+			name:   "fail open",
+			claim:  testClaim(accessTestNow, "c:members-only"),
+			facts:  fakeAccessFacts{memberErr: unavailable, failOpen: []string{"members-only"}},
+			allow:  true,
+			reason: reasonFailOpen,
+		},
+		{
+			name:  "fail closed",
+			claim: testClaim(accessTestNow, "c:members-only"),
+			facts: fakeAccessFacts{memberErr: unavailable, failOpen: []string{"purchase"}},
+			err:   "entitlements are unavailable: server returned non-OK status: 502",
+		},
+		{
+			name:  "internal-apis errors",
+			claim: testClaim(accessTestNow, "c:members-only"),
+			facts: fakeAccessFacts{memberErr: iapiError(fmt.Errorf("%w: %s", iapi.APIError, "user not found")), failOpen: []string{"members-only"}},
+			err:   "internal-api error: user not found",
+		},
+	})
+	runAccessCases(t, "rental", []accessCase{
+		{
+			name:   "fail open",
+			claim:  testClaim(accessTestNow, "c:rental"),
+			facts:  fakeAccessFacts{purchaseErr: unavailable, failOpen: []string{"rental"}},
+			allow:  true,
+			reason: reasonFailOpen,
+		},
+	})
+}
+
+func TestAccessFactsCached(t *testing.T) {
+	facts := fakeAccessFacts{purchaseErr: errors.Err("authentication required")}
+	r := newAccessRequest(context.Background(), testClaim(accessTestNow), map[string]any{}, facts.source())
+	for range 3 {
+		_, err := r.purchase()
+		require.EqualError(t, err, "authentication required")
+		r.now()
+		r.isModerator()
+	}
+	assert.Equal(t, map[accessFact]int{factPurchase: 1, factTime: 1, factModerator: 1}, facts.lookups)
+	assert.Equal(t, []accessFact{factPurchase, factTime, factModerator}, r.loaded)
+}
+
+func TestAccessDeniedErrorData(t *testing.T) {
+	err := rpcerrors.NewSDKError(&AccessDeniedError{Rule: "rental", Reason: reasonRentalExpired, Message: "rental expired"})
+
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(rpcerrors.ToJSON(err), &res))
+	assert.Equal(t, "rental expired", res.Error.Message)
+	assert.Equal(t, map[string]any{
+		"access_denied": map[string]any{"rule": "rental", "reason": "rental_expired", "message": "rental expired"},
+	}, res.Error.Data)
+}
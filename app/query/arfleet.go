@@ -0,0 +1,42 @@
+package query
+
+import (
+	"sync/atomic"
+
+	"github.com/OdyseeTeam/odysee-api/app/arweave"
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+
+	"github.com/eko/gocache/lib/v4/cache"
+)
+
+var arfleetResolver atomic.Pointer[arweave.ArfleetResolver]
+
+// NewArfleetResolver creates a resolver for Arfleet hooks from config, caching lookups in baseCache if it's not nil.
+func NewArfleetResolver(baseCache cache.CacheInterface[any]) *arweave.ArfleetResolver {
+	cfg := config.GetArfleetConfig()
+	options := []arweave.ResolverOption{
+		arweave.WithEndpoints(cfg.BatchResolverURL, cfg.BatchClaimResolverURL),
+		arweave.WithTimeout(cfg.Timeout),
+		arweave.WithBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		arweave.WithLogger(zapadapter.NewKV(nil).With("module", "arweave")),
+	}
+	if baseCache != nil {
+		options = append(options, arweave.WithCache(baseCache, cfg.CacheTTL, cfg.NegativeCacheTTL))
+	}
+	return arweave.NewArfleetResolver(config.GetArfleetCDN(), options...)
+}
+
+// SetArfleetResolver sets the resolver shared by Arfleet hooks of all callers.
+func SetArfleetResolver(r *arweave.ArfleetResolver) {
+	arfleetResolver.Store(r)
+}
+
+// getArfleetResolver returns the resolver set by SetArfleetResolver, creating an uncached one if it wasn't set.
+func getArfleetResolver() *arweave.ArfleetResolver {
+	if r := arfleetResolver.Load(); r != nil {
+		return r
+	}
+	arfleetResolver.CompareAndSwap(nil, NewArfleetResolver(nil))
+	return arfleetResolver.Load()
+}
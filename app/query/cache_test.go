@@ -2,8 +2,12 @@ package query
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/OdyseeTeam/odysee-api/pkg/chainquery"
+	"github.com/OdyseeTeam/odysee-api/pkg/sturdycache"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/ybbus/jsonrpc/v2"
@@ -62,3 +66,30 @@ func TestCachedResponseMarshal(t *testing.T) {
 	require.NoError(err)
 	assert.Equal(r, r2)
 }
+
+func TestQueryCacheInvalidator(t *testing.T) {
+	cq := chainquery.NewFakeServer(t)
+	cq.SetHeight(1500100)
+	origClient := chainquery.Default()
+	chainquery.SetDefault(cq.Client())
+	defer chainquery.SetDefault(origClient)
+
+	store, _, _, teardown := sturdycache.CreateTestCache(t)
+	defer teardown()
+	qc, err := NewQueryCacheWithInvalidator(store)
+	require.NoError(t, err)
+	defer close(qc.stopChan)
+	assert.Equal(t, 1500100, qc.height)
+
+	require.NoError(t, qc.runInvalidator())
+	assert.Equal(t, 1500100, qc.height)
+
+	cq.SetHeight(1500101)
+	require.NoError(t, qc.runInvalidator())
+	assert.Equal(t, 1500101, qc.height)
+
+	cq.SetHeight(1500102)
+	cq.FailNext(3, http.StatusBadGateway)
+	require.ErrorContains(t, qc.runInvalidator(), "failed to get current height")
+	assert.Equal(t, 1500101, qc.height)
+}
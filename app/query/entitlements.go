@@ -0,0 +1,102 @@
+package query
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/OdyseeTeam/odysee-api/app/auth"
+	"github.com/OdyseeTeam/odysee-api/app/entitlements"
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/internal/errors"
+	"github.com/OdyseeTeam/odysee-api/pkg/iapi"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+
+	"github.com/eko/gocache/lib/v4/cache"
+)
+
+var entitlementCache atomic.Pointer[entitlements.Cache]
+
+// NewEntitlementCache creates a cache for access rule lookups from config, keeping entitlements in baseCache
+// if it's not nil.
+func NewEntitlementCache(baseCache cache.CacheInterface[any]) *entitlements.Cache {
+	cfg := config.GetEntitlementsConfig()
+	options := []entitlements.Option{
+		entitlements.WithTTL(cfg.TTL, cfg.NegativeTTL),
+		entitlements.WithFailOpen(cfg.FailOpen...),
+		entitlements.WithLogger(zapadapter.NewKV(nil).With("module", "entitlements")),
+	}
+	if baseCache != nil {
+		options = append(options, entitlements.WithStore(baseCache))
+	}
+	return entitlements.New(options...)
+}
+
+// SetEntitlementCache sets the cache shared by access checks of all callers.
+func SetEntitlementCache(c *entitlements.Cache) {
+	entitlementCache.Store(c)
+}
+
+// EntitlementCache returns the cache set by SetEntitlementCache, entitlements are looked up
+// in internal-apis every time if it wasn't set.
+func EntitlementCache() *entitlements.Cache {
+	return entitlementCache.Load()
+}
+
+// currentUserID returns ID of the authenticated user, zero if there's none.
+func currentUserID(ctx context.Context) int {
+	cu, err := auth.GetCurrentUserData(ctx)
+	if err != nil || cu.User() == nil {
+		return 0
+	}
+	return cu.User().ID
+}
+
+// lookupPurchase is checkCustomerListPurchase going through the entitlement cache.
+func lookupPurchase(ctx context.Context, claimID, environ string) (*CustomerPurchase, error) {
+	userID := currentUserID(ctx)
+	if userID == 0 {
+		return checkCustomerListPurchase(ctx, claimID, environ)
+	}
+	key := entitlements.Key{Kind: entitlements.KindPurchase, UserID: userID, ClaimID: claimID, Variant: environ}
+	return entitlements.Lookup(ctx, EntitlementCache(), key, func(ctx context.Context) (*CustomerPurchase, bool, error) {
+		p, err := checkCustomerListPurchase(ctx, claimID, environ)
+		return p, p != nil, err
+	})
+}
+
+// lookupMembership is checkMembershipPerk going through the entitlement cache.
+func lookupMembership(ctx context.Context, claimID, perkType, environ string) (bool, error) {
+	userID := currentUserID(ctx)
+	if userID == 0 {
+		return checkMembershipPerk(ctx, claimID, perkType, environ)
+	}
+	key := entitlements.Key{Kind: entitlements.KindMembership, UserID: userID, ClaimID: claimID, Variant: perkType + ":" + environ}
+	return entitlements.Lookup(ctx, EntitlementCache(), key, func(ctx context.Context) (bool, bool, error) {
+		ok, err := checkMembershipPerk(ctx, claimID, perkType, environ)
+		return ok, ok, err
+	})
+}
+
+// isUserAMod checks and weakly returns if the user is a mod, if errors occur false is assumed
+func isUserAMod(ctx context.Context, environ string) bool {
+	userID := currentUserID(ctx)
+	if userID == 0 {
+		isMod, err := checkUserIsMod(ctx, environ)
+		return err == nil && isMod
+	}
+	key := entitlements.Key{Kind: entitlements.KindModerator, UserID: userID, Variant: environ}
+	isMod, err := entitlements.Lookup(ctx, EntitlementCache(), key, func(ctx context.Context) (bool, bool, error) {
+		ok, err := checkUserIsMod(ctx, environ)
+		return ok, ok, err
+	})
+	return err == nil && isMod
+}
+
+// iapiError marks errors of requests internal-apis didn't answer as entitlements.ErrUnavailable,
+// errors it returned are passed as is.
+func iapiError(err error) error {
+	if err == nil || errors.Is(err, iapi.APIError) {
+		return err
+	}
+	return entitlements.Unavailable(err)
+}
@@ -1,34 +1,119 @@
 package query
 
 import (
-	"crypto/md5"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"path"
 	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
+	"github.com/OdyseeTeam/odysee-api/pkg/urlsigner"
+	"github.com/OdyseeTeam/player-server/pkg/paid"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+var (
+	urlSigners       atomic.Pointer[StreamSigners]
+	paidTokenKeyring atomic.Pointer[keybox.Keyring]
 )
 
-func signStreamURL77(host, filePath, secureToken string, expiryTimestamp int64) (string, error) {
-	strippedPath := path.Dir(filePath)
+// StreamSigners signs streaming URLs of paid and members-only content.
+type StreamSigners struct {
+	*urlsigner.Signers
+	paidHost  string
+	startPath string
+}
 
-	hash := strippedPath + secureToken
-	if expiryTimestamp > 0 {
-		hash = fmt.Sprintf("%d%s", expiryTimestamp, hash)
+// NewURLSigners creates streaming URL signers for player hosts from config.
+// Hosts without a configured signer get CDN77 secure tokens made with StreamsV6 token,
+// the paid host repeating them in the hash77 query parameter.
+func NewURLSigners() (*StreamSigners, error) {
+	stConfig := config.GetStreamsV6()
+	if stConfig["paidhost"] == "" {
+		return nil, errors.New("StreamsV6 PaidHost is not set")
+	}
+	if strings.Count(stConfig["startpath"], "%s") != 2 {
+		return nil, fmt.Errorf("StreamsV6 StartPath %q should have placeholders for claim ID and SD hash", stConfig["startpath"])
 	}
+	cfgs := map[string]urlsigner.Config{}
+	for _, c := range config.GetURLSigners() {
+		for _, host := range c.Hosts {
+			cfgs[host] = c.Config
+		}
+	}
+	if _, ok := cfgs[stConfig["paidhost"]]; !ok {
+		cfgs[stConfig["paidhost"]] = urlsigner.Config{
+			Scheme: urlsigner.SchemeCDN77, Secret: stConfig["token"], HashParam: paramHash77,
+		}
+	}
+	s, err := urlsigner.NewSigners(cfgs, urlsigner.NewCDN77(stConfig["token"], ""))
+	if err != nil {
+		return nil, err
+	}
+	return &StreamSigners{Signers: s, paidHost: stConfig["paidhost"], startPath: stConfig["startpath"]}, nil
+}
 
-	finalHash := md5.Sum([]byte(hash))
-	encodedFinalHash := base64.StdEncoding.EncodeToString(finalHash[:])
-	encodedFinalHash = strings.NewReplacer("+", "-", "/", "_").Replace(encodedFinalHash)
+// SignStream returns a URL of the stream on the paid content host, valid until expiry.
+func (s *StreamSigners) SignStream(claimID, sdHash string, expiry time.Time) (string, error) {
+	return s.Sign(s.paidHost, fmt.Sprintf(s.startPath, claimID, sdHash), expiry)
+}
 
-	// signedURL := "https://" + fmt.Sprintf("%s/%s", host, encodedFinalHash)
-	// if expiryTimestamp > 0 {
-	// 	signedURL += fmt.Sprintf(",%d", expiryTimestamp)
-	// }
-	// signedURL += filePath
+// SetURLSigners sets signers of paid and members-only streaming URLs.
+func SetURLSigners(s *StreamSigners) {
+	urlSigners.Store(s)
+}
 
-	if expiryTimestamp > 0 {
-		return fmt.Sprintf("%s,%d", encodedFinalHash, expiryTimestamp), nil
+// URLSigners returns signers set by SetURLSigners, creating them from config if they weren't set.
+func URLSigners() (*StreamSigners, error) {
+	if s := urlSigners.Load(); s != nil {
+		return s, nil
 	}
+	s, err := NewURLSigners()
+	if err != nil {
+		return nil, err
+	}
+	urlSigners.CompareAndSwap(nil, s)
+	return urlSigners.Load(), nil
+}
+
+// NewPaidTokenKeyring loads keys from the PaidTokenKeyring file, it returns nil if the file isn't set,
+// in which case paid stream tokens are signed with the PaidTokenPrivKey RSA key.
+func NewPaidTokenKeyring() (*keybox.Keyring, error) {
+	kc := config.GetPaidTokenKeyringConfig()
+	if kc.File == "" {
+		return nil, nil
+	}
+	return keybox.LoadKeyring(kc.File, keybox.WithGracePeriod(kc.GracePeriod))
+}
 
-	return encodedFinalHash, nil
+// SetPaidTokenKeyring sets keys paid stream tokens are signed with instead of the PaidTokenPrivKey RSA key.
+func SetPaidTokenKeyring(kr *keybox.Keyring) {
+	paidTokenKeyring.Store(kr)
+}
+
+// PaidTokenKeyring returns the keyring set by SetPaidTokenKeyring, nil if paid stream tokens are signed with the RSA key.
+func PaidTokenKeyring() *keybox.Keyring {
+	return paidTokenKeyring.Load()
+}
+
+// createPaidToken creates a token granting access to the LBC-paid stream.
+// Tokens carry the same claims whether they're signed with the RSA key or with the keyring.
+func createPaidToken(streamID, txID string, size uint64) (string, error) {
+	kr := PaidTokenKeyring()
+	if kr == nil {
+		return paid.CreateToken(streamID, txID, size, paid.ExpTenSecPer100MB)
+	}
+	t, err := jwt.NewBuilder().
+		Claim("sid", streamID).
+		Claim("txid", txID).
+		IssuedAt(time.Now()).
+		Expiration(time.Unix(paid.ExpTenSecPer100MB(size), 0)).
+		Build()
+	if err != nil {
+		return "", fmt.Errorf("unable to build token: %w", err)
+	}
+	return kr.Sign(t)
 }
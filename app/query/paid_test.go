@@ -20,10 +20,13 @@ import (
 	"github.com/OdyseeTeam/odysee-api/internal/test"
 	"github.com/OdyseeTeam/odysee-api/models"
 	"github.com/OdyseeTeam/odysee-api/pkg/iapi"
+	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
 	"github.com/OdyseeTeam/odysee-api/pkg/migrator"
+	"github.com/OdyseeTeam/odysee-api/pkg/urlsigner"
 	"github.com/OdyseeTeam/player-server/pkg/paid"
 
 	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/ybbus/jsonrpc/v2"
@@ -162,8 +165,7 @@ func (s *paidContentSuite) TestAccess() {
 	defer func() { timeSource = realTimeSource{} }()
 
 	signShortcut := func(host, path string) string {
-		hash, err := signStreamURL77(host, path, token, timeSource.Now().Add(24*time.Hour).Unix())
-		s.Require().NoError(err)
+		hash := urlsigner.NewCDN77(token, "").Hash(path, timeSource.Now().Add(24*time.Hour).Unix())
 		if strings.Contains(host, "live") {
 			return fmt.Sprintf("https://%s/%s%s", host, hash, path)
 		}
@@ -219,6 +221,52 @@ func (s *paidContentSuite) TestAccess() {
 	}
 }
 
+func TestNewURLSigners(t *testing.T) {
+	defer config.RestoreOverridden()
+
+	config.Override("StreamsV6", map[string]string{
+		"paidhost": "secure.odycdn.com", "startpath": "/v6/streams/%s/%s.mp4", "token": "abc",
+	})
+	config.Override("URLSigners", []map[string]any{
+		{"hosts": []string{"cloud.odysee.live"}, "scheme": urlsigner.SchemeHMACSHA256, "secret": "def"},
+	})
+	signers, err := NewURLSigners()
+	require.NoError(t, err)
+	expiry := time.Unix(1695977338, 0)
+	signed, err := signers.SignStream("claim", "sdhash", expiry)
+	require.NoError(t, err)
+	hash := urlsigner.NewCDN77("abc", "").Hash("/v6/streams/claim/sdhash.mp4", expiry.Unix())
+	assert.Equal(t, fmt.Sprintf("https://secure.odycdn.com/%s/v6/streams/claim/sdhash.mp4?%s=%s", hash, paramHash77, hash), signed)
+	assert.IsType(t, &urlsigner.HMACSHA256{}, signers.For("cloud.odysee.live"))
+
+	config.Override("StreamsV6", map[string]string{"paidhost": "secure.odycdn.com", "startpath": "/v6/streams/%s.mp4", "token": "abc"})
+	_, err = NewURLSigners()
+	assert.ErrorContains(t, err, "StartPath")
+
+	config.Override("StreamsV6", map[string]string{"paidhost": "secure.odycdn.com", "startpath": "/v6/streams/%s/%s.mp4"})
+	_, err = NewURLSigners()
+	assert.ErrorContains(t, err, "requires a secret")
+}
+
+func TestCreatePaidTokenWithKeyring(t *testing.T) {
+	e, err := keybox.GenerateKeyringEntry(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	kr, err := keybox.NewKeyring([]keybox.KeyringEntry{e})
+	require.NoError(t, err)
+	SetPaidTokenKeyring(kr)
+	defer SetPaidTokenKeyring(nil)
+
+	token, err := createPaidToken("name/claimid", "txid", 585600621)
+	require.NoError(t, err)
+	pt, err := kr.Validator().ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "name/claimid", pt.PrivateClaims()["sid"])
+	assert.Equal(t, "txid", pt.PrivateClaims()["txid"])
+	assert.Empty(t, pt.Issuer())
+	assert.WithinDuration(t, time.Now(), pt.IssuedAt(), time.Minute)
+	assert.InDelta(t, paid.ExpTenSecPer100MB(585600621), pt.Expiration().Unix(), 1)
+}
+
 func (s *paidContentSuite) TestAccessLBC() {
 	s.T().Skip("skipping this in automated mode as it requires extra setup")
 	params := map[string]interface{}{"uri": urlLbcPurchase}
@@ -254,16 +302,3 @@ func (s *paidContentSuite) getClaim(url string) *ljsonrpc.Claim {
 	s.Require().NoError(err)
 	return claim
 }
-
-func TestSignStreamURL77(t *testing.T) {
-	cdnResourceURL := "player.odycdn.com"
-	filePath := "/api/v4/streams/tc/all-the-times-we-nearly-blew-up-the" +
-		"/ac809d68d201e2f58dcd241b5aaeefe817634dda" +
-		"/2f562bd1dd318db726014d255c3c7f4e5cae3e746f77647e00ad7e9b272d193bcad634b515bf0a2bc471719cfdde0c00" +
-		"/master.m3u8"
-	secureToken := "aiphaechiSietee3heiKaezosaitip0i"
-	expiryTimestamp := int64(1695977338)
-	hash, err := signStreamURL77(cdnResourceURL, filePath, secureToken, expiryTimestamp)
-	require.NoError(t, err)
-	require.Equal(t, "Syc1EWOyivHWw9L4aquM1g==,1695977338", hash)
-}
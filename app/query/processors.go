@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/app/arweave"
@@ -19,7 +18,6 @@ import (
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 	"github.com/OdyseeTeam/odysee-api/pkg/rpcerrors"
-	"github.com/OdyseeTeam/player-server/pkg/paid"
 
 	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
 
@@ -39,13 +37,6 @@ var (
 )
 
 const (
-	accessTypePurchase   = "purchase"
-	accessTypeRental     = "rental"
-	accessTypeMemberOnly = "memberonly"
-	accessTypeUnlisted   = "unlisted"
-	accessTypeScheduled  = "scheduled"
-	accessTypeFree       = ""
-
 	iapiTypeMembershipVod        = "Exclusive content"
 	iapiTypeMembershipLiveStream = "Exclusive livestreams"
 
@@ -154,15 +145,16 @@ func preflightHookGet(caller *Caller, ctx context.Context) (*jsonrpc.RPCResponse
 			return nil, errors.Err(m)
 		}
 		sdHash := hex.EncodeToString(src.SdHash)
-		hash, err := signStreamURL77(
-			stConfig["paidhost"], fmt.Sprintf(stConfig["startpath"], claim.ClaimID, sdHash),
-			stConfig["token"], timeSource.Now().Add(24*time.Hour).Unix())
+		signers, err := URLSigners()
+		if err != nil {
+			return nil, err
+		}
+		signedUrl, err := signers.SignStream(claim.ClaimID, sdHash, timeSource.Now().Add(24*time.Hour))
 		if err != nil {
 			return nil, err
 		}
-		signedUrl := fmt.Sprintf("https://%s/%s%s", stConfig["paidhost"], hash, fmt.Sprintf(stConfig["startpath"], claim.ClaimID, sdHash))
 
-		responseResult[ParamStreamingUrl] = signedUrl + fmt.Sprintf("?%s=%s", paramHash77, hash)
+		responseResult[ParamStreamingUrl] = signedUrl
 		response.Result = responseResult
 		return response, nil
 	} else if errors.Is(err, errNeedSignedLivestreamUrl) {
@@ -174,12 +166,16 @@ func preflightHookGet(caller *Caller, ctx context.Context) (*jsonrpc.RPCResponse
 		if err != nil {
 			return nil, errors.Err("invalid base_streaming_url supplied")
 		}
-		hash, err := signStreamURL77(u.Host, u.Path, stConfig["token"], timeSource.Now().Add(24*time.Hour).Unix())
+		signers, err := URLSigners()
+		if err != nil {
+			return nil, err
+		}
+		signedUrl, err := signers.Sign(u.Host, u.Path, timeSource.Now().Add(24*time.Hour))
 		if err != nil {
 			return nil, err
 		}
 
-		responseResult[ParamStreamingUrl] = fmt.Sprintf("https://%s/%s%s", u.Host, hash, u.Path)
+		responseResult[ParamStreamingUrl] = signedUrl
 		response.Result = responseResult
 		return response, nil
 	}
@@ -204,7 +200,7 @@ func preflightHookGet(caller *Caller, ctx context.Context) (*jsonrpc.RPCResponse
 					environ = env
 				}
 
-				purchase, err := checkCustomerListPurchase(ctx, claim.ClaimID, environ)
+				purchase, err := lookupPurchase(ctx, claim.ClaimID, environ)
 				if err != nil {
 					log.Debug("couldn't check customer/list for purchase", "err", err)
 				} else if purchase != nil {
@@ -282,7 +278,7 @@ func preflightHookGet(caller *Caller, ctx context.Context) (*jsonrpc.RPCResponse
 	if isPaidStream {
 		size := src.GetSize()
 
-		token, err := paid.CreateToken(claim.Name+"/"+claim.ClaimID, purchaseTxId, size, paid.ExpTenSecPer100MB)
+		token, err := createPaidToken(claim.Name+"/"+claim.ClaimID, purchaseTxId, size)
 		if err != nil {
 			return nil, err
 		}
@@ -297,7 +293,7 @@ func preflightHookGet(caller *Caller, ctx context.Context) (*jsonrpc.RPCResponse
 	}
 
 	if config.GetArfleetEnabled() {
-		arUrl, err := arweave.GetClaimUrl(config.GetArfleetCDN(), claim.ClaimID)
+		arUrl, err := getArfleetResolver().GetClaimUrl(ctx, claim.ClaimID)
 		if err != nil || arUrl == "" {
 			responseResult[ParamStreamingUrl] = contentURL
 		} else {
@@ -331,7 +327,7 @@ func checkCustomerListPurchase(ctx context.Context, claimID string, environ stri
 	resp := &iapi.CustomerListResponse{}
 	err = iac.Call(ctx, "customer/list", map[string]string{"claim_id_filter": claimID}, resp)
 	if err != nil {
-		return nil, err
+		return nil, iapiError(err)
 	}
 
 	if len(resp.Data) == 0 {
@@ -353,130 +349,6 @@ func checkCustomerListPurchase(ctx context.Context, claimID string, environ stri
 	return customerPurchase, nil
 }
 
-func checkStreamAccess(ctx context.Context, claim *ljsonrpc.Claim) (bool, error) {
-	var (
-		accessType, environ string
-	)
-
-	params := QueryFromContext(ctx).ParamsAsMap()
-	_, isLivestream := params["base_streaming_url"]
-	if p, ok := params[iapi.ParamEnviron]; ok {
-		environ, _ = p.(string)
-	}
-
-	logger := logging.GetFromContext(ctx).With("claim_id", claim.CanonicalURL)
-
-TagLoop:
-	for _, t := range claim.Value.Tags {
-		switch {
-		case (t == "c:scheduled:hide" || t == "c:scheduled:show") && claim.Value.GetStream().ReleaseTime > timeSource.NowUnix():
-			accessType = accessTypeScheduled
-			break TagLoop
-		case strings.HasPrefix(t, "purchase:") || t == "c:purchase":
-			accessType = accessTypePurchase
-			break TagLoop
-		case strings.HasPrefix(t, "rental:") || t == "c:rental":
-			accessType = accessTypeRental
-			break TagLoop
-		case t == "c:members-only":
-			accessType = accessTypeMemberOnly
-			break TagLoop
-		case t == ClaimTagUnlisted:
-			accessType = accessTypeUnlisted
-			break TagLoop
-		}
-	}
-
-	if accessType == accessTypeFree {
-		return true, nil
-	}
-	if claim.IsMyOutput {
-		if isLivestream {
-			return true, errNeedSignedLivestreamUrl
-		}
-		return true, errNeedSignedUrl
-	}
-
-	signErr := errNeedSignedUrl
-	if isLivestream {
-		signErr = errNeedSignedLivestreamUrl
-	}
-
-	if isUserAMod(ctx, environ) {
-		return true, signErr
-	}
-
-	if accessType == accessTypeUnlisted {
-		// check signature and signature_ts params, error if not present
-		signature, ok := params["signature"]
-		if !ok {
-			logger.Warn("missing signature param for unlisted claim")
-			return false, errors.Err("missing required signature param")
-		}
-
-		signatureTS, ok := params["signature_ts"]
-		if !ok {
-			return false, errors.Err("missing required signature_ts param")
-		}
-		validateErr := ValidateSignatureFromClaim(claim, signature.(string), signatureTS.(string), claim.ClaimID)
-		if validateErr != nil {
-			return false, validateErr
-		}
-		return true, signErr
-	} else if accessType == accessTypeScheduled {
-		return false, errors.Err("claim release time is in the future, not ready to be viewed yet")
-	}
-
-	switch accessType {
-	case accessTypePurchase, accessTypeRental:
-		purchase, err := checkCustomerListPurchase(ctx, claim.ClaimID, environ)
-		if err != nil {
-			return false, err
-		}
-		if purchase == nil {
-			return false, errors.Err("no access to paid content")
-		}
-		if accessType == accessTypeRental {
-			if purchase.Type != "rental" {
-				return false, errors.Err("incorrect purchase type")
-			}
-			if purchase.Type == "rental" && timeSource.NowAfter(purchase.ValidThrough) {
-				return false, errors.Err("rental expired")
-			}
-		}
-		return true, signErr
-	case accessTypeMemberOnly:
-		cu, err := auth.GetCurrentUserData(ctx)
-		if err != nil {
-			return false, errors.Err("no user data in context: %w", err)
-		}
-
-		iac := cu.IAPIClient()
-		if iac == nil {
-			return false, errors.Err("authentication required")
-		}
-		if environ == iapi.EnvironTest {
-			iac = iac.Clone(iapi.WithEnvironment(iapi.EnvironTest))
-		}
-
-		resp := &iapi.MembershipPerkCheck{}
-		perkType := iapiTypeMembershipVod
-		if isLivestream {
-			perkType = iapiTypeMembershipLiveStream
-		}
-		err = iac.Call(ctx, "membership_perk/check", map[string]string{"claim_id": claim.ClaimID, "type": perkType}, resp)
-		if err != nil {
-			return false, err
-		}
-		if !resp.Data.HasAccess {
-			return false, errors.Err("no access to members-only content")
-		}
-		return true, signErr
-	default:
-		return false, errors.Err("unknown access type")
-	}
-}
-
 func resolve(ctx context.Context, c *Caller, q *Query, url string) (*ljsonrpc.Claim, error) {
 	resolveQuery, err := NewQuery(jsonrpc.NewRequest(
 		MethodResolve,
@@ -557,11 +429,10 @@ func preflightHookClaimSearch(_ *Caller, ctx context.Context) (*jsonrpc.RPCRespo
 
 func postClaimSearchArfleetThumbs(_ *Caller, ctx context.Context) (*jsonrpc.RPCResponse, error) {
 	logger := zapadapter.NewKV(nil).With("module", "query.preprocessors")
-	baseUrl := config.GetArfleetCDN()
 	resp := ResponseFromContext(ctx)
-	pRes, err := arweave.ReplaceAssetUrls(baseUrl, resp.Result, "items", "value.thumbnail.url")
+	pRes, err := getArfleetResolver().ReplaceAssetUrls(ctx, resp.Result, "items", "value.thumbnail.url")
 	if err != nil {
-		logger.Warn("error replacing asset urls", "err", err)
+		logArfleetError(logger, "error replacing asset urls", err)
 		return resp, nil
 	}
 	resp.Result = pRes
@@ -570,7 +441,6 @@ func postClaimSearchArfleetThumbs(_ *Caller, ctx context.Context) (*jsonrpc.RPCR
 
 func postResolveArfleetThumbs(_ *Caller, ctx context.Context) (*jsonrpc.RPCResponse, error) {
 	logger := zapadapter.NewKV(nil).With("module", "query.preprocessors")
-	baseUrl := config.GetArfleetCDN()
 
 	resp := ResponseFromContext(ctx)
 	claims, ok := resp.Result.(map[string]any)
@@ -582,15 +452,24 @@ func postResolveArfleetThumbs(_ *Caller, ctx context.Context) (*jsonrpc.RPCRespo
 	for k, v := range claims {
 		claimUrl, claim = k, v
 	}
-	pClaim, err := arweave.ReplaceAssetUrl(baseUrl, claim, "value.thumbnail.url")
+	pClaim, err := getArfleetResolver().ReplaceAssetUrl(ctx, claim, "value.thumbnail.url")
 	if err != nil {
-		logger.Warn("error replacing asset url", "err", err)
+		logArfleetError(logger, "error replacing asset url", err)
 		return resp, nil
 	}
 	resp.Result = map[string]any{claimUrl: pClaim}
 	return resp, nil
 }
 
+// logArfleetError logs failed substitutions, skipped ones are expected while Arfleet is degraded.
+func logArfleetError(logger logging.KVLogger, msg string, err error) {
+	if errors.Is(err, arweave.ErrUnavailable) {
+		logger.Debug(msg, "err", err)
+		return
+	}
+	logger.Warn(msg, "err", err)
+}
+
 func sliceContains[V comparable](cont []V, items ...V) bool {
 	for _, t := range cont {
 		for _, i := range items {
@@ -693,16 +572,16 @@ func getStatusResponse(_ *Caller, ctx context.Context) (*jsonrpc.RPCResponse, er
 	return rpcResponse, nil
 }
 
-// isUserAMod checks and weakly returns if the user is a mod, if errors occur false is assumed
-func isUserAMod(ctx context.Context, environ string) bool {
+// checkUserIsMod checks if the user is a global moderator.
+func checkUserIsMod(ctx context.Context, environ string) (bool, error) {
 	cu, err := auth.GetCurrentUserData(ctx)
 	if err != nil {
-		return false
+		return false, err
 	}
 
 	iac := cu.IAPIClient()
 	if iac == nil {
-		return false
+		return false, errors.Err("authentication required")
 	}
 	if environ == iapi.EnvironTest {
 		iac = iac.Clone(iapi.WithEnvironment(iapi.EnvironTest))
@@ -711,9 +590,9 @@ func isUserAMod(ctx context.Context, environ string) bool {
 	var userResp iapi.UserMeResponse
 	err = iac.Call(ctx, "user/me", nil, &userResp)
 	if err != nil {
-		return false
+		return false, iapiError(err)
 	}
-	return userResp.Success && userResp.Data.GlobalMod
+	return userResp.Success && userResp.Data.GlobalMod, nil
 }
 
 // preflightCacheHook should be routed into caller for methods that must be cached:
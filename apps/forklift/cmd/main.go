@@ -1,21 +1,63 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/forklift"
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/blobs"
 	"github.com/OdyseeTeam/odysee-api/pkg/configng"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 	"github.com/OdyseeTeam/odysee-api/pkg/migrator"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+	"github.com/OdyseeTeam/odysee-api/pkg/sanitizer"
 
 	"github.com/alecthomas/kong"
+	"github.com/hibiken/asynq"
 )
 
 var cli struct {
-	Serve struct{} `cmd:"" help:"Start forklift service"`
-	Debug bool     `help:"Enable verbose logging"`
+	Serve       struct{} `cmd:"" help:"Start forklift service"`
+	DeadLetters struct {
+		Queue string `help:"Queue to operate on" enum:"forklift,asynquery" default:"forklift"`
+		List  struct {
+			Type     string `help:"Only list tasks of this type"`
+			Page     int    `help:"Page number" default:"1"`
+			PageSize int    `help:"Page size" default:"30"`
+		} `cmd:"" help:"List failed tasks with their payloads and errors"`
+		Show struct {
+			ID string `arg:"" help:"Task ID"`
+		} `cmd:"" help:"Show failed task"`
+		Requeue struct {
+			ID string `arg:"" help:"Task ID"`
+		} `cmd:"" help:"Put failed task back to the queue"`
+		Edit struct {
+			ID      string `arg:"" help:"Task ID"`
+			Payload string `help:"New task payload in JSON, read from stdin if omitted"`
+		} `cmd:"" help:"Replace failed task payload and put it back to the queue"`
+		Discard struct {
+			ID string `arg:"" help:"Task ID"`
+		} `cmd:"" help:"Delete failed task"`
+	} `cmd:"" help:"Inspect and manage tasks that have run out of retries"`
+	VerifyBlobs struct {
+		SDHash   string `help:"SD hash of the stream to verify" xor:"stream"`
+		UploadID string `help:"ID of an upload or URL download to verify the stream of" xor:"stream"`
+		Reupload bool   `help:"Upload missing and corrupt blobs again"`
+		File     string `help:"Original file to encode missing blobs from, taken from the upload checkpoint if omitted"`
+		BlobPath string `help:"Directory with blobs of the split file, taken from the upload checkpoint if omitted"`
+	} `cmd:"" help:"Check that all blobs of a stream are stored intact and optionally upload broken ones again"`
+	Debug bool `help:"Enable verbose logging"`
 }
 
 type loggingConfig struct {
@@ -35,9 +77,13 @@ func main() {
 	}
 	logger := zapadapter.NewKV(nil)
 
-	switch ctx.Command() {
-	case "serve":
+	switch cmd := ctx.Command(); {
+	case cmd == "serve":
 		serve(logger)
+	case strings.HasPrefix(cmd, "dead-letters "):
+		deadLetters(logger, strings.Fields(cmd)[1])
+	case cmd == "verify-blobs":
+		verifyBlobs(logger)
 	default:
 		logger.Fatal("unknown command", "name", ctx.Command())
 	}
@@ -78,7 +124,16 @@ func serve(logger logging.KVLogger) {
 		logger.Fatal("failed to create working directory", "err", err, "path", uploadPath)
 	}
 
-	l := forklift.NewLauncher(
+	cfg.V.SetDefault("URLDownloads.Timeout", 10*time.Minute)
+	httpRetriever := forklift.NewHTTPRetriever(
+		uploadPath,
+		forklift.WithMaxFileSize(cfg.V.GetInt64("URLDownloads.MaxSize")),
+		forklift.WithDownloadTimeout(cfg.V.GetDuration("URLDownloads.Timeout")),
+		forklift.WithAllowedHosts(cfg.V.GetStringSlice("URLDownloads.AllowedHosts")...),
+		forklift.WithDeniedHosts(cfg.V.GetStringSlice("URLDownloads.DeniedHosts")...),
+	)
+
+	opts := []forklift.LauncherOption{
 		forklift.WithDB(db),
 		forklift.WithReflectorConfig(cfg.V.Sub("ReflectorStorage")),
 		forklift.WithConcurrency(cfg.V.GetInt("Concurrency")),
@@ -86,15 +141,189 @@ func serve(logger logging.KVLogger) {
 		forklift.WithBlobPath(blobPath),
 		forklift.WithS3Client(client),
 		forklift.WithDownloadsPath(uploadPath),
+		forklift.WithHTTPRetriever(httpRetriever),
 		forklift.WithRequestsConnURL(cfg.V.GetString("ForkliftRequestsConnURL")),   // Redis connection for listening to complete upload requests
 		forklift.WithResponsesConnURL(cfg.V.GetString("AsynqueryRequestsConnURL")), // Redis connection for publishing processed upload results
 		forklift.WithLogger(logger),
 		forklift.ExposeMetrics(),
-	)
+	}
+
+	var queueConfig queue.Config
+	if err := cfg.V.UnmarshalKey("Queue", &queueConfig); err != nil {
+		logger.Fatal("invalid queue config", "err", err)
+	}
+	opts = append(opts, forklift.WithQueueOptions(queueConfig.Options()...))
+
+	cfg.V.SetDefault("Sanitizer.Enabled", true)
+	if cfg.V.GetBool("Sanitizer.Enabled") {
+		opts = append(opts, forklift.WithSanitizer(sanitizer.New(
+			sanitizer.WithDocuments(cfg.V.GetBool("Sanitizer.Documents")),
+			sanitizer.WithVideos(cfg.V.GetBool("Sanitizer.Videos")),
+		)))
+		logger.Info("metadata sanitizer enabled")
+	}
+
+	if cfg.V.GetBool("VerifyBlobs") {
+		opts = append(opts, forklift.WithBlobVerification())
+		logger.Info("stream blobs verification enabled")
+	}
+
+	l := forklift.NewLauncher(opts...)
 
 	b, err := l.Build()
 	if err != nil {
 		panic(err)
 	}
+
+	var rcfg reaper.Config
+	if err := cfg.V.UnmarshalKey("Reaper", &rcfg); err != nil {
+		logger.Fatal("reaper config failed", "err", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Reaper(rcfg.Options()...).Start(ctx)
+
 	b.ServeUntilShutdown()
 }
+
+func deadLetters(logger logging.KVLogger, command string) {
+	cfg, err := configng.Read("./config", "forklift", "yaml")
+	if err != nil {
+		logger.Fatal("config reading failed", "err", err)
+	}
+	connURL := cfg.V.GetString("ForkliftRequestsConnURL")
+	if cli.DeadLetters.Queue == "asynquery" {
+		connURL = cfg.V.GetString("AsynqueryRequestsConnURL")
+	}
+	q, err := queue.New(queue.WithRequestsConnURL(connURL), queue.WithLogger(logger))
+	if err != nil {
+		logger.Fatal("queue connection failed", "err", err)
+	}
+	defer q.Shutdown()
+	// Dead tasks of these types have failed the asynquery the upload belongs to, which cannot be reopened from here.
+	for _, t := range []string{
+		tasks.ForkliftUploadIncoming, tasks.ForkliftURLIncoming, tasks.ForkliftUploadDone, tasks.AsynqueryIncomingQuery,
+	} {
+		q.AddRequeueHandler(t, refuseQueryRequeue)
+	}
+
+	dl := cli.DeadLetters
+	switch command {
+	case "list":
+		tasks, err := q.ListDeadTasks(dl.List.Type, dl.List.Page, dl.List.PageSize)
+		if err != nil {
+			logger.Fatal("failed to list tasks", "err", err)
+		}
+		printJSON(tasks)
+	case "show":
+		task, err := q.GetDeadTask(dl.Show.ID)
+		if err != nil {
+			logger.Fatal("failed to get task", "id", dl.Show.ID, "err", err)
+		}
+		printJSON(task)
+	case "requeue":
+		if err := q.RequeueDeadTask(dl.Requeue.ID); err != nil {
+			logger.Fatal("failed to requeue task", "id", dl.Requeue.ID, "err", err)
+		}
+	case "edit":
+		payload := []byte(dl.Edit.Payload)
+		if len(payload) == 0 {
+			payload, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				logger.Fatal("failed to read payload", "err", err)
+			}
+		}
+		newID, err := q.EditDeadTask(dl.Edit.ID, payload)
+		if err != nil {
+			logger.Fatal("failed to edit task", "id", dl.Edit.ID, "err", err)
+		}
+		fmt.Println(newID)
+	case "discard":
+		if err := q.DiscardDeadTask(dl.Discard.ID); err != nil {
+			logger.Fatal("failed to discard task", "id", dl.Discard.ID, "err", err)
+		}
+	}
+}
+
+func refuseQueryRequeue(_ context.Context, task *asynq.Task) error {
+	return fmt.Errorf("%s tasks must be requeued through the admin API so their query is reopened", task.Type())
+}
+
+// verifyBlobs prints the verification report and exits with non-zero status if the stream is still broken.
+func verifyBlobs(logger logging.KVLogger) {
+	cfg, err := configng.Read("./config", "forklift", "yaml")
+	if err != nil {
+		logger.Fatal("config reading failed", "err", err)
+	}
+	vb := cli.VerifyBlobs
+	source := blobs.ReuploadSource{BlobPath: vb.BlobPath, FilePath: vb.File}
+	sdHash := vb.SDHash
+	if vb.UploadID != "" {
+		db, err := migrator.ConnectDB(cfg.ReadPostgresConfig("Database"))
+		if err != nil {
+			logger.Fatal("db connection failed", "err", err)
+		}
+		ctx := context.Background()
+		sdHash, err = database.New(db).GetSDHash(ctx, vb.UploadID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Fatal("failed to get upload", "upload_id", vb.UploadID, "err", err)
+		}
+		// Checkpoints of uploads which are still being processed point to local copies of the stream.
+		cp, err := forklift.NewDBCheckpointStore(db).Load(ctx, vb.UploadID)
+		if err != nil {
+			logger.Warn("failed to load upload checkpoint", "upload_id", vb.UploadID, "err", err)
+		}
+		if cp != nil {
+			if sdHash == "" && cp.Meta != nil {
+				sdHash = cp.Meta.SDHash
+			}
+			if source.BlobPath == "" {
+				source.BlobPath = cp.BlobPath
+			}
+			if source.FilePath == "" && cp.LocalFile != nil {
+				source.FilePath = cp.LocalFile.Name
+			}
+		}
+	}
+	if sdHash == "" {
+		logger.Fatal("stream to verify is unknown, pass --sd-hash or --upload-id of a processed upload")
+	}
+
+	dest, err := blobs.NewDestinationFromConfig(cfg.V.Sub("ReflectorStorage"))
+	if err != nil {
+		logger.Fatal("blob destination initialization failed", "err", err)
+	}
+	verifier := blobs.NewVerifier(dest, cfg.V.GetInt("ReflectorWorkers"))
+	report, err := verifier.Verify(sdHash)
+	if err != nil && report == nil {
+		logger.Fatal("stream verification failed", "sd_hash", sdHash, "err", err)
+	}
+	printJSON(report)
+	if report.OK() {
+		return
+	}
+	if vb.Reupload {
+		summary, err := verifier.Reupload(report, source)
+		if err != nil {
+			logger.Fatal("failed to upload broken blobs", "err", err)
+		}
+		printJSON(summary)
+		report, err = verifier.Verify(sdHash)
+		if err != nil && report == nil {
+			logger.Fatal("stream verification failed", "sd_hash", sdHash, "err", err)
+		}
+		printJSON(report)
+		if report.OK() {
+			return
+		}
+	}
+	os.Exit(1)
+}
+
+func printJSON(v any) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+}
@@ -2,14 +2,12 @@ package forklift
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
-	"path"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
@@ -18,6 +16,8 @@ import (
 	"github.com/OdyseeTeam/odysee-api/pkg/fileanalyzer"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+	"github.com/OdyseeTeam/odysee-api/pkg/sanitizer"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/go-chi/chi/v5"
@@ -26,7 +26,10 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
-var ErrReflector = errors.New("errors found while uploading blobs to reflector")
+var (
+	ErrReflector    = errors.New("errors found while uploading blobs to reflector")
+	ErrBrokenStream = errors.New("stream blobs are missing or corrupt after upload")
+)
 
 type Deleter interface {
 	Delete(context.Context, tasks.FileLocationS3) error
@@ -44,8 +47,13 @@ type Launcher struct {
 	db               database.DBTX
 	concurrency      int
 	reflectorWorkers int
+	verifyBlobs      bool
 	metricsAddress   string
 	s3client         *s3.Client
+	sanitizer        *sanitizer.Sanitizer
+	checkpoints      CheckpointStore
+	extraStages      []stagePlacement
+	queueOptions     []func(*queue.Options)
 	forklift         *Forklift
 }
 
@@ -56,9 +64,13 @@ type Forklift struct {
 	logger        logging.KVLogger
 	retriever     *S3Retriever
 	httpRetriever *HTTPRetriever
-	store         *blobs.Store
+	store         blobs.Destination
+	workers       int
+	verifier      *blobs.Verifier
 	queries       *database.Queries
 	queue         *queue.Queue
+	sanitizer     *sanitizer.Sanitizer
+	pipeline      *Pipeline
 }
 
 type LauncherOption func(l *Launcher)
@@ -93,6 +105,7 @@ func WithResponsesConnURL(url string) LauncherOption {
 	}
 }
 
+// WithReflectorConfig sets up where stream blobs are uploaded to, see blobs.NewDestinationFromConfig.
 func WithReflectorConfig(cfg *viper.Viper) LauncherOption {
 	return func(l *Launcher) {
 		l.reflectorConfig = cfg
@@ -123,6 +136,13 @@ func WithConcurrency(concurrency int) LauncherOption {
 	}
 }
 
+// WithQueueOptions sets queue scheduling options, see queue.Config.
+func WithQueueOptions(options ...func(*queue.Options)) LauncherOption {
+	return func(l *Launcher) {
+		l.queueOptions = append(l.queueOptions, options...)
+	}
+}
+
 // WithReflectorWorkers sets the number of workers uploading each stream to the reflector.
 func WithReflectorWorkers(workers int) LauncherOption {
 	return func(l *Launcher) {
@@ -130,6 +150,35 @@ func WithReflectorWorkers(workers int) LauncherOption {
 	}
 }
 
+// WithSanitizer enables stripping of privacy-sensitive metadata from files before they are split into blobs.
+func WithSanitizer(s *sanitizer.Sanitizer) LauncherOption {
+	return func(l *Launcher) {
+		l.sanitizer = s
+	}
+}
+
+// WithBlobVerification adds a stage checking that all blobs of a stream are stored intact after they are uploaded.
+// Broken blobs are uploaded again, failing the upload if they can't be restored.
+func WithBlobVerification() LauncherOption {
+	return func(l *Launcher) {
+		l.verifyBlobs = true
+	}
+}
+
+// WithCheckpointStore sets the store for pipeline checkpoints, uploads database is used by default.
+func WithCheckpointStore(store CheckpointStore) LauncherOption {
+	return func(l *Launcher) {
+		l.checkpoints = store
+	}
+}
+
+// WithStage plugs an additional stage into the processing pipeline right after the named stage.
+func WithStage(stage Stage, after string) LauncherOption {
+	return func(l *Launcher) {
+		l.extraStages = append(l.extraStages, stagePlacement{stage, after})
+	}
+}
+
 func WithDB(db database.DBTX) LauncherOption {
 	return func(l *Launcher) {
 		l.db = db
@@ -195,21 +244,20 @@ func (l *Launcher) Build() (*queue.Queue, error) {
 		return nil, err
 	}
 
-	destinations, err := blobs.CreateStoresFromConfig(l.reflectorConfig, "destinations")
-	if err != nil {
-		return nil, fmt.Errorf("cannot initialize reflector destination stores: %w", err)
-	}
-	store, err := blobs.NewStore(l.reflectorConfig.GetString("databasedsn"), destinations)
+	store, err := blobs.NewDestinationFromConfig(l.reflectorConfig)
 	if err != nil {
-		return nil, fmt.Errorf("cannot initialize reflector store: %w", err)
+		return nil, fmt.Errorf("cannot initialize blob destination: %w", err)
 	}
-	store.SetWorkers(l.reflectorWorkers)
-	l.logger.Info("reflector store initialized")
+	l.logger.Info("blob destination initialized", "destination", store.Name())
 
 	taskQueue, err := queue.NewWithResponses(
 		l.requestsConnURL, l.responsesConnURL,
-		queue.WithConcurrency(l.concurrency),
-		queue.WithLogger(l.logger))
+		append([]func(*queue.Options){
+			queue.WithConcurrency(l.concurrency),
+			queue.WithLogger(l.logger),
+			queue.WithQueues(tasks.ForkliftQueues),
+			queue.WithRoutes(tasks.Routes),
+		}, l.queueOptions...)...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize queue: %w", err)
 	}
@@ -241,22 +289,52 @@ func (l *Launcher) Build() (*queue.Queue, error) {
 		retriever:     l.retriever,
 		httpRetriever: l.httpRetriever,
 		store:         store,
+		workers:       l.reflectorWorkers,
 		queries:       database.New(l.db),
 		queue:         taskQueue,
+		sanitizer:     l.sanitizer,
 	}
+	if l.checkpoints == nil {
+		l.checkpoints = NewDBCheckpointStore(l.db)
+	}
+	forklift.pipeline = NewPipeline(l.checkpoints, forklift.defaultStages()...)
+	if l.verifyBlobs {
+		forklift.verifier = blobs.NewVerifier(store, l.reflectorWorkers)
+		if err := forklift.pipeline.Insert(NewStage(LabelVerify, forklift.verify), LabelUpstream); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range l.extraStages {
+		if err := forklift.pipeline.Insert(p.stage, p.after); err != nil {
+			return nil, fmt.Errorf("cannot add %s stage: %w", p.stage.Name(), err)
+		}
+	}
+	l.logger.Info("pipeline configured", "stages", forklift.pipeline.Stages())
 	l.forklift = forklift
 	taskQueue.AddHandler(tasks.ForkliftUploadIncoming, forklift.HandleUpload)
 	taskQueue.AddHandler(tasks.ForkliftURLIncoming, forklift.HandleURL)
+	taskQueue.AddDeadLetterHandler(tasks.ForkliftUploadIncoming, forklift.HandleFailure)
+	taskQueue.AddDeadLetterHandler(tasks.ForkliftURLIncoming, forklift.HandleFailure)
 	l.logger.Info("forklift initialized")
 	return taskQueue, nil
 }
 
+// Reaper creates a reaper for local files left behind by tasks, for example when a retry
+// has been processed on another node or the process was killed mid-task.
+// Max age should be well above the time a task may spend retrying.
+func (l *Launcher) Reaper(options ...reaper.Option) *reaper.Reaper {
+	sweepers := []reaper.Sweeper{
+		reaper.NewDirSweeper("downloads", l.downloadsPath),
+		reaper.NewDirSweeper("blobs", l.blobPath),
+	}
+	return reaper.New(append([]reaper.Option{reaper.WithLogger(l.logger), reaper.WithSweepers(sweepers...)}, options...)...)
+}
+
 func (f *Forklift) HandleUpload(ctx context.Context, task *asynq.Task) error {
 	if task.Type() != tasks.ForkliftUploadIncoming {
 		f.logger.Warn("cannot handle task", "type", task.Type())
 		return asynq.SkipRetry
 	}
-	start := time.Now()
 	waitStart := time.Now()
 	defer func() {
 		waitTimeMinutes.Observe(time.Since(waitStart).Minutes())
@@ -271,124 +349,25 @@ func (f *Forklift) HandleUpload(ctx context.Context, task *asynq.Task) error {
 	log := logging.TracedLogger(f.logger, payload)
 	log.Debug("task received")
 
-	localFile, err := f.retriever.Retrieve(context.TODO(), payload.UploadID, payload.FileLocation)
-	if err != nil {
-		log.Warn("failed to retrieve file", "err", err)
-		return err
-	}
-	defer localFile.Cleanup()
-	observeDuration(LabelRetrieve, start)
-	log.Debug("file retrieved", "location", payload.FileLocation, "size", localFile.Size, "seconds", time.Since(start).Seconds())
-
-	blobPath := path.Join(f.blobPath, localFile.Name)
-	if err != nil {
-		log.Warn("failed to close upload file", "err", err)
-	}
-	defer func() {
-		if err := os.RemoveAll(blobPath); err != nil {
-			log.Warn("failed to remove blobs", "err", err)
-		}
-	}()
-
-	uploader := f.store.Uploader()
-
-	start = time.Now()
-	info, err := f.analyzer.Analyze(context.Background(), localFile.Name, payload.FileName)
-	observeDuration(LabelAnalyze, start)
-	if info == nil {
-		observeError(LabelAnalyze)
-		log.Warn("file analysis failed", "err", err, "file", localFile.Name)
-		return err
-	}
-	log.Debug("file analyzed", "result", info, "err", err)
-
-	src := blobs.NewSource(localFile.Name, blobPath, payload.FileName)
-	start = time.Now()
-	log.Debug("creating stream")
-	stream, err := src.Split()
-	observeDuration(LabelStreamCreate, start)
-	if err != nil {
-		observeError(LabelStreamCreate)
-		log.Warn("failed to create stream", "err", err, "file", localFile.Name, "blobs_path", f.blobPath)
-		return err
-	}
-	streamSource := stream.GetSource()
-	sdHash := hex.EncodeToString(streamSource.GetSdHash())
-
-	log = log.With("sd_hash", sdHash)
-	log.Debug("stream created", "seconds", time.Since(start).Seconds())
-
-	start = time.Now()
-	log.Debug("starting upload")
-	summary, err := uploader.Upload(src)
-	observeDuration(LabelUpstream, start)
-	egressDurationSeconds.Add(float64(time.Since(start)))
-	egressVolumeMB.Add(float64(streamSource.GetSize() / 1024 / 1024))
-	if err != nil {
-		observeError(LabelUpstream)
-		log.Warn("blobs upload failed, not retrying", "err", err, "blobs_path", f.blobPath)
-		return err
-	} else if summary.Err > 0 {
-		observeError(LabelUpstream)
-		log.Warn(ErrReflector.Error(), "err_count", summary.Err, "blobs_path", f.blobPath)
-		return ErrReflector
-	}
-	log.Debug("stream blobs uploaded", "seconds", time.Since(start).Seconds())
-
-	meta := tasks.UploadMeta{
-		Hash:      hex.EncodeToString(streamSource.GetHash()),
-		MIME:      info.MediaType.MIME,
-		FileName:  payload.FileName,
-		Extension: info.MediaType.Extension,
-		Size:      streamSource.Size,
-		SDHash:    sdHash,
-	}
-
-	if path.Ext(meta.FileName) == "" {
-		meta.FileName += info.MediaType.Extension
-	}
-
-	if info.MediaInfo != nil {
-		meta.Width = info.MediaInfo.Width
-		meta.Height = info.MediaInfo.Height
-		meta.Duration = info.MediaInfo.Duration
-	}
-
-	jbMeta, err := json.Marshal(meta)
-	if err != nil {
-		log.Error("failed to marshal media info", "err", err)
-	}
-
-	err = f.queries.MarkUploadProcessed(context.TODO(), database.MarkUploadProcessedParams{
-		ID:     payload.UploadID,
-		SDHash: sdHash,
-		Meta:   pqtype.NullRawMessage{RawMessage: jbMeta, Valid: true},
-	})
-	if err != nil {
-		log.Error("failed to mark upload as processed", "err", err)
-		return err
-	}
-	log.Debug("upload processed")
-
-	defer func() {
-		err := f.retriever.Delete(context.TODO(), payload.FileLocation)
-		if err != nil {
-			log.Warn("failed to complete retrieved file", "err", err)
-		}
-	}()
-
-	err = f.queue.SendResponse(tasks.ForkliftUploadDone, tasks.ForkliftUploadDonePayload{
-		UploadID: payload.UploadID,
-		UserID:   payload.UserID,
-		Meta:     meta,
-	}, queue.WithRequestRetry(15), queue.WithRequestTimeout(15*time.Minute))
-	if err != nil {
-		log.Error("merge request failed, bus error", "err", err)
-		return err
-	}
-	log.Debug("forklift done")
-
-	return nil
+	job := &Job{
+		UploadID:     payload.UploadID,
+		UserID:       payload.UserID,
+		FileName:     payload.FileName,
+		KeepMetadata: payload.KeepMetadata,
+		log:          log,
+		retrieve: func(ctx context.Context) (*LocalFile, error) {
+			return f.retriever.Retrieve(ctx, payload.UploadID, payload.FileLocation)
+		},
+		remove: func(ctx context.Context) error {
+			return f.retriever.Delete(ctx, payload.FileLocation)
+		},
+		markProcessed: func(ctx context.Context, sdHash string, meta pqtype.NullRawMessage) error {
+			return f.queries.MarkUploadProcessed(ctx, database.MarkUploadProcessedParams{
+				ID: payload.UploadID, SDHash: sdHash, Meta: meta,
+			})
+		},
+	}
+	return f.process(ctx, job)
 }
 
 func (f *Forklift) HandleURL(ctx context.Context, task *asynq.Task) error {
@@ -396,7 +375,6 @@ func (f *Forklift) HandleURL(ctx context.Context, task *asynq.Task) error {
 		f.logger.Warn("cannot handle task", "type", task.Type())
 		return asynq.SkipRetry
 	}
-	start := time.Now()
 	waitStart := time.Now()
 	defer func() {
 		waitTimeMinutes.Observe(time.Since(waitStart).Minutes())
@@ -411,121 +389,127 @@ func (f *Forklift) HandleURL(ctx context.Context, task *asynq.Task) error {
 	log := logging.TracedLogger(f.logger, payload)
 	log.Debug("task received")
 
-	localFile, err := f.httpRetriever.Retrieve(context.TODO(), payload.UploadID, payload.FileLocation)
+	job := &Job{
+		UploadID:     payload.UploadID,
+		UserID:       payload.UserID,
+		FileName:     payload.FileName,
+		KeepMetadata: payload.KeepMetadata,
+		log:          log,
+		retrieve: func(ctx context.Context) (*LocalFile, error) {
+			return f.retrieveURL(ctx, log, payload)
+		},
+		markProcessed: func(ctx context.Context, sdHash string, meta pqtype.NullRawMessage) error {
+			return f.queries.MarkURLProcessed(ctx, database.MarkURLProcessedParams{
+				ID: payload.UploadID, SDHash: sdHash, Meta: meta,
+			})
+		},
+	}
+	return f.process(ctx, job)
+}
+
+// retrieveURL downloads the remote file, keeping its status and byte counts in the database up to date.
+func (f *Forklift) retrieveURL(ctx context.Context, log logging.KVLogger, payload tasks.ForkliftURLIncomingPayload) (*LocalFile, error) {
+	lf, err := f.httpRetriever.Retrieve(ctx, payload.UploadID, payload.FileLocation, func(received, total int64) {
+		err := f.queries.RecordURLProgress(ctx, database.RecordURLProgressParams{
+			ID: payload.UploadID, Received: received, Size: max(total, 0),
+		})
+		if err != nil {
+			log.Warn("failed to record download progress", "err", err)
+		}
+	})
 	if err != nil {
-		log.Info("failed to retrieve file", "err", err)
-		return err
+		return nil, err
 	}
-	defer localFile.Cleanup()
-	observeDuration(LabelRetrieve, start)
-	log.Debug("file retrieved", "location", payload.FileLocation, "size", localFile.Size, "seconds", time.Since(start).Seconds())
-
-	blobPath := path.Join(f.blobPath, localFile.Name)
+	err = f.queries.MarkURLDownloaded(ctx, database.MarkURLDownloadedParams{ID: payload.UploadID, Received: lf.Size})
 	if err != nil {
-		log.Warn("failed to close upload file", "err", err)
+		log.Warn("failed to mark url as downloaded", "err", err)
 	}
+	return lf, nil
+}
+
+// process runs the job through the pipeline and cleans up after it.
+// Local files are kept for a retry to resume from unless the task is done or won't be retried.
+func (f *Forklift) process(ctx context.Context, job *Job) (err error) {
 	defer func() {
-		if err := os.RemoveAll(blobPath); err != nil {
-			log.Warn("failed to remove blobs", "err", err)
+		if err == nil || !willRetry(ctx, err) {
+			f.removeLocalFiles(job)
 		}
 	}()
-
-	uploader := f.store.Uploader()
-
-	start = time.Now()
-	info, err := f.analyzer.Analyze(context.Background(), localFile.Name, payload.FileName)
-	observeDuration(LabelAnalyze, start)
-	if info == nil {
-		observeError(LabelAnalyze)
-		log.Warn("file analysis failed", "err", err, "file", localFile.Name)
+	if err := f.pipeline.Run(ctx, job); err != nil {
 		return err
 	}
-	log.Debug("file analyzed", "result", info, "err", err)
 
-	src := blobs.NewSource(localFile.Name, blobPath, payload.FileName)
-	start = time.Now()
-	log.Debug("creating stream")
-	stream, err := src.Split()
-	observeDuration(LabelStreamCreate, start)
-	if err != nil {
-		observeError(LabelStreamCreate)
-		log.Warn("failed to create stream", "err", err, "file", localFile.Name, "blobs_path", f.blobPath)
-		return err
+	if err := f.pipeline.checkpoints.Delete(ctx, job.UploadID); err != nil {
+		job.log.Warn("failed to delete checkpoint", "err", err)
 	}
-	streamSource := stream.GetSource()
-	sdHash := hex.EncodeToString(streamSource.GetSdHash())
-
-	log = log.With("sd_hash", sdHash)
-	log.Debug("stream created", "seconds", time.Since(start).Seconds())
-
-	start = time.Now()
-	log.Debug("starting upload")
-	summary, err := uploader.Upload(src)
-	observeDuration(LabelUpstream, start)
-	egressDurationSeconds.Add(float64(time.Since(start)))
-	egressVolumeMB.Add(float64(streamSource.GetSize() / 1024 / 1024))
-	if err != nil {
-		observeError(LabelUpstream)
-		log.Warn("blobs upload failed, not retrying", "err", err, "blobs_path", f.blobPath)
-		return err
-	} else if summary.Err > 0 {
-		observeError(LabelUpstream)
-		log.Warn(ErrReflector.Error(), "err_count", summary.Err, "blobs_path", f.blobPath)
-		return ErrReflector
+	if job.remove != nil {
+		if err := job.remove(ctx); err != nil {
+			job.log.Warn("failed to complete retrieved file", "err", err)
+		}
 	}
-	log.Debug("stream blobs uploaded", "seconds", time.Since(start).Seconds())
+	job.log.Debug("forklift done")
+	return nil
+}
 
-	meta := tasks.UploadMeta{
-		Hash:      hex.EncodeToString(streamSource.GetHash()),
-		MIME:      info.MediaType.MIME,
-		FileName:  payload.FileName,
-		Extension: info.MediaType.Extension,
-		Size:      streamSource.Size,
-		SDHash:    sdHash,
+// willRetry tells if the task which failed with err is going to be retried.
+func willRetry(ctx context.Context, err error) bool {
+	if errors.Is(err, asynq.SkipRetry) {
+		return false
 	}
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return !ok || retried < maxRetry
+}
 
-	if path.Ext(meta.FileName) == "" {
-		meta.FileName += info.MediaType.Extension
+// HandleFailure is called for upload tasks that have run out of retries.
+// It marks the upload as failed and lets asynquery know so the user gets an error instead of a publish stuck forever.
+// The checkpoint is kept so the task can resume if an operator requeues it.
+func (f *Forklift) HandleFailure(ctx context.Context, task *asynq.Task, taskErr error) {
+	var payload tasks.ForkliftUploadFailedPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		f.logger.Warn("message unmarshal failed", "err", err)
+		return
 	}
+	payload.Error = fmt.Sprintf("upload processing failed: %s", taskErr)
+	log := logging.TracedLogger(f.logger, payload)
 
-	if info.MediaInfo != nil {
-		meta.Width = info.MediaInfo.Width
-		meta.Height = info.MediaInfo.Height
-		meta.Duration = info.MediaInfo.Duration
+	var err error
+	switch task.Type() {
+	case tasks.ForkliftUploadIncoming:
+		err = f.queries.MarkUploadFailed(ctx, payload.UploadID)
+	case tasks.ForkliftURLIncoming:
+		err = f.queries.MarkURLFailed(ctx, database.MarkURLFailedParams{ID: payload.UploadID, Error: taskErr.Error()})
 	}
-
-	jbMeta, err := json.Marshal(meta)
 	if err != nil {
-		log.Error("failed to marshal media info", "err", err)
+		log.Error("failed to mark upload as failed", "err", err)
 	}
-
-	err = f.queries.MarkUploadProcessed(context.TODO(), database.MarkUploadProcessedParams{
-		ID:     payload.UploadID,
-		SDHash: sdHash,
-		Meta:   pqtype.NullRawMessage{RawMessage: jbMeta, Valid: true},
-	})
+	err = f.queue.SendResponse(tasks.ForkliftUploadFailed, payload, queue.WithRequestRetry(15))
 	if err != nil {
-		log.Error("failed to mark upload as processed", "err", err)
-		return err
+		log.Error("failure notification failed, bus error", "err", err)
+		return
 	}
-	log.Debug("upload processed")
+	log.Info("upload marked as failed", "err", taskErr)
+}
 
-	err = f.queue.SendResponse(tasks.ForkliftUploadDone, tasks.ForkliftUploadDonePayload{
-		UploadID: payload.UploadID,
-		UserID:   payload.UserID,
-		Meta:     meta,
-	}, queue.WithRequestRetry(15), queue.WithRequestTimeout(15*time.Minute))
-	if err != nil {
-		log.Error("merge request failed, bus error", "err", err)
-		return err
+func (f *Forklift) removeLocalFiles(job *Job) {
+	cp := job.Checkpoint
+	if cp == nil {
+		return
+	}
+	if cp.LocalFile != nil {
+		if err := cp.LocalFile.Cleanup(); err != nil && !os.IsNotExist(err) {
+			job.log.Warn("failed to remove retrieved file", "err", err)
+		}
+	}
+	if cp.BlobPath != "" {
+		if err := os.RemoveAll(cp.BlobPath); err != nil {
+			job.log.Warn("failed to remove blobs", "err", err)
+		}
 	}
-	log.Debug("forklift done")
-
-	return nil
 }
 
 func (c *Forklift) RetryDelay(count int, err error, t *asynq.Task) time.Duration {
-	if errors.Is(err, ErrReflector) {
+	if errors.Is(err, ErrReflector) || errors.Is(err, ErrBrokenStream) {
 		return time.Duration(count) * time.Minute
 	}
 	return 10 * time.Second
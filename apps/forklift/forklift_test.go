@@ -14,6 +14,7 @@ import (
 	"github.com/OdyseeTeam/odysee-api/internal/tasks"
 	"github.com/OdyseeTeam/odysee-api/internal/test"
 	"github.com/OdyseeTeam/odysee-api/internal/testdeps"
+	"github.com/OdyseeTeam/odysee-api/pkg/blobs"
 	"github.com/OdyseeTeam/odysee-api/pkg/configng"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 	"github.com/OdyseeTeam/odysee-api/pkg/queue"
@@ -60,7 +61,10 @@ func (s *forkliftSuite) TestHandleTask() {
 	s.Require().NoError(err)
 
 	// A queue for the mocking results handler
-	responsesQueue, err := queue.New(queue.WithRequestsConnURL(redisResponsesHelper.URL), queue.WithLogger(zapadapter.NewKV(nil)))
+	responsesQueue, err := queue.New(
+		queue.WithRequestsConnURL(redisResponsesHelper.URL),
+		queue.WithLogger(zapadapter.NewKV(nil)),
+		queue.WithQueues(tasks.AsynqueryQueues))
 	s.Require().NoError(err)
 
 	merges := make(chan tasks.ForkliftUploadDonePayload)
@@ -97,10 +101,7 @@ func (s *forkliftSuite) TestHandleTask() {
 				s.NotEmpty(payload.Meta.SDHash)
 				s.False(fileExists(s.s3c, s.upHelper.S3Config.Bucket, upload.Key))
 
-				for _, blobStore := range l.forklift.store.BlobStores() {
-					_, _, err = blobStore.Get(payload.Meta.SDHash)
-					s.NoError(err)
-				}
+				s.assertBlobStored(l.forklift.store, payload.Meta.SDHash)
 			},
 		},
 		{
@@ -117,10 +118,7 @@ func (s *forkliftSuite) TestHandleTask() {
 				s.NotEmpty(payload.Meta.SDHash)
 				s.False(fileExists(s.s3c, s.upHelper.S3Config.Bucket, upload.Key))
 
-				for _, blobStore := range l.forklift.store.BlobStores() {
-					_, _, err = blobStore.Get(payload.Meta.SDHash)
-					s.NoError(err)
-				}
+				s.assertBlobStored(l.forklift.store, payload.Meta.SDHash)
 			},
 		},
 		{
@@ -137,10 +135,7 @@ func (s *forkliftSuite) TestHandleTask() {
 				s.NotEmpty(payload.Meta.SDHash)
 				s.False(fileExists(s.s3c, s.upHelper.S3Config.Bucket, upload.Key))
 
-				for _, blobStore := range l.forklift.store.BlobStores() {
-					_, _, err = blobStore.Get(payload.Meta.SDHash)
-					s.NoError(err)
-				}
+				s.assertBlobStored(l.forklift.store, payload.Meta.SDHash)
 			},
 		},
 	}
@@ -164,14 +159,11 @@ func (s *forkliftSuite) TestHandleTask() {
 func (s *forkliftSuite) SetupSuite() {
 	var err error
 	s.helper, err = NewTestHelper(s.T())
-	s.Require().NoError(err)
-
-	if err != nil {
-		if errors.Is(err, ErrMissingEnv) {
-			s.T().Skip("skipping due to missing env variable")
-		} else {
-			s.FailNow(err.Error())
-		}
+	if errors.Is(err, ErrMissingEnv) {
+		s.T().Log("reflector config is missing, uploading blobs to memory")
+		s.helper = &TestHelper{ReflectorConfig: MemoryReflectorConfig()}
+	} else {
+		s.Require().NoError(err)
 	}
 
 	s.upHelper, err = uploads.NewTestHelper(s.T())
@@ -182,6 +174,20 @@ func (s *forkliftSuite) SetupSuite() {
 	s.Require().NoError(err)
 }
 
+// assertBlobStored checks that the blob made it to every S3 store of the reflector destination,
+// other destinations are checked directly.
+func (s *forkliftSuite) assertBlobStored(dest blobs.Destination, hash string) {
+	if store, ok := dest.(*blobs.Store); ok {
+		for _, blobStore := range store.BlobStores() {
+			_, _, err := blobStore.Get(hash)
+			s.NoError(err, blobStore.Name())
+		}
+		return
+	}
+	_, err := dest.Get(hash)
+	s.NoError(err, dest.Name())
+}
+
 func putFileIntoBucket(client *s3.Client, bucket, key string, file *os.File) error {
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(bucket),
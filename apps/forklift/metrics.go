@@ -5,6 +5,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/OdyseeTeam/odysee-api/pkg/blobs"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -14,6 +18,7 @@ const LabelFatal = "fatal"
 const LabelCommon = "common"
 const LabelRetrieve = "retrieve"
 const LabelAnalyze = "analyze"
+const LabelSanitize = "sanitize"
 const LabelStreamCreate = "stream_create"
 const LabelUpstream = "upstream"
 
@@ -25,15 +30,26 @@ var (
 		Name:      "wait_time_minutes",
 		Buckets:   []float64{1, 5, 10, 15, 20, 30, 45, 60, 120},
 	})
-	processingDurationSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	processingDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: ns,
 		Name:      "processing_duration_seconds",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1200, 3600},
 	}, []string{"stage"})
 	processingErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: ns,
 		Name:      "processing_errors",
 	}, []string{"stage"})
 
+	sanitizedFiles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "sanitized_files",
+	}, []string{"format"})
+
+	urlDownloadsBlocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "url_downloads_blocked",
+	}, []string{"reason"})
+
 	egressVolumeMB = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: ns,
 		Name:      "egress_volume_mb",
@@ -49,7 +65,7 @@ func registerMetrics(registry prometheus.Registerer) {
 		registry = prometheus.DefaultRegisterer
 	}
 	registry.MustRegister(
-		waitTimeMinutes, processingDurationSeconds, processingErrors, egressVolumeMB, egressDurationSeconds,
+		waitTimeMinutes, processingDurationSeconds, processingErrors, sanitizedFiles, urlDownloadsBlocked, egressVolumeMB, egressDurationSeconds,
 	)
 }
 
@@ -57,6 +73,9 @@ func BuildMetricsHandler() http.Handler {
 	registry := prometheus.NewRegistry()
 	onceMetrics.Do(func() {
 		registerMetrics(registry)
+		queue.RegisterMetrics(registry)
+		blobs.RegisterMetrics(registry)
+		reaper.RegisterMetrics(registry)
 	})
 	return promhttp.InstrumentMetricHandler(
 		registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
@@ -64,7 +83,7 @@ func BuildMetricsHandler() http.Handler {
 }
 
 func observeDuration(stage string, start time.Time) {
-	processingDurationSeconds.WithLabelValues(stage).Add(float64(time.Since(start)))
+	processingDurationSeconds.WithLabelValues(stage).Observe(time.Since(start).Seconds())
 }
 
 func observeError(stage string) {
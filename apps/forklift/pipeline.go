@@ -0,0 +1,254 @@
+package forklift
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/fileanalyzer"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/sqlc-dev/pqtype"
+)
+
+// ErrStaleCheckpoint should be returned by a stage when artifacts recorded in the checkpoint
+// by previous stages are no longer available (i.e. the task got retried on a different node).
+// Pipeline will then start over from the first stage.
+var ErrStaleCheckpoint = errors.New("checkpoint is stale")
+
+// Stage is a single step of upload processing. Stages communicate through the job checkpoint,
+// which gets persisted after each successful stage so a retried task can resume from where it failed.
+type Stage interface {
+	Name() string
+	Run(ctx context.Context, job *Job) error
+}
+
+type stageFunc struct {
+	name string
+	run  func(ctx context.Context, job *Job) error
+}
+
+// Job is an upload being processed by the pipeline.
+type Job struct {
+	UploadID     string
+	UserID       int32
+	FileName     string
+	KeepMetadata bool
+	Checkpoint   *Checkpoint
+
+	log      logging.KVLogger
+	retrieve func(ctx context.Context) (*LocalFile, error)
+	// remove deletes the original upload after processing, can be nil.
+	remove func(ctx context.Context) error
+	// markProcessed records processing results for the upload.
+	markProcessed func(ctx context.Context, sdHash string, meta pqtype.NullRawMessage) error
+}
+
+// Checkpoint contains outputs of finished stages.
+type Checkpoint struct {
+	// Stage is the last successfully finished stage.
+	Stage     string                  `json:"stage"`
+	LocalFile *LocalFile              `json:"local_file,omitempty"`
+	MediaType *fileanalyzer.MediaType `json:"media_type,omitempty"`
+	MediaInfo *fileanalyzer.MediaInfo `json:"media_info,omitempty"`
+	Sanitized []string                `json:"sanitized,omitempty"`
+	BlobPath  string                  `json:"blob_path,omitempty"`
+	Blobs     []string                `json:"blobs,omitempty"`
+	Meta      *tasks.UploadMeta       `json:"meta,omitempty"`
+}
+
+// CheckpointStore persists pipeline checkpoints between task retries.
+type CheckpointStore interface {
+	// Load returns nil checkpoint if none is saved for the upload.
+	Load(ctx context.Context, uploadID string) (*Checkpoint, error)
+	Save(ctx context.Context, uploadID string, cp *Checkpoint) error
+	Delete(ctx context.Context, uploadID string) error
+}
+
+// Pipeline runs stages in order, saving a checkpoint after each of them.
+type Pipeline struct {
+	stages      []Stage
+	checkpoints CheckpointStore
+}
+
+type stagePlacement struct {
+	stage Stage
+	after string
+}
+
+type dbCheckpointStore struct {
+	queries *database.Queries
+}
+
+type memoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string][]byte
+}
+
+// NewStage creates a pipeline stage from a function.
+func NewStage(name string, run func(ctx context.Context, job *Job) error) Stage {
+	return stageFunc{name, run}
+}
+
+func (s stageFunc) Name() string {
+	return s.name
+}
+
+func (s stageFunc) Run(ctx context.Context, job *Job) error {
+	return s.run(ctx, job)
+}
+
+func NewPipeline(checkpoints CheckpointStore, stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages, checkpoints: checkpoints}
+}
+
+// Insert adds a stage right after the stage with the given name, or to the end of the pipeline if after is empty.
+func (p *Pipeline) Insert(stage Stage, after string) error {
+	if after == "" {
+		p.stages = append(p.stages, stage)
+		return nil
+	}
+	for i, s := range p.stages {
+		if s.Name() == after {
+			p.stages = slices.Insert(p.stages, i+1, stage)
+			return nil
+		}
+	}
+	return fmt.Errorf("stage %s not found", after)
+}
+
+// Stages returns names of pipeline stages in order of execution.
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, s := range p.stages {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Run executes the pipeline for the job, skipping stages finished during previous attempts.
+func (p *Pipeline) Run(ctx context.Context, job *Job) error {
+	cp, err := p.checkpoints.Load(ctx, job.UploadID)
+	if err != nil {
+		job.log.Warn("failed to load checkpoint, starting over", "err", err)
+	}
+	if cp == nil {
+		cp = &Checkpoint{}
+	}
+	job.Checkpoint = cp
+
+	start := p.resumeIndex(cp.Stage)
+	if start > 0 {
+		job.log.Info("resuming from checkpoint", "stage", cp.Stage)
+	}
+	for i := start; i < len(p.stages); i++ {
+		stage := p.stages[i]
+		t := time.Now()
+		err := stage.Run(ctx, job)
+		observeDuration(stage.Name(), t)
+		if errors.Is(err, ErrStaleCheckpoint) && start > 0 {
+			job.log.Info("checkpoint is stale, starting over", "stage", stage.Name())
+			job.Checkpoint = &Checkpoint{}
+			start, i = 0, -1
+			continue
+		}
+		if err != nil {
+			observeError(stage.Name())
+			return fmt.Errorf("%s stage failed: %w", stage.Name(), err)
+		}
+		job.Checkpoint.Stage = stage.Name()
+		if err := p.checkpoints.Save(ctx, job.UploadID, job.Checkpoint); err != nil {
+			job.log.Warn("failed to save checkpoint", "stage", stage.Name(), "err", err)
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) resumeIndex(lastStage string) int {
+	if lastStage == "" {
+		return 0
+	}
+	for i, s := range p.stages {
+		if s.Name() == lastStage {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// NewDBCheckpointStore creates a checkpoint store backed by the uploads database.
+func NewDBCheckpointStore(db database.DBTX) CheckpointStore {
+	return &dbCheckpointStore{queries: database.New(db)}
+}
+
+func (s *dbCheckpointStore) Load(ctx context.Context, uploadID string) (*Checkpoint, error) {
+	row, err := s.queries.GetCheckpoint(ctx, uploadID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(row.State, cp); err != nil {
+		return nil, err
+	}
+	cp.Stage = row.Stage
+	return cp, nil
+}
+
+func (s *dbCheckpointStore) Save(ctx context.Context, uploadID string, cp *Checkpoint) error {
+	state, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return s.queries.SaveCheckpoint(ctx, database.SaveCheckpointParams{
+		UploadID: uploadID,
+		Stage:    cp.Stage,
+		State:    state,
+	})
+}
+
+func (s *dbCheckpointStore) Delete(ctx context.Context, uploadID string) error {
+	return s.queries.DeleteCheckpoint(ctx, uploadID)
+}
+
+// NewMemoryCheckpointStore creates a process-local checkpoint store, useful for tests and single node setups.
+func NewMemoryCheckpointStore() CheckpointStore {
+	return &memoryCheckpointStore{checkpoints: map[string][]byte{}}
+}
+
+func (s *memoryCheckpointStore) Load(_ context.Context, uploadID string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.checkpoints[uploadID]
+	if !ok {
+		return nil, nil
+	}
+	cp := &Checkpoint{}
+	return cp, json.Unmarshal(data, cp)
+}
+
+func (s *memoryCheckpointStore) Save(_ context.Context, uploadID string, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[uploadID] = data
+	return nil
+}
+
+func (s *memoryCheckpointStore) Delete(_ context.Context, uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, uploadID)
+	return nil
+}
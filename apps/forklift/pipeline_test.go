@@ -0,0 +1,124 @@
+package forklift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countingStage(name string, calls map[string]int, fail *bool) Stage {
+	return NewStage(name, func(_ context.Context, job *Job) error {
+		calls[name]++
+		if fail != nil && *fail {
+			return errors.New("stage failure")
+		}
+		job.Checkpoint.Blobs = append(job.Checkpoint.Blobs, name)
+		return nil
+	})
+}
+
+func TestPipelineResume(t *testing.T) {
+	calls := map[string]int{}
+	fail := true
+	store := NewMemoryCheckpointStore()
+	p := NewPipeline(store,
+		countingStage("one", calls, nil),
+		countingStage("two", calls, nil),
+		countingStage("three", calls, &fail),
+	)
+	job := &Job{UploadID: "abc", log: logging.NoopKVLogger{}}
+
+	err := p.Run(context.Background(), job)
+	require.Error(t, err)
+	assert.Equal(t, map[string]int{"one": 1, "two": 1, "three": 1}, calls)
+
+	cp, err := store.Load(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "two", cp.Stage)
+	assert.Equal(t, []string{"one", "two"}, cp.Blobs)
+
+	fail = false
+	job = &Job{UploadID: "abc", log: logging.NoopKVLogger{}}
+	require.NoError(t, p.Run(context.Background(), job))
+	assert.Equal(t, map[string]int{"one": 1, "two": 1, "three": 2}, calls)
+	assert.Equal(t, []string{"one", "two", "three"}, job.Checkpoint.Blobs)
+}
+
+func TestPipelineStaleCheckpoint(t *testing.T) {
+	calls := map[string]int{}
+	store := NewMemoryCheckpointStore()
+	require.NoError(t, store.Save(context.Background(), "abc", &Checkpoint{Stage: "one"}))
+
+	p := NewPipeline(store,
+		countingStage("one", calls, nil),
+		NewStage("two", func(_ context.Context, job *Job) error {
+			calls["two"]++
+			if len(job.Checkpoint.Blobs) == 0 {
+				return ErrStaleCheckpoint
+			}
+			return nil
+		}),
+	)
+	job := &Job{UploadID: "abc", log: logging.NoopKVLogger{}}
+	require.NoError(t, p.Run(context.Background(), job))
+	assert.Equal(t, map[string]int{"one": 1, "two": 2}, calls)
+}
+
+func TestPipelineInsert(t *testing.T) {
+	calls := map[string]int{}
+	p := NewPipeline(NewMemoryCheckpointStore(), countingStage("one", calls, nil), countingStage("three", calls, nil))
+	require.NoError(t, p.Insert(countingStage("two", calls, nil), "one"))
+	require.NoError(t, p.Insert(countingStage("four", calls, nil), ""))
+	assert.Equal(t, []string{"one", "two", "three", "four"}, p.Stages())
+	assert.Error(t, p.Insert(countingStage("five", calls, nil), "six"))
+}
+
+func TestProcessRemovesLocalFilesOnFatalError(t *testing.T) {
+	dir := t.TempDir()
+	retrieve := func(_ context.Context, job *Job) error {
+		name := filepath.Join(dir, job.UploadID)
+		if err := os.WriteFile(name, []byte("data"), 0o644); err != nil {
+			return err
+		}
+		job.Checkpoint.LocalFile = &LocalFile{Name: name, Size: 4}
+		return nil
+	}
+	cases := []struct {
+		name string
+		err  error
+		kept bool
+	}{
+		{"retried", errors.New("temporary failure"), true},
+		{"fatal", fmt.Errorf("bad file: %w", asynq.SkipRetry), false},
+		{"done", nil, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := &Forklift{pipeline: NewPipeline(NewMemoryCheckpointStore(),
+				NewStage(LabelRetrieve, retrieve),
+				NewStage(LabelAnalyze, func(context.Context, *Job) error { return c.err }),
+			)}
+			job := &Job{UploadID: c.name, log: logging.NoopKVLogger{}}
+			err := f.process(context.Background(), job)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			if c.kept {
+				assert.FileExists(t, filepath.Join(dir, c.name))
+			} else {
+				assert.NoFileExists(t, filepath.Join(dir, c.name))
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"time"
@@ -17,17 +19,26 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hibiken/asynq"
 )
 
 const (
 	defaultRequestTimeout = 600 * time.Second
+	maxRedirects          = 10
+	progressInterval      = 5 * time.Second
 )
 
-var errFatalRetrieverFailure = fmt.Errorf("fatal retriever error")
+var (
+	// errFatalRetrieverFailure marks errors that retrying won't fix, the task is failed right away.
+	errFatalRetrieverFailure = fmt.Errorf("fatal retriever error: %w", asynq.SkipRetry)
+
+	ErrURLNotAllowed = fmt.Errorf("%w: url is not allowed", errFatalRetrieverFailure)
+	ErrFileTooLarge  = fmt.Errorf("%w: remote file is too large", errFatalRetrieverFailure)
+)
 
 type LocalFile struct {
-	Name string
-	Size int64
+	Name string `json:"name"`
+	Size int64  `json:"size"`
 }
 
 type S3Retriever struct {
@@ -39,6 +50,22 @@ type S3Retriever struct {
 type HTTPRetriever struct {
 	tempPath string
 	client   *http.Client
+	timeout  time.Duration
+	maxSize  int64
+	guard    *urlGuard
+}
+
+type HTTPRetrieverOption func(r *HTTPRetriever)
+
+// ProgressFunc receives the number of bytes downloaded so far and the expected total, -1 if unknown.
+type ProgressFunc func(received, total int64)
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	reported time.Time
+	report   ProgressFunc
 }
 
 // NewS3Retriever creates a new retriever that downloads files from S3 uploads storage.
@@ -91,23 +118,87 @@ func (f LocalFile) Cleanup() error {
 	return os.Remove(f.Name)
 }
 
-// NewS3Retriever creates a new retriever that downloads files from S3 uploads storage.
-func NewHTTPRetriever(tempPath string) *HTTPRetriever {
-	return &HTTPRetriever{
+// NewHTTPRetriever creates a new retriever that downloads files from remote URLs.
+// Files are only downloaded from public addresses unless WithPrivateNetworks is supplied.
+func NewHTTPRetriever(tempPath string, options ...HTTPRetrieverOption) *HTTPRetriever {
+	r := &HTTPRetriever{
 		tempPath: tempPath,
-		client: &http.Client{
-			Timeout: defaultRequestTimeout,
+		timeout:  defaultRequestTimeout,
+		guard:    &urlGuard{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   r.guard.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxies would make the dialer connect to the proxy instead of the target host, bypassing address checks.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	r.client = &http.Client{
+		Timeout:   r.timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("%w: too many redirects", errFatalRetrieverFailure)
+			}
+			return r.guard.checkURL(req.URL)
 		},
 	}
+	return r
 }
 
-// Retrieve downloads the uploaded file from S3 and returns a local file path.
-func (r *HTTPRetriever) Retrieve(ctx context.Context, uploadID string, loc tasks.FileLocationHTTP) (*LocalFile, error) {
-	httpReq, err := http.NewRequest(
-		http.MethodGet,
-		loc.URL,
-		http.NoBody,
-	)
+// WithMaxFileSize limits size of downloaded files, both declared in Content-Length and actually received.
+func WithMaxFileSize(size int64) HTTPRetrieverOption {
+	return func(r *HTTPRetriever) {
+		r.maxSize = size
+	}
+}
+
+// WithDownloadTimeout limits time a single download can take.
+func WithDownloadTimeout(timeout time.Duration) HTTPRetrieverOption {
+	return func(r *HTTPRetriever) {
+		r.timeout = timeout
+	}
+}
+
+// WithAllowedHosts restricts downloads to hosts matching any of the glob patterns (i.e. "*.example.com").
+func WithAllowedHosts(patterns ...string) HTTPRetrieverOption {
+	return func(r *HTTPRetriever) {
+		r.guard.allowedHosts = append(r.guard.allowedHosts, patterns...)
+	}
+}
+
+// WithDeniedHosts forbids downloads from hosts matching any of the glob patterns.
+func WithDeniedHosts(patterns ...string) HTTPRetrieverOption {
+	return func(r *HTTPRetriever) {
+		r.guard.deniedHosts = append(r.guard.deniedHosts, patterns...)
+	}
+}
+
+// WithPrivateNetworks allows downloads from private and loopback addresses, should only be used for testing.
+func WithPrivateNetworks() HTTPRetrieverOption {
+	return func(r *HTTPRetriever) {
+		r.guard.allowPrivate = true
+	}
+}
+
+// Retrieve downloads the remote file and returns a local file path.
+// Progress function is optional and gets called periodically with the number of bytes received
+// and the expected file size, which is -1 when unknown.
+func (r *HTTPRetriever) Retrieve(ctx context.Context, uploadID string, loc tasks.FileLocationHTTP, progress ProgressFunc) (*LocalFile, error) {
+	u, err := url.Parse(loc.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid url: %s", errFatalRetrieverFailure, err)
+	}
+	if err := r.guard.checkURL(u); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("%w: error creating request", errFatalRetrieverFailure)
 	}
@@ -116,30 +207,61 @@ func (r *HTTPRetriever) Retrieve(ctx context.Context, uploadID string, loc tasks
 	if err != nil {
 		return nil, fmt.Errorf("error fetching remote file: %w", err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("remote server returned non-OK status: %v", resp.StatusCode)
 	}
-	defer resp.Body.Close()
+	if r.maxSize > 0 && resp.ContentLength > r.maxSize {
+		urlDownloadsBlocked.WithLabelValues(blockedReasonSize).Inc()
+		return nil, fmt.Errorf("%w: declared size %d exceeds %d", ErrFileTooLarge, resp.ContentLength, r.maxSize)
+	}
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+	progress(0, resp.ContentLength)
 
 	sf, err := os.Create(path.Join(r.tempPath, uploadID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create local file (%s): %w", path.Join(r.tempPath, uploadID), err)
 	}
 	defer sf.Close()
-	n, err := io.Copy(sf, resp.Body)
+
+	var body io.Reader = resp.Body
+	if r.maxSize > 0 {
+		body = io.LimitReader(resp.Body, r.maxSize+1)
+	}
+	pw := &progressWriter{w: sf, total: resp.ContentLength, report: progress, reported: time.Now()}
+	n, err := io.Copy(pw, body)
 	if err != nil {
 		os.Remove(sf.Name())
 		return nil, fmt.Errorf("error saving uploaded file: %w", err)
 	}
+	if r.maxSize > 0 && n > r.maxSize {
+		sf.Close()
+		os.Remove(sf.Name())
+		urlDownloadsBlocked.WithLabelValues(blockedReasonSize).Inc()
+		return nil, fmt.Errorf("%w: received more than %d bytes", ErrFileTooLarge, r.maxSize)
+	}
 	if n == 0 {
 		sf.Close()
 		os.Remove(sf.Name())
 		return nil, errors.New("remote file is empty")
 	}
+	progress(n, resp.ContentLength)
 
 	return &LocalFile{sf.Name(), n}, nil
 }
 
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	if time.Since(w.reported) >= progressInterval {
+		w.reported = time.Now()
+		w.report(w.written, w.total)
+	}
+	return n, err
+}
+
 func hashURL(url string) string {
 	hash := sha256.Sum256([]byte(url))
 	return hex.EncodeToString(hash[:])
@@ -0,0 +1,124 @@
+package forklift
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLGuardCheckAddr(t *testing.T) {
+	g := &urlGuard{}
+	for _, a := range []string{"127.0.0.1", "10.1.2.3", "172.20.0.1", "192.168.1.1", "169.254.169.254", "100.100.100.200", "::1", "fd00:ec2::254", "fe80::1", "::ffff:10.0.0.1", "0.0.0.0", "192.88.99.1", "2002:a9fe:a9fe::1", "2002:7f00:1::1", "::7f00:1", "::", "64:ff9b::a00:1", "64:ff9b:1::a00:1", "2001:0:4136:e378:8000:63bf:3fff:fdd2"} {
+		assert.ErrorIs(t, g.checkAddr(netip.MustParseAddr(a)), ErrURLNotAllowed, a)
+	}
+	for _, a := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.NoError(t, g.checkAddr(netip.MustParseAddr(a)), a)
+	}
+	assert.NoError(t, (&urlGuard{allowPrivate: true}).checkAddr(netip.MustParseAddr("127.0.0.1")))
+}
+
+func TestURLGuardCheckURL(t *testing.T) {
+	g := &urlGuard{allowedHosts: []string{"*.example.com", "example.com"}, deniedHosts: []string{"bad.example.com"}}
+	cases := map[string]bool{
+		"https://example.com/file.mp4":        true,
+		"https://cdn.Example.com/file.mp4":    true,
+		"https://bad.example.com/file.mp4":    false,
+		"https://example.org/file.mp4":        false,
+		"ftp://example.com/file.mp4":          false,
+		"file:///etc/passwd":                  false,
+		"http://169.254.169.254/latest/meta/": false,
+	}
+	for raw, allowed := range cases {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		err = g.checkURL(u)
+		if allowed {
+			assert.NoError(t, err, raw)
+		} else {
+			assert.ErrorIs(t, err, ErrURLNotAllowed, raw)
+		}
+	}
+}
+
+func TestHTTPRetriever(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file":
+			w.Write([]byte(content))
+		case "/chunked":
+			for range 10 {
+				w.Write([]byte(content))
+				w.(http.Flusher).Flush()
+			}
+		case "/redirect":
+			http.Redirect(w, r, strings.Replace(r.Host, "127.0.0.1", "http://localhost", 1)+"/file", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+	localhostURL := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	retrieve := func(r *HTTPRetriever, u string, progress ProgressFunc) (*LocalFile, error) {
+		return r.Retrieve(context.Background(), "abc", tasks.FileLocationHTTP{URL: u}, progress)
+	}
+
+	t.Run("blocks private addresses after resolution", func(t *testing.T) {
+		_, err := retrieve(NewHTTPRetriever(t.TempDir()), localhostURL+"/file", nil)
+		assert.ErrorIs(t, err, ErrURLNotAllowed)
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+	})
+
+	t.Run("checks redirects", func(t *testing.T) {
+		r := NewHTTPRetriever(t.TempDir(), WithPrivateNetworks(), WithDeniedHosts("localhost"))
+		_, err := retrieve(r, ts.URL+"/redirect", nil)
+		assert.ErrorIs(t, err, ErrURLNotAllowed)
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		r := NewHTTPRetriever(t.TempDir(), WithPrivateNetworks(), WithAllowedHosts("*.example.com"))
+		_, err := retrieve(r, ts.URL+"/file", nil)
+		assert.ErrorIs(t, err, ErrURLNotAllowed)
+	})
+
+	t.Run("declared size cap", func(t *testing.T) {
+		r := NewHTTPRetriever(t.TempDir(), WithPrivateNetworks(), WithMaxFileSize(999))
+		_, err := retrieve(r, ts.URL+"/file", nil)
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+	})
+
+	t.Run("received size cap", func(t *testing.T) {
+		dir := t.TempDir()
+		r := NewHTTPRetriever(dir, WithPrivateNetworks(), WithMaxFileSize(5000))
+		_, err := retrieve(r, ts.URL+"/chunked", nil)
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var received, total []int64
+		r := NewHTTPRetriever(t.TempDir(), WithPrivateNetworks(), WithMaxFileSize(1000))
+		lf, err := retrieve(r, ts.URL+"/file", func(r, t int64) {
+			received = append(received, r)
+			total = append(total, t)
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1000, lf.Size)
+		assert.Equal(t, []int64{0, 1000}, received)
+		assert.Equal(t, []int64{1000, 1000}, total)
+		data, err := os.ReadFile(lf.Name)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+}
@@ -0,0 +1,291 @@
+package forklift
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/blobs"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/sanitizer"
+
+	"github.com/hibiken/asynq"
+	pb "github.com/lbryio/types/v2/go"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const (
+	LabelVerify  = "verify"
+	LabelMark    = "mark"
+	LabelRespond = "respond"
+)
+
+// ErrSanitizationFailed is returned for files which metadata was found in but couldn't be removed from, they are never published.
+var ErrSanitizationFailed = fmt.Errorf("%w: file metadata cannot be removed", asynq.SkipRetry)
+
+// defaultStages returns the standard upload processing sequence.
+func (f *Forklift) defaultStages() []Stage {
+	return []Stage{
+		NewStage(LabelRetrieve, f.retrieve),
+		NewStage(LabelAnalyze, f.analyze),
+		NewStage(LabelSanitize, f.sanitize),
+		NewStage(LabelStreamCreate, f.split),
+		NewStage(LabelUpstream, f.upstream),
+		NewStage(LabelMark, f.mark),
+		NewStage(LabelRespond, f.respond),
+	}
+}
+
+func (f *Forklift) retrieve(ctx context.Context, job *Job) error {
+	localFile, err := job.retrieve(ctx)
+	if err != nil {
+		job.log.Warn("failed to retrieve file", "err", err)
+		return err
+	}
+	job.Checkpoint.LocalFile = localFile
+	job.log.Debug("file retrieved", "size", localFile.Size)
+	return nil
+}
+
+func (f *Forklift) analyze(ctx context.Context, job *Job) error {
+	localFile, err := job.localFile()
+	if err != nil {
+		return err
+	}
+	info, err := f.analyzer.Analyze(ctx, localFile.Name, job.FileName)
+	if info == nil {
+		job.log.Warn("file analysis failed", "err", err, "file", localFile.Name)
+		return err
+	}
+	job.log.Debug("file analyzed", "result", info, "err", err)
+	job.Checkpoint.MediaType = info.MediaType
+	job.Checkpoint.MediaInfo = info.MediaInfo
+	return nil
+}
+
+// sanitize strips privacy-sensitive metadata from the retrieved file unless the publisher opted out of it.
+// Files known to keep metadata after a failure are not published, retrying won't change the outcome so the task is failed.
+// Other files sanitization fails for, like too large or malformed ones, are published as is,
+// while failures to read or write the file are retried.
+func (f *Forklift) sanitize(_ context.Context, job *Job) error {
+	localFile, err := job.localFile()
+	if err != nil {
+		return err
+	}
+	if f.sanitizer == nil {
+		return nil
+	}
+	if job.KeepMetadata {
+		job.log.Debug("publisher opted out of metadata sanitization")
+		return nil
+	}
+	report, err := f.sanitizer.Sanitize(localFile.Name)
+	if err != nil {
+		observeError(LabelSanitize)
+		var pathErr *fs.PathError
+		var linkErr *os.LinkError
+		switch {
+		case errors.Is(err, sanitizer.ErrMetadataRemains):
+			job.log.Warn("file sanitization failed", "err", err, "file", localFile.Name)
+			return fmt.Errorf("%w: %w", ErrSanitizationFailed, err)
+		case errors.As(err, &pathErr), errors.As(err, &linkErr):
+			job.log.Warn("file sanitization failed", "err", err, "file", localFile.Name)
+			return err
+		default:
+			job.log.Warn("file sanitization failed, publishing it as is", "err", err, "file", localFile.Name)
+			return nil
+		}
+	}
+	if report.Empty() {
+		return nil
+	}
+	sanitizedFiles.WithLabelValues(report.Format).Inc()
+	job.log.Info("file metadata removed", "format", report.Format, "removed", report.Removed)
+	job.Checkpoint.Sanitized = report.Removed
+	return nil
+}
+
+func (f *Forklift) split(_ context.Context, job *Job) error {
+	localFile, err := job.localFile()
+	if err != nil {
+		return err
+	}
+	if job.Checkpoint.MediaType == nil {
+		return ErrStaleCheckpoint
+	}
+	blobPath := path.Join(f.blobPath, localFile.Name)
+	src := blobs.NewSource(localFile.Name, blobPath, job.FileName)
+	job.log.Debug("creating stream")
+	stream, err := src.Split()
+	if err != nil {
+		job.log.Warn("failed to create stream", "err", err, "file", localFile.Name, "blobs_path", f.blobPath)
+		return err
+	}
+	streamSource := stream.GetSource()
+	sdHash := hex.EncodeToString(streamSource.GetSdHash())
+	job.log = job.log.With("sd_hash", sdHash)
+	job.log.Debug("stream created")
+
+	meta := &tasks.UploadMeta{
+		Hash:      hex.EncodeToString(streamSource.GetHash()),
+		MIME:      job.Checkpoint.MediaType.MIME,
+		FileName:  job.FileName,
+		Extension: job.Checkpoint.MediaType.Extension,
+		Size:      streamSource.Size,
+		SDHash:    sdHash,
+		Sanitized: job.Checkpoint.Sanitized,
+	}
+	if path.Ext(meta.FileName) == "" {
+		meta.FileName += meta.Extension
+	}
+	if info := job.Checkpoint.MediaInfo; info != nil {
+		meta.Width = info.Width
+		meta.Height = info.Height
+		meta.Duration = info.Duration
+	}
+
+	job.Checkpoint.BlobPath = src.BlobPath()
+	job.Checkpoint.Blobs = src.Manifest()
+	job.Checkpoint.Meta = meta
+	return nil
+}
+
+func (f *Forklift) upstream(_ context.Context, job *Job) error {
+	cp := job.Checkpoint
+	if _, err := os.Stat(cp.BlobPath); err != nil {
+		return ErrStaleCheckpoint
+	}
+	sdHash, err := hex.DecodeString(cp.Meta.SDHash)
+	if err != nil {
+		return err
+	}
+	hash, err := hex.DecodeString(cp.Meta.Hash)
+	if err != nil {
+		return err
+	}
+	src := blobs.RestoreSource(cp.BlobPath, cp.Blobs, &pb.Stream{
+		Source: &pb.Source{
+			SdHash: sdHash,
+			Hash:   hash,
+			Name:   job.FileName,
+			Size:   cp.Meta.Size,
+		},
+	})
+
+	start := time.Now()
+	job.log.Debug("starting upload")
+	uploader := blobs.NewUploader(
+		f.store,
+		blobs.WithWorkers(f.workers),
+		blobs.WithUploaderLogger(job.log),
+		blobs.WithProgress(func(p blobs.Progress) {
+			if p.Done()%50 == 0 {
+				job.log.Debug("uploading stream blobs", "done", p.Done(), "total", p.Total, "destination", p.Destination)
+			}
+		}),
+	)
+	summary, err := uploader.Upload(src)
+	egressDurationSeconds.Add(float64(time.Since(start)))
+	egressVolumeMB.Add(float64(cp.Meta.Size / 1024 / 1024))
+	if err != nil {
+		job.log.Warn("blobs upload failed", "err", err, "blobs_path", cp.BlobPath)
+		return err
+	} else if summary.Err > 0 {
+		job.log.Warn(ErrReflector.Error(), "err_count", summary.Err, "blobs_path", cp.BlobPath, "destination", f.store.Name())
+		return ErrReflector
+	}
+	job.log.Debug("stream blobs uploaded", "seconds", time.Since(start).Seconds())
+	return nil
+}
+
+// verify checks that all blobs of the stream made it to the destination intact.
+// Broken blobs are uploaded again from the local blobs directory or the retrieved file and checked once more.
+func (f *Forklift) verify(_ context.Context, job *Job) error {
+	cp := job.Checkpoint
+	if cp.Meta == nil {
+		return ErrStaleCheckpoint
+	}
+	report, err := f.verifier.Verify(cp.Meta.SDHash)
+	if err != nil {
+		job.log.Warn("stream verification failed", "err", err)
+		return err
+	}
+	if report.OK() {
+		job.log.Debug("stream blobs verified", "blobs", report.Blobs)
+		return nil
+	}
+	job.log.Warn("stream blobs are broken", "missing", len(report.Missing), "corrupt", len(report.Corrupt))
+
+	source := blobs.ReuploadSource{BlobPath: cp.BlobPath}
+	if cp.LocalFile != nil {
+		source.FilePath = cp.LocalFile.Name
+	}
+	summary, err := f.verifier.Reupload(report, source)
+	if err != nil {
+		job.log.Warn("failed to upload broken blobs again", "err", err)
+		return err
+	}
+	if summary.Err > 0 {
+		if _, err := os.Stat(cp.BlobPath); err != nil {
+			return ErrStaleCheckpoint
+		}
+		job.log.Warn(ErrBrokenStream.Error(), "not_restored", summary.Err)
+		return ErrBrokenStream
+	}
+	report, err = f.verifier.Verify(cp.Meta.SDHash)
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		job.log.Warn(ErrBrokenStream.Error(), "missing", len(report.Missing), "corrupt", len(report.Corrupt))
+		return ErrBrokenStream
+	}
+	job.log.Info("broken stream blobs uploaded again", "count", summary.Sd+summary.Blob)
+	return nil
+}
+
+func (f *Forklift) mark(ctx context.Context, job *Job) error {
+	jbMeta, err := json.Marshal(job.Checkpoint.Meta)
+	if err != nil {
+		job.log.Error("failed to marshal media info", "err", err)
+	}
+	err = job.markProcessed(ctx, job.Checkpoint.Meta.SDHash, pqtype.NullRawMessage{RawMessage: jbMeta, Valid: true})
+	if err != nil {
+		job.log.Error("failed to mark upload as processed", "err", err)
+		return err
+	}
+	job.log.Debug("upload processed")
+	return nil
+}
+
+func (f *Forklift) respond(_ context.Context, job *Job) error {
+	err := f.queue.SendResponse(tasks.ForkliftUploadDone, tasks.ForkliftUploadDonePayload{
+		UploadID: job.UploadID,
+		UserID:   job.UserID,
+		Meta:     *job.Checkpoint.Meta,
+	}, queue.WithRequestRetry(15), queue.WithRequestTimeout(15*time.Minute))
+	if err != nil {
+		job.log.Error("merge request failed, bus error", "err", err)
+		return err
+	}
+	return nil
+}
+
+// localFile returns the retrieved file if it is still present on disk.
+func (job *Job) localFile() (*LocalFile, error) {
+	lf := job.Checkpoint.LocalFile
+	if lf == nil {
+		return nil, ErrStaleCheckpoint
+	}
+	if _, err := os.Stat(lf.Name); err != nil {
+		return nil, ErrStaleCheckpoint
+	}
+	return lf, nil
+}
@@ -0,0 +1,66 @@
+package forklift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+	"github.com/OdyseeTeam/odysee-api/pkg/sanitizer"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFailure(t *testing.T) {
+	// A JPEG header is enough to be detected as an image needing sanitization.
+	jfif := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00}
+	exif := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x08, 'E', 'x', 'i', 'f', 0x00, 0x00}
+
+	cases := []struct {
+		name     string
+		original []byte
+		options  []sanitizer.Option
+		fatal    bool
+	}{
+		{"too large", append(jfif, make([]byte, 512)...), []sanitizer.Option{sanitizer.WithMaxInMemorySize(16)}, false},
+		{"malformed", append(jfif, make([]byte, 512)...), nil, false},
+		{"malformed with metadata", append(exif, make([]byte, 512)...), nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "photo.jpg")
+			require.NoError(t, os.WriteFile(name, c.original, 0o644))
+
+			f := &Forklift{sanitizer: sanitizer.New(c.options...)}
+			job := &Job{UploadID: "abc", log: logging.NoopKVLogger{}, Checkpoint: &Checkpoint{LocalFile: &LocalFile{Name: name}}}
+
+			err := f.sanitize(context.Background(), job)
+			if c.fatal {
+				require.ErrorIs(t, err, ErrSanitizationFailed)
+				require.ErrorIs(t, err, asynq.SkipRetry)
+				assert.ErrorIs(t, err, sanitizer.ErrMetadataRemains)
+			} else {
+				require.NoError(t, err)
+				assert.Empty(t, job.Checkpoint.Sanitized)
+			}
+
+			content, err := os.ReadFile(name)
+			require.NoError(t, err)
+			assert.Equal(t, c.original, content)
+
+			// Publishers opting out of sanitization get their files published as is.
+			job.KeepMetadata = true
+			require.NoError(t, f.sanitize(context.Background(), job))
+		})
+	}
+
+	// Files which can't be read are retried.
+	f := &Forklift{sanitizer: sanitizer.New()}
+	job := &Job{UploadID: "abc", log: logging.NoopKVLogger{}, Checkpoint: &Checkpoint{LocalFile: &LocalFile{Name: filepath.Join(t.TempDir(), "missing.jpg")}}}
+	err := f.sanitize(context.Background(), job)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, asynq.SkipRetry)
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/OdyseeTeam/odysee-api/pkg/blobs"
+
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
@@ -32,6 +34,13 @@ func NewTestHelper(t *testing.T) (*TestHelper, error) {
 	return th, nil
 }
 
+// MemoryReflectorConfig returns a config keeping uploaded blobs in memory, for running tests without reflector.
+func MemoryReflectorConfig() *viper.Viper {
+	v := viper.New()
+	v.Set("Type", blobs.DestinationMemory)
+	return v
+}
+
 func DecodeSecretViperConfig(t *testing.T, secretEnvName string) *viper.Viper {
 	require := require.New(t)
 	secretValueEncoded := os.Getenv(secretEnvName)
@@ -0,0 +1,121 @@
+package forklift
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+)
+
+const (
+	blockedReasonScheme  = "scheme"
+	blockedReasonHost    = "host"
+	blockedReasonAddress = "address"
+	blockedReasonSize    = "size"
+)
+
+// blockedPrefixes are address ranges remote files are never downloaded from: loopback, private,
+// link-local (cloud metadata endpoints included), carrier-grade NAT, multicast and reserved networks.
+// Ranges embedding arbitrary IPv4 addresses (IPv4-compatible, NAT64, Teredo and 6to4) are blocked
+// too, as they can reach private IPv4 networks; IPv4-mapped addresses are unmapped before the check.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/32"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// urlGuard decides which remote URLs are safe to download.
+// Host patterns are checked before each request, redirects included, while addresses are checked
+// at connection time, after DNS resolution, so hostnames pointing to internal networks are caught too.
+type urlGuard struct {
+	allowedHosts []string
+	deniedHosts  []string
+	allowPrivate bool
+}
+
+// checkURL verifies URL scheme and host against configured patterns.
+func (g *urlGuard) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		urlDownloadsBlocked.WithLabelValues(blockedReasonScheme).Inc()
+		return fmt.Errorf("%w: unsupported scheme %q", ErrURLNotAllowed, u.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		urlDownloadsBlocked.WithLabelValues(blockedReasonHost).Inc()
+		return fmt.Errorf("%w: empty host", ErrURLNotAllowed)
+	}
+	if matchHost(g.deniedHosts, host) {
+		urlDownloadsBlocked.WithLabelValues(blockedReasonHost).Inc()
+		return fmt.Errorf("%w: host %s is denied", ErrURLNotAllowed, host)
+	}
+	if len(g.allowedHosts) > 0 && !matchHost(g.allowedHosts, host) {
+		urlDownloadsBlocked.WithLabelValues(blockedReasonHost).Inc()
+		return fmt.Errorf("%w: host %s is not allowed", ErrURLNotAllowed, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.checkAddr(addr)
+	}
+	return nil
+}
+
+// control is a net.Dialer hook verifying the resolved address right before connecting.
+func (g *urlGuard) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrURLNotAllowed, err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrURLNotAllowed, err)
+	}
+	return g.checkAddr(addr)
+}
+
+func (g *urlGuard) checkAddr(addr netip.Addr) error {
+	if g.allowPrivate {
+		return nil
+	}
+	addr = addr.Unmap()
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			urlDownloadsBlocked.WithLabelValues(blockedReasonAddress).Inc()
+			return fmt.Errorf("%w: address %s is in blocked network %s", ErrURLNotAllowed, addr, p)
+		}
+	}
+	return nil
+}
+
+// matchHost matches host against glob patterns like "*.example.com".
+func matchHost(patterns []string, host string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), host); ok {
+			return true
+		}
+	}
+	return false
+}
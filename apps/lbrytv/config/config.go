@@ -9,6 +9,9 @@ import (
 
 	cfg "github.com/OdyseeTeam/odysee-api/config"
 	"github.com/OdyseeTeam/odysee-api/models"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+	"github.com/OdyseeTeam/odysee-api/pkg/urlsigner"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
 
@@ -42,6 +45,11 @@ func IsProduction() bool {
 	return Config.IsProduction()
 }
 
+// GetChainqueryURL returns the chainquery SQL API endpoint, empty for the default one.
+func GetChainqueryURL() string {
+	return Config.Viper.GetString("ChainqueryURL")
+}
+
 // GetInternalAPIHost returns the address of internal-api server.
 func GetInternalAPIHost() string {
 	return Config.Viper.GetString("InternalAPIHost")
@@ -82,6 +90,16 @@ func GetAsynqueryRequestsConnOpts() (asynq.RedisConnOpt, error) {
 	return asynq.ParseRedisURI(Config.Viper.GetString("AsynqueryRequestsConnURL"))
 }
 
+// GetForkliftRequestsConnOpts returns Redis connection options for forklift requests queue,
+// nil is returned if it is not configured.
+func GetForkliftRequestsConnOpts() (asynq.RedisConnOpt, error) {
+	url := Config.Viper.GetString("ForkliftRequestsConnURL")
+	if url == "" {
+		return nil, nil
+	}
+	return asynq.ParseRedisURI(url)
+}
+
 func GetSturdyCacheMaster() string {
 	return Config.Viper.GetString("sturdycache.master")
 }
@@ -116,6 +134,49 @@ func GetGeoPublishSourceDir() string {
 	return Config.Viper.GetString("GeoPublishSourceDir")
 }
 
+// GetPublishReaperConfig returns settings for removing abandoned uploads from publish source directories.
+func GetPublishReaperConfig() reaper.Config {
+	var c reaper.Config
+	if err := Config.Viper.UnmarshalKey("PublishReaper", &c); err != nil {
+		panic(fmt.Sprintf("invalid PublishReaper config: %s", err))
+	}
+	return c
+}
+
+// GetAsynqueryQueueConfig returns queue weights and per-user limits for asynquery request processing.
+func GetAsynqueryQueueConfig() queue.Config {
+	var c queue.Config
+	if err := Config.Viper.UnmarshalKey("AsynqueryQueue", &c); err != nil {
+		panic(fmt.Sprintf("invalid AsynqueryQueue config: %s", err))
+	}
+	return c
+}
+
+// PeriodicTasksConfig schedules maintenance jobs which are run by a single API instance at a time.
+type PeriodicTasksConfig struct {
+	Enabled bool
+	// UnloadWalletsSchedule is a cron expression in UTC or "@every <duration>", "off" disables wallet unloading.
+	UnloadWalletsSchedule string
+	// UnloadWalletsAfter is how long a wallet can stay unused before it gets unloaded.
+	UnloadWalletsAfter time.Duration
+}
+
+// GetPeriodicTasksConfig returns schedules for maintenance jobs coordinated through asynquery Redis.
+func GetPeriodicTasksConfig() PeriodicTasksConfig {
+	Config.Viper.SetDefault("PeriodicTasks.UnloadWalletsSchedule", "@every 10m")
+	Config.Viper.SetDefault("PeriodicTasks.UnloadWalletsAfter", time.Hour)
+	var c PeriodicTasksConfig
+	if err := Config.Viper.UnmarshalKey("PeriodicTasks", &c); err != nil {
+		panic(fmt.Sprintf("invalid PeriodicTasks config: %s", err))
+	}
+	return c
+}
+
+// GetPublishReaperEnabled enables periodic removal of abandoned uploads by each API instance.
+func GetPublishReaperEnabled() bool {
+	return Config.Viper.GetBool("PublishReaper.Enabled")
+}
+
 // GetGeoPublishConcurrency sets the number of simultaneously processed uploads per each API instance.
 func GetGeoPublishConcurrency() int {
 	Config.Viper.SetDefault("GeoPublishConcurrency", 3)
@@ -137,6 +198,32 @@ func GetUploadTokenPrivateKey() string {
 	return strings.TrimSpace(Config.Viper.GetString("UploadTokenPrivateKey"))
 }
 
+// KeyringConfig points to the keyring file tokens are signed with, see the keys command.
+// A single private key from config is used if File is empty.
+type KeyringConfig struct {
+	File string
+	// GracePeriod is how long tokens signed with a retired key are still accepted.
+	GracePeriod time.Duration
+}
+
+// GetUploadTokenKeyringConfig returns upload token keyring settings, UploadTokenPrivateKey is used if File is empty.
+func GetUploadTokenKeyringConfig() KeyringConfig {
+	return getKeyringConfig("UploadTokenKeyring")
+}
+
+// GetPaidTokenKeyringConfig returns paid stream token keyring settings, the PaidTokenPrivKey RSA key is used if File is empty.
+func GetPaidTokenKeyringConfig() KeyringConfig {
+	return getKeyringConfig("PaidTokenKeyring")
+}
+
+func getKeyringConfig(key string) KeyringConfig {
+	var c KeyringConfig
+	if err := Config.Viper.UnmarshalKey(key, &c); err != nil {
+		panic(fmt.Sprintf("invalid %s config: %s", key, err))
+	}
+	return c
+}
+
 // GetUploadServiceURL returns url to the v4 upload service.
 func GetUploadServiceURL() string {
 	return Config.Viper.GetString("UploadServiceURL")
@@ -152,6 +239,21 @@ func GetStreamsV6() map[string]string {
 	return Config.Viper.GetStringMapString("StreamsV6")
 }
 
+// URLSignerConfig selects the scheme signing streaming URLs on player Hosts.
+type URLSignerConfig struct {
+	Hosts            []string
+	urlsigner.Config `mapstructure:",squash"`
+}
+
+// GetURLSigners returns streaming URL signers for player hosts.
+func GetURLSigners() []URLSignerConfig {
+	var c []URLSignerConfig
+	if err := Config.Viper.UnmarshalKey("URLSigners", &c); err != nil {
+		panic(fmt.Sprintf("invalid URLSigners config: %s", err))
+	}
+	return c
+}
+
 func GetArfleetCDN() string {
 	return Config.Viper.GetString("ArfleetCDN")
 }
@@ -160,6 +262,54 @@ func GetArfleetEnabled() bool {
 	return Config.Viper.GetBool("ArfleetEnabled")
 }
 
+// ArfleetConfig sets up the resolver substituting thumbnail and stream URLs with Arfleet ones.
+// Zero values keep resolver defaults.
+type ArfleetConfig struct {
+	BatchResolverURL      string
+	BatchClaimResolverURL string
+	// CacheTTL is how long found substitutes are cached in SturdyCache Redis.
+	CacheTTL time.Duration
+	// NegativeCacheTTL is how long URLs and claims without substitutes are cached.
+	NegativeCacheTTL time.Duration
+	// Timeout is the time budget of a single lookup, substitution is skipped when it runs out.
+	Timeout time.Duration
+	// BreakerThreshold is the number of consecutive failed lookups after which Arfleet isn't queried
+	// for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// GetArfleetConfig returns Arfleet resolver endpoints, caching and failure handling settings.
+func GetArfleetConfig() ArfleetConfig {
+	var c ArfleetConfig
+	if err := Config.Viper.UnmarshalKey("Arfleet", &c); err != nil {
+		panic(fmt.Sprintf("invalid Arfleet config: %s", err))
+	}
+	return c
+}
+
+// EntitlementsConfig sets up caching of purchases, memberships and moderator status looked up in internal-apis
+// for paid and members-only streams. Zero values keep defaults.
+type EntitlementsConfig struct {
+	// TTL is how long granted entitlements are cached in SturdyCache Redis.
+	TTL time.Duration
+	// NegativeTTL is how long missing entitlements are cached.
+	NegativeTTL time.Duration
+	// WebhookToken authenticates internal-apis calls to the invalidation webhook, which is disabled without it.
+	WebhookToken string
+	// FailOpen lists access types (purchase, rental, members-only) granted while internal-apis is unavailable.
+	FailOpen []string
+}
+
+// GetEntitlementsConfig returns entitlements caching, invalidation and failure handling settings.
+func GetEntitlementsConfig() EntitlementsConfig {
+	var c EntitlementsConfig
+	if err := Config.Viper.UnmarshalKey("Entitlements", &c); err != nil {
+		panic(fmt.Sprintf("invalid Entitlements config: %s", err))
+	}
+	return c
+}
+
 // GetReflectorUpstream returns config map for publish reflector server.
 func GetReflectorUpstream() *viper.Viper {
 	return Config.Viper.Sub("ReflectorUpstream")
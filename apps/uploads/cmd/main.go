@@ -15,6 +15,8 @@ import (
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 	"github.com/OdyseeTeam/odysee-api/pkg/migrator"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
 	"github.com/OdyseeTeam/odysee-api/pkg/redislocker"
 
 	"github.com/alecthomas/kong"
@@ -25,9 +27,13 @@ var cli struct {
 	migrator.CLI
 	Serve         struct{} `cmd:"" help:"Start upload service"`
 	RetryComplete struct {
-		UploadID string `help:"Upload ID"`
-		UserID   int32  `help:"User ID"`
+		UploadID     string `help:"Upload ID"`
+		UserID       int32  `help:"User ID"`
+		KeepMetadata bool   `help:"Skip file metadata sanitization"`
 	} `cmd:"" help:"Retry upload hand-off for further processing"`
+	Reap struct {
+		DryRun bool `help:"Only report abandoned uploads without removing them"`
+	} `cmd:"" help:"Remove abandoned uploads from the database and storage once, serve runs it on schedule"`
 	Debug bool `help:"Enable verbose logging"`
 }
 
@@ -55,6 +61,8 @@ func main() {
 		logger.Fatal("migrate command is not supported")
 	case "retry-complete":
 		retryComplete(logger)
+	case "reap":
+		reap(logger)
 	default:
 		logger.Fatal("unknown command", "name", ctx.Command())
 	}
@@ -83,11 +91,6 @@ func serve(logger logging.KVLogger) {
 		logger.Fatal("redislocker launch failed", "err", err)
 	}
 
-	k, err := keybox.PublicKeyFromURL(cfg.V.GetString("PublicKeyURL"))
-	if err != nil {
-		logger.Fatal("public key loading failed", "url", cfg.V.GetString("PublicKeyURL"), "err", err)
-	}
-
 	pgcfg := cfg.ReadPostgresConfig("Database")
 	db, err := migrator.ConnectDB(pgcfg, database.MigrationsFS)
 	if err != nil {
@@ -96,22 +99,73 @@ func serve(logger logging.KVLogger) {
 
 	runCtx, runCancel := context.WithCancel(context.Background())
 
-	launcher := uploads.NewLauncher(
+	var keyOption uploads.LauncherOption
+	if jwksURL := cfg.V.GetString("JWKSURL"); jwksURL != "" {
+		v, err := keybox.ValidatorFromJWKSURL(runCtx, jwksURL, cfg.V.GetDuration("JWKSRefreshInterval"))
+		if err != nil {
+			logger.Fatal("key set loading failed", "url", jwksURL, "err", err)
+		}
+		keyOption = uploads.WithTokenValidator(v)
+	} else {
+		k, err := keybox.PublicKeyFromURL(cfg.V.GetString("PublicKeyURL"))
+		if err != nil {
+			logger.Fatal("public key loading failed", "url", cfg.V.GetString("PublicKeyURL"), "err", err)
+		}
+		keyOption = uploads.WithPublicKey(k)
+	}
+
+	options := []uploads.LauncherOption{
 		uploads.WithFileLocker(locker),
 		uploads.WithS3Client(client),
 		uploads.WithS3Bucket(s3cfg.Bucket),
 		uploads.WithDB(db),
-		uploads.WithPublicKey(k),
+		keyOption,
 		uploads.WithLogger(logger),
 		uploads.WithCORSDomains(cfg.V.GetStringSlice("CORSDomains")),
 		uploads.WithForkliftRequestsConnURL(cfg.V.GetString("ForkliftRequestsConnURL")),
-	)
+		uploads.WithAdminToken(cfg.V.GetString("AdminToken")),
+	}
+	if cfg.V.GetBool("PresignedUploads.Enabled") {
+		options = append(options, uploads.WithPresignedUploads(cfg.V.GetDuration("PresignedUploads.Expiry")))
+	}
+	if cfg.V.GetBool("Quotas.Enabled") {
+		tiers := map[string]uploads.Limits{}
+		if err := cfg.V.UnmarshalKey("Quotas.Tiers", &tiers); err != nil {
+			logger.Fatal("quota tiers config failed", "err", err)
+		}
+		options = append(options, uploads.WithQuotas(tiers))
+	}
+	launcher := uploads.NewLauncher(options...)
+
+	var periodic *queue.Queue
+	var pcfg uploads.PeriodicConfig
+	if err := cfg.V.UnmarshalKey("Periodic", &pcfg); err != nil {
+		logger.Fatal("periodic tasks config failed", "err", err)
+	}
+	if pcfg.Enabled {
+		var rcfg reaper.Config
+		if err := cfg.V.UnmarshalKey("Reaper", &rcfg); err != nil {
+			logger.Fatal("reaper config failed", "err", err)
+		}
+		periodic, err = launcher.PeriodicTasks(pcfg, rcfg.Options()...)
+		if err != nil {
+			logger.Fatal("periodic tasks setup failed", "err", err)
+		}
+		go func() {
+			if err := periodic.ServeUntilShutdown(); err != nil {
+				logger.Fatal("periodic tasks failed", "err", err)
+			}
+		}()
+	}
 
 	go func() {
 		trap := make(chan os.Signal, 1)
 		signal.Notify(trap, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 		<-trap
 
+		if periodic != nil {
+			periodic.Shutdown()
+		}
 		launcher.StartShutdown()
 		// Wait for the readiness probe to detect the failure
 		<-time.After(cfg.V.GetDuration("GracefulShutdown"))
@@ -175,10 +229,60 @@ func retryComplete(logger logging.KVLogger) {
 		tasks.FileLocationS3{
 			Key:    upload.Key,
 			Bucket: s3cfg.Bucket,
-		})
+		},
+		cli.RetryComplete.KeepMetadata)
 	if err != nil {
 		logger.Fatal("failed to complete upload", "err", err)
 		return
 	}
 	logger.Info("upload sent off for processing")
 }
+
+func reap(logger logging.KVLogger) {
+	cfg, err := configng.Read("./config", "uploads", "yaml")
+	if err != nil {
+		logger.Fatal("config reading failed", "err", err)
+	}
+	s3cfg, err := cfg.ReadS3Config("Storage")
+	if err != nil {
+		logger.Fatal("s3 config failed", "err", err)
+	}
+	client, err := configng.NewS3Client(s3cfg)
+	if err != nil {
+		logger.Fatal("s3 client failed", "err", err)
+	}
+	redisOpts, err := redis.ParseURL(cfg.V.GetString("RedisLocker"))
+	if err != nil {
+		logger.Fatal("redis config parse failed", "err", err)
+	}
+	locker, err := redislocker.New(redisOpts)
+	if err != nil {
+		logger.Fatal("redislocker launch failed", "err", err)
+	}
+	pgcfg := cfg.ReadPostgresConfig("Database")
+	db, err := migrator.ConnectDB(pgcfg, database.MigrationsFS)
+	if err != nil {
+		logger.Fatal("db connection failed", "err", err)
+	}
+
+	var rcfg reaper.Config
+	if err := cfg.V.UnmarshalKey("Reaper", &rcfg); err != nil {
+		logger.Fatal("reaper config failed", "err", err)
+	}
+	rcfg.DryRun = rcfg.DryRun || cli.Reap.DryRun
+
+	launcher := uploads.NewLauncher(
+		uploads.WithFileLocker(locker),
+		uploads.WithS3Client(client),
+		uploads.WithS3Bucket(s3cfg.Bucket),
+		uploads.WithDB(db),
+		uploads.WithLogger(logger),
+	)
+	r := launcher.Reaper(rcfg.Options()...)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer cancel()
+	if _, err := r.RunOnce(ctx); err != nil {
+		logger.Fatal("reaper failed", "err", err)
+	}
+}
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE forklift_checkpoints (
+    upload_id text NOT NULL UNIQUE PRIMARY KEY CHECK (upload_id <> ''),
+    stage text NOT NULL,
+    state jsonb NOT NULL,
+
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    updated_at timestamp
+);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DROP TABLE forklift_checkpoints;
+-- +migrate StatementEnd
@@ -0,0 +1,5 @@
+-- +migrate Up notransaction
+ALTER TYPE upload_status ADD VALUE IF NOT EXISTS 'failed';
+
+-- +migrate Down
+-- Enum values cannot be dropped in Postgres, 'failed' is left in place.
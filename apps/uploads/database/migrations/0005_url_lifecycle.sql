@@ -0,0 +1,13 @@
+-- +migrate Up notransaction
+ALTER TYPE url_status ADD VALUE IF NOT EXISTS 'downloading' BEFORE 'downloaded';
+ALTER TYPE url_status ADD VALUE IF NOT EXISTS 'failed';
+
+ALTER TABLE urls
+    ADD COLUMN received bigint NOT NULL DEFAULT 0,
+    ADD COLUMN error text NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- Enum values cannot be dropped in Postgres, 'downloading' and 'failed' are left in place.
+ALTER TABLE urls
+    DROP COLUMN received,
+    DROP COLUMN error;
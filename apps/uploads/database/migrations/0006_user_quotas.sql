@@ -0,0 +1,25 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE user_quotas (
+    user_id int NOT NULL UNIQUE PRIMARY KEY,
+    tier text NOT NULL DEFAULT '',
+
+    -- Limits overriding tier defaults, NULL means the tier value is used.
+    bytes_per_day bigint,
+    concurrent_uploads int,
+    files_per_hour int,
+
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    updated_at timestamp
+);
+
+CREATE INDEX uploads_user_id_created_at ON uploads(user_id, created_at);
+CREATE INDEX urls_user_id_created_at ON urls(user_id, created_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DROP INDEX urls_user_id_created_at;
+DROP INDEX uploads_user_id_created_at;
+DROP TABLE user_quotas;
+-- +migrate StatementEnd
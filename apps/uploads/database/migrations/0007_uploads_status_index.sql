@@ -0,0 +1,9 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE INDEX uploads_status_created_at ON uploads(status, created_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DROP INDEX uploads_status_created_at;
+-- +migrate StatementEnd
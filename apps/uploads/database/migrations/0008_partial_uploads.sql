@@ -0,0 +1,9 @@
+-- +migrate Up
+-- +migrate StatementBegin
+ALTER TABLE uploads ADD COLUMN is_partial boolean NOT NULL DEFAULT false;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+ALTER TABLE uploads DROP COLUMN is_partial;
+-- +migrate StatementEnd
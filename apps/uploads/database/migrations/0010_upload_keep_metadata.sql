@@ -0,0 +1,9 @@
+-- +migrate Up
+-- +migrate StatementBegin
+ALTER TABLE uploads ADD COLUMN keep_metadata boolean NOT NULL DEFAULT false;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+ALTER TABLE uploads DROP COLUMN keep_metadata;
+-- +migrate StatementEnd
@@ -7,6 +7,7 @@ package database
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ const (
 	UploadStatusCompleted  UploadStatus = "completed"
 	UploadStatusTerminated UploadStatus = "terminated"
 	UploadStatusProcessed  UploadStatus = "processed"
+	UploadStatusFailed     UploadStatus = "failed"
 )
 
 func (e *UploadStatus) Scan(src interface{}) error {
@@ -61,9 +63,11 @@ func (ns NullUploadStatus) Value() (driver.Value, error) {
 type UrlStatus string
 
 const (
-	UrlStatusCreated    UrlStatus = "created"
-	UrlStatusDownloaded UrlStatus = "downloaded"
-	UrlStatusProcessed  UrlStatus = "processed"
+	UrlStatusCreated     UrlStatus = "created"
+	UrlStatusDownloading UrlStatus = "downloading"
+	UrlStatusDownloaded  UrlStatus = "downloaded"
+	UrlStatusProcessed   UrlStatus = "processed"
+	UrlStatusFailed      UrlStatus = "failed"
 )
 
 func (e *UrlStatus) Scan(src interface{}) error {
@@ -101,6 +105,14 @@ func (ns NullUrlStatus) Value() (driver.Value, error) {
 	return string(ns.UrlStatus), nil
 }
 
+type ForkliftCheckpoint struct {
+	UploadID  string
+	Stage     string
+	State     json.RawMessage
+	CreatedAt time.Time
+	UpdatedAt sql.NullTime
+}
+
 type URL struct {
 	ID        string
 	UserID    int32
@@ -112,18 +124,33 @@ type URL struct {
 	Size      int64
 	SDHash    string
 	Meta      pqtype.NullRawMessage
+	Received  int64
+	Error     string
 }
 
 type Upload struct {
-	ID        string
-	UserID    int32
-	Filename  string
-	Key       string
-	CreatedAt time.Time
-	UpdatedAt sql.NullTime
-	Status    UploadStatus
-	Size      int64
-	Received  int64
-	SDHash    string
-	Meta      pqtype.NullRawMessage
+	ID           string
+	UserID       int32
+	Filename     string
+	Key          string
+	CreatedAt    time.Time
+	UpdatedAt    sql.NullTime
+	Status       UploadStatus
+	Size         int64
+	Received     int64
+	SDHash       string
+	Meta         pqtype.NullRawMessage
+	IsPartial    bool
+	IsFinal      bool
+	KeepMetadata bool
+}
+
+type UserQuota struct {
+	UserID            int32
+	Tier              string
+	BytesPerDay       sql.NullInt64
+	ConcurrentUploads sql.NullInt32
+	FilesPerHour      sql.NullInt32
+	CreatedAt         time.Time
+	UpdatedAt         sql.NullTime
 }
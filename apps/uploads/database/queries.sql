@@ -1,8 +1,8 @@
 -- name: CreateUpload :one
 INSERT INTO uploads (
-    id, user_id, size, status, filename, key, sd_hash
+    id, user_id, size, is_partial, is_final, keep_metadata, status, filename, key, sd_hash
 ) VALUES (
-    $1, $2, $3, 'created', '', '', ''
+    $1, $2, $3, $4, $5, $6, 'created', '', '', ''
 )
 RETURNING *;
 
@@ -10,6 +10,18 @@ RETURNING *;
 SELECT * FROM uploads
 WHERE user_id = $1 AND id = $2;
 
+-- name: GetUploadStatus :one
+SELECT status FROM uploads
+WHERE id = $1;
+
+-- name: GetSDHash :one
+SELECT sd_hash FROM uploads
+WHERE uploads.id = $1
+UNION ALL
+SELECT sd_hash FROM urls
+WHERE urls.id = $1
+LIMIT 1;
+
 -- name: RecordUploadProgress :exec
 UPDATE uploads SET
     updated_at = NOW(),
@@ -19,7 +31,7 @@ WHERE user_id = $1 AND id = $2 AND status IN ('receiving', 'created');
 -- name: MarkUploadTerminated :exec
 UPDATE uploads SET
     updated_at = NOW(),
-    status = 'completed'
+    status = 'terminated'
 WHERE user_id = $1 AND id = $2;
 
 -- name: MarkUploadCompleted :exec
@@ -30,6 +42,17 @@ UPDATE uploads SET
     key = $4
 WHERE user_id = $1 AND id = $2;
 
+-- name: MarkPresignedUploadCompleted :one
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'completed',
+    size = $3,
+    filename = $4,
+    key = $5,
+    keep_metadata = $6
+WHERE user_id = $1 AND id = $2 AND status = 'created'
+RETURNING *;
+
 -- name: MarkUploadProcessed :exec
 UPDATE uploads SET
     updated_at = NOW(),
@@ -38,6 +61,37 @@ UPDATE uploads SET
     meta = $3
 WHERE id = $1;
 
+-- name: MarkUploadFailed :exec
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'failed'
+WHERE id = $1 AND status <> 'processed';
+
+-- name: ListExpiredUploads :many
+SELECT * FROM uploads
+WHERE (status IN ('created', 'receiving') OR (is_partial AND status = 'completed'))
+    AND COALESCE(updated_at, created_at) < $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: TerminateExpiredUpload :exec
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'terminated'
+WHERE id = $1 AND (status IN ('created', 'receiving') OR (is_partial AND status = 'completed'));
+
+-- name: ListStaleCompletedUploads :many
+SELECT * FROM uploads
+WHERE status = 'completed' AND NOT is_partial
+    AND COALESCE(updated_at, created_at) < $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: TouchCompletedUpload :exec
+UPDATE uploads SET
+    updated_at = NOW()
+WHERE id = $1 AND status = 'completed';
+
 -- name: CreateURL :one
 INSERT INTO urls (
     id, user_id, url, filename, size, sd_hash, status
@@ -45,3 +99,97 @@ INSERT INTO urls (
     $1, $2, $3, $4, 0, '', 'created'
 )
 RETURNING *;
+
+-- name: RecordURLProgress :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'downloading',
+    received = $2,
+    size = $3
+WHERE id = $1 AND status <> 'processed';
+
+-- name: MarkURLDownloaded :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'downloaded',
+    received = $2,
+    size = $2
+WHERE id = $1;
+
+-- name: MarkURLProcessed :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'processed',
+    sd_hash = $2,
+    meta = $3
+WHERE id = $1;
+
+-- name: MarkURLFailed :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'failed',
+    error = $2
+WHERE id = $1 AND status <> 'processed';
+
+-- name: GetCheckpoint :one
+SELECT * FROM forklift_checkpoints
+WHERE upload_id = $1;
+
+-- name: SaveCheckpoint :exec
+INSERT INTO forklift_checkpoints (
+    upload_id, stage, state
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (upload_id) DO UPDATE SET
+    updated_at = NOW(),
+    stage = EXCLUDED.stage,
+    state = EXCLUDED.state;
+
+-- name: DeleteCheckpoint :exec
+DELETE FROM forklift_checkpoints
+WHERE upload_id = $1;
+
+-- name: GetUserQuota :one
+SELECT * FROM user_quotas
+WHERE user_id = $1;
+
+-- name: SetUserQuota :one
+INSERT INTO user_quotas (
+    user_id, tier, bytes_per_day, concurrent_uploads, files_per_hour
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    updated_at = NOW(),
+    tier = EXCLUDED.tier,
+    bytes_per_day = EXCLUDED.bytes_per_day,
+    concurrent_uploads = EXCLUDED.concurrent_uploads,
+    files_per_hour = EXCLUDED.files_per_hour
+RETURNING *;
+
+-- name: DeleteUserQuota :exec
+DELETE FROM user_quotas
+WHERE user_id = $1;
+
+-- name: GetUserUsage :one
+SELECT
+    (
+        (SELECT COALESCE(SUM(uploads.size), 0) FROM uploads
+         WHERE uploads.user_id = $1 AND uploads.created_at > NOW() - INTERVAL '1 day' AND uploads.status <> 'terminated') +
+        (SELECT COALESCE(SUM(urls.size), 0) FROM urls
+         WHERE urls.user_id = $1 AND urls.created_at > NOW() - INTERVAL '1 day')
+    )::bigint AS bytes_per_day,
+    (
+        (SELECT COUNT(*) FROM uploads
+         WHERE uploads.user_id = $1 AND (uploads.status IN ('created', 'receiving') OR (uploads.is_partial AND uploads.status = 'completed'))
+           AND uploads.created_at > NOW() - INTERVAL '1 day' AND NOT uploads.is_final) +
+        (SELECT COUNT(*) FROM urls
+         WHERE urls.user_id = $1 AND urls.status IN ('created', 'downloading') AND urls.created_at > NOW() - INTERVAL '1 day')
+    )::int AS concurrent_uploads,
+    (
+        (SELECT COUNT(*) FROM uploads
+         WHERE uploads.user_id = $1 AND uploads.created_at > NOW() - INTERVAL '1 hour' AND NOT uploads.is_final) +
+        (SELECT COUNT(*) FROM urls
+         WHERE urls.user_id = $1 AND urls.created_at > NOW() - INTERVAL '1 hour')
+    )::int AS files_per_hour;
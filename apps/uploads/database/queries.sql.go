@@ -7,6 +7,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/sqlc-dev/pqtype"
 )
@@ -17,7 +20,7 @@ INSERT INTO urls (
 ) VALUES (
     $1, $2, $3, $4, 0, '', 'created'
 )
-RETURNING id, user_id, url, filename, created_at, updated_at, status, size, sd_hash, meta
+RETURNING id, user_id, url, filename, created_at, updated_at, status, size, sd_hash, meta, received, error
 `
 
 type CreateURLParams struct {
@@ -46,27 +49,39 @@ func (q *Queries) CreateURL(ctx context.Context, arg CreateURLParams) (URL, erro
 		&i.Size,
 		&i.SDHash,
 		&i.Meta,
+		&i.Received,
+		&i.Error,
 	)
 	return i, err
 }
 
 const createUpload = `-- name: CreateUpload :one
 INSERT INTO uploads (
-    id, user_id, size, status, filename, key, sd_hash
+    id, user_id, size, is_partial, is_final, keep_metadata, status, filename, key, sd_hash
 ) VALUES (
-    $1, $2, $3, 'created', '', '', ''
+    $1, $2, $3, $4, $5, $6, 'created', '', '', ''
 )
-RETURNING id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta
+RETURNING id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta, is_partial, is_final, keep_metadata
 `
 
 type CreateUploadParams struct {
-	ID     string
-	UserID int32
-	Size   int64
+	ID           string
+	UserID       int32
+	Size         int64
+	IsPartial    bool
+	IsFinal      bool
+	KeepMetadata bool
 }
 
 func (q *Queries) CreateUpload(ctx context.Context, arg CreateUploadParams) (Upload, error) {
-	row := q.db.QueryRowContext(ctx, createUpload, arg.ID, arg.UserID, arg.Size)
+	row := q.db.QueryRowContext(ctx, createUpload,
+		arg.ID,
+		arg.UserID,
+		arg.Size,
+		arg.IsPartial,
+		arg.IsFinal,
+		arg.KeepMetadata,
+	)
 	var i Upload
 	err := row.Scan(
 		&i.ID,
@@ -80,12 +95,69 @@ func (q *Queries) CreateUpload(ctx context.Context, arg CreateUploadParams) (Upl
 		&i.Received,
 		&i.SDHash,
 		&i.Meta,
+		&i.IsPartial,
+		&i.IsFinal,
+		&i.KeepMetadata,
+	)
+	return i, err
+}
+
+const deleteCheckpoint = `-- name: DeleteCheckpoint :exec
+DELETE FROM forklift_checkpoints
+WHERE upload_id = $1
+`
+
+func (q *Queries) DeleteCheckpoint(ctx context.Context, uploadID string) error {
+	_, err := q.db.ExecContext(ctx, deleteCheckpoint, uploadID)
+	return err
+}
+
+const deleteUserQuota = `-- name: DeleteUserQuota :exec
+DELETE FROM user_quotas
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserQuota(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserQuota, userID)
+	return err
+}
+
+const getCheckpoint = `-- name: GetCheckpoint :one
+SELECT upload_id, stage, state, created_at, updated_at FROM forklift_checkpoints
+WHERE upload_id = $1
+`
+
+func (q *Queries) GetCheckpoint(ctx context.Context, uploadID string) (ForkliftCheckpoint, error) {
+	row := q.db.QueryRowContext(ctx, getCheckpoint, uploadID)
+	var i ForkliftCheckpoint
+	err := row.Scan(
+		&i.UploadID,
+		&i.Stage,
+		&i.State,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getSDHash = `-- name: GetSDHash :one
+SELECT sd_hash FROM uploads
+WHERE uploads.id = $1
+UNION ALL
+SELECT sd_hash FROM urls
+WHERE urls.id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSDHash(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getSDHash, id)
+	var sd_hash string
+	err := row.Scan(&sd_hash)
+	return sd_hash, err
+}
+
 const getUpload = `-- name: GetUpload :one
-SELECT id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta FROM uploads
+SELECT id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta, is_partial, is_final, keep_metadata FROM uploads
 WHERE user_id = $1 AND id = $2
 `
 
@@ -109,10 +181,290 @@ func (q *Queries) GetUpload(ctx context.Context, arg GetUploadParams) (Upload, e
 		&i.Received,
 		&i.SDHash,
 		&i.Meta,
+		&i.IsPartial,
+		&i.IsFinal,
+		&i.KeepMetadata,
+	)
+	return i, err
+}
+
+const getUploadStatus = `-- name: GetUploadStatus :one
+SELECT status FROM uploads
+WHERE id = $1
+`
+
+func (q *Queries) GetUploadStatus(ctx context.Context, id string) (UploadStatus, error) {
+	row := q.db.QueryRowContext(ctx, getUploadStatus, id)
+	var status UploadStatus
+	err := row.Scan(&status)
+	return status, err
+}
+
+const getUserQuota = `-- name: GetUserQuota :one
+SELECT user_id, tier, bytes_per_day, concurrent_uploads, files_per_hour, created_at, updated_at FROM user_quotas
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserQuota(ctx context.Context, userID int32) (UserQuota, error) {
+	row := q.db.QueryRowContext(ctx, getUserQuota, userID)
+	var i UserQuota
+	err := row.Scan(
+		&i.UserID,
+		&i.Tier,
+		&i.BytesPerDay,
+		&i.ConcurrentUploads,
+		&i.FilesPerHour,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserUsage = `-- name: GetUserUsage :one
+SELECT
+    (
+        (SELECT COALESCE(SUM(uploads.size), 0) FROM uploads
+         WHERE uploads.user_id = $1 AND uploads.created_at > NOW() - INTERVAL '1 day' AND uploads.status <> 'terminated') +
+        (SELECT COALESCE(SUM(urls.size), 0) FROM urls
+         WHERE urls.user_id = $1 AND urls.created_at > NOW() - INTERVAL '1 day')
+    )::bigint AS bytes_per_day,
+    (
+        (SELECT COUNT(*) FROM uploads
+         WHERE uploads.user_id = $1 AND (uploads.status IN ('created', 'receiving') OR (uploads.is_partial AND uploads.status = 'completed'))
+           AND uploads.created_at > NOW() - INTERVAL '1 day' AND NOT uploads.is_final) +
+        (SELECT COUNT(*) FROM urls
+         WHERE urls.user_id = $1 AND urls.status IN ('created', 'downloading') AND urls.created_at > NOW() - INTERVAL '1 day')
+    )::int AS concurrent_uploads,
+    (
+        (SELECT COUNT(*) FROM uploads
+         WHERE uploads.user_id = $1 AND uploads.created_at > NOW() - INTERVAL '1 hour' AND NOT uploads.is_final) +
+        (SELECT COUNT(*) FROM urls
+         WHERE urls.user_id = $1 AND urls.created_at > NOW() - INTERVAL '1 hour')
+    )::int AS files_per_hour
+`
+
+type GetUserUsageRow struct {
+	BytesPerDay       int64
+	ConcurrentUploads int32
+	FilesPerHour      int32
+}
+
+func (q *Queries) GetUserUsage(ctx context.Context, userID int32) (GetUserUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserUsage, userID)
+	var i GetUserUsageRow
+	err := row.Scan(&i.BytesPerDay, &i.ConcurrentUploads, &i.FilesPerHour)
+	return i, err
+}
+
+const listExpiredUploads = `-- name: ListExpiredUploads :many
+SELECT id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta, is_partial, is_final, keep_metadata FROM uploads
+WHERE (status IN ('created', 'receiving') OR (is_partial AND status = 'completed'))
+    AND COALESCE(updated_at, created_at) < $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type ListExpiredUploadsParams struct {
+	UpdatedAt time.Time
+	Limit     int32
+}
+
+func (q *Queries) ListExpiredUploads(ctx context.Context, arg ListExpiredUploadsParams) ([]Upload, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredUploads, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Upload
+	for rows.Next() {
+		var i Upload
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Filename,
+			&i.Key,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Size,
+			&i.Received,
+			&i.SDHash,
+			&i.Meta,
+			&i.IsPartial,
+			&i.IsFinal,
+			&i.KeepMetadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleCompletedUploads = `-- name: ListStaleCompletedUploads :many
+SELECT id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta, is_partial, is_final, keep_metadata FROM uploads
+WHERE status = 'completed' AND NOT is_partial
+    AND COALESCE(updated_at, created_at) < $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type ListStaleCompletedUploadsParams struct {
+	UpdatedAt time.Time
+	Limit     int32
+}
+
+func (q *Queries) ListStaleCompletedUploads(ctx context.Context, arg ListStaleCompletedUploadsParams) ([]Upload, error) {
+	rows, err := q.db.QueryContext(ctx, listStaleCompletedUploads, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Upload
+	for rows.Next() {
+		var i Upload
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Filename,
+			&i.Key,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Size,
+			&i.Received,
+			&i.SDHash,
+			&i.Meta,
+			&i.IsPartial,
+			&i.IsFinal,
+			&i.KeepMetadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPresignedUploadCompleted = `-- name: MarkPresignedUploadCompleted :one
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'completed',
+    size = $3,
+    filename = $4,
+    key = $5,
+    keep_metadata = $6
+WHERE user_id = $1 AND id = $2 AND status = 'created'
+RETURNING id, user_id, filename, key, created_at, updated_at, status, size, received, sd_hash, meta, is_partial, is_final, keep_metadata
+`
+
+type MarkPresignedUploadCompletedParams struct {
+	UserID       int32
+	ID           string
+	Size         int64
+	Filename     string
+	Key          string
+	KeepMetadata bool
+}
+
+func (q *Queries) MarkPresignedUploadCompleted(ctx context.Context, arg MarkPresignedUploadCompletedParams) (Upload, error) {
+	row := q.db.QueryRowContext(ctx, markPresignedUploadCompleted,
+		arg.UserID,
+		arg.ID,
+		arg.Size,
+		arg.Filename,
+		arg.Key,
+		arg.KeepMetadata,
+	)
+	var i Upload
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Filename,
+		&i.Key,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.Size,
+		&i.Received,
+		&i.SDHash,
+		&i.Meta,
+		&i.IsPartial,
+		&i.IsFinal,
+		&i.KeepMetadata,
 	)
 	return i, err
 }
 
+const markURLDownloaded = `-- name: MarkURLDownloaded :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'downloaded',
+    received = $2,
+    size = $2
+WHERE id = $1
+`
+
+type MarkURLDownloadedParams struct {
+	ID       string
+	Received int64
+}
+
+func (q *Queries) MarkURLDownloaded(ctx context.Context, arg MarkURLDownloadedParams) error {
+	_, err := q.db.ExecContext(ctx, markURLDownloaded, arg.ID, arg.Received)
+	return err
+}
+
+const markURLFailed = `-- name: MarkURLFailed :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'failed',
+    error = $2
+WHERE id = $1 AND status <> 'processed'
+`
+
+type MarkURLFailedParams struct {
+	ID    string
+	Error string
+}
+
+func (q *Queries) MarkURLFailed(ctx context.Context, arg MarkURLFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markURLFailed, arg.ID, arg.Error)
+	return err
+}
+
+const markURLProcessed = `-- name: MarkURLProcessed :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'processed',
+    sd_hash = $2,
+    meta = $3
+WHERE id = $1
+`
+
+type MarkURLProcessedParams struct {
+	ID     string
+	SDHash string
+	Meta   pqtype.NullRawMessage
+}
+
+func (q *Queries) MarkURLProcessed(ctx context.Context, arg MarkURLProcessedParams) error {
+	_, err := q.db.ExecContext(ctx, markURLProcessed, arg.ID, arg.SDHash, arg.Meta)
+	return err
+}
+
 const markUploadCompleted = `-- name: MarkUploadCompleted :exec
 UPDATE uploads SET
     updated_at = NOW(),
@@ -139,6 +491,18 @@ func (q *Queries) MarkUploadCompleted(ctx context.Context, arg MarkUploadComplet
 	return err
 }
 
+const markUploadFailed = `-- name: MarkUploadFailed :exec
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'failed'
+WHERE id = $1 AND status <> 'processed'
+`
+
+func (q *Queries) MarkUploadFailed(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, markUploadFailed, id)
+	return err
+}
+
 const markUploadProcessed = `-- name: MarkUploadProcessed :exec
 UPDATE uploads SET
     updated_at = NOW(),
@@ -162,7 +526,7 @@ func (q *Queries) MarkUploadProcessed(ctx context.Context, arg MarkUploadProcess
 const markUploadTerminated = `-- name: MarkUploadTerminated :exec
 UPDATE uploads SET
     updated_at = NOW(),
-    status = 'completed'
+    status = 'terminated'
 WHERE user_id = $1 AND id = $2
 `
 
@@ -176,6 +540,26 @@ func (q *Queries) MarkUploadTerminated(ctx context.Context, arg MarkUploadTermin
 	return err
 }
 
+const recordURLProgress = `-- name: RecordURLProgress :exec
+UPDATE urls SET
+    updated_at = NOW(),
+    status = 'downloading',
+    received = $2,
+    size = $3
+WHERE id = $1 AND status <> 'processed'
+`
+
+type RecordURLProgressParams struct {
+	ID       string
+	Received int64
+	Size     int64
+}
+
+func (q *Queries) RecordURLProgress(ctx context.Context, arg RecordURLProgressParams) error {
+	_, err := q.db.ExecContext(ctx, recordURLProgress, arg.ID, arg.Received, arg.Size)
+	return err
+}
+
 const recordUploadProgress = `-- name: RecordUploadProgress :exec
 UPDATE uploads SET
     updated_at = NOW(),
@@ -193,3 +577,93 @@ func (q *Queries) RecordUploadProgress(ctx context.Context, arg RecordUploadProg
 	_, err := q.db.ExecContext(ctx, recordUploadProgress, arg.UserID, arg.ID, arg.Received)
 	return err
 }
+
+const saveCheckpoint = `-- name: SaveCheckpoint :exec
+INSERT INTO forklift_checkpoints (
+    upload_id, stage, state
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (upload_id) DO UPDATE SET
+    updated_at = NOW(),
+    stage = EXCLUDED.stage,
+    state = EXCLUDED.state
+`
+
+type SaveCheckpointParams struct {
+	UploadID string
+	Stage    string
+	State    json.RawMessage
+}
+
+func (q *Queries) SaveCheckpoint(ctx context.Context, arg SaveCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, saveCheckpoint, arg.UploadID, arg.Stage, arg.State)
+	return err
+}
+
+const setUserQuota = `-- name: SetUserQuota :one
+INSERT INTO user_quotas (
+    user_id, tier, bytes_per_day, concurrent_uploads, files_per_hour
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    updated_at = NOW(),
+    tier = EXCLUDED.tier,
+    bytes_per_day = EXCLUDED.bytes_per_day,
+    concurrent_uploads = EXCLUDED.concurrent_uploads,
+    files_per_hour = EXCLUDED.files_per_hour
+RETURNING user_id, tier, bytes_per_day, concurrent_uploads, files_per_hour, created_at, updated_at
+`
+
+type SetUserQuotaParams struct {
+	UserID            int32
+	Tier              string
+	BytesPerDay       sql.NullInt64
+	ConcurrentUploads sql.NullInt32
+	FilesPerHour      sql.NullInt32
+}
+
+func (q *Queries) SetUserQuota(ctx context.Context, arg SetUserQuotaParams) (UserQuota, error) {
+	row := q.db.QueryRowContext(ctx, setUserQuota,
+		arg.UserID,
+		arg.Tier,
+		arg.BytesPerDay,
+		arg.ConcurrentUploads,
+		arg.FilesPerHour,
+	)
+	var i UserQuota
+	err := row.Scan(
+		&i.UserID,
+		&i.Tier,
+		&i.BytesPerDay,
+		&i.ConcurrentUploads,
+		&i.FilesPerHour,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const terminateExpiredUpload = `-- name: TerminateExpiredUpload :exec
+UPDATE uploads SET
+    updated_at = NOW(),
+    status = 'terminated'
+WHERE id = $1 AND (status IN ('created', 'receiving') OR (is_partial AND status = 'completed'))
+`
+
+func (q *Queries) TerminateExpiredUpload(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, terminateExpiredUpload, id)
+	return err
+}
+
+const touchCompletedUpload = `-- name: TouchCompletedUpload :exec
+UPDATE uploads SET
+    updated_at = NOW()
+WHERE id = $1 AND status = 'completed'
+`
+
+func (q *Queries) TouchCompletedUpload(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, touchCompletedUpload, id)
+	return err
+}
@@ -19,6 +19,10 @@ var (
 		Namespace: ns,
 		Name:      "redis_errors",
 	})
+	quotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "quota_rejections",
+	}, []string{"quota"})
 )
 
 func registerMetrics(registry prometheus.Registerer) {
@@ -26,6 +30,6 @@ func registerMetrics(registry prometheus.Registerer) {
 		registry = prometheus.DefaultRegisterer
 	}
 	registry.MustRegister(
-		userAuthErrors, sqlErrors, redisErrors,
+		userAuthErrors, sqlErrors, redisErrors, quotaRejections,
 	)
 }
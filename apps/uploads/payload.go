@@ -18,7 +18,11 @@ const MaxURLLength = 2083
 const (
 	StatusInputError         = "input_error"
 	StatusInternalError      = "internal_error"
+	StatusPresignedCreated   = "presigned_upload_created"
+	StatusQuota              = "quota"
+	StatusQuotaExceeded      = "quota_exceeded"
 	StatusSerializationError = "serialization_error"
+	StatusUploadCompleted    = "upload_completed"
 	StatusURLCreated         = "url_created"
 )
 
@@ -34,9 +38,11 @@ type Response struct {
 }
 
 type URLPayload struct {
-	URL      string `json:"url"`
-	Filename string `json:"-"`
-	UploadID string `json:"-"`
+	URL string `json:"url"`
+	// KeepMetadata opts out of stripping location and device metadata from the downloaded file.
+	KeepMetadata bool   `json:"keep_metadata"`
+	Filename     string `json:"-"`
+	UploadID     string `json:"-"`
 }
 
 type URLCreatedPayload struct {
@@ -53,6 +59,9 @@ func (u *URLPayload) Bind(r *http.Request) error {
 	if err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
+	if (pu.Scheme != "http" && pu.Scheme != "https") || pu.Hostname() == "" {
+		return errors.New("only http and https URLs are supported")
+	}
 	fn := path.Base(pu.Path)
 	if fn == "/" || fn == "." || fn == "" {
 		return errors.New("couldn't determine remote file name")
@@ -84,6 +93,17 @@ func ErrInternalError(err error) render.Renderer {
 	}
 }
 
+// ErrQuotaExceeded is returned when the user has used up one of their upload quotas.
+// Quota status is included in the payload.
+func ErrQuotaExceeded(status *QuotaStatus) render.Renderer {
+	return &Response{
+		HTTPStatusCode: http.StatusTooManyRequests,
+		Status:         StatusQuotaExceeded,
+		Error:          status.Error(),
+		Payload:        status,
+	}
+}
+
 func ErrRender(err error) render.Renderer {
 	return &Response{
 		Err:            err,
@@ -101,6 +121,31 @@ func ResponseURLCreated(uploadID string) render.Renderer {
 	}
 }
 
+func ResponsePresignedUploadCreated(upload *PresignedUpload) render.Renderer {
+	return &Response{
+		HTTPStatusCode: http.StatusCreated,
+		Status:         StatusPresignedCreated,
+		Payload:        upload,
+	}
+}
+
+func ResponseUploadCompleted(uploadID string, size int64) render.Renderer {
+	return &Response{
+		HTTPStatusCode: http.StatusOK,
+		Status:         StatusUploadCompleted,
+		Payload:        &UploadCompletedPayload{UploadID: uploadID, Size: size},
+	}
+}
+
+// ResponseQuota returns user quota limits and usage.
+func ResponseQuota(status *QuotaStatus) render.Renderer {
+	return &Response{
+		HTTPStatusCode: http.StatusOK,
+		Status:         StatusQuota,
+		Payload:        status,
+	}
+}
+
 func (e *Response) Render(w http.ResponseWriter, r *http.Request) error {
 	render.Status(r, e.HTTPStatusCode)
 	return nil
@@ -120,6 +165,12 @@ func (e *Response) UnmarshalJSON(data []byte) error {
 	switch e.Status {
 	case StatusURLCreated:
 		payload = URLCreatedPayload{}
+	case StatusQuota, StatusQuotaExceeded:
+		payload = QuotaStatus{}
+	case StatusPresignedCreated:
+		payload = PresignedUpload{}
+	case StatusUploadCompleted:
+		payload = UploadCompletedPayload{}
 	default:
 		return errors.New("unknown status")
 	}
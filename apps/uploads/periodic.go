@@ -0,0 +1,124 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskReapUploads removes abandoned uploads, see Launcher.Reaper.
+	TaskReapUploads = "uploads:reap"
+	// TaskRetryCompleted hands off uploads which got completed but never reached forklift.
+	TaskRetryCompleted = "uploads:retry_completed"
+
+	// periodicQueue is only served by the upload service, forklift shares the same Redis database.
+	periodicQueue = "uploads_periodic"
+)
+
+// PeriodicConfig sets up maintenance requests run by one of upload service instances on schedule.
+type PeriodicConfig struct {
+	Enabled bool
+	// ReapSchedule and RetryCompletedSchedule are cron expressions or "@every <duration>", "off" disables the task.
+	ReapSchedule           string
+	RetryCompletedSchedule string
+	// RetryCompletedAfter is how long a completed upload can stay without being processed before it is sent off again.
+	RetryCompletedAfter time.Duration
+	RetryCompletedLimit int32
+}
+
+// PeriodicTasks creates a queue for upload maintenance requests, serve it with ServeUntilShutdown.
+func (l *Launcher) PeriodicTasks(cfg PeriodicConfig, reaperOptions ...reaper.Option) (*queue.Queue, error) {
+	if cfg.ReapSchedule == "" {
+		cfg.ReapSchedule = "@every 1h"
+	}
+	if cfg.RetryCompletedSchedule == "" {
+		cfg.RetryCompletedSchedule = "@every 15m"
+	}
+	if cfg.RetryCompletedAfter == 0 {
+		cfg.RetryCompletedAfter = 6 * time.Hour
+	}
+	if cfg.RetryCompletedLimit == 0 {
+		cfg.RetryCompletedLimit = 100
+	}
+	if l.queueRedisURL == "" {
+		return nil, errors.New("forklift requests connection url is required for periodic tasks")
+	}
+	notifier, err := l.Notifier()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := asynq.ParseRedisURI(l.queueRedisURL)
+	if err != nil {
+		return nil, err
+	}
+	q, err := queue.New(
+		queue.WithRequestsConnOpts(opts),
+		queue.WithLogger(l.logger),
+		queue.WithConcurrency(1),
+		queue.WithQueues(map[string]int{periodicQueue: 1}),
+		queue.WithSchedulerName("uploads"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r := l.Reaper(reaperOptions...)
+	q.AddHandler(TaskReapUploads, func(ctx context.Context, _ *asynq.Task) error {
+		_, err := r.RunOnce(ctx)
+		return err
+	})
+	q.AddHandler(TaskRetryCompleted, func(ctx context.Context, _ *asynq.Task) error {
+		return l.retryCompleted(ctx, notifier, cfg.RetryCompletedAfter, cfg.RetryCompletedLimit)
+	})
+	err = q.AddPeriodicTask(TaskReapUploads, cfg.ReapSchedule, nil,
+		queue.WithRequestQueue(periodicQueue), queue.WithRequestTimeout(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	err = q.AddPeriodicTask(TaskRetryCompleted, cfg.RetryCompletedSchedule, nil,
+		queue.WithRequestQueue(periodicQueue), queue.WithRequestTimeout(10*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// retryCompleted sends off uploads which have been sitting in completed state for longer than after.
+// Uploads forklift still has a request for are skipped, as requests are identified by upload ID.
+// Each upload is touched so it doesn't get checked again until after passes once more.
+func (l *Launcher) retryCompleted(ctx context.Context, notifier *forkliftNotifier, after time.Duration, limit int32) error {
+	queries := database.New(l.db)
+	uploads, err := queries.ListStaleCompletedUploads(ctx, database.ListStaleCompletedUploadsParams{
+		UpdatedAt: time.Now().Add(-after),
+		Limit:     limit,
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	var skipped int
+	for _, u := range uploads {
+		err := notifier.UploadReceived(u.UserID, u.ID, u.Filename, tasks.FileLocationS3{Key: u.Key, Bucket: l.s3bucket}, u.KeepMetadata)
+		if errors.Is(err, queue.ErrDuplicateRequest) {
+			skipped++
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := queries.TouchCompletedUpload(ctx, u.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(uploads) > 0 {
+		l.logger.Info("completed uploads retried", "count", len(uploads), "in_progress", skipped, "failed", len(errs))
+	}
+	return errors.Join(errs...)
+}
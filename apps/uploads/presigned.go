@@ -0,0 +1,381 @@
+package uploads
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/internal/tasks"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	// S3 limits for multipart uploads.
+	minPartSize = 5 << 20
+	maxParts    = 10000
+
+	defaultPartSize = 64 << 20
+	presignedPrefix = "presigned"
+)
+
+var (
+	errUploadTerminated = errors.New("upload has been terminated")
+
+	rePresignedUploadID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// PresignedUploadRequest starts a direct-to-storage multipart upload.
+type PresignedUploadRequest struct {
+	Size     int64 `json:"size"`
+	PartSize int64 `json:"part_size"`
+}
+
+// PresignedUpload lists URLs the client should PUT file parts to, in order.
+type PresignedUpload struct {
+	UploadID    string          `json:"upload_id"`
+	MultipartID string          `json:"multipart_id"`
+	PartSize    int64           `json:"part_size"`
+	Parts       []PresignedPart `json:"parts"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+type PresignedPart struct {
+	Number int32  `json:"number"`
+	URL    string `json:"url"`
+}
+
+// PresignedCompleteRequest finalizes a direct-to-storage upload with ETags returned by the storage for each part.
+type PresignedCompleteRequest struct {
+	MultipartID  string              `json:"multipart_id"`
+	Filename     string              `json:"filename"`
+	KeepMetadata bool                `json:"keep_metadata"`
+	Parts        []PresignedPartETag `json:"parts"`
+}
+
+type PresignedPartETag struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+type UploadCompletedPayload struct {
+	UploadID string `json:"upload_id"`
+	Size     int64  `json:"size"`
+}
+
+func (p *PresignedUploadRequest) Bind(r *http.Request) error {
+	if p.Size <= 0 {
+		return errors.New("size must be positive")
+	}
+	if p.PartSize != 0 && p.PartSize < minPartSize {
+		return fmt.Errorf("part size cannot be smaller than %d", minPartSize)
+	}
+	return nil
+}
+
+func (p *PresignedCompleteRequest) Bind(r *http.Request) error {
+	if p.MultipartID == "" {
+		return errors.New("multipart_id is required")
+	}
+	p.Filename = path.Base(p.Filename)
+	if p.Filename == "." || p.Filename == "/" {
+		return errors.New("filename is required")
+	}
+	if len(p.Parts) == 0 || len(p.Parts) > maxParts {
+		return errors.New("invalid number of parts")
+	}
+	for i, part := range p.Parts {
+		if part.Number != int32(i+1) || part.ETag == "" {
+			return fmt.Errorf("part %d is missing or out of order", i+1)
+		}
+	}
+	return nil
+}
+
+// CreatePresignedUpload starts an S3 multipart upload and returns presigned URLs for each part,
+// so the client can upload the file directly to the bucket, bypassing the service.
+// The object key is derived from user and upload IDs, so the upload can only be completed by the same user.
+// The upload is recorded with the declared size right away so it counts towards user quotas while in progress.
+func (h *Handler) CreatePresignedUpload(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserIDFromRequest(r)
+	data := &PresignedUploadRequest{}
+	if err := render.Bind(r, data); err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if h.quotas != nil {
+		status, err := h.quotas.Check(r.Context(), userID, data.Size)
+		if err != nil {
+			sqlErrors.Inc()
+			_ = render.Render(w, r, ErrInternalError(err))
+			return
+		}
+		for k, v := range status.Headers() {
+			w.Header().Set(k, v)
+		}
+		if len(status.Exceeded) > 0 {
+			h.logger.Info("upload quota exceeded", "user_id", userID, "type", "presigned", "exceeded", status.Exceeded)
+			_ = render.Render(w, r, ErrQuotaExceeded(status))
+			return
+		}
+	}
+
+	uploadID, err := generateURLUID()
+	if err != nil {
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	key := presignedKey(userID, uploadID)
+	mp, err := h.s3client.CreateMultipartUpload(r.Context(), &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(h.s3bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	_, err = h.queries.CreateUpload(r.Context(), database.CreateUploadParams{ID: uploadID, UserID: userID, Size: data.Size})
+	if err != nil {
+		sqlErrors.Inc()
+		h.abortMultipart(r.Context(), key, aws.ToString(mp.UploadId))
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+
+	partSize, count := planParts(data.Size, data.PartSize)
+	upload := &PresignedUpload{
+		UploadID:    uploadID,
+		MultipartID: aws.ToString(mp.UploadId),
+		PartSize:    partSize,
+		Parts:       make([]PresignedPart, count),
+		ExpiresAt:   time.Now().Add(h.presignExpiry),
+	}
+	presigner := s3.NewPresignClient(h.s3client, s3.WithPresignExpires(h.presignExpiry))
+	for i := range upload.Parts {
+		n := int32(i + 1)
+		req, err := presigner.PresignUploadPart(r.Context(), &s3.UploadPartInput{
+			Bucket:     aws.String(h.s3bucket),
+			Key:        aws.String(key),
+			UploadId:   mp.UploadId,
+			PartNumber: aws.Int32(n),
+		})
+		if err != nil {
+			_ = render.Render(w, r, ErrInternalError(err))
+			return
+		}
+		upload.Parts[i] = PresignedPart{Number: n, URL: req.URL}
+	}
+	h.logger.Info("presigned upload created", "user_id", userID, "upload_id", uploadID, "size", data.Size, "parts", count)
+	_ = render.Render(w, r, ResponsePresignedUploadCreated(upload))
+}
+
+// CompletePresignedUpload assembles uploaded parts, verifies the resulting object,
+// records the upload and sends it off to forklift, same as for tus uploads.
+// Files larger than the size declared at creation are removed.
+// Completion can be retried, uploads which have already been completed are sent off to forklift again.
+func (h *Handler) CompletePresignedUpload(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserIDFromRequest(r)
+	uploadID := chi.URLParam(r, "upload_id")
+	if !rePresignedUploadID.MatchString(uploadID) {
+		_ = render.Render(w, r, ErrNotFound)
+		return
+	}
+	data := &PresignedCompleteRequest{}
+	if err := render.Bind(r, data); err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	log := h.logger.With("user_id", userID, "upload_id", uploadID)
+	key := presignedKey(userID, uploadID)
+
+	upload, err := h.queries.GetUpload(r.Context(), database.GetUploadParams{UserID: userID, ID: uploadID})
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = render.Render(w, r, ErrNotFound)
+		return
+	} else if err != nil {
+		sqlErrors.Inc()
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	if upload.Status == database.UploadStatusCreated {
+		parts := make([]types.CompletedPart, len(data.Parts))
+		for i, p := range data.Parts {
+			parts[i] = types.CompletedPart{PartNumber: aws.Int32(p.Number), ETag: aws.String(p.ETag)}
+		}
+		_, completeErr := h.s3client.CompleteMultipartUpload(r.Context(), &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(h.s3bucket),
+			Key:             aws.String(key),
+			UploadId:        aws.String(data.MultipartID),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		// The object might have been assembled by a previous attempt which failed afterwards.
+		var nsu *types.NoSuchUpload
+		if completeErr != nil && !errors.As(completeErr, &nsu) {
+			log.Info("completing multipart upload failed", "err", completeErr)
+			_ = render.Render(w, r, ErrInvalidRequest(fmt.Errorf("cannot complete upload: %w", completeErr)))
+			return
+		}
+
+		obj, err := h.s3client.HeadObject(r.Context(), &s3.HeadObjectInput{
+			Bucket: aws.String(h.s3bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil && completeErr != nil {
+			log.Info("completing multipart upload failed", "err", completeErr)
+			_ = render.Render(w, r, ErrInvalidRequest(fmt.Errorf("cannot complete upload: %w", completeErr)))
+			return
+		} else if err != nil {
+			_ = render.Render(w, r, ErrInternalError(fmt.Errorf("cannot verify uploaded object: %w", err)))
+			return
+		}
+		size := aws.ToInt64(obj.ContentLength)
+		if size <= 0 || size > upload.Size {
+			if err := h.rejectPresignedObject(r.Context(), upload, key); err != nil {
+				_ = render.Render(w, r, ErrInternalError(err))
+				return
+			}
+			log.Info("presigned upload rejected", "size", size, "declared_size", upload.Size)
+			if size <= 0 {
+				_ = render.Render(w, r, ErrInvalidRequest(errors.New("uploaded file is empty")))
+			} else {
+				_ = render.Render(w, r, ErrInvalidRequest(fmt.Errorf("uploaded file is larger than declared size of %d bytes", upload.Size)))
+			}
+			return
+		}
+
+		completed, err := h.queries.MarkPresignedUploadCompleted(r.Context(), database.MarkPresignedUploadCompletedParams{
+			UserID:       userID,
+			ID:           uploadID,
+			Size:         size,
+			Filename:     data.Filename,
+			Key:          key,
+			KeepMetadata: data.KeepMetadata,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Completed by a concurrent request.
+			completed, err = h.queries.GetUpload(r.Context(), database.GetUploadParams{UserID: userID, ID: uploadID})
+		}
+		if err != nil {
+			sqlErrors.Inc()
+			_ = render.Render(w, r, ErrInternalError(err))
+			return
+		}
+		upload = completed
+	}
+
+	switch upload.Status {
+	case database.UploadStatusCompleted:
+	case database.UploadStatusProcessed, database.UploadStatusFailed:
+		// Already picked up by forklift, the previous response must have been lost.
+		_ = render.Render(w, r, ResponseUploadCompleted(uploadID, upload.Size))
+		return
+	default:
+		_ = render.Render(w, r, ErrInvalidRequest(errUploadTerminated))
+		return
+	}
+
+	if h.notifier != nil {
+		err = h.notifier.UploadReceived(
+			userID, uploadID, upload.Filename,
+			tasks.FileLocationS3{Key: upload.Key, Bucket: h.s3bucket},
+			upload.KeepMetadata)
+		if errors.Is(err, queue.ErrDuplicateRequest) {
+			// Forklift has the request from an earlier completion.
+			err = nil
+		}
+		if err != nil {
+			redisErrors.Inc()
+			log.Warn("completing upload failed", "err", err)
+			_ = render.Render(w, r, ErrInternalError(err))
+			return
+		}
+	}
+	log.Info("upload received", "type", "presigned", "size", upload.Size)
+	_ = render.Render(w, r, ResponseUploadCompleted(uploadID, upload.Size))
+}
+
+// AbortPresignedUpload cancels a direct-to-storage upload, removing already uploaded parts.
+func (h *Handler) AbortPresignedUpload(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserIDFromRequest(r)
+	uploadID := chi.URLParam(r, "upload_id")
+	if !rePresignedUploadID.MatchString(uploadID) {
+		_ = render.Render(w, r, ErrNotFound)
+		return
+	}
+	multipartID := r.URL.Query().Get("multipart_id")
+	if multipartID == "" {
+		_ = render.Render(w, r, ErrInvalidRequest(errors.New("multipart_id is required")))
+		return
+	}
+	_, err := h.s3client.AbortMultipartUpload(r.Context(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(h.s3bucket),
+		Key:      aws.String(presignedKey(userID, uploadID)),
+		UploadId: aws.String(multipartID),
+	})
+	var nsu *types.NoSuchUpload
+	if errors.As(err, &nsu) {
+		_ = render.Render(w, r, ErrNotFound)
+		return
+	} else if err != nil {
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	err = h.queries.MarkUploadTerminated(r.Context(), database.MarkUploadTerminatedParams{UserID: userID, ID: uploadID})
+	if err != nil {
+		sqlErrors.Inc()
+		h.logger.Warn("failed to mark presigned upload terminated", "user_id", userID, "upload_id", uploadID, "err", err)
+	}
+	h.logger.Info("presigned upload aborted", "user_id", userID, "upload_id", uploadID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rejectPresignedObject removes the uploaded object and terminates the upload.
+func (h *Handler) rejectPresignedObject(ctx context.Context, upload database.Upload, key string) error {
+	_, err := h.s3client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(h.s3bucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("cannot remove rejected object: %w", err)
+	}
+	err = h.queries.MarkUploadTerminated(ctx, database.MarkUploadTerminatedParams{UserID: upload.UserID, ID: upload.ID})
+	if err != nil {
+		sqlErrors.Inc()
+		return err
+	}
+	return nil
+}
+
+// abortMultipart removes parts of a multipart upload which won't be completed, leaving it for the reaper on failure.
+func (h *Handler) abortMultipart(ctx context.Context, key, multipartID string) {
+	_, err := h.s3client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(h.s3bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(multipartID),
+	})
+	if err != nil {
+		h.logger.Warn("failed to abort multipart upload", "key", key, "err", err)
+	}
+}
+
+// planParts splits the file into as few parts as S3 limits allow, starting from the requested part size.
+func planParts(size, partSize int64) (int64, int) {
+	if partSize == 0 {
+		partSize = defaultPartSize
+	}
+	partSize = max(partSize, minPartSize, (size+maxParts-1)/maxParts)
+	return partSize, int((size + partSize - 1) / partSize)
+}
+
+func presignedKey(userID int32, uploadID string) string {
+	return strings.Join([]string{presignedPrefix, fmt.Sprint(userID), uploadID}, "/")
+}
@@ -0,0 +1,196 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/internal/test"
+
+	"github.com/Pallinder/go-randomdata"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+// createPresignedUpload starts a presigned upload of declaredSize and uploads data to its part URLs, if any.
+func (s *uploadSuite) createPresignedUpload(baseURL, tokenHeader string, declaredSize int64, data []byte) (PresignedUpload, []PresignedPartETag) {
+	response := (&test.HTTPTest{
+		Method:      http.MethodPost,
+		URL:         baseURL + "/v1/presigned/",
+		ReqHeader:   map[string]string{AuthorizationHeader: tokenHeader, "Content-Type": "application/json"},
+		ReqBodyJSON: map[string]any{"size": declaredSize, "part_size": minPartSize},
+		Code:        http.StatusCreated,
+	}).RunHTTP(s.T())
+	defer response.Body.Close()
+	var created struct {
+		Status  string          `json:"status"`
+		Payload PresignedUpload `json:"payload"`
+	}
+	s.Require().NoError(json.NewDecoder(response.Body).Decode(&created))
+	s.Equal(StatusPresignedCreated, created.Status)
+	upload := created.Payload
+
+	parts := []PresignedPartETag{}
+	if data == nil {
+		return upload, parts
+	}
+	for i, p := range upload.Parts {
+		end := int64(i+1) * upload.PartSize
+		if i == len(upload.Parts)-1 {
+			end = int64(len(data))
+		}
+		req, err := http.NewRequest(http.MethodPut, p.URL, bytes.NewReader(data[int64(i)*upload.PartSize:end]))
+		s.Require().NoError(err)
+		res, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		res.Body.Close()
+		s.Require().Equal(http.StatusOK, res.StatusCode)
+		parts = append(parts, PresignedPartETag{Number: p.Number, ETag: res.Header.Get("ETag")})
+	}
+	return upload, parts
+}
+
+func (s *uploadSuite) TestPresignedUpload() {
+	testServer := httptest.NewServer(s.router)
+	defer testServer.Close()
+	queries := database.New(s.db)
+
+	userID := int32(randomdata.Number(1, 1000000))
+	token, err := s.keyfob.GenerateToken(userID, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	tokenHeader := fmt.Sprintf("Bearer %s", token)
+
+	var fileSize int64 = minPartSize + 1000
+	file := s.createRandomFile(uint64(fileSize))
+	defer file.Close()
+	data := make([]byte, fileSize)
+	_, err = io.ReadFull(file, data)
+	s.Require().NoError(err)
+
+	upload, parts := s.createPresignedUpload(testServer.URL, tokenHeader, fileSize, data)
+	s.Require().Len(upload.Parts, 2)
+
+	// The upload counts towards quotas before it's completed.
+	u, err := queries.GetUpload(context.Background(), database.GetUploadParams{UserID: userID, ID: upload.UploadID})
+	s.Require().NoError(err)
+	s.Equal(database.UploadStatusCreated, u.Status)
+	s.Equal(fileSize, u.Size)
+	usage, err := queries.GetUserUsage(context.Background(), userID)
+	s.Require().NoError(err)
+	s.EqualValues(1, usage.ConcurrentUploads)
+	s.EqualValues(1, usage.FilesPerHour)
+
+	otherToken, err := s.keyfob.GenerateToken(userID+1, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	completeURL := testServer.URL + "/v1/presigned/" + upload.UploadID + "/complete"
+	completion := PresignedCompleteRequest{MultipartID: upload.MultipartID, Filename: "video.mp4", Parts: parts}
+	(&test.HTTPTest{
+		Method:      http.MethodPost,
+		URL:         completeURL,
+		ReqHeader:   map[string]string{AuthorizationHeader: fmt.Sprintf("Bearer %s", otherToken), "Content-Type": "application/json"},
+		ReqBodyJSON: completion,
+		Code:        http.StatusNotFound,
+	}).RunHTTP(s.T())
+
+	// Completion can be retried.
+	for range 2 {
+		(&test.HTTPTest{
+			Method:      http.MethodPost,
+			URL:         completeURL,
+			ReqHeader:   map[string]string{AuthorizationHeader: tokenHeader, "Content-Type": "application/json"},
+			ReqBodyJSON: completion,
+			Code:        http.StatusOK,
+			ResContains: StatusUploadCompleted,
+		}).RunHTTP(s.T())
+	}
+
+	u, err = queries.GetUpload(context.Background(), database.GetUploadParams{UserID: userID, ID: upload.UploadID})
+	s.Require().NoError(err)
+	s.Equal(database.UploadStatusCompleted, u.Status)
+	s.Equal(fileSize, u.Size)
+	s.Equal("video.mp4", u.Filename)
+	s.Equal(presignedKey(userID, upload.UploadID), u.Key)
+}
+
+func (s *uploadSuite) TestPresignedUploadLargerThanDeclared() {
+	testServer := httptest.NewServer(s.router)
+	defer testServer.Close()
+
+	userID := int32(randomdata.Number(1, 1000000))
+	token, err := s.keyfob.GenerateToken(userID, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	tokenHeader := fmt.Sprintf("Bearer %s", token)
+
+	var fileSize int64 = minPartSize + 1000
+	file := s.createRandomFile(uint64(fileSize))
+	defer file.Close()
+	data := make([]byte, fileSize)
+	_, err = io.ReadFull(file, data)
+	s.Require().NoError(err)
+
+	upload, parts := s.createPresignedUpload(testServer.URL, tokenHeader, fileSize-500, data)
+	(&test.HTTPTest{
+		Method:      http.MethodPost,
+		URL:         testServer.URL + "/v1/presigned/" + upload.UploadID + "/complete",
+		ReqHeader:   map[string]string{AuthorizationHeader: tokenHeader, "Content-Type": "application/json"},
+		ReqBodyJSON: PresignedCompleteRequest{MultipartID: upload.MultipartID, Filename: "video.mp4", Parts: parts},
+		Code:        http.StatusBadRequest,
+		ResContains: "larger than declared size",
+	}).RunHTTP(s.T())
+
+	u, err := database.New(s.db).GetUpload(context.Background(), database.GetUploadParams{UserID: userID, ID: upload.UploadID})
+	s.Require().NoError(err)
+	s.Equal(database.UploadStatusTerminated, u.Status)
+	_, err = s.launcher.s3client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.launcher.s3bucket),
+		Key:    aws.String(presignedKey(userID, upload.UploadID)),
+	})
+	s.Error(err)
+}
+
+func (s *uploadSuite) TestAbortPresignedUpload() {
+	testServer := httptest.NewServer(s.router)
+	defer testServer.Close()
+
+	userID := int32(randomdata.Number(1, 1000000))
+	token, err := s.keyfob.GenerateToken(userID, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	tokenHeader := fmt.Sprintf("Bearer %s", token)
+
+	upload, _ := s.createPresignedUpload(testServer.URL, tokenHeader, minPartSize, nil)
+	(&test.HTTPTest{
+		Method:    http.MethodDelete,
+		URL:       testServer.URL + "/v1/presigned/" + upload.UploadID + "?multipart_id=" + upload.MultipartID,
+		ReqHeader: map[string]string{AuthorizationHeader: tokenHeader},
+		Code:      http.StatusNoContent,
+	}).RunHTTP(s.T())
+
+	u, err := database.New(s.db).GetUpload(context.Background(), database.GetUploadParams{UserID: userID, ID: upload.UploadID})
+	s.Require().NoError(err)
+	s.Equal(database.UploadStatusTerminated, u.Status)
+}
+
+func TestPlanParts(t *testing.T) {
+	cases := []struct {
+		size, partSize, expectedSize int64
+		expectedCount                int
+	}{
+		{100, 0, defaultPartSize, 1},
+		{defaultPartSize + 1, 0, defaultPartSize, 2},
+		{20 << 20, minPartSize, minPartSize, 4},
+		{1 << 40, minPartSize, (1<<40 + maxParts - 1) / maxParts, maxParts},
+	}
+	for _, c := range cases {
+		partSize, count := planParts(c.size, c.partSize)
+		assert.Equal(t, c.expectedSize, partSize, c.size)
+		assert.Equal(t, c.expectedCount, count, c.size)
+	}
+}
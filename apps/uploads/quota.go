@@ -0,0 +1,290 @@
+package uploads
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	// DefaultTier applies to users without an explicitly assigned tier.
+	DefaultTier = "default"
+
+	QuotaBytesPerDay       = "bytes_per_day"
+	QuotaConcurrentUploads = "concurrent_uploads"
+	QuotaFilesPerHour      = "files_per_hour"
+
+	headerQuotaPrefix = "X-Upload-Quota-"
+)
+
+// Limits is a set of upload quotas, zero value means no limit.
+type Limits struct {
+	BytesPerDay       int64 `json:"bytes_per_day" mapstructure:"BytesPerDay"`
+	ConcurrentUploads int32 `json:"concurrent_uploads" mapstructure:"ConcurrentUploads"`
+	FilesPerHour      int32 `json:"files_per_hour" mapstructure:"FilesPerHour"`
+}
+
+// Usage is the amount of quotas consumed by the user.
+type Usage struct {
+	BytesPerDay       int64 `json:"bytes_per_day"`
+	ConcurrentUploads int32 `json:"concurrent_uploads"`
+	FilesPerHour      int32 `json:"files_per_hour"`
+}
+
+// QuotaStatus is the outcome of a quota check.
+type QuotaStatus struct {
+	UserID int32  `json:"user_id"`
+	Tier   string `json:"tier"`
+	Limits Limits `json:"limits"`
+	Usage  Usage  `json:"usage"`
+	// Overrides are limits set for the user individually, overriding their tier.
+	Overrides *Limits `json:"overrides,omitempty"`
+	// Exceeded lists quotas that the requested upload would go over.
+	Exceeded []string `json:"exceeded,omitempty"`
+}
+
+// QuotaOverride is a per-user quota assignment, nil limits fall back to the tier values.
+type QuotaOverride struct {
+	Tier              string `json:"tier"`
+	BytesPerDay       *int64 `json:"bytes_per_day"`
+	ConcurrentUploads *int32 `json:"concurrent_uploads"`
+	FilesPerHour      *int32 `json:"files_per_hour"`
+}
+
+// QuotaManager checks user uploads against their tier limits.
+type QuotaManager struct {
+	queries *database.Queries
+	tiers   map[string]Limits
+}
+
+// NewQuotaManager creates a quota manager with limits for each tier.
+// Tier names are case-insensitive, users without a tier get DefaultTier limits.
+func NewQuotaManager(db database.DBTX, tiers map[string]Limits) *QuotaManager {
+	lt := make(map[string]Limits, len(tiers))
+	for name, limits := range tiers {
+		lt[strings.ToLower(name)] = limits
+	}
+	return &QuotaManager{queries: database.New(db), tiers: lt}
+}
+
+// Check returns quota status for the user, assuming they are about to upload a file of the given size.
+// Size can be zero if not known yet.
+func (m *QuotaManager) Check(ctx context.Context, userID int32, size int64) (*QuotaStatus, error) {
+	status, err := m.Status(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Exceeded = status.Limits.exceeded(status.Usage, size)
+	for _, q := range status.Exceeded {
+		quotaRejections.WithLabelValues(q).Inc()
+	}
+	return status, nil
+}
+
+// CheckFinal returns quota status for a final upload concatenated from partial uploads.
+// Partial uploads are checked and counted like any other upload, so the final one doesn't go over any quota by itself.
+func (m *QuotaManager) CheckFinal(ctx context.Context, userID int32) (*QuotaStatus, error) {
+	return m.Status(ctx, userID)
+}
+
+// Status returns limits and current usage for the user.
+func (m *QuotaManager) Status(ctx context.Context, userID int32) (*QuotaStatus, error) {
+	status := &QuotaStatus{UserID: userID, Tier: DefaultTier}
+	uq, err := m.queries.GetUserQuota(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user quota: %w", err)
+	}
+	if err == nil && uq.Tier != "" {
+		status.Tier = strings.ToLower(uq.Tier)
+	}
+	status.Limits = m.tiers[status.Tier]
+	if err == nil {
+		status.Overrides = overrideLimits(uq)
+		status.Limits = status.Limits.apply(uq)
+	}
+
+	usage, err := m.queries.GetUserUsage(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user usage: %w", err)
+	}
+	status.Usage = Usage(usage)
+	return status, nil
+}
+
+// Override assigns tier and individual limits to the user.
+func (m *QuotaManager) Override(ctx context.Context, userID int32, o QuotaOverride) error {
+	tier := strings.ToLower(o.Tier)
+	if tier != "" {
+		if _, ok := m.tiers[tier]; !ok {
+			return fmt.Errorf("unknown tier %q", o.Tier)
+		}
+	}
+	p := database.SetUserQuotaParams{UserID: userID, Tier: tier}
+	if o.BytesPerDay != nil {
+		p.BytesPerDay = sql.NullInt64{Int64: *o.BytesPerDay, Valid: true}
+	}
+	if o.ConcurrentUploads != nil {
+		p.ConcurrentUploads = sql.NullInt32{Int32: *o.ConcurrentUploads, Valid: true}
+	}
+	if o.FilesPerHour != nil {
+		p.FilesPerHour = sql.NullInt32{Int32: *o.FilesPerHour, Valid: true}
+	}
+	_, err := m.queries.SetUserQuota(ctx, p)
+	return err
+}
+
+// Reset removes tier assignment and individual limits of the user.
+func (m *QuotaManager) Reset(ctx context.Context, userID int32) error {
+	return m.queries.DeleteUserQuota(ctx, userID)
+}
+
+// Bind validates override values supplied by the admin.
+func (o *QuotaOverride) Bind(r *http.Request) error {
+	if (o.BytesPerDay != nil && *o.BytesPerDay < 0) ||
+		(o.ConcurrentUploads != nil && *o.ConcurrentUploads < 0) ||
+		(o.FilesPerHour != nil && *o.FilesPerHour < 0) {
+		return errors.New("limits cannot be negative")
+	}
+	return nil
+}
+
+// GetQuota is an admin endpoint returning quota limits and usage of the user.
+func (h *Handler) GetQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := quotaUserID(r)
+	if err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	status, err := h.quotas.Status(r.Context(), userID)
+	if err != nil {
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	_ = render.Render(w, r, ResponseQuota(status))
+}
+
+// SetQuota is an admin endpoint assigning tier and individual limits to the user.
+func (h *Handler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := quotaUserID(r)
+	if err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	o := &QuotaOverride{}
+	if err := render.Bind(r, o); err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if err := h.quotas.Override(r.Context(), userID, *o); err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	h.logger.Info("user quota overridden", "user_id", userID, "tier", o.Tier)
+	h.GetQuota(w, r)
+}
+
+// ResetQuota is an admin endpoint reverting the user to default tier limits.
+func (h *Handler) ResetQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := quotaUserID(r)
+	if err != nil {
+		_ = render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if err := h.quotas.Reset(r.Context(), userID); err != nil {
+		_ = render.Render(w, r, ErrInternalError(err))
+		return
+	}
+	h.logger.Info("user quota reset", "user_id", userID)
+	h.GetQuota(w, r)
+}
+
+// authByAdminToken is a middleware protecting admin endpoints with a static bearer token.
+func authByAdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(jwtauth.TokenFromHeader(r)), []byte(token)) != 1 {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func quotaUserID(r *http.Request) (int32, error) {
+	uid, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 32)
+	if err != nil || uid <= 0 {
+		return 0, errors.New("invalid user id")
+	}
+	return int32(uid), nil
+}
+
+// Headers returns quota limits and remaining values to be added to the response.
+func (s *QuotaStatus) Headers() map[string]string {
+	h := map[string]string{}
+	set := func(name string, limit, used int64) {
+		if limit <= 0 {
+			return
+		}
+		h[headerQuotaPrefix+name+"-Limit"] = strconv.FormatInt(limit, 10)
+		h[headerQuotaPrefix+name+"-Remaining"] = strconv.FormatInt(max(limit-used, 0), 10)
+	}
+	set("Bytes-Per-Day", s.Limits.BytesPerDay, s.Usage.BytesPerDay)
+	set("Concurrent-Uploads", int64(s.Limits.ConcurrentUploads), int64(s.Usage.ConcurrentUploads))
+	set("Files-Per-Hour", int64(s.Limits.FilesPerHour), int64(s.Usage.FilesPerHour))
+	return h
+}
+
+// Error returns a user-facing description of exceeded quotas.
+func (s *QuotaStatus) Error() string {
+	return "upload quota exceeded: " + strings.Join(s.Exceeded, ", ")
+}
+
+func (l Limits) exceeded(u Usage, size int64) []string {
+	var exceeded []string
+	if l.BytesPerDay > 0 && u.BytesPerDay+size > l.BytesPerDay {
+		exceeded = append(exceeded, QuotaBytesPerDay)
+	}
+	if l.ConcurrentUploads > 0 && u.ConcurrentUploads >= l.ConcurrentUploads {
+		exceeded = append(exceeded, QuotaConcurrentUploads)
+	}
+	if l.FilesPerHour > 0 && u.FilesPerHour >= l.FilesPerHour {
+		exceeded = append(exceeded, QuotaFilesPerHour)
+	}
+	return exceeded
+}
+
+func (l Limits) apply(uq database.UserQuota) Limits {
+	if uq.BytesPerDay.Valid {
+		l.BytesPerDay = uq.BytesPerDay.Int64
+	}
+	if uq.ConcurrentUploads.Valid {
+		l.ConcurrentUploads = uq.ConcurrentUploads.Int32
+	}
+	if uq.FilesPerHour.Valid {
+		l.FilesPerHour = uq.FilesPerHour.Int32
+	}
+	return l
+}
+
+func overrideLimits(uq database.UserQuota) *Limits {
+	if !uq.BytesPerDay.Valid && !uq.ConcurrentUploads.Valid && !uq.FilesPerHour.Valid {
+		return nil
+	}
+	return &Limits{
+		BytesPerDay:       uq.BytesPerDay.Int64,
+		ConcurrentUploads: uq.ConcurrentUploads.Int32,
+		FilesPerHour:      uq.FilesPerHour.Int32,
+	}
+}
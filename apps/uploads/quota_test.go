@@ -0,0 +1,59 @@
+package uploads
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitsExceeded(t *testing.T) {
+	l := Limits{BytesPerDay: 1000, ConcurrentUploads: 2, FilesPerHour: 5}
+	assert.Empty(t, l.exceeded(Usage{BytesPerDay: 500, ConcurrentUploads: 1, FilesPerHour: 4}, 500))
+	assert.Equal(t, []string{QuotaBytesPerDay}, l.exceeded(Usage{BytesPerDay: 500}, 501))
+	assert.Equal(t,
+		[]string{QuotaConcurrentUploads, QuotaFilesPerHour},
+		l.exceeded(Usage{ConcurrentUploads: 2, FilesPerHour: 5}, 0))
+	assert.Empty(t, Limits{}.exceeded(Usage{BytesPerDay: 1 << 40, ConcurrentUploads: 100, FilesPerHour: 100}, 1<<40))
+}
+
+func TestLimitsApply(t *testing.T) {
+	l := Limits{BytesPerDay: 1000, ConcurrentUploads: 2, FilesPerHour: 5}
+	uq := database.UserQuota{
+		BytesPerDay:  sql.NullInt64{Int64: 0, Valid: true},
+		FilesPerHour: sql.NullInt32{Int32: 50, Valid: true},
+	}
+	assert.Equal(t, Limits{BytesPerDay: 0, ConcurrentUploads: 2, FilesPerHour: 50}, l.apply(uq))
+	assert.Equal(t, &Limits{FilesPerHour: 50}, overrideLimits(uq))
+	assert.Nil(t, overrideLimits(database.UserQuota{Tier: "premium"}))
+}
+
+func TestQuotaStatusHeaders(t *testing.T) {
+	s := &QuotaStatus{
+		Limits: Limits{BytesPerDay: 1000, FilesPerHour: 5},
+		Usage:  Usage{BytesPerDay: 1500, ConcurrentUploads: 3, FilesPerHour: 2},
+	}
+	assert.Equal(t, map[string]string{
+		"X-Upload-Quota-Bytes-Per-Day-Limit":      "1000",
+		"X-Upload-Quota-Bytes-Per-Day-Remaining":  "0",
+		"X-Upload-Quota-Files-Per-Hour-Limit":     "5",
+		"X-Upload-Quota-Files-Per-Hour-Remaining": "3",
+	}, s.Headers())
+}
+
+func TestAuthByAdminToken(t *testing.T) {
+	h := authByAdminToken("secret")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for token, code := range map[string]int{"": http.StatusUnauthorized, "Bearer wrong": http.StatusUnauthorized, "Bearer secret": http.StatusNoContent} {
+		r := httptest.NewRequest(http.MethodGet, "/admin/v1/quotas/1", nil)
+		r.Header.Set(AuthorizationHeader, token)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		assert.Equal(t, code, rr.Code, token)
+	}
+}
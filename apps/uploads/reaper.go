@@ -0,0 +1,159 @@
+package uploads
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/uploads/database"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	tusd "github.com/tus/tusd/v2/pkg/handler"
+	"github.com/tus/tusd/v2/pkg/s3store"
+)
+
+// expiredUploadsSweeper terminates tus uploads that stopped receiving data before completion,
+// aborting their S3 multipart uploads and removing .info and .part objects.
+// Partial uploads which never got concatenated into a final upload are removed too.
+type expiredUploadsSweeper struct {
+	queries *database.Queries
+	store   s3store.S3Store
+	locker  tusd.Locker
+	limit   int32
+}
+
+// multipartSweeper aborts S3 multipart uploads which tus lost track of,
+// for example when upload record creation failed.
+// Uploads still in progress according to the database are left to expiredUploadsSweeper.
+type multipartSweeper struct {
+	queries *database.Queries
+	client  *s3.Client
+	bucket  string
+}
+
+// Reaper creates a reaper for abandoned uploads and their leftovers in S3 storage.
+func (l *Launcher) Reaper(options ...reaper.Option) *reaper.Reaper {
+	sweepers := []reaper.Sweeper{
+		&expiredUploadsSweeper{
+			queries: database.New(l.db),
+			store:   s3store.New(l.s3bucket, l.s3client),
+			locker:  l.fileLocker,
+			limit:   10000,
+		},
+		&multipartSweeper{queries: database.New(l.db), client: l.s3client, bucket: l.s3bucket},
+	}
+	return reaper.New(append([]reaper.Option{reaper.WithLogger(l.logger), reaper.WithSweepers(sweepers...)}, options...)...)
+}
+
+func (s *expiredUploadsSweeper) Name() string {
+	return "uploads"
+}
+
+func (s *expiredUploadsSweeper) Find(ctx context.Context, cutoff time.Time) ([]reaper.Item, error) {
+	uploads, err := s.queries.ListExpiredUploads(ctx, database.ListExpiredUploadsParams{
+		UpdatedAt: cutoff,
+		Limit:     s.limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]reaper.Item, len(uploads))
+	for i, u := range uploads {
+		items[i] = reaper.Item{ID: u.ID, Size: u.Received, LastActive: u.CreatedAt}
+		if u.UpdatedAt.Valid {
+			items[i].LastActive = u.UpdatedAt.Time
+		}
+	}
+	return items, nil
+}
+
+func (s *expiredUploadsSweeper) Reap(ctx context.Context, item reaper.Item) error {
+	if s.locker != nil {
+		lock, err := s.locker.NewLock(item.ID)
+		if err != nil {
+			return err
+		}
+		lockCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if err := lock.Lock(lockCtx, func() {}); err != nil {
+			return fmt.Errorf("upload is locked: %w", err)
+		}
+		defer lock.Unlock()
+	}
+
+	upload, err := s.store.GetUpload(ctx, item.ID)
+	switch {
+	case errors.Is(err, tusd.ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		if err := s.store.AsTerminatableUpload(upload).Terminate(ctx); err != nil {
+			return fmt.Errorf("failed to terminate upload: %w", err)
+		}
+	}
+	return s.queries.TerminateExpiredUpload(ctx, item.ID)
+}
+
+func (s *multipartSweeper) Name() string {
+	return "multipart"
+}
+
+func (s *multipartSweeper) Find(ctx context.Context, cutoff time.Time) ([]reaper.Item, error) {
+	var items []reaper.Item
+	p := s3.NewListMultipartUploadsPaginator(s.client, &s3.ListMultipartUploadsInput{Bucket: aws.String(s.bucket)})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page.Uploads {
+			if u.Initiated == nil || !u.Initiated.Before(cutoff) {
+				continue
+			}
+			// Same format as tus upload IDs produced by s3store.
+			id := aws.ToString(u.Key) + "+" + aws.ToString(u.UploadId)
+			status, err := s.queries.GetUploadStatus(ctx, id)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if err == nil && (status == database.UploadStatusCreated || status == database.UploadStatusReceiving) {
+				continue
+			}
+			items = append(items, reaper.Item{ID: id, LastActive: *u.Initiated})
+		}
+	}
+	return items, nil
+}
+
+func (s *multipartSweeper) Reap(ctx context.Context, item reaper.Item) error {
+	key, uploadID, ok := strings.Cut(item.ID, "+")
+	if !ok {
+		return fmt.Errorf("malformed multipart upload id: %s", item.ID)
+	}
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	var nsu *types.NoSuchUpload
+	if err != nil && !errors.As(err, &nsu) {
+		return err
+	}
+	_, err = s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(s.bucket),
+		Delete: &types.Delete{
+			Objects: []types.ObjectIdentifier{
+				{Key: aws.String(key + ".info")},
+				{Key: aws.String(key + ".part")},
+			},
+			Quiet: aws.Bool(true),
+		},
+	})
+	return err
+}
@@ -112,7 +112,7 @@ func (th *TestHelper) CreateUpload(filePath string, queue *queue.Queue) (*databa
 	err = notifier.UploadReceived(up.UserID, up.ID, path.Base(filePath), tasks.FileLocationS3{
 		Key:    uploadKey,
 		Bucket: th.S3Config.Bucket,
-	})
+	}, false)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ import (
 	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
 	"github.com/OdyseeTeam/odysee-api/pkg/redislocker"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -37,6 +38,9 @@ import (
 const (
 	AuthorizationHeader = "Authorization"
 	userContextKey      = "user"
+
+	// MetaKeepMetadata is the tus upload metadata key for opting out of file metadata sanitization.
+	MetaKeepMetadata = "keep_metadata"
 )
 
 var (
@@ -56,6 +60,11 @@ type Handler struct {
 	jwtAuth        *jwtauth.JWTAuth
 	tokenValidator *keybox.Validator
 	notifier       *forkliftNotifier
+	quotas         *QuotaManager
+	store          s3store.S3Store
+	locker         tusd.Locker
+	s3client       *s3.Client
+	presignExpiry  time.Duration
 	stopChan       chan struct{}
 }
 
@@ -68,6 +77,7 @@ type Launcher struct {
 	httpAddress   string
 	prefix        string
 	publicKey     crypto.PublicKey
+	validator     *keybox.Validator
 	router        chi.Router
 	queueRedisURL string
 	s3client      *s3.Client
@@ -77,6 +87,9 @@ type Launcher struct {
 	logger        logging.KVLogger
 	notifier      *forkliftNotifier
 	readyCancel   context.CancelFunc
+	quotaTiers    map[string]Limits
+	adminToken    string
+	presignExpiry time.Duration
 }
 
 type forkliftNotifier struct {
@@ -115,6 +128,13 @@ func WithPublicKey(publicKey crypto.PublicKey) LauncherOption {
 	}
 }
 
+// WithTokenValidator sets the validator of upload tokens, it takes precedence over WithPublicKey.
+func WithTokenValidator(validator *keybox.Validator) LauncherOption {
+	return func(l *Launcher) {
+		l.validator = validator
+	}
+}
+
 func WithForkliftRequestsConnURL(url string) LauncherOption {
 	return func(l *Launcher) {
 		l.queueRedisURL = url
@@ -133,6 +153,29 @@ func WithCORSDomains(domains []string) LauncherOption {
 	}
 }
 
+// WithQuotas enables per-user upload quotas with limits for each user tier.
+// Limits under DefaultTier apply to users without an assigned tier.
+func WithQuotas(tiers map[string]Limits) LauncherOption {
+	return func(l *Launcher) {
+		l.quotaTiers = tiers
+	}
+}
+
+// WithPresignedUploads enables uploading files directly to S3 using presigned multipart URLs,
+// valid for the expiry duration.
+func WithPresignedUploads(expiry time.Duration) LauncherOption {
+	return func(l *Launcher) {
+		l.presignExpiry = expiry
+	}
+}
+
+// WithAdminToken enables admin endpoints, authenticated by the supplied bearer token.
+func WithAdminToken(token string) LauncherOption {
+	return func(l *Launcher) {
+		l.adminToken = token
+	}
+}
+
 func NewLauncher(options ...LauncherOption) *Launcher {
 	launcher := &Launcher{
 		logger:      logging.NoopKVLogger{},
@@ -157,7 +200,7 @@ func (l *Launcher) Notifier() (*forkliftNotifier, error) {
 	if err != nil {
 		return nil, err
 	}
-	q, err := queue.New(queue.WithRequestsConnOpts(opts), queue.WithLogger(l.logger))
+	q, err := queue.New(queue.WithRequestsConnOpts(opts), queue.WithLogger(l.logger), queue.WithRoutes(tasks.Routes))
 	if err != nil {
 		return nil, err
 	}
@@ -172,9 +215,13 @@ func (l *Launcher) Notifier() (*forkliftNotifier, error) {
 }
 
 func (l *Launcher) BuildHandler() (chi.Router, error) {
-	validator, err := keybox.NewValidator(l.publicKey)
-	if err != nil {
-		return nil, err
+	var err error
+	validator := l.validator
+	if validator == nil {
+		validator, err = keybox.NewValidator(l.publicKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	l.logger.Info("creating s3 bucket", "bucket", l.s3bucket)
@@ -204,10 +251,19 @@ func (l *Launcher) BuildHandler() (chi.Router, error) {
 		queries:        database.New(l.db),
 		tokenValidator: validator,
 		notifier:       notifier,
+		store:          store,
+		locker:         l.fileLocker,
+		s3client:       l.s3client,
+		presignExpiry:  l.presignExpiry,
 		stopChan:       make(chan struct{}),
 	}
+	if l.quotaTiers != nil {
+		handler.quotas = NewQuotaManager(l.db, l.quotaTiers)
+	}
 	l.readyCancel = readyCancel
 
+	// s3store also provides the concatenation extension, allowing clients to send parts
+	// of a file in parallel as partial uploads and join them into a final upload.
 	composer := tusd.NewStoreComposer()
 	composer.UseLocker(l.fileLocker)
 	store.UseIn(composer)
@@ -231,6 +287,9 @@ func (l *Launcher) BuildHandler() (chi.Router, error) {
 		NotifyUploadProgress:    true,
 		NotifyCompleteUploads:   true,
 	}
+	if handler.quotas != nil {
+		tusConfig.PreUploadCreateCallback = handler.checkUploadQuota
+	}
 
 	httpLogger := &JSONLogger{logger: l.logger}
 
@@ -242,7 +301,7 @@ func (l *Launcher) BuildHandler() (chi.Router, error) {
 
 	nonUploadCors := cors.Handler(cors.Options{
 		AllowedOrigins:   l.corsDomains,
-		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Requested-With"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -255,6 +314,8 @@ func (l *Launcher) BuildHandler() (chi.Router, error) {
 	onceMetrics.Do(func() {
 		registerMetrics(registry)
 		redislocker.RegisterMetrics(registry)
+		reaper.RegisterMetrics(registry)
+		queue.RegisterMetrics(registry)
 		tusMetrics := prometheuscollector.New(handler.Metrics)
 		registry.MustRegister(tusMetrics)
 	})
@@ -285,8 +346,28 @@ func (l *Launcher) BuildHandler() (chi.Router, error) {
 			r.Use(nonUploadCors)
 			r.Post("/", handler.PostURL)
 		})
+		if l.presignExpiry > 0 {
+			r.Route("/presigned", func(r chi.Router) {
+				r.Use(nonUploadCors)
+				r.Post("/", handler.CreatePresignedUpload)
+				r.Post("/{upload_id}/complete", handler.CompletePresignedUpload)
+				r.Delete("/{upload_id}", handler.AbortPresignedUpload)
+			})
+		}
 	})
 
+	// Admin endpoints
+	if l.adminToken != "" {
+		router.Route("/admin"+l.prefix, func(r chi.Router) {
+			r.Use(authByAdminToken(l.adminToken))
+			if handler.quotas != nil {
+				r.Get("/quotas/{user_id}", handler.GetQuota)
+				r.Put("/quotas/{user_id}", handler.SetQuota)
+				r.Delete("/quotas/{user_id}", handler.ResetQuota)
+			}
+		})
+	}
+
 	// Internal endpoints
 	router.Get("/livez", func(w http.ResponseWriter, _ *http.Request) {
 		if readyCtx.Err() != nil {
@@ -345,6 +426,22 @@ func (h *Handler) PostURL(w http.ResponseWriter, r *http.Request) {
 		_ = render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
+	if h.quotas != nil {
+		status, err := h.quotas.Check(r.Context(), userID, 0)
+		if err != nil {
+			sqlErrors.Inc()
+			_ = render.Render(w, r, ErrInternalError(err))
+			return
+		}
+		for k, v := range status.Headers() {
+			w.Header().Set(k, v)
+		}
+		if len(status.Exceeded) > 0 {
+			h.logger.Info("upload quota exceeded", "user_id", userID, "type", "url", "exceeded", status.Exceeded)
+			_ = render.Render(w, r, ErrQuotaExceeded(status))
+			return
+		}
+	}
 	_, err := h.queries.CreateURL(r.Context(), database.CreateURLParams{
 		UserID:   userID,
 		ID:       data.UploadID,
@@ -356,7 +453,7 @@ func (h *Handler) PostURL(w http.ResponseWriter, r *http.Request) {
 		_ = render.Render(w, r, ErrInternalError(err))
 		return
 	}
-	err = h.notifier.URLReceived(userID, data.UploadID, data.Filename, tasks.FileLocationHTTP{URL: data.URL})
+	err = h.notifier.URLReceived(userID, data.UploadID, data.Filename, tasks.FileLocationHTTP{URL: data.URL}, data.KeepMetadata)
 	if err != nil {
 		_ = render.Render(w, r, ErrInvalidRequest(err))
 		return
@@ -385,10 +482,17 @@ func (h *Handler) listenToHooks() {
 	}
 
 	go listen(h.CreatedUploads, func(uid int32, event tusd.HookEvent) {
+		// Final uploads are complete right after creation and get recorded by the completion listener,
+		// otherwise creation and completion events for them would race.
+		if event.Upload.IsFinal {
+			return
+		}
 		p := database.CreateUploadParams{
-			UserID: uid,
-			ID:     event.Upload.ID,
-			Size:   event.Upload.Size,
+			UserID:       uid,
+			ID:           event.Upload.ID,
+			Size:         event.Upload.Size,
+			IsPartial:    event.Upload.IsPartial,
+			KeepMetadata: keepMetadata(event.Upload.MetaData),
 		}
 		_, err := h.queries.CreateUpload(context.Background(), p)
 		if err != nil {
@@ -429,6 +533,21 @@ func (h *Handler) listenToHooks() {
 	})
 
 	go listen(h.CompleteUploads, func(uid int32, event tusd.HookEvent) {
+		if event.Upload.IsFinal {
+			_, err := h.queries.CreateUpload(context.Background(), database.CreateUploadParams{
+				UserID:       uid,
+				ID:           event.Upload.ID,
+				Size:         event.Upload.Size,
+				IsFinal:      true,
+				KeepMetadata: keepMetadata(event.Upload.MetaData),
+			})
+			if err != nil {
+				sqlErrors.Inc()
+				h.logger.Warn("creating final upload failed", "user_id", uid, "upload_id", event.Upload.ID, "err", err)
+				return
+			}
+			defer h.removePartialUploads(uid, event.Upload.PartialUploads)
+		}
 		p := database.MarkUploadCompletedParams{
 			UserID:   uid,
 			ID:       event.Upload.ID,
@@ -442,6 +561,11 @@ func (h *Handler) listenToHooks() {
 			return
 		}
 
+		// Partial uploads are only processed as part of a final upload.
+		if event.Upload.IsPartial {
+			h.logger.Info("partial upload received", "user_id", uid, "upload_id", event.Upload.ID, "size", event.Upload.Size)
+			return
+		}
 		if h.notifier == nil {
 			return
 		}
@@ -452,7 +576,8 @@ func (h *Handler) listenToHooks() {
 			tasks.FileLocationS3{
 				Key:    p.Key,
 				Bucket: h.s3bucket,
-			})
+			},
+			keepMetadata(event.Upload.MetaData))
 		if err != nil {
 			h.logger.Warn("completing upload failed", "user_id", uid, "upload_id", event.Upload.ID, "err", err)
 			redisErrors.Inc()
@@ -481,7 +606,36 @@ func (h *Handler) authByToken(next http.Handler) http.Handler {
 	})
 }
 
+// checkUploadQuota is a tus pre-create hook rejecting uploads that would put the user over their quota.
+func (h *Handler) checkUploadQuota(hook tusd.HookEvent) (tusd.HTTPResponse, tusd.FileInfoChanges, error) {
+	userID, err := h.extractUserIDFromRequest(&http.Request{Header: hook.HTTPRequest.Header})
+	if err != nil {
+		return tusd.HTTPResponse{}, tusd.FileInfoChanges{}, tusd.NewError("ERR_UNAUTHORIZED", err.Error(), http.StatusUnauthorized)
+	}
+	var status *QuotaStatus
+	if hook.Upload.IsFinal {
+		status, err = h.quotas.CheckFinal(hook.Context, userID)
+	} else {
+		status, err = h.quotas.Check(hook.Context, userID, hook.Upload.Size)
+	}
+	if err != nil {
+		sqlErrors.Inc()
+		h.logger.Warn("upload quota check failed", "user_id", userID, "err", err)
+		return tusd.HTTPResponse{}, tusd.FileInfoChanges{}, err
+	}
+	resp := tusd.HTTPResponse{Header: tusd.HTTPHeader(status.Headers())}
+	if len(status.Exceeded) > 0 {
+		h.logger.Info("upload quota exceeded", "user_id", userID, "type", "upload", "exceeded", status.Exceeded)
+		qerr := tusd.NewError("ERR_UPLOAD_QUOTA_EXCEEDED", status.Error(), http.StatusTooManyRequests)
+		qerr.HTTPResponse = qerr.HTTPResponse.MergeWith(resp)
+		return tusd.HTTPResponse{}, tusd.FileInfoChanges{}, qerr
+	}
+	return resp, tusd.FileInfoChanges{}, nil
+}
+
 // verifyUploadFileAccess is a middleware to verify that accessed upload is owned by the user.
+// For final uploads, which are concatenated from partial ones, each partial upload is verified
+// and locked for the duration of the request so it cannot be modified while being concatenated.
 func (h *Handler) verifyUploadFileAccess(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		uploadID := extractUploadIDFromPath(r.URL.Path)
@@ -500,11 +654,66 @@ func (h *Handler) verifyUploadFileAccess(next http.Handler) http.Handler {
 				return
 			}
 		}
+		if r.Method == http.MethodPost {
+			for _, partialID := range extractPartialUploadIDs(r.Header.Get("Upload-Concat")) {
+				upload, err := h.queries.GetUpload(r.Context(), database.GetUploadParams{
+					UserID: userID, ID: partialID,
+				})
+				if err != nil || !upload.IsPartial {
+					h.logger.Info("partial upload not found", "upload_id", partialID, "user_id", userID, "err", err)
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+					return
+				}
+				if h.locker == nil {
+					continue
+				}
+				lock, err := h.lockUpload(r.Context(), partialID)
+				if err != nil {
+					h.logger.Info("partial upload is locked", "upload_id", partialID, "user_id", userID, "err", err)
+					http.Error(w, "partial upload is in use", http.StatusLocked)
+					return
+				}
+				defer lock.Unlock()
+			}
+		}
 
 		next.ServeHTTP(w, r.Clone(context.WithValue(r.Context(), userContextKey, userID)))
 	})
 }
 
+func (h *Handler) lockUpload(ctx context.Context, id string) (tusd.Lock, error) {
+	lock, err := h.locker.NewLock(id)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := lock.Lock(ctx, func() {}); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// removePartialUploads deletes partial uploads from storage once they have been concatenated into a final upload.
+func (h *Handler) removePartialUploads(userID int32, ids []string) {
+	ctx := context.Background()
+	for _, id := range ids {
+		upload, err := h.store.GetUpload(ctx, id)
+		if err == nil {
+			err = h.store.AsTerminatableUpload(upload).Terminate(ctx)
+		}
+		if err != nil && !errors.Is(err, tusd.ErrNotFound) {
+			h.logger.Warn("removing partial upload failed", "user_id", userID, "upload_id", id, "err", err)
+			continue
+		}
+		err = h.queries.MarkUploadTerminated(ctx, database.MarkUploadTerminatedParams{UserID: userID, ID: id})
+		if err != nil {
+			sqlErrors.Inc()
+			h.logger.Warn("recording partial upload removal failed", "user_id", userID, "upload_id", id, "err", err)
+		}
+	}
+}
+
 func (h *Handler) getUserIDFromRequest(r *http.Request) int32 {
 	return r.Context().Value(userContextKey).(int32)
 }
@@ -542,8 +751,34 @@ func extractUploadIDFromPath(url string) string {
 	return result[1]
 }
 
+// extractPartialUploadIDs returns IDs of partial uploads listed in the Upload-Concat header of a final upload.
+func extractPartialUploadIDs(header string) []string {
+	list, ok := strings.CutPrefix(header, "final;")
+	if !ok {
+		return nil
+	}
+	var ids []string
+	for _, u := range strings.Fields(list) {
+		// Unrecognized IDs are kept empty so the request gets rejected instead of skipping the check.
+		ids = append(ids, extractUploadIDFromPath(u))
+	}
+	return ids
+}
+
+// keepMetadata checks if the publisher opted out of metadata sanitization via tus upload metadata.
+func keepMetadata(meta tusd.MetaData) bool {
+	v, err := strconv.ParseBool(meta[MetaKeepMetadata])
+	return err == nil && v
+}
+
+// uploadRequestID is the ID of forklift request for the upload, it keeps the upload from being processed twice at once.
+func uploadRequestID(uploadID string) string {
+	return tasks.ForkliftUploadIncoming + ":" + uploadID
+}
+
 // UploadReceived sends off a finalized upload to forklift queue for further processing.
-func (c forkliftNotifier) UploadReceived(userID int32, uploadID, filename string, location tasks.FileLocationS3) error {
+// It returns queue.ErrDuplicateRequest if the upload was sent off already and forklift still has the request.
+func (c forkliftNotifier) UploadReceived(userID int32, uploadID, filename string, location tasks.FileLocationS3, keepMetadata bool) error {
 	err := c.queue.SendRequest(
 		tasks.ForkliftUploadIncoming,
 		tasks.ForkliftUploadIncomingPayload{
@@ -551,7 +786,9 @@ func (c forkliftNotifier) UploadReceived(userID int32, uploadID, filename string
 			UserID:       userID,
 			FileName:     filename,
 			FileLocation: location,
+			KeepMetadata: keepMetadata,
 		}, queue.WithRequestRetry(10), queue.WithRequestTimeout(24*time.Hour),
+		queue.WithRequestID(uploadRequestID(uploadID)),
 	)
 	if err != nil {
 		return err
@@ -561,7 +798,7 @@ func (c forkliftNotifier) UploadReceived(userID int32, uploadID, filename string
 }
 
 // URLReceived sends off a finalized upload to forklift queue for further processing.
-func (c forkliftNotifier) URLReceived(userID int32, uploadID, filename string, location tasks.FileLocationHTTP) error {
+func (c forkliftNotifier) URLReceived(userID int32, uploadID, filename string, location tasks.FileLocationHTTP, keepMetadata bool) error {
 	err := c.queue.SendRequest(
 		tasks.ForkliftURLIncoming,
 		tasks.ForkliftURLIncomingPayload{
@@ -569,6 +806,7 @@ func (c forkliftNotifier) URLReceived(userID int32, uploadID, filename string, l
 			UploadID:     uploadID,
 			FileName:     filename,
 			FileLocation: location,
+			KeepMetadata: keepMetadata,
 		}, queue.WithRequestRetry(10), queue.WithRequestTimeout(24*time.Hour),
 	)
 	if err != nil {
@@ -135,6 +135,106 @@ func (s *uploadSuite) TestUpload() {
 	s.Equal("dummy.md", upload.Filename)
 }
 
+func (s *uploadSuite) TestUploadConcatenation() {
+	testServer := httptest.NewServer(s.router)
+	defer testServer.Close()
+
+	queries := database.New(s.db)
+	baseURL := "/v1/uploads/"
+	userID := int32(randomdata.Number(1, 1000000))
+	token, err := s.keyfob.GenerateToken(userID, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	tokenHeader := fmt.Sprintf("Bearer %s", token)
+
+	waitForStatus := func(id string, status database.UploadStatus) database.Upload {
+		var upload database.Upload
+		e2etest.Wait(s.T(), "upload settling into database", 5*time.Second, 100*time.Millisecond, func() error {
+			var err error
+			upload, err = queries.GetUpload(context.Background(), database.GetUploadParams{UserID: userID, ID: id})
+			if errors.Is(err, sql.ErrNoRows) {
+				return e2etest.ErrWaitContinue
+			} else if err != nil {
+				return err
+			} else if upload.Status != status {
+				return e2etest.ErrWaitContinue
+			}
+			return nil
+		})
+		return upload
+	}
+
+	partials := []string{}
+	for _, part := range []string{"first part, ", "second part"} {
+		response := (&test.HTTPTest{
+			Method: http.MethodPost,
+			URL:    testServer.URL + baseURL,
+			ReqHeader: map[string]string{
+				"Tus-Resumable":     "1.0.0",
+				"Upload-Concat":     "partial",
+				"Upload-Length":     fmt.Sprintf("%d", len(part)),
+				"Content-Type":      "application/offset+octet-stream",
+				AuthorizationHeader: tokenHeader,
+			},
+			ReqBody: strings.NewReader(part),
+			Code:    http.StatusCreated,
+		}).RunHTTP(s.T())
+		loc, err := url.Parse(response.Header.Get("Location"))
+		s.Require().NoError(err)
+		upload := waitForStatus(filepath.Base(loc.Path), database.UploadStatusCompleted)
+		s.True(upload.IsPartial)
+		partials = append(partials, loc.RequestURI())
+	}
+
+	// Partial uploads count towards quotas until they're concatenated.
+	usage, err := queries.GetUserUsage(context.Background(), userID)
+	s.Require().NoError(err)
+	s.Equal(database.GetUserUsageRow{
+		BytesPerDay: int64(len("first part, second part")), ConcurrentUploads: 2, FilesPerHour: 2,
+	}, usage)
+
+	otherToken, err := s.keyfob.GenerateToken(userID+1, time.Now().Add(time.Hour*24))
+	s.Require().NoError(err)
+	(&test.HTTPTest{
+		Method: http.MethodPost,
+		URL:    testServer.URL + baseURL,
+		ReqHeader: map[string]string{
+			"Tus-Resumable":     "1.0.0",
+			"Upload-Concat":     "final;" + strings.Join(partials, " "),
+			AuthorizationHeader: fmt.Sprintf("Bearer %s", otherToken),
+		},
+		Code: http.StatusNotFound,
+	}).RunHTTP(s.T())
+
+	response := (&test.HTTPTest{
+		Method: http.MethodPost,
+		URL:    testServer.URL + baseURL,
+		ReqHeader: map[string]string{
+			"Tus-Resumable":     "1.0.0",
+			"Upload-Concat":     "final;" + strings.Join(partials, " "),
+			"Upload-Metadata":   fmt.Sprintf("filename %s", base64.StdEncoding.EncodeToString([]byte("dummy.md"))),
+			AuthorizationHeader: tokenHeader,
+		},
+		Code: http.StatusCreated,
+	}).RunHTTP(s.T())
+	loc, err := url.Parse(response.Header.Get("Location"))
+	s.Require().NoError(err)
+
+	upload := waitForStatus(filepath.Base(loc.Path), database.UploadStatusCompleted)
+	s.False(upload.IsPartial)
+	s.EqualValues(len("first part, second part"), upload.Size)
+	s.Equal("dummy.md", upload.Filename)
+	for _, p := range partials {
+		waitForStatus(filepath.Base(p), database.UploadStatusTerminated)
+	}
+	s.True(upload.IsFinal)
+
+	usage, err = queries.GetUserUsage(context.Background(), userID)
+	s.Require().NoError(err)
+	s.Equal(database.GetUserUsageRow{
+		BytesPerDay: int64(len("first part, second part")), ConcurrentUploads: 0, FilesPerHour: 2,
+	}, usage)
+}
+
 func (s *uploadSuite) TestUploadLarger() {
 	testServer := httptest.NewServer(s.router)
 	defer testServer.Close()
@@ -427,6 +527,7 @@ func (s *uploadSuite) SetupSuite() {
 		WithPublicKey(kf.PublicKey()),
 		WithLogger(zapadapter.NewKV(nil)),
 		WithCORSDomains([]string{"http://localhost:9090"}),
+		WithPresignedUploads(time.Hour),
 	)
 	r, err := l.BuildHandler()
 	s.Require().NoError(err)
@@ -480,6 +581,17 @@ func TestCORSWildcardOrigin(t *testing.T) {
 	}
 }
 
+func TestExtractPartialUploadIDs(t *testing.T) {
+	id1 := "2c5d1d8a1e0a4e6b8d0b6e1c4a8b9f7e+Mjk2NGZkZGQtN2E0Ni00MjNjLWJkMjEtNzQyODM0NmI4ZGE1"
+	id2 := "3c5d1d8a1e0a4e6b8d0b6e1c4a8b9f7e+Mjk2NGZkZGQtN2E0Ni00MjNjLWJkMjEtNzQyODM0NmI4ZGE2"
+	assert.Nil(t, extractPartialUploadIDs(""))
+	assert.Nil(t, extractPartialUploadIDs("partial"))
+	assert.Equal(t,
+		[]string{id1, id2},
+		extractPartialUploadIDs("final;/v1/uploads/"+id1+" https://uploads.odysee.com/v1/uploads/"+id2))
+	assert.Equal(t, []string{id1, ""}, extractPartialUploadIDs("final;/v1/uploads/"+id1+" /v1/uploads/short"))
+}
+
 func TestUploadSuite(t *testing.T) {
 	suite.Run(t, new(uploadSuite))
 }
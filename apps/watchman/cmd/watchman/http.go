@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman"
+	qoesvr "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/http/qoe/server"
 	reportersvr "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/http/reporter/server"
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
 	reporter "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -20,7 +22,7 @@ import (
 
 // handleHTTPServer starts configures and starts a HTTP server on the given
 // URL. It shuts down the server if any error is received in the error channel.
-func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *reporter.Endpoints, wg *sync.WaitGroup, errc chan error, logger *log.Logger, debug bool) {
+func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *reporter.Endpoints, qoeEndpoints *qoe.Endpoints, wg *sync.WaitGroup, errc chan error, logger *log.Logger, debug bool) {
 
 	// Setup goa log adapter.
 	var (
@@ -55,21 +57,25 @@ func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *repor
 	// responses.
 	var (
 		reporterServer *reportersvr.Server
+		qoeServer      *qoesvr.Server
 	)
 	{
 		eh := errorHandler(logger)
 		reporterServer = reportersvr.New(reporterEndpoints, mux, dec, enc, eh, nil)
 		reporterServer.Use(watchman.RemoteAddressMiddleware())
+		qoeServer = qoesvr.New(qoeEndpoints, mux, dec, enc, eh, nil)
 
 		if debug {
 			servers := goahttp.Servers{
 				reporterServer,
+				qoeServer,
 			}
 			servers.Use(httpmdlwr.Debug(mux, os.Stdout))
 		}
 	}
 	// Configure the mux.
 	reportersvr.Mount(mux, reporterServer)
+	qoesvr.Mount(mux, qoeServer)
 
 	// Wrap the multiplexer with additional middlewares. Middlewares mounted
 	// here apply to all the service endpoints.
@@ -86,6 +92,9 @@ func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *repor
 	for _, m := range reporterServer.Mounts {
 		logger.Printf("HTTP %q mounted on %s %s", m.Method, m.Verb, m.Pattern)
 	}
+	for _, m := range qoeServer.Mounts {
+		logger.Printf("HTTP %q mounted on %s %s", m.Method, m.Verb, m.Pattern)
+	}
 
 	(*wg).Add(1)
 	go func() {
@@ -12,11 +12,15 @@ import (
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/config"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/olapdb"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/privacy"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/rolling"
 
 	"github.com/alecthomas/kong"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var CLI struct {
@@ -44,44 +48,80 @@ func main() {
 	}
 	log.Configure(logCfg["level"], logCfg["encoding"])
 
-	dbCfg := cfg.GetStringMapString("clickhouse")
-	err = olapdb.Connect(dbCfg["url"], "watchman")
+	storage, err := olapdb.Open(cfg)
 	if err != nil {
 		log.Log.Fatal(err)
 	}
+	log.Log.Infof("using %s storage", storage.Name())
+	if c, ok := storage.(prometheus.Collector); ok {
+		prometheus.MustRegister(c)
+	}
 	err = olapdb.OpenGeoDB(cfg.GetString("geoipdb"))
 	if err != nil {
 		log.Log.Fatal(err)
 	}
+	if asnDB := cfg.GetString("geoipasndb"); asnDB != "" {
+		if err := olapdb.OpenASNDB(asnDB); err != nil {
+			log.Log.Fatal(err)
+		}
+	}
 
 	ctx := kong.Parse(&CLI)
 	switch ctx.Command() {
 	case "serve":
-		serve(CLI.Serve.Bind, CLI.Serve.Debug)
+		aggregator := rolling.NewAggregator(
+			rolling.WithWindow(cfg.GetDuration("qoe.window")),
+			rolling.WithMaxSeries(cfg.GetInt("qoe.maxseries")),
+		)
+		secret := cfg.GetString("privacy.useridsecret")
+		if secret == "" {
+			log.Log.Warn("no user ID secret configured, user ID hashes will change on every restart")
+		}
+		anonymizer := privacy.NewAnonymizer(
+			privacy.WithIPPrefixes(cfg.GetInt("privacy.ipv4prefix"), cfg.GetInt("privacy.ipv6prefix")),
+			privacy.WithUserIDSecret(secret),
+			privacy.WithRotation(cfg.GetDuration("privacy.useridrotation")),
+		)
+		serve(CLI.Serve.Bind, CLI.Serve.Debug, cfg.GetStringSlice("qoe.apikeys"), aggregator, anonymizer)
+		if err := storage.Close(); err != nil {
+			log.Log.Errorf("error closing storage: %v", err)
+		}
 	case "generate":
+		if storage.Name() != olapdb.StorageClickHouse {
+			log.Log.Fatalf("test data can only be generated in %s storage", olapdb.StorageClickHouse)
+		}
 		generate(CLI.Generate.Number, CLI.Generate.Days)
 	default:
 		log.Log.Fatal(ctx.Command())
 	}
 }
 
-func serve(bindF string, dbgF bool) {
+func serve(bindF string, dbgF bool, qoeKeys []string, aggregator *rolling.Aggregator, anonymizer *privacy.Anonymizer) {
 	// Initialize the services.
 	var (
 		reporterSvc reporter.Service
+		qoeSvc      qoe.Service
 	)
 	{
 		// TODO: provide DB connection as the first argument
-		reporterSvc = watchman.NewReporter(nil, log.Log)
+		reporterSvc = watchman.NewReporter(nil, log.Log, aggregator, anonymizer)
+		qoeSvc = watchman.NewQoE(qoeKeys, log.Log)
+	}
+	prometheus.MustRegister(aggregator)
+	log.Log.Infof("exporting QoE metrics over a %v window", aggregator.Window())
+	if len(qoeKeys) == 0 {
+		log.Log.Warn("no QoE API keys configured, aggregate endpoints will reject all requests")
 	}
 
 	// Wrap the services in endpoints that can be invoked from other services
 	// potentially running in different processes.
 	var (
 		reporterEndpoints *reporter.Endpoints
+		qoeEndpoints      *qoe.Endpoints
 	)
 	{
 		reporterEndpoints = reporter.NewEndpoints(reporterSvc)
+		qoeEndpoints = qoe.NewEndpoints(qoeSvc)
 	}
 
 	// Create channel used by both the signal handler and server goroutines
@@ -100,7 +140,7 @@ func serve(bindF string, dbgF bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Start the servers and send errors (if any) to the error channel.
-	handleHTTPServer(ctx, bindF, reporterEndpoints, &wg, errc, stdlog.New(io.Discard, "[watchman] ", stdlog.Ltime), dbgF)
+	handleHTTPServer(ctx, bindF, reporterEndpoints, qoeEndpoints, &wg, errc, stdlog.New(io.Discard, "[watchman] ", stdlog.Ltime), dbgF)
 
 	// Wait for signal.
 	log.Log.Infof("exiting (%v)", <-errc)
@@ -24,7 +24,7 @@ var _ = API("watchman", func() {
 	Server("watchman", func() {
 		Description("watchman hosts the Watchman service")
 
-		Services("reporter")
+		Services("reporter", "qoe")
 
 		Host("production", func() {
 			Description("Production host")
@@ -41,6 +41,11 @@ var _ = Service("reporter", func() {
 	Description("Media playback reports")
 
 	Error("multi_field_error", MultiFieldError) // Use custom error type
+	Error("unavailable", String, "Reports can't be accepted right now, retry later")
+
+	HTTP(func() {
+		Response("unavailable", StatusServiceUnavailable)
+	})
 
 	Method("add", func() {
 		Payload(PlaybackReport)
@@ -51,6 +56,56 @@ var _ = Service("reporter", func() {
 			Response(StatusCreated)
 		})
 	})
+	Method("startup", func() {
+		Description("Playback startup, sent once the first frame is rendered")
+		Payload(StartupEvent)
+		Result(Empty)
+		HTTP(func() {
+			POST("/reports/startup")
+			Response("multi_field_error", StatusBadRequest)
+			Response(StatusCreated)
+		})
+	})
+	Method("playback_error", func() {
+		Description("Player error, fatal ones stop the playback")
+		Payload(ErrorEvent)
+		Result(Empty)
+		HTTP(func() {
+			POST("/reports/error")
+			Response("multi_field_error", StatusBadRequest)
+			Response(StatusCreated)
+		})
+	})
+	Method("seek", func() {
+		Description("Seek to another stream position")
+		Payload(SeekEvent)
+		Result(Empty)
+		HTTP(func() {
+			POST("/reports/seek")
+			Response("multi_field_error", StatusBadRequest)
+			Response(StatusCreated)
+		})
+	})
+	Method("quality_switch", func() {
+		Description("Switch to another media rendition")
+		Payload(QualitySwitchEvent)
+		Result(Empty)
+		HTTP(func() {
+			POST("/reports/quality")
+			Response("multi_field_error", StatusBadRequest)
+			Response(StatusCreated)
+		})
+	})
+	Method("session_end", func() {
+		Description("End of a playback session")
+		Payload(SessionEndEvent)
+		Result(Empty)
+		HTTP(func() {
+			POST("/reports/end")
+			Response("multi_field_error", StatusBadRequest)
+			Response(StatusCreated)
+		})
+	})
 	Method("healthz", func() {
 		Result(String, func() {
 			Example("OK")
@@ -127,3 +182,108 @@ var PlaybackReport = Type("PlaybackReport", func() {
 		"url", "duration", "position", "rel_position", "rebuf_count", "rebuf_duration", "protocol",
 		"player", "user_id", "device")
 })
+
+// PlaybackEvent holds attributes common to all playback events, it is not accepted by itself.
+var PlaybackEvent = Type("PlaybackEvent", func() {
+	Attribute("url", String, "LBRY URL (lbry://... without the protocol part)", func() {
+		Example("@veritasium#f/driverless-cars-are-already-here#1")
+		MaxLength(512)
+	})
+	Attribute("protocol", String, "Video delivery protocol", func() {
+		Description("Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)")
+		Enum("stb", "hls", "lvs")
+	})
+	Attribute("player", String, "Player server name", func() {
+		Example("sg-p2")
+		MaxLength(128)
+	})
+	Attribute("user_id", String, "User ID", func() {
+		Example("432521")
+		MinLength(1)
+		MaxLength(45)
+	})
+	Attribute("device", String, "Client device", func() {
+		Enum("ios", "adr", "web", "dsk", "stb")
+	})
+
+	Required("url", "protocol", "player", "user_id", "device")
+})
+
+var StartupEvent = Type("StartupEvent", func() {
+	Extend(PlaybackEvent)
+	Attribute("ttff", Int32, "Time to first frame since the playback was requested, ms", func() {
+		Example(1200)
+		Minimum(0)
+		Maximum(600000)
+	})
+	Attribute("bitrate", Int32, "Media bitrate of the initial rendition, bit/s", func() {
+		Minimum(0)
+	})
+
+	Required("ttff")
+})
+
+var ErrorEvent = Type("ErrorEvent", func() {
+	Extend(PlaybackEvent)
+	Attribute("position", Int32, "Stream position the error happened at, ms", func() {
+		Minimum(0)
+	})
+	Attribute("code", String, "Player error code", func() {
+		Example("MEDIA_ERR_NETWORK")
+		MinLength(1)
+		MaxLength(64)
+	})
+	Attribute("message", String, "Error message", func() {
+		MaxLength(1024)
+	})
+	Attribute("fatal", Boolean, "Whether the error stopped the playback", func() {
+		Default(false)
+	})
+
+	Required("position", "code")
+})
+
+var SeekEvent = Type("SeekEvent", func() {
+	Extend(PlaybackEvent)
+	Attribute("from", Int32, "Stream position before the seek, ms", func() {
+		Minimum(0)
+	})
+	Attribute("to", Int32, "Stream position after the seek, ms", func() {
+		Minimum(0)
+	})
+
+	Required("from", "to")
+})
+
+var QualitySwitchEvent = Type("QualitySwitchEvent", func() {
+	Extend(PlaybackEvent)
+	Attribute("position", Int32, "Stream position the switch happened at, ms", func() {
+		Minimum(0)
+	})
+	Attribute("from_bitrate", Int32, "Media bitrate before the switch, bit/s", func() {
+		Minimum(0)
+	})
+	Attribute("to_bitrate", Int32, "Media bitrate after the switch, bit/s", func() {
+		Minimum(0)
+	})
+	Attribute("reason", String, "Whether the switch was made by adaptive bitrate logic or by the user", func() {
+		Enum("auto", "manual")
+	})
+
+	Required("position", "from_bitrate", "to_bitrate", "reason")
+})
+
+var SessionEndEvent = Type("SessionEndEvent", func() {
+	Extend(PlaybackEvent)
+	Attribute("position", Int32, "Stream position at the end of the session, ms", func() {
+		Minimum(0)
+	})
+	Attribute("watched", Int32, "Total time spent playing during the session, ms", func() {
+		Minimum(0)
+	})
+	Attribute("reason", String, "Why the session ended", func() {
+		Enum("completed", "closed", "navigated", "error")
+	})
+
+	Required("position", "watched", "reason")
+})
@@ -0,0 +1,176 @@
+package design
+
+import (
+	. "goa.design/goa/v3/dsl"
+)
+
+var APIKeyAuth = APIKeySecurity("api_key", func() {
+	Description("Key for reading aggregated playback data, see QoE.APIKeys in watchman.yaml")
+})
+
+var _ = Service("qoe", func() {
+	Description(`Quality of experience aggregates over playback reports.
+		Reports are grouped by any combination of player, area, device, protocol and time bucket,
+		groups are ordered by their key and returned in pages.`)
+
+	Security(APIKeyAuth)
+
+	Error("multi_field_error", MultiFieldError)
+	Error("unauthorized", String, "API key is missing or invalid")
+	Error("not_implemented", String, "Configured storage doesn't support aggregate reads")
+
+	HTTP(func() {
+		Path("/qoe")
+		Response("multi_field_error", StatusBadRequest)
+		Response("unauthorized", StatusUnauthorized)
+		Response("not_implemented", StatusNotImplemented)
+	})
+
+	Method("rebuffering", func() {
+		Description("Rebuffering events and the share of playback time spent rebuffering")
+		Payload(AggregateQuery)
+		Result(RebufferingPage)
+		HTTP(func() {
+			GET("/rebuffering")
+			aggregateQueryParams()
+			Response(StatusOK)
+		})
+	})
+	Method("bitrate", func() {
+		Description("Media bitrate and client bandwidth")
+		Payload(AggregateQuery)
+		Result(BitratePage)
+		HTTP(func() {
+			GET("/bitrate")
+			aggregateQueryParams()
+			Response(StatusOK)
+		})
+	})
+	Method("startup", func() {
+		Description("Playback starts, counted from reports covering the beginning of a stream, and rebuffering during them")
+		Payload(AggregateQuery)
+		Result(StartupPage)
+		HTTP(func() {
+			GET("/startup")
+			aggregateQueryParams()
+			Response(StatusOK)
+		})
+	})
+})
+
+func aggregateQueryParams() {
+	Header("key:X-Api-Key")
+	Param("group_by")
+	Param("bucket")
+	Param("from")
+	Param("to")
+	Param("player")
+	Param("area")
+	Param("device")
+	Param("protocol")
+	Param("page")
+	Param("page_size")
+}
+
+var AggregateQuery = Type("AggregateQuery", func() {
+	APIKey("api_key", "key", String, "API key")
+
+	Attribute("group_by", ArrayOf(String, func() {
+		Enum("player", "area", "device", "protocol", "time")
+	}), "Fields to group reports by, all reports in the time range are aggregated together when empty", func() {
+		Example([]string{"player", "time"})
+	})
+	Attribute("bucket", String, "Time bucket size used when grouping by time", func() {
+		Enum("minute", "hour", "day")
+		Default("hour")
+	})
+	Attribute("from", String, "Start of the time range, 24 hours before its end by default", func() {
+		Format(FormatDateTime)
+	})
+	Attribute("to", String, "End of the time range, now by default", func() {
+		Format(FormatDateTime)
+	})
+	Attribute("player", String, "Only include reports from this player server", func() {
+		MaxLength(128)
+	})
+	Attribute("area", String, "Only include reports from this area (country code)", func() {
+		Example("US")
+		MaxLength(2)
+	})
+	Attribute("device", String, "Only include reports from this client device", func() {
+		Enum("ios", "adr", "web", "dsk", "stb")
+	})
+	Attribute("protocol", String, "Only include reports for this delivery protocol", func() {
+		Enum("stb", "hls", "lvs")
+	})
+	Attribute("page", Int, "Page number", func() {
+		Minimum(1)
+		Default(1)
+	})
+	Attribute("page_size", Int, "Number of groups per page", func() {
+		Minimum(1)
+		Maximum(1000)
+		Default(100)
+	})
+
+	Required("key")
+})
+
+var AggregateGroup = Type("AggregateGroup", func() {
+	Description("Values of the fields reports are grouped by, only requested fields are set")
+	Attribute("player", String)
+	Attribute("area", String)
+	Attribute("device", String)
+	Attribute("protocol", String)
+	Attribute("time", String, "Start of the time bucket", func() {
+		Format(FormatDateTime)
+	})
+	Attribute("reports", UInt64, "Number of playback reports in the group")
+	Required("reports")
+})
+
+var RebufferingStats = Type("RebufferingStats", func() {
+	Extend(AggregateGroup)
+	Attribute("playback_ms", UInt64, "Total reported playback time")
+	Attribute("rebuf_count", UInt64, "Total rebuffering events")
+	Attribute("rebuf_ms", UInt64, "Total rebuffering time")
+	Attribute("rebuf_ratio", Float64, "Share of playback time spent rebuffering, 0—1")
+	Required("playback_ms", "rebuf_count", "rebuf_ms", "rebuf_ratio")
+})
+
+var BitrateStats = Type("BitrateStats", func() {
+	Extend(AggregateGroup)
+	Attribute("avg_bitrate", Float64, "Average media bitrate, bit/s")
+	Attribute("median_bitrate", Float64, "Median media bitrate, bit/s")
+	Attribute("avg_bandwidth", Float64, "Average client bandwidth, bit/s")
+	Required("avg_bitrate", "median_bitrate", "avg_bandwidth")
+})
+
+var StartupStats = Type("StartupStats", func() {
+	Extend(AggregateGroup)
+	Attribute("starts", UInt64, "Reports covering the first reporting window of a playback")
+	Attribute("rebuffered_starts", UInt64, "Starts during which rebuffering happened")
+	Attribute("rebuffered_ratio", Float64, "Share of starts with rebuffering, 0—1")
+	Attribute("avg_rebuf_ms", Float64, "Average rebuffering time during a start")
+	Required("starts", "rebuffered_starts", "rebuffered_ratio", "avg_rebuf_ms")
+})
+
+var pageAttributes = func(item any) {
+	Attribute("items", ArrayOf(item))
+	Attribute("page", Int)
+	Attribute("page_size", Int)
+	Attribute("has_more", Boolean, "True when there are more groups on the following pages")
+	Required("items", "page", "page_size", "has_more")
+}
+
+var RebufferingPage = Type("RebufferingPage", func() {
+	pageAttributes(RebufferingStats)
+})
+
+var BitratePage = Type("BitratePage", func() {
+	pageAttributes(BitrateStats)
+})
+
+var StartupPage = Type("StartupPage", func() {
+	pageAttributes(StartupStats)
+})
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 
+	qoec "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/http/qoe/client"
 	reporterc "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/http/reporter/client"
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
@@ -24,13 +25,15 @@ import (
 //	command (subcommand1|subcommand2|...)
 func UsageCommands() []string {
 	return []string{
-		"reporter (add|healthz)",
+		"reporter (add|startup|playback-error|seek|quality-switch|session-end|healthz)",
+		"qoe (rebuffering|bitrate|startup)",
 	}
 }
 
 // UsageExamples produces an example of a valid invocation of the CLI tool.
 func UsageExamples() string {
-	return os.Args[0] + " " + "reporter add --body '{\n      \"bandwidth\": 64944106,\n      \"bitrate\": 13952061,\n      \"cache\": \"miss\",\n      \"device\": \"ios\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 1045058586,\n      \"protocol\": \"lvs\",\n      \"rebuf_count\": 2095695930,\n      \"rebuf_duration\": 38439,\n      \"rel_position\": 13,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'" + "\n" +
+	return os.Args[0] + " " + "reporter add --body '{\n      \"bandwidth\": 916523488,\n      \"bitrate\": 1993692080,\n      \"cache\": \"miss\",\n      \"device\": \"stb\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 671190817,\n      \"protocol\": \"hls\",\n      \"rebuf_count\": 469799269,\n      \"rebuf_duration\": 34743,\n      \"rel_position\": 94,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'" + "\n" +
+		os.Args[0] + " " + "qoe rebuffering --group-by '[\n      \"player\",\n      \"time\"\n   ]' --bucket \"minute\" --from \"1992-06-20T09:17:40Z\" --to \"1975-06-30T08:12:48Z\" --player \"rdm\" --area \"US\" --device \"web\" --protocol \"stb\" --page 4948674265565454327 --page-size 349 --key \"Rerum facere temporibus maxime.\"" + "\n" +
 		""
 }
 
@@ -49,12 +52,78 @@ func ParseEndpoint(
 		reporterAddFlags    = flag.NewFlagSet("add", flag.ExitOnError)
 		reporterAddBodyFlag = reporterAddFlags.String("body", "REQUIRED", "")
 
+		reporterStartupFlags    = flag.NewFlagSet("startup", flag.ExitOnError)
+		reporterStartupBodyFlag = reporterStartupFlags.String("body", "REQUIRED", "")
+
+		reporterPlaybackErrorFlags    = flag.NewFlagSet("playback-error", flag.ExitOnError)
+		reporterPlaybackErrorBodyFlag = reporterPlaybackErrorFlags.String("body", "REQUIRED", "")
+
+		reporterSeekFlags    = flag.NewFlagSet("seek", flag.ExitOnError)
+		reporterSeekBodyFlag = reporterSeekFlags.String("body", "REQUIRED", "")
+
+		reporterQualitySwitchFlags    = flag.NewFlagSet("quality-switch", flag.ExitOnError)
+		reporterQualitySwitchBodyFlag = reporterQualitySwitchFlags.String("body", "REQUIRED", "")
+
+		reporterSessionEndFlags    = flag.NewFlagSet("session-end", flag.ExitOnError)
+		reporterSessionEndBodyFlag = reporterSessionEndFlags.String("body", "REQUIRED", "")
+
 		reporterHealthzFlags = flag.NewFlagSet("healthz", flag.ExitOnError)
+
+		qoeFlags = flag.NewFlagSet("qoe", flag.ContinueOnError)
+
+		qoeRebufferingFlags        = flag.NewFlagSet("rebuffering", flag.ExitOnError)
+		qoeRebufferingGroupByFlag  = qoeRebufferingFlags.String("group-by", "", "")
+		qoeRebufferingBucketFlag   = qoeRebufferingFlags.String("bucket", "hour", "")
+		qoeRebufferingFromFlag     = qoeRebufferingFlags.String("from", "", "")
+		qoeRebufferingToFlag       = qoeRebufferingFlags.String("to", "", "")
+		qoeRebufferingPlayerFlag   = qoeRebufferingFlags.String("player", "", "")
+		qoeRebufferingAreaFlag     = qoeRebufferingFlags.String("area", "", "")
+		qoeRebufferingDeviceFlag   = qoeRebufferingFlags.String("device", "", "")
+		qoeRebufferingProtocolFlag = qoeRebufferingFlags.String("protocol", "", "")
+		qoeRebufferingPageFlag     = qoeRebufferingFlags.String("page", "1", "")
+		qoeRebufferingPageSizeFlag = qoeRebufferingFlags.String("page-size", "100", "")
+		qoeRebufferingKeyFlag      = qoeRebufferingFlags.String("key", "REQUIRED", "")
+
+		qoeBitrateFlags        = flag.NewFlagSet("bitrate", flag.ExitOnError)
+		qoeBitrateGroupByFlag  = qoeBitrateFlags.String("group-by", "", "")
+		qoeBitrateBucketFlag   = qoeBitrateFlags.String("bucket", "hour", "")
+		qoeBitrateFromFlag     = qoeBitrateFlags.String("from", "", "")
+		qoeBitrateToFlag       = qoeBitrateFlags.String("to", "", "")
+		qoeBitratePlayerFlag   = qoeBitrateFlags.String("player", "", "")
+		qoeBitrateAreaFlag     = qoeBitrateFlags.String("area", "", "")
+		qoeBitrateDeviceFlag   = qoeBitrateFlags.String("device", "", "")
+		qoeBitrateProtocolFlag = qoeBitrateFlags.String("protocol", "", "")
+		qoeBitratePageFlag     = qoeBitrateFlags.String("page", "1", "")
+		qoeBitratePageSizeFlag = qoeBitrateFlags.String("page-size", "100", "")
+		qoeBitrateKeyFlag      = qoeBitrateFlags.String("key", "REQUIRED", "")
+
+		qoeStartupFlags        = flag.NewFlagSet("startup", flag.ExitOnError)
+		qoeStartupGroupByFlag  = qoeStartupFlags.String("group-by", "", "")
+		qoeStartupBucketFlag   = qoeStartupFlags.String("bucket", "hour", "")
+		qoeStartupFromFlag     = qoeStartupFlags.String("from", "", "")
+		qoeStartupToFlag       = qoeStartupFlags.String("to", "", "")
+		qoeStartupPlayerFlag   = qoeStartupFlags.String("player", "", "")
+		qoeStartupAreaFlag     = qoeStartupFlags.String("area", "", "")
+		qoeStartupDeviceFlag   = qoeStartupFlags.String("device", "", "")
+		qoeStartupProtocolFlag = qoeStartupFlags.String("protocol", "", "")
+		qoeStartupPageFlag     = qoeStartupFlags.String("page", "1", "")
+		qoeStartupPageSizeFlag = qoeStartupFlags.String("page-size", "100", "")
+		qoeStartupKeyFlag      = qoeStartupFlags.String("key", "REQUIRED", "")
 	)
 	reporterFlags.Usage = reporterUsage
 	reporterAddFlags.Usage = reporterAddUsage
+	reporterStartupFlags.Usage = reporterStartupUsage
+	reporterPlaybackErrorFlags.Usage = reporterPlaybackErrorUsage
+	reporterSeekFlags.Usage = reporterSeekUsage
+	reporterQualitySwitchFlags.Usage = reporterQualitySwitchUsage
+	reporterSessionEndFlags.Usage = reporterSessionEndUsage
 	reporterHealthzFlags.Usage = reporterHealthzUsage
 
+	qoeFlags.Usage = qoeUsage
+	qoeRebufferingFlags.Usage = qoeRebufferingUsage
+	qoeBitrateFlags.Usage = qoeBitrateUsage
+	qoeStartupFlags.Usage = qoeStartupUsage
+
 	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
 		return nil, nil, err
 	}
@@ -72,6 +141,8 @@ func ParseEndpoint(
 		switch svcn {
 		case "reporter":
 			svcf = reporterFlags
+		case "qoe":
+			svcf = qoeFlags
 		default:
 			return nil, nil, fmt.Errorf("unknown service %q", svcn)
 		}
@@ -92,11 +163,39 @@ func ParseEndpoint(
 			case "add":
 				epf = reporterAddFlags
 
+			case "startup":
+				epf = reporterStartupFlags
+
+			case "playback-error":
+				epf = reporterPlaybackErrorFlags
+
+			case "seek":
+				epf = reporterSeekFlags
+
+			case "quality-switch":
+				epf = reporterQualitySwitchFlags
+
+			case "session-end":
+				epf = reporterSessionEndFlags
+
 			case "healthz":
 				epf = reporterHealthzFlags
 
 			}
 
+		case "qoe":
+			switch epn {
+			case "rebuffering":
+				epf = qoeRebufferingFlags
+
+			case "bitrate":
+				epf = qoeBitrateFlags
+
+			case "startup":
+				epf = qoeStartupFlags
+
+			}
+
 		}
 	}
 	if epf == nil {
@@ -123,9 +222,37 @@ func ParseEndpoint(
 			case "add":
 				endpoint = c.Add()
 				data, err = reporterc.BuildAddPayload(*reporterAddBodyFlag)
+			case "startup":
+				endpoint = c.Startup()
+				data, err = reporterc.BuildStartupPayload(*reporterStartupBodyFlag)
+			case "playback-error":
+				endpoint = c.PlaybackError()
+				data, err = reporterc.BuildPlaybackErrorPayload(*reporterPlaybackErrorBodyFlag)
+			case "seek":
+				endpoint = c.Seek()
+				data, err = reporterc.BuildSeekPayload(*reporterSeekBodyFlag)
+			case "quality-switch":
+				endpoint = c.QualitySwitch()
+				data, err = reporterc.BuildQualitySwitchPayload(*reporterQualitySwitchBodyFlag)
+			case "session-end":
+				endpoint = c.SessionEnd()
+				data, err = reporterc.BuildSessionEndPayload(*reporterSessionEndBodyFlag)
 			case "healthz":
 				endpoint = c.Healthz()
 			}
+		case "qoe":
+			c := qoec.NewClient(scheme, host, doer, enc, dec, restore)
+			switch epn {
+			case "rebuffering":
+				endpoint = c.Rebuffering()
+				data, err = qoec.BuildRebufferingPayload(*qoeRebufferingGroupByFlag, *qoeRebufferingBucketFlag, *qoeRebufferingFromFlag, *qoeRebufferingToFlag, *qoeRebufferingPlayerFlag, *qoeRebufferingAreaFlag, *qoeRebufferingDeviceFlag, *qoeRebufferingProtocolFlag, *qoeRebufferingPageFlag, *qoeRebufferingPageSizeFlag, *qoeRebufferingKeyFlag)
+			case "bitrate":
+				endpoint = c.Bitrate()
+				data, err = qoec.BuildBitratePayload(*qoeBitrateGroupByFlag, *qoeBitrateBucketFlag, *qoeBitrateFromFlag, *qoeBitrateToFlag, *qoeBitratePlayerFlag, *qoeBitrateAreaFlag, *qoeBitrateDeviceFlag, *qoeBitrateProtocolFlag, *qoeBitratePageFlag, *qoeBitratePageSizeFlag, *qoeBitrateKeyFlag)
+			case "startup":
+				endpoint = c.Startup()
+				data, err = qoec.BuildStartupPayload(*qoeStartupGroupByFlag, *qoeStartupBucketFlag, *qoeStartupFromFlag, *qoeStartupToFlag, *qoeStartupPlayerFlag, *qoeStartupAreaFlag, *qoeStartupDeviceFlag, *qoeStartupProtocolFlag, *qoeStartupPageFlag, *qoeStartupPageSizeFlag, *qoeStartupKeyFlag)
+			}
 		}
 	}
 	if err != nil {
@@ -141,6 +268,11 @@ func reporterUsage() {
 	fmt.Fprintf(os.Stderr, "Usage:\n    %s [globalflags] reporter COMMAND [flags]\n\n", os.Args[0])
 	fmt.Fprintln(os.Stderr, "COMMAND:")
 	fmt.Fprintln(os.Stderr, `    add: Add implements add.`)
+	fmt.Fprintln(os.Stderr, `    startup: Playback startup, sent once the first frame is rendered`)
+	fmt.Fprintln(os.Stderr, `    playback-error: Player error, fatal ones stop the playback`)
+	fmt.Fprintln(os.Stderr, `    seek: Seek to another stream position`)
+	fmt.Fprintln(os.Stderr, `    quality-switch: Switch to another media rendition`)
+	fmt.Fprintln(os.Stderr, `    session-end: End of a playback session`)
 	fmt.Fprintln(os.Stderr, `    healthz: Healthz implements healthz.`)
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "Additional help:")
@@ -161,7 +293,97 @@ func reporterAddUsage() {
 
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "Example:")
-	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter add --body '{\n      \"bandwidth\": 64944106,\n      \"bitrate\": 13952061,\n      \"cache\": \"miss\",\n      \"device\": \"ios\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 1045058586,\n      \"protocol\": \"lvs\",\n      \"rebuf_count\": 2095695930,\n      \"rebuf_duration\": 38439,\n      \"rel_position\": 13,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter add --body '{\n      \"bandwidth\": 916523488,\n      \"bitrate\": 1993692080,\n      \"cache\": \"miss\",\n      \"device\": \"stb\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 671190817,\n      \"protocol\": \"hls\",\n      \"rebuf_count\": 469799269,\n      \"rebuf_duration\": 34743,\n      \"rel_position\": 94,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+}
+
+func reporterStartupUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] reporter startup", os.Args[0])
+	fmt.Fprint(os.Stderr, " -body JSON")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Playback startup, sent once the first frame is rendered`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -body JSON: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter startup --body '{\n      \"bitrate\": 547967682,\n      \"device\": \"ios\",\n      \"player\": \"sg-p2\",\n      \"protocol\": \"stb\",\n      \"ttff\": 1200,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+}
+
+func reporterPlaybackErrorUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] reporter playback-error", os.Args[0])
+	fmt.Fprint(os.Stderr, " -body JSON")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Player error, fatal ones stop the playback`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -body JSON: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter playback-error --body '{\n      \"code\": \"MEDIA_ERR_NETWORK\",\n      \"device\": \"dsk\",\n      \"fatal\": true,\n      \"message\": \"p25\",\n      \"player\": \"sg-p2\",\n      \"position\": 689136710,\n      \"protocol\": \"lvs\",\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+}
+
+func reporterSeekUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] reporter seek", os.Args[0])
+	fmt.Fprint(os.Stderr, " -body JSON")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Seek to another stream position`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -body JSON: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter seek --body '{\n      \"device\": \"stb\",\n      \"from\": 776748396,\n      \"player\": \"sg-p2\",\n      \"protocol\": \"hls\",\n      \"to\": 668987034,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+}
+
+func reporterQualitySwitchUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] reporter quality-switch", os.Args[0])
+	fmt.Fprint(os.Stderr, " -body JSON")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Switch to another media rendition`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -body JSON: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter quality-switch --body '{\n      \"device\": \"web\",\n      \"from_bitrate\": 1631807523,\n      \"player\": \"sg-p2\",\n      \"position\": 1896972294,\n      \"protocol\": \"lvs\",\n      \"reason\": \"manual\",\n      \"to_bitrate\": 1881035567,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+}
+
+func reporterSessionEndUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] reporter session-end", os.Args[0])
+	fmt.Fprint(os.Stderr, " -body JSON")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `End of a playback session`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -body JSON: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter session-end --body '{\n      \"device\": \"dsk\",\n      \"player\": \"sg-p2\",\n      \"position\": 1762124359,\n      \"protocol\": \"lvs\",\n      \"reason\": \"completed\",\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\",\n      \"watched\": 1164577066\n   }'")
 }
 
 func reporterHealthzUsage() {
@@ -179,3 +401,131 @@ func reporterHealthzUsage() {
 	fmt.Fprintln(os.Stderr, "Example:")
 	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "reporter healthz")
 }
+
+// qoeUsage displays the usage of the qoe command and its subcommands.
+func qoeUsage() {
+	fmt.Fprintln(os.Stderr, `Quality of experience aggregates over playback reports.
+			Reports are grouped by any combination of player, area, device, protocol and time bucket,
+			groups are ordered by their key and returned in pages.`)
+	fmt.Fprintf(os.Stderr, "Usage:\n    %s [globalflags] qoe COMMAND [flags]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "COMMAND:")
+	fmt.Fprintln(os.Stderr, `    rebuffering: Rebuffering events and the share of playback time spent rebuffering`)
+	fmt.Fprintln(os.Stderr, `    bitrate: Media bitrate and client bandwidth`)
+	fmt.Fprintln(os.Stderr, `    startup: Playback starts, counted from reports covering the beginning of a stream, and rebuffering during them`)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Additional help:")
+	fmt.Fprintf(os.Stderr, "    %s qoe COMMAND --help\n", os.Args[0])
+}
+func qoeRebufferingUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] qoe rebuffering", os.Args[0])
+	fmt.Fprint(os.Stderr, " -group-by JSON")
+	fmt.Fprint(os.Stderr, " -bucket STRING")
+	fmt.Fprint(os.Stderr, " -from STRING")
+	fmt.Fprint(os.Stderr, " -to STRING")
+	fmt.Fprint(os.Stderr, " -player STRING")
+	fmt.Fprint(os.Stderr, " -area STRING")
+	fmt.Fprint(os.Stderr, " -device STRING")
+	fmt.Fprint(os.Stderr, " -protocol STRING")
+	fmt.Fprint(os.Stderr, " -page INT")
+	fmt.Fprint(os.Stderr, " -page-size INT")
+	fmt.Fprint(os.Stderr, " -key STRING")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Rebuffering events and the share of playback time spent rebuffering`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -group-by JSON: `)
+	fmt.Fprintln(os.Stderr, `    -bucket STRING: `)
+	fmt.Fprintln(os.Stderr, `    -from STRING: `)
+	fmt.Fprintln(os.Stderr, `    -to STRING: `)
+	fmt.Fprintln(os.Stderr, `    -player STRING: `)
+	fmt.Fprintln(os.Stderr, `    -area STRING: `)
+	fmt.Fprintln(os.Stderr, `    -device STRING: `)
+	fmt.Fprintln(os.Stderr, `    -protocol STRING: `)
+	fmt.Fprintln(os.Stderr, `    -page INT: `)
+	fmt.Fprintln(os.Stderr, `    -page-size INT: `)
+	fmt.Fprintln(os.Stderr, `    -key STRING: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "qoe rebuffering --group-by '[\n      \"player\",\n      \"time\"\n   ]' --bucket \"minute\" --from \"1992-06-20T09:17:40Z\" --to \"1975-06-30T08:12:48Z\" --player \"rdm\" --area \"US\" --device \"web\" --protocol \"stb\" --page 4948674265565454327 --page-size 349 --key \"Rerum facere temporibus maxime.\"")
+}
+
+func qoeBitrateUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] qoe bitrate", os.Args[0])
+	fmt.Fprint(os.Stderr, " -group-by JSON")
+	fmt.Fprint(os.Stderr, " -bucket STRING")
+	fmt.Fprint(os.Stderr, " -from STRING")
+	fmt.Fprint(os.Stderr, " -to STRING")
+	fmt.Fprint(os.Stderr, " -player STRING")
+	fmt.Fprint(os.Stderr, " -area STRING")
+	fmt.Fprint(os.Stderr, " -device STRING")
+	fmt.Fprint(os.Stderr, " -protocol STRING")
+	fmt.Fprint(os.Stderr, " -page INT")
+	fmt.Fprint(os.Stderr, " -page-size INT")
+	fmt.Fprint(os.Stderr, " -key STRING")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Media bitrate and client bandwidth`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -group-by JSON: `)
+	fmt.Fprintln(os.Stderr, `    -bucket STRING: `)
+	fmt.Fprintln(os.Stderr, `    -from STRING: `)
+	fmt.Fprintln(os.Stderr, `    -to STRING: `)
+	fmt.Fprintln(os.Stderr, `    -player STRING: `)
+	fmt.Fprintln(os.Stderr, `    -area STRING: `)
+	fmt.Fprintln(os.Stderr, `    -device STRING: `)
+	fmt.Fprintln(os.Stderr, `    -protocol STRING: `)
+	fmt.Fprintln(os.Stderr, `    -page INT: `)
+	fmt.Fprintln(os.Stderr, `    -page-size INT: `)
+	fmt.Fprintln(os.Stderr, `    -key STRING: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "qoe bitrate --group-by '[\n      \"player\",\n      \"time\"\n   ]' --bucket \"hour\" --from \"2003-07-11T12:56:07Z\" --to \"2004-11-30T21:30:21Z\" --player \"nqt\" --area \"US\" --device \"adr\" --protocol \"stb\" --page 8742533217492586420 --page-size 29 --key \"Nihil veniam vel nemo reiciendis pariatur.\"")
+}
+
+func qoeStartupUsage() {
+	// Header with flags
+	fmt.Fprintf(os.Stderr, "%s [flags] qoe startup", os.Args[0])
+	fmt.Fprint(os.Stderr, " -group-by JSON")
+	fmt.Fprint(os.Stderr, " -bucket STRING")
+	fmt.Fprint(os.Stderr, " -from STRING")
+	fmt.Fprint(os.Stderr, " -to STRING")
+	fmt.Fprint(os.Stderr, " -player STRING")
+	fmt.Fprint(os.Stderr, " -area STRING")
+	fmt.Fprint(os.Stderr, " -device STRING")
+	fmt.Fprint(os.Stderr, " -protocol STRING")
+	fmt.Fprint(os.Stderr, " -page INT")
+	fmt.Fprint(os.Stderr, " -page-size INT")
+	fmt.Fprint(os.Stderr, " -key STRING")
+	fmt.Fprintln(os.Stderr)
+
+	// Description
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Playback starts, counted from reports covering the beginning of a stream, and rebuffering during them`)
+
+	// Flags list
+	fmt.Fprintln(os.Stderr, `    -group-by JSON: `)
+	fmt.Fprintln(os.Stderr, `    -bucket STRING: `)
+	fmt.Fprintln(os.Stderr, `    -from STRING: `)
+	fmt.Fprintln(os.Stderr, `    -to STRING: `)
+	fmt.Fprintln(os.Stderr, `    -player STRING: `)
+	fmt.Fprintln(os.Stderr, `    -area STRING: `)
+	fmt.Fprintln(os.Stderr, `    -device STRING: `)
+	fmt.Fprintln(os.Stderr, `    -protocol STRING: `)
+	fmt.Fprintln(os.Stderr, `    -page INT: `)
+	fmt.Fprintln(os.Stderr, `    -page-size INT: `)
+	fmt.Fprintln(os.Stderr, `    -key STRING: `)
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Example:")
+	fmt.Fprintf(os.Stderr, "    %s %s\n", os.Args[0], "qoe startup --group-by '[\n      \"player\",\n      \"time\"\n   ]' --bucket \"minute\" --from \"1971-03-04T07:25:59Z\" --to \"2002-08-06T07:41:24Z\" --player \"giq\" --area \"US\" --device \"dsk\" --protocol \"stb\" --page 3238000460001352303 --page-size 114 --key \"Aut nostrum.\"")
+}
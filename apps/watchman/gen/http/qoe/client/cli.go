@@ -0,0 +1,493 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP client CLI support package
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// BuildRebufferingPayload builds the payload for the qoe rebuffering endpoint
+// from CLI flags.
+func BuildRebufferingPayload(qoeRebufferingGroupBy string, qoeRebufferingBucket string, qoeRebufferingFrom string, qoeRebufferingTo string, qoeRebufferingPlayer string, qoeRebufferingArea string, qoeRebufferingDevice string, qoeRebufferingProtocol string, qoeRebufferingPage string, qoeRebufferingPageSize string, qoeRebufferingKey string) (*qoe.AggregateQuery, error) {
+	var err error
+	var groupBy []string
+	{
+		if qoeRebufferingGroupBy != "" {
+			err = json.Unmarshal([]byte(qoeRebufferingGroupBy), &groupBy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON for groupBy, \nerror: %s, \nexample of valid JSON:\n%s", err, "'[\n      \"player\",\n      \"time\"\n   ]'")
+			}
+			for _, e := range groupBy {
+				if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+					err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var bucket string
+	{
+		if qoeRebufferingBucket != "" {
+			bucket = qoeRebufferingBucket
+			if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var from *string
+	{
+		if qoeRebufferingFrom != "" {
+			from = &qoeRebufferingFrom
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var to *string
+	{
+		if qoeRebufferingTo != "" {
+			to = &qoeRebufferingTo
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var player *string
+	{
+		if qoeRebufferingPlayer != "" {
+			player = &qoeRebufferingPlayer
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var area *string
+	{
+		if qoeRebufferingArea != "" {
+			area = &qoeRebufferingArea
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var device *string
+	{
+		if qoeRebufferingDevice != "" {
+			device = &qoeRebufferingDevice
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var protocol *string
+	{
+		if qoeRebufferingProtocol != "" {
+			protocol = &qoeRebufferingProtocol
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var page int
+	{
+		if qoeRebufferingPage != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeRebufferingPage, 10, strconv.IntSize)
+			page = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for page, must be INT")
+			}
+			if page < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var pageSize int
+	{
+		if qoeRebufferingPageSize != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeRebufferingPageSize, 10, strconv.IntSize)
+			pageSize = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for pageSize, must be INT")
+			}
+			if pageSize < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+			}
+			if pageSize > 1000 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var key string
+	{
+		key = qoeRebufferingKey
+	}
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v, nil
+}
+
+// BuildBitratePayload builds the payload for the qoe bitrate endpoint from CLI
+// flags.
+func BuildBitratePayload(qoeBitrateGroupBy string, qoeBitrateBucket string, qoeBitrateFrom string, qoeBitrateTo string, qoeBitratePlayer string, qoeBitrateArea string, qoeBitrateDevice string, qoeBitrateProtocol string, qoeBitratePage string, qoeBitratePageSize string, qoeBitrateKey string) (*qoe.AggregateQuery, error) {
+	var err error
+	var groupBy []string
+	{
+		if qoeBitrateGroupBy != "" {
+			err = json.Unmarshal([]byte(qoeBitrateGroupBy), &groupBy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON for groupBy, \nerror: %s, \nexample of valid JSON:\n%s", err, "'[\n      \"player\",\n      \"time\"\n   ]'")
+			}
+			for _, e := range groupBy {
+				if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+					err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var bucket string
+	{
+		if qoeBitrateBucket != "" {
+			bucket = qoeBitrateBucket
+			if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var from *string
+	{
+		if qoeBitrateFrom != "" {
+			from = &qoeBitrateFrom
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var to *string
+	{
+		if qoeBitrateTo != "" {
+			to = &qoeBitrateTo
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var player *string
+	{
+		if qoeBitratePlayer != "" {
+			player = &qoeBitratePlayer
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var area *string
+	{
+		if qoeBitrateArea != "" {
+			area = &qoeBitrateArea
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var device *string
+	{
+		if qoeBitrateDevice != "" {
+			device = &qoeBitrateDevice
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var protocol *string
+	{
+		if qoeBitrateProtocol != "" {
+			protocol = &qoeBitrateProtocol
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var page int
+	{
+		if qoeBitratePage != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeBitratePage, 10, strconv.IntSize)
+			page = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for page, must be INT")
+			}
+			if page < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var pageSize int
+	{
+		if qoeBitratePageSize != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeBitratePageSize, 10, strconv.IntSize)
+			pageSize = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for pageSize, must be INT")
+			}
+			if pageSize < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+			}
+			if pageSize > 1000 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var key string
+	{
+		key = qoeBitrateKey
+	}
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v, nil
+}
+
+// BuildStartupPayload builds the payload for the qoe startup endpoint from CLI
+// flags.
+func BuildStartupPayload(qoeStartupGroupBy string, qoeStartupBucket string, qoeStartupFrom string, qoeStartupTo string, qoeStartupPlayer string, qoeStartupArea string, qoeStartupDevice string, qoeStartupProtocol string, qoeStartupPage string, qoeStartupPageSize string, qoeStartupKey string) (*qoe.AggregateQuery, error) {
+	var err error
+	var groupBy []string
+	{
+		if qoeStartupGroupBy != "" {
+			err = json.Unmarshal([]byte(qoeStartupGroupBy), &groupBy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON for groupBy, \nerror: %s, \nexample of valid JSON:\n%s", err, "'[\n      \"player\",\n      \"time\"\n   ]'")
+			}
+			for _, e := range groupBy {
+				if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+					err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var bucket string
+	{
+		if qoeStartupBucket != "" {
+			bucket = qoeStartupBucket
+			if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var from *string
+	{
+		if qoeStartupFrom != "" {
+			from = &qoeStartupFrom
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var to *string
+	{
+		if qoeStartupTo != "" {
+			to = &qoeStartupTo
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var player *string
+	{
+		if qoeStartupPlayer != "" {
+			player = &qoeStartupPlayer
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var area *string
+	{
+		if qoeStartupArea != "" {
+			area = &qoeStartupArea
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var device *string
+	{
+		if qoeStartupDevice != "" {
+			device = &qoeStartupDevice
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var protocol *string
+	{
+		if qoeStartupProtocol != "" {
+			protocol = &qoeStartupProtocol
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var page int
+	{
+		if qoeStartupPage != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeStartupPage, 10, strconv.IntSize)
+			page = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for page, must be INT")
+			}
+			if page < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var pageSize int
+	{
+		if qoeStartupPageSize != "" {
+			var v int64
+			v, err = strconv.ParseInt(qoeStartupPageSize, 10, strconv.IntSize)
+			pageSize = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for pageSize, must be INT")
+			}
+			if pageSize < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+			}
+			if pageSize > 1000 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var key string
+	{
+		key = qoeStartupKey
+	}
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v, nil
+}
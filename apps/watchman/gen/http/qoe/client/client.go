@@ -0,0 +1,138 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe client HTTP transport
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client lists the qoe service endpoint HTTP clients.
+type Client struct {
+	// Rebuffering Doer is the HTTP client used to make requests to the rebuffering
+	// endpoint.
+	RebufferingDoer goahttp.Doer
+
+	// Bitrate Doer is the HTTP client used to make requests to the bitrate
+	// endpoint.
+	BitrateDoer goahttp.Doer
+
+	// Startup Doer is the HTTP client used to make requests to the startup
+	// endpoint.
+	StartupDoer goahttp.Doer
+
+	// CORS Doer is the HTTP client used to make requests to the  endpoint.
+	CORSDoer goahttp.Doer
+
+	// RestoreResponseBody controls whether the response bodies are reset after
+	// decoding so they can be read again.
+	RestoreResponseBody bool
+
+	scheme  string
+	host    string
+	encoder func(*http.Request) goahttp.Encoder
+	decoder func(*http.Response) goahttp.Decoder
+}
+
+// NewClient instantiates HTTP clients for all the qoe service servers.
+func NewClient(
+	scheme string,
+	host string,
+	doer goahttp.Doer,
+	enc func(*http.Request) goahttp.Encoder,
+	dec func(*http.Response) goahttp.Decoder,
+	restoreBody bool,
+) *Client {
+	return &Client{
+		RebufferingDoer:     doer,
+		BitrateDoer:         doer,
+		StartupDoer:         doer,
+		CORSDoer:            doer,
+		RestoreResponseBody: restoreBody,
+		scheme:              scheme,
+		host:                host,
+		decoder:             dec,
+		encoder:             enc,
+	}
+}
+
+// Rebuffering returns an endpoint that makes HTTP requests to the qoe service
+// rebuffering server.
+func (c *Client) Rebuffering() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeRebufferingRequest(c.encoder)
+		decodeResponse = DecodeRebufferingResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildRebufferingRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.RebufferingDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("qoe", "rebuffering", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// Bitrate returns an endpoint that makes HTTP requests to the qoe service
+// bitrate server.
+func (c *Client) Bitrate() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeBitrateRequest(c.encoder)
+		decodeResponse = DecodeBitrateResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildBitrateRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.BitrateDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("qoe", "bitrate", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// Startup returns an endpoint that makes HTTP requests to the qoe service
+// startup server.
+func (c *Client) Startup() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeStartupRequest(c.encoder)
+		decodeResponse = DecodeStartupResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildStartupRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.StartupDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("qoe", "startup", err)
+		}
+		return decodeResponse(resp)
+	}
+}
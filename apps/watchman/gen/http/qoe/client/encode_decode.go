@@ -0,0 +1,486 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP client encoders and decoders
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// BuildRebufferingRequest instantiates a HTTP request object with method and
+// path set to call the "qoe" service "rebuffering" endpoint
+func (c *Client) BuildRebufferingRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: RebufferingQoePath()}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("qoe", "rebuffering", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeRebufferingRequest returns an encoder for requests sent to the qoe
+// rebuffering server.
+func EncodeRebufferingRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*qoe.AggregateQuery)
+		if !ok {
+			return goahttp.ErrInvalidType("qoe", "rebuffering", "*qoe.AggregateQuery", v)
+		}
+		{
+			head := p.Key
+			req.Header.Set("X-Api-Key", head)
+		}
+		values := req.URL.Query()
+		for _, value := range p.GroupBy {
+			values.Add("group_by", value)
+		}
+		values.Add("bucket", p.Bucket)
+		if p.From != nil {
+			values.Add("from", *p.From)
+		}
+		if p.To != nil {
+			values.Add("to", *p.To)
+		}
+		if p.Player != nil {
+			values.Add("player", *p.Player)
+		}
+		if p.Area != nil {
+			values.Add("area", *p.Area)
+		}
+		if p.Device != nil {
+			values.Add("device", *p.Device)
+		}
+		if p.Protocol != nil {
+			values.Add("protocol", *p.Protocol)
+		}
+		values.Add("page", fmt.Sprintf("%v", p.Page))
+		values.Add("page_size", fmt.Sprintf("%v", p.PageSize))
+		req.URL.RawQuery = values.Encode()
+		return nil
+	}
+}
+
+// DecodeRebufferingResponse returns a decoder for responses returned by the
+// qoe rebuffering endpoint. restoreBody controls whether the response body
+// should be restored after having been read.
+// DecodeRebufferingResponse may return the following errors:
+//   - "multi_field_error" (type *qoe.MultiFieldError): http.StatusBadRequest
+//   - "not_implemented" (type qoe.NotImplemented): http.StatusNotImplemented
+//   - "unauthorized" (type qoe.Unauthorized): http.StatusUnauthorized
+//   - error: internal error
+func DecodeRebufferingResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var (
+				body RebufferingResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "rebuffering", err)
+			}
+			err = ValidateRebufferingResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "rebuffering", err)
+			}
+			res := NewRebufferingPageOK(&body)
+			return res, nil
+		case http.StatusBadRequest:
+			var (
+				body RebufferingMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "rebuffering", err)
+			}
+			err = ValidateRebufferingMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "rebuffering", err)
+			}
+			return nil, NewRebufferingMultiFieldError(&body)
+		case http.StatusNotImplemented:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "rebuffering", err)
+			}
+			return nil, NewRebufferingNotImplemented(body)
+		case http.StatusUnauthorized:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "rebuffering", err)
+			}
+			return nil, NewRebufferingUnauthorized(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("qoe", "rebuffering", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildBitrateRequest instantiates a HTTP request object with method and path
+// set to call the "qoe" service "bitrate" endpoint
+func (c *Client) BuildBitrateRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: BitrateQoePath()}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("qoe", "bitrate", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeBitrateRequest returns an encoder for requests sent to the qoe bitrate
+// server.
+func EncodeBitrateRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*qoe.AggregateQuery)
+		if !ok {
+			return goahttp.ErrInvalidType("qoe", "bitrate", "*qoe.AggregateQuery", v)
+		}
+		{
+			head := p.Key
+			req.Header.Set("X-Api-Key", head)
+		}
+		values := req.URL.Query()
+		for _, value := range p.GroupBy {
+			values.Add("group_by", value)
+		}
+		values.Add("bucket", p.Bucket)
+		if p.From != nil {
+			values.Add("from", *p.From)
+		}
+		if p.To != nil {
+			values.Add("to", *p.To)
+		}
+		if p.Player != nil {
+			values.Add("player", *p.Player)
+		}
+		if p.Area != nil {
+			values.Add("area", *p.Area)
+		}
+		if p.Device != nil {
+			values.Add("device", *p.Device)
+		}
+		if p.Protocol != nil {
+			values.Add("protocol", *p.Protocol)
+		}
+		values.Add("page", fmt.Sprintf("%v", p.Page))
+		values.Add("page_size", fmt.Sprintf("%v", p.PageSize))
+		req.URL.RawQuery = values.Encode()
+		return nil
+	}
+}
+
+// DecodeBitrateResponse returns a decoder for responses returned by the qoe
+// bitrate endpoint. restoreBody controls whether the response body should be
+// restored after having been read.
+// DecodeBitrateResponse may return the following errors:
+//   - "multi_field_error" (type *qoe.MultiFieldError): http.StatusBadRequest
+//   - "not_implemented" (type qoe.NotImplemented): http.StatusNotImplemented
+//   - "unauthorized" (type qoe.Unauthorized): http.StatusUnauthorized
+//   - error: internal error
+func DecodeBitrateResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var (
+				body BitrateResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "bitrate", err)
+			}
+			err = ValidateBitrateResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "bitrate", err)
+			}
+			res := NewBitratePageOK(&body)
+			return res, nil
+		case http.StatusBadRequest:
+			var (
+				body BitrateMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "bitrate", err)
+			}
+			err = ValidateBitrateMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "bitrate", err)
+			}
+			return nil, NewBitrateMultiFieldError(&body)
+		case http.StatusNotImplemented:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "bitrate", err)
+			}
+			return nil, NewBitrateNotImplemented(body)
+		case http.StatusUnauthorized:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "bitrate", err)
+			}
+			return nil, NewBitrateUnauthorized(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("qoe", "bitrate", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildStartupRequest instantiates a HTTP request object with method and path
+// set to call the "qoe" service "startup" endpoint
+func (c *Client) BuildStartupRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: StartupQoePath()}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("qoe", "startup", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeStartupRequest returns an encoder for requests sent to the qoe startup
+// server.
+func EncodeStartupRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*qoe.AggregateQuery)
+		if !ok {
+			return goahttp.ErrInvalidType("qoe", "startup", "*qoe.AggregateQuery", v)
+		}
+		{
+			head := p.Key
+			req.Header.Set("X-Api-Key", head)
+		}
+		values := req.URL.Query()
+		for _, value := range p.GroupBy {
+			values.Add("group_by", value)
+		}
+		values.Add("bucket", p.Bucket)
+		if p.From != nil {
+			values.Add("from", *p.From)
+		}
+		if p.To != nil {
+			values.Add("to", *p.To)
+		}
+		if p.Player != nil {
+			values.Add("player", *p.Player)
+		}
+		if p.Area != nil {
+			values.Add("area", *p.Area)
+		}
+		if p.Device != nil {
+			values.Add("device", *p.Device)
+		}
+		if p.Protocol != nil {
+			values.Add("protocol", *p.Protocol)
+		}
+		values.Add("page", fmt.Sprintf("%v", p.Page))
+		values.Add("page_size", fmt.Sprintf("%v", p.PageSize))
+		req.URL.RawQuery = values.Encode()
+		return nil
+	}
+}
+
+// DecodeStartupResponse returns a decoder for responses returned by the qoe
+// startup endpoint. restoreBody controls whether the response body should be
+// restored after having been read.
+// DecodeStartupResponse may return the following errors:
+//   - "multi_field_error" (type *qoe.MultiFieldError): http.StatusBadRequest
+//   - "not_implemented" (type qoe.NotImplemented): http.StatusNotImplemented
+//   - "unauthorized" (type qoe.Unauthorized): http.StatusUnauthorized
+//   - error: internal error
+func DecodeStartupResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var (
+				body StartupResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "startup", err)
+			}
+			err = ValidateStartupResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "startup", err)
+			}
+			res := NewStartupPageOK(&body)
+			return res, nil
+		case http.StatusBadRequest:
+			var (
+				body StartupMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "startup", err)
+			}
+			err = ValidateStartupMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("qoe", "startup", err)
+			}
+			return nil, NewStartupMultiFieldError(&body)
+		case http.StatusNotImplemented:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "startup", err)
+			}
+			return nil, NewStartupNotImplemented(body)
+		case http.StatusUnauthorized:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("qoe", "startup", err)
+			}
+			return nil, NewStartupUnauthorized(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("qoe", "startup", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// unmarshalRebufferingStatsResponseBodyToQoeRebufferingStats builds a value of
+// type *qoe.RebufferingStats from a value of type
+// *RebufferingStatsResponseBody.
+func unmarshalRebufferingStatsResponseBodyToQoeRebufferingStats(v *RebufferingStatsResponseBody) *qoe.RebufferingStats {
+	res := &qoe.RebufferingStats{
+		PlaybackMs: *v.PlaybackMs,
+		RebufCount: *v.RebufCount,
+		RebufMs:    *v.RebufMs,
+		RebufRatio: *v.RebufRatio,
+		Player:     v.Player,
+		Area:       v.Area,
+		Device:     v.Device,
+		Protocol:   v.Protocol,
+		Time:       v.Time,
+		Reports:    *v.Reports,
+	}
+
+	return res
+}
+
+// unmarshalBitrateStatsResponseBodyToQoeBitrateStats builds a value of type
+// *qoe.BitrateStats from a value of type *BitrateStatsResponseBody.
+func unmarshalBitrateStatsResponseBodyToQoeBitrateStats(v *BitrateStatsResponseBody) *qoe.BitrateStats {
+	res := &qoe.BitrateStats{
+		AvgBitrate:    *v.AvgBitrate,
+		MedianBitrate: *v.MedianBitrate,
+		AvgBandwidth:  *v.AvgBandwidth,
+		Player:        v.Player,
+		Area:          v.Area,
+		Device:        v.Device,
+		Protocol:      v.Protocol,
+		Time:          v.Time,
+		Reports:       *v.Reports,
+	}
+
+	return res
+}
+
+// unmarshalStartupStatsResponseBodyToQoeStartupStats builds a value of type
+// *qoe.StartupStats from a value of type *StartupStatsResponseBody.
+func unmarshalStartupStatsResponseBodyToQoeStartupStats(v *StartupStatsResponseBody) *qoe.StartupStats {
+	res := &qoe.StartupStats{
+		Starts:           *v.Starts,
+		RebufferedStarts: *v.RebufferedStarts,
+		RebufferedRatio:  *v.RebufferedRatio,
+		AvgRebufMs:       *v.AvgRebufMs,
+		Player:           v.Player,
+		Area:             v.Area,
+		Device:           v.Device,
+		Protocol:         v.Protocol,
+		Time:             v.Time,
+		Reports:          *v.Reports,
+	}
+
+	return res
+}
@@ -0,0 +1,429 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP client types
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package client
+
+import (
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// RebufferingResponseBody is the type of the "qoe" service "rebuffering"
+// endpoint HTTP response body.
+type RebufferingResponseBody struct {
+	Items    []*RebufferingStatsResponseBody `form:"items,omitempty" json:"items,omitempty" xml:"items,omitempty"`
+	Page     *int                            `form:"page,omitempty" json:"page,omitempty" xml:"page,omitempty"`
+	PageSize *int                            `form:"page_size,omitempty" json:"page_size,omitempty" xml:"page_size,omitempty"`
+	// True when there are more groups on the following pages
+	HasMore *bool `form:"has_more,omitempty" json:"has_more,omitempty" xml:"has_more,omitempty"`
+}
+
+// BitrateResponseBody is the type of the "qoe" service "bitrate" endpoint HTTP
+// response body.
+type BitrateResponseBody struct {
+	Items    []*BitrateStatsResponseBody `form:"items,omitempty" json:"items,omitempty" xml:"items,omitempty"`
+	Page     *int                        `form:"page,omitempty" json:"page,omitempty" xml:"page,omitempty"`
+	PageSize *int                        `form:"page_size,omitempty" json:"page_size,omitempty" xml:"page_size,omitempty"`
+	// True when there are more groups on the following pages
+	HasMore *bool `form:"has_more,omitempty" json:"has_more,omitempty" xml:"has_more,omitempty"`
+}
+
+// StartupResponseBody is the type of the "qoe" service "startup" endpoint HTTP
+// response body.
+type StartupResponseBody struct {
+	Items    []*StartupStatsResponseBody `form:"items,omitempty" json:"items,omitempty" xml:"items,omitempty"`
+	Page     *int                        `form:"page,omitempty" json:"page,omitempty" xml:"page,omitempty"`
+	PageSize *int                        `form:"page_size,omitempty" json:"page_size,omitempty" xml:"page_size,omitempty"`
+	// True when there are more groups on the following pages
+	HasMore *bool `form:"has_more,omitempty" json:"has_more,omitempty" xml:"has_more,omitempty"`
+}
+
+// RebufferingMultiFieldErrorResponseBody is the type of the "qoe" service
+// "rebuffering" endpoint HTTP response body for the "multi_field_error" error.
+type RebufferingMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// BitrateMultiFieldErrorResponseBody is the type of the "qoe" service
+// "bitrate" endpoint HTTP response body for the "multi_field_error" error.
+type BitrateMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// StartupMultiFieldErrorResponseBody is the type of the "qoe" service
+// "startup" endpoint HTTP response body for the "multi_field_error" error.
+type StartupMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// RebufferingStatsResponseBody is used to define fields on response body types.
+type RebufferingStatsResponseBody struct {
+	// Total reported playback time
+	PlaybackMs *uint64 `form:"playback_ms,omitempty" json:"playback_ms,omitempty" xml:"playback_ms,omitempty"`
+	// Total rebuffering events
+	RebufCount *uint64 `form:"rebuf_count,omitempty" json:"rebuf_count,omitempty" xml:"rebuf_count,omitempty"`
+	// Total rebuffering time
+	RebufMs *uint64 `form:"rebuf_ms,omitempty" json:"rebuf_ms,omitempty" xml:"rebuf_ms,omitempty"`
+	// Share of playback time spent rebuffering, 0—1
+	RebufRatio *float64 `form:"rebuf_ratio,omitempty" json:"rebuf_ratio,omitempty" xml:"rebuf_ratio,omitempty"`
+	Player     *string  `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area       *string  `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device     *string  `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol   *string  `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports *uint64 `form:"reports,omitempty" json:"reports,omitempty" xml:"reports,omitempty"`
+}
+
+// BitrateStatsResponseBody is used to define fields on response body types.
+type BitrateStatsResponseBody struct {
+	// Average media bitrate, bit/s
+	AvgBitrate *float64 `form:"avg_bitrate,omitempty" json:"avg_bitrate,omitempty" xml:"avg_bitrate,omitempty"`
+	// Median media bitrate, bit/s
+	MedianBitrate *float64 `form:"median_bitrate,omitempty" json:"median_bitrate,omitempty" xml:"median_bitrate,omitempty"`
+	// Average client bandwidth, bit/s
+	AvgBandwidth *float64 `form:"avg_bandwidth,omitempty" json:"avg_bandwidth,omitempty" xml:"avg_bandwidth,omitempty"`
+	Player       *string  `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area         *string  `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device       *string  `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol     *string  `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports *uint64 `form:"reports,omitempty" json:"reports,omitempty" xml:"reports,omitempty"`
+}
+
+// StartupStatsResponseBody is used to define fields on response body types.
+type StartupStatsResponseBody struct {
+	// Reports covering the first reporting window of a playback
+	Starts *uint64 `form:"starts,omitempty" json:"starts,omitempty" xml:"starts,omitempty"`
+	// Starts during which rebuffering happened
+	RebufferedStarts *uint64 `form:"rebuffered_starts,omitempty" json:"rebuffered_starts,omitempty" xml:"rebuffered_starts,omitempty"`
+	// Share of starts with rebuffering, 0—1
+	RebufferedRatio *float64 `form:"rebuffered_ratio,omitempty" json:"rebuffered_ratio,omitempty" xml:"rebuffered_ratio,omitempty"`
+	// Average rebuffering time during a start
+	AvgRebufMs *float64 `form:"avg_rebuf_ms,omitempty" json:"avg_rebuf_ms,omitempty" xml:"avg_rebuf_ms,omitempty"`
+	Player     *string  `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area       *string  `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device     *string  `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol   *string  `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports *uint64 `form:"reports,omitempty" json:"reports,omitempty" xml:"reports,omitempty"`
+}
+
+// NewRebufferingPageOK builds a "qoe" service "rebuffering" endpoint result
+// from a HTTP "OK" response.
+func NewRebufferingPageOK(body *RebufferingResponseBody) *qoe.RebufferingPage {
+	v := &qoe.RebufferingPage{
+		Page:     *body.Page,
+		PageSize: *body.PageSize,
+		HasMore:  *body.HasMore,
+	}
+	v.Items = make([]*qoe.RebufferingStats, len(body.Items))
+	for i, val := range body.Items {
+		if val == nil {
+			v.Items[i] = nil
+			continue
+		}
+		v.Items[i] = unmarshalRebufferingStatsResponseBodyToQoeRebufferingStats(val)
+	}
+
+	return v
+}
+
+// NewRebufferingMultiFieldError builds a qoe service rebuffering endpoint
+// multi_field_error error.
+func NewRebufferingMultiFieldError(body *RebufferingMultiFieldErrorResponseBody) *qoe.MultiFieldError {
+	v := &qoe.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewRebufferingNotImplemented builds a qoe service rebuffering endpoint
+// not_implemented error.
+func NewRebufferingNotImplemented(body string) qoe.NotImplemented {
+	v := qoe.NotImplemented(body)
+
+	return v
+}
+
+// NewRebufferingUnauthorized builds a qoe service rebuffering endpoint
+// unauthorized error.
+func NewRebufferingUnauthorized(body string) qoe.Unauthorized {
+	v := qoe.Unauthorized(body)
+
+	return v
+}
+
+// NewBitratePageOK builds a "qoe" service "bitrate" endpoint result from a
+// HTTP "OK" response.
+func NewBitratePageOK(body *BitrateResponseBody) *qoe.BitratePage {
+	v := &qoe.BitratePage{
+		Page:     *body.Page,
+		PageSize: *body.PageSize,
+		HasMore:  *body.HasMore,
+	}
+	v.Items = make([]*qoe.BitrateStats, len(body.Items))
+	for i, val := range body.Items {
+		if val == nil {
+			v.Items[i] = nil
+			continue
+		}
+		v.Items[i] = unmarshalBitrateStatsResponseBodyToQoeBitrateStats(val)
+	}
+
+	return v
+}
+
+// NewBitrateMultiFieldError builds a qoe service bitrate endpoint
+// multi_field_error error.
+func NewBitrateMultiFieldError(body *BitrateMultiFieldErrorResponseBody) *qoe.MultiFieldError {
+	v := &qoe.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewBitrateNotImplemented builds a qoe service bitrate endpoint
+// not_implemented error.
+func NewBitrateNotImplemented(body string) qoe.NotImplemented {
+	v := qoe.NotImplemented(body)
+
+	return v
+}
+
+// NewBitrateUnauthorized builds a qoe service bitrate endpoint unauthorized
+// error.
+func NewBitrateUnauthorized(body string) qoe.Unauthorized {
+	v := qoe.Unauthorized(body)
+
+	return v
+}
+
+// NewStartupPageOK builds a "qoe" service "startup" endpoint result from a
+// HTTP "OK" response.
+func NewStartupPageOK(body *StartupResponseBody) *qoe.StartupPage {
+	v := &qoe.StartupPage{
+		Page:     *body.Page,
+		PageSize: *body.PageSize,
+		HasMore:  *body.HasMore,
+	}
+	v.Items = make([]*qoe.StartupStats, len(body.Items))
+	for i, val := range body.Items {
+		if val == nil {
+			v.Items[i] = nil
+			continue
+		}
+		v.Items[i] = unmarshalStartupStatsResponseBodyToQoeStartupStats(val)
+	}
+
+	return v
+}
+
+// NewStartupMultiFieldError builds a qoe service startup endpoint
+// multi_field_error error.
+func NewStartupMultiFieldError(body *StartupMultiFieldErrorResponseBody) *qoe.MultiFieldError {
+	v := &qoe.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewStartupNotImplemented builds a qoe service startup endpoint
+// not_implemented error.
+func NewStartupNotImplemented(body string) qoe.NotImplemented {
+	v := qoe.NotImplemented(body)
+
+	return v
+}
+
+// NewStartupUnauthorized builds a qoe service startup endpoint unauthorized
+// error.
+func NewStartupUnauthorized(body string) qoe.Unauthorized {
+	v := qoe.Unauthorized(body)
+
+	return v
+}
+
+// ValidateRebufferingResponseBody runs the validations defined on
+// RebufferingResponseBody
+func ValidateRebufferingResponseBody(body *RebufferingResponseBody) (err error) {
+	if body.Items == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("items", "body"))
+	}
+	if body.Page == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page", "body"))
+	}
+	if body.PageSize == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page_size", "body"))
+	}
+	if body.HasMore == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("has_more", "body"))
+	}
+	for _, e := range body.Items {
+		if e != nil {
+			if err2 := ValidateRebufferingStatsResponseBody(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// ValidateBitrateResponseBody runs the validations defined on
+// BitrateResponseBody
+func ValidateBitrateResponseBody(body *BitrateResponseBody) (err error) {
+	if body.Items == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("items", "body"))
+	}
+	if body.Page == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page", "body"))
+	}
+	if body.PageSize == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page_size", "body"))
+	}
+	if body.HasMore == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("has_more", "body"))
+	}
+	for _, e := range body.Items {
+		if e != nil {
+			if err2 := ValidateBitrateStatsResponseBody(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// ValidateStartupResponseBody runs the validations defined on
+// StartupResponseBody
+func ValidateStartupResponseBody(body *StartupResponseBody) (err error) {
+	if body.Items == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("items", "body"))
+	}
+	if body.Page == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page", "body"))
+	}
+	if body.PageSize == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page_size", "body"))
+	}
+	if body.HasMore == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("has_more", "body"))
+	}
+	for _, e := range body.Items {
+		if e != nil {
+			if err2 := ValidateStartupStatsResponseBody(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// ValidateRebufferingMultiFieldErrorResponseBody runs the validations defined
+// on rebuffering_multi_field_error_response_body
+func ValidateRebufferingMultiFieldErrorResponseBody(body *RebufferingMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateBitrateMultiFieldErrorResponseBody runs the validations defined on
+// bitrate_multi_field_error_response_body
+func ValidateBitrateMultiFieldErrorResponseBody(body *BitrateMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateStartupMultiFieldErrorResponseBody runs the validations defined on
+// startup_multi_field_error_response_body
+func ValidateStartupMultiFieldErrorResponseBody(body *StartupMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateRebufferingStatsResponseBody runs the validations defined on
+// RebufferingStatsResponseBody
+func ValidateRebufferingStatsResponseBody(body *RebufferingStatsResponseBody) (err error) {
+	if body.PlaybackMs == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("playback_ms", "body"))
+	}
+	if body.RebufCount == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuf_count", "body"))
+	}
+	if body.RebufMs == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuf_ms", "body"))
+	}
+	if body.RebufRatio == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuf_ratio", "body"))
+	}
+	if body.Reports == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("reports", "body"))
+	}
+	if body.Time != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.time", *body.Time, goa.FormatDateTime))
+	}
+	return
+}
+
+// ValidateBitrateStatsResponseBody runs the validations defined on
+// BitrateStatsResponseBody
+func ValidateBitrateStatsResponseBody(body *BitrateStatsResponseBody) (err error) {
+	if body.AvgBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("avg_bitrate", "body"))
+	}
+	if body.MedianBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("median_bitrate", "body"))
+	}
+	if body.AvgBandwidth == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("avg_bandwidth", "body"))
+	}
+	if body.Reports == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("reports", "body"))
+	}
+	if body.Time != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.time", *body.Time, goa.FormatDateTime))
+	}
+	return
+}
+
+// ValidateStartupStatsResponseBody runs the validations defined on
+// StartupStatsResponseBody
+func ValidateStartupStatsResponseBody(body *StartupStatsResponseBody) (err error) {
+	if body.Starts == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("starts", "body"))
+	}
+	if body.RebufferedStarts == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuffered_starts", "body"))
+	}
+	if body.RebufferedRatio == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuffered_ratio", "body"))
+	}
+	if body.AvgRebufMs == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("avg_rebuf_ms", "body"))
+	}
+	if body.Reports == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("reports", "body"))
+	}
+	if body.Time != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.time", *body.Time, goa.FormatDateTime))
+	}
+	return
+}
@@ -0,0 +1,658 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP server encoders and decoders
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// EncodeRebufferingResponse returns an encoder for responses returned by the
+// qoe rebuffering endpoint.
+func EncodeRebufferingResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, any) error {
+	return func(ctx context.Context, w http.ResponseWriter, v any) error {
+		res, _ := v.(*qoe.RebufferingPage)
+		enc := encoder(ctx, w)
+		body := NewRebufferingResponseBody(res)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeRebufferingRequest returns a decoder for requests sent to the qoe
+// rebuffering endpoint.
+func DecodeRebufferingRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (*qoe.AggregateQuery, error) {
+	return func(r *http.Request) (*qoe.AggregateQuery, error) {
+		var payload *qoe.AggregateQuery
+		var (
+			groupBy  []string
+			bucket   string
+			from     *string
+			to       *string
+			player   *string
+			area     *string
+			device   *string
+			protocol *string
+			page     int
+			pageSize int
+			key      string
+			err      error
+		)
+		qp := r.URL.Query()
+		groupBy = qp["group_by"]
+		for _, e := range groupBy {
+			if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+			}
+		}
+		bucketRaw := qp.Get("bucket")
+		if bucketRaw != "" {
+			bucket = bucketRaw
+		} else {
+			bucket = "hour"
+		}
+		if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+		}
+		fromRaw := qp.Get("from")
+		if fromRaw != "" {
+			from = &fromRaw
+		}
+		if from != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+		}
+		toRaw := qp.Get("to")
+		if toRaw != "" {
+			to = &toRaw
+		}
+		if to != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+		}
+		playerRaw := qp.Get("player")
+		if playerRaw != "" {
+			player = &playerRaw
+		}
+		if player != nil {
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+		}
+		areaRaw := qp.Get("area")
+		if areaRaw != "" {
+			area = &areaRaw
+		}
+		if area != nil {
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+		}
+		deviceRaw := qp.Get("device")
+		if deviceRaw != "" {
+			device = &deviceRaw
+		}
+		if device != nil {
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+		}
+		protocolRaw := qp.Get("protocol")
+		if protocolRaw != "" {
+			protocol = &protocolRaw
+		}
+		if protocol != nil {
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+		}
+		{
+			pageRaw := qp.Get("page")
+			if pageRaw == "" {
+				page = 1
+			} else {
+				v, err2 := strconv.ParseInt(pageRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page", pageRaw, "integer"))
+				}
+				page = int(v)
+			}
+		}
+		if page < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+		}
+		{
+			pageSizeRaw := qp.Get("page_size")
+			if pageSizeRaw == "" {
+				pageSize = 100
+			} else {
+				v, err2 := strconv.ParseInt(pageSizeRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page_size", pageSizeRaw, "integer"))
+				}
+				pageSize = int(v)
+			}
+		}
+		if pageSize < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+		}
+		if pageSize > 1000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+		}
+		key = r.Header.Get("X-Api-Key")
+		if key == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("key", "header"))
+		}
+		if err != nil {
+			return payload, err
+		}
+		payload = NewRebufferingAggregateQuery(groupBy, bucket, from, to, player, area, device, protocol, page, pageSize, key)
+		if strings.Contains(payload.Key, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.Key, " ", 2)[1]
+			payload.Key = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeRebufferingError returns an encoder for errors returned by the
+// rebuffering qoe endpoint.
+func EncodeRebufferingError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "multi_field_error":
+			var res *qoe.MultiFieldError
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body any
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewRebufferingMultiFieldErrorResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "not_implemented":
+			var res qoe.NotImplemented
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "unauthorized":
+			var res qoe.Unauthorized
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return enc.Encode(body)
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeBitrateResponse returns an encoder for responses returned by the qoe
+// bitrate endpoint.
+func EncodeBitrateResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, any) error {
+	return func(ctx context.Context, w http.ResponseWriter, v any) error {
+		res, _ := v.(*qoe.BitratePage)
+		enc := encoder(ctx, w)
+		body := NewBitrateResponseBody(res)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeBitrateRequest returns a decoder for requests sent to the qoe bitrate
+// endpoint.
+func DecodeBitrateRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (*qoe.AggregateQuery, error) {
+	return func(r *http.Request) (*qoe.AggregateQuery, error) {
+		var payload *qoe.AggregateQuery
+		var (
+			groupBy  []string
+			bucket   string
+			from     *string
+			to       *string
+			player   *string
+			area     *string
+			device   *string
+			protocol *string
+			page     int
+			pageSize int
+			key      string
+			err      error
+		)
+		qp := r.URL.Query()
+		groupBy = qp["group_by"]
+		for _, e := range groupBy {
+			if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+			}
+		}
+		bucketRaw := qp.Get("bucket")
+		if bucketRaw != "" {
+			bucket = bucketRaw
+		} else {
+			bucket = "hour"
+		}
+		if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+		}
+		fromRaw := qp.Get("from")
+		if fromRaw != "" {
+			from = &fromRaw
+		}
+		if from != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+		}
+		toRaw := qp.Get("to")
+		if toRaw != "" {
+			to = &toRaw
+		}
+		if to != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+		}
+		playerRaw := qp.Get("player")
+		if playerRaw != "" {
+			player = &playerRaw
+		}
+		if player != nil {
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+		}
+		areaRaw := qp.Get("area")
+		if areaRaw != "" {
+			area = &areaRaw
+		}
+		if area != nil {
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+		}
+		deviceRaw := qp.Get("device")
+		if deviceRaw != "" {
+			device = &deviceRaw
+		}
+		if device != nil {
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+		}
+		protocolRaw := qp.Get("protocol")
+		if protocolRaw != "" {
+			protocol = &protocolRaw
+		}
+		if protocol != nil {
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+		}
+		{
+			pageRaw := qp.Get("page")
+			if pageRaw == "" {
+				page = 1
+			} else {
+				v, err2 := strconv.ParseInt(pageRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page", pageRaw, "integer"))
+				}
+				page = int(v)
+			}
+		}
+		if page < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+		}
+		{
+			pageSizeRaw := qp.Get("page_size")
+			if pageSizeRaw == "" {
+				pageSize = 100
+			} else {
+				v, err2 := strconv.ParseInt(pageSizeRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page_size", pageSizeRaw, "integer"))
+				}
+				pageSize = int(v)
+			}
+		}
+		if pageSize < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+		}
+		if pageSize > 1000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+		}
+		key = r.Header.Get("X-Api-Key")
+		if key == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("key", "header"))
+		}
+		if err != nil {
+			return payload, err
+		}
+		payload = NewBitrateAggregateQuery(groupBy, bucket, from, to, player, area, device, protocol, page, pageSize, key)
+		if strings.Contains(payload.Key, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.Key, " ", 2)[1]
+			payload.Key = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeBitrateError returns an encoder for errors returned by the bitrate qoe
+// endpoint.
+func EncodeBitrateError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "multi_field_error":
+			var res *qoe.MultiFieldError
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body any
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewBitrateMultiFieldErrorResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "not_implemented":
+			var res qoe.NotImplemented
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "unauthorized":
+			var res qoe.Unauthorized
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return enc.Encode(body)
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeStartupResponse returns an encoder for responses returned by the qoe
+// startup endpoint.
+func EncodeStartupResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, any) error {
+	return func(ctx context.Context, w http.ResponseWriter, v any) error {
+		res, _ := v.(*qoe.StartupPage)
+		enc := encoder(ctx, w)
+		body := NewStartupResponseBody(res)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeStartupRequest returns a decoder for requests sent to the qoe startup
+// endpoint.
+func DecodeStartupRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (*qoe.AggregateQuery, error) {
+	return func(r *http.Request) (*qoe.AggregateQuery, error) {
+		var payload *qoe.AggregateQuery
+		var (
+			groupBy  []string
+			bucket   string
+			from     *string
+			to       *string
+			player   *string
+			area     *string
+			device   *string
+			protocol *string
+			page     int
+			pageSize int
+			key      string
+			err      error
+		)
+		qp := r.URL.Query()
+		groupBy = qp["group_by"]
+		for _, e := range groupBy {
+			if !(e == "player" || e == "area" || e == "device" || e == "protocol" || e == "time") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("group_by[*]", e, []any{"player", "area", "device", "protocol", "time"}))
+			}
+		}
+		bucketRaw := qp.Get("bucket")
+		if bucketRaw != "" {
+			bucket = bucketRaw
+		} else {
+			bucket = "hour"
+		}
+		if !(bucket == "minute" || bucket == "hour" || bucket == "day") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []any{"minute", "hour", "day"}))
+		}
+		fromRaw := qp.Get("from")
+		if fromRaw != "" {
+			from = &fromRaw
+		}
+		if from != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("from", *from, goa.FormatDateTime))
+		}
+		toRaw := qp.Get("to")
+		if toRaw != "" {
+			to = &toRaw
+		}
+		if to != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("to", *to, goa.FormatDateTime))
+		}
+		playerRaw := qp.Get("player")
+		if playerRaw != "" {
+			player = &playerRaw
+		}
+		if player != nil {
+			if utf8.RuneCountInString(*player) > 128 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("player", *player, utf8.RuneCountInString(*player), 128, false))
+			}
+		}
+		areaRaw := qp.Get("area")
+		if areaRaw != "" {
+			area = &areaRaw
+		}
+		if area != nil {
+			if utf8.RuneCountInString(*area) > 2 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("area", *area, utf8.RuneCountInString(*area), 2, false))
+			}
+		}
+		deviceRaw := qp.Get("device")
+		if deviceRaw != "" {
+			device = &deviceRaw
+		}
+		if device != nil {
+			if !(*device == "ios" || *device == "adr" || *device == "web" || *device == "dsk" || *device == "stb") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("device", *device, []any{"ios", "adr", "web", "dsk", "stb"}))
+			}
+		}
+		protocolRaw := qp.Get("protocol")
+		if protocolRaw != "" {
+			protocol = &protocolRaw
+		}
+		if protocol != nil {
+			if !(*protocol == "stb" || *protocol == "hls" || *protocol == "lvs") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("protocol", *protocol, []any{"stb", "hls", "lvs"}))
+			}
+		}
+		{
+			pageRaw := qp.Get("page")
+			if pageRaw == "" {
+				page = 1
+			} else {
+				v, err2 := strconv.ParseInt(pageRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page", pageRaw, "integer"))
+				}
+				page = int(v)
+			}
+		}
+		if page < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+		}
+		{
+			pageSizeRaw := qp.Get("page_size")
+			if pageSizeRaw == "" {
+				pageSize = 100
+			} else {
+				v, err2 := strconv.ParseInt(pageSizeRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page_size", pageSizeRaw, "integer"))
+				}
+				pageSize = int(v)
+			}
+		}
+		if pageSize < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1, true))
+		}
+		if pageSize > 1000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page_size", pageSize, 1000, false))
+		}
+		key = r.Header.Get("X-Api-Key")
+		if key == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("key", "header"))
+		}
+		if err != nil {
+			return payload, err
+		}
+		payload = NewStartupAggregateQuery(groupBy, bucket, from, to, player, area, device, protocol, page, pageSize, key)
+		if strings.Contains(payload.Key, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.Key, " ", 2)[1]
+			payload.Key = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeStartupError returns an encoder for errors returned by the startup qoe
+// endpoint.
+func EncodeStartupError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "multi_field_error":
+			var res *qoe.MultiFieldError
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body any
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewStartupMultiFieldErrorResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "not_implemented":
+			var res qoe.NotImplemented
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "unauthorized":
+			var res qoe.Unauthorized
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			body := res
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return enc.Encode(body)
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// marshalQoeRebufferingStatsToRebufferingStatsResponseBody builds a value of
+// type *RebufferingStatsResponseBody from a value of type
+// *qoe.RebufferingStats.
+func marshalQoeRebufferingStatsToRebufferingStatsResponseBody(v *qoe.RebufferingStats) *RebufferingStatsResponseBody {
+	res := &RebufferingStatsResponseBody{
+		PlaybackMs: v.PlaybackMs,
+		RebufCount: v.RebufCount,
+		RebufMs:    v.RebufMs,
+		RebufRatio: v.RebufRatio,
+		Player:     v.Player,
+		Area:       v.Area,
+		Device:     v.Device,
+		Protocol:   v.Protocol,
+		Time:       v.Time,
+		Reports:    v.Reports,
+	}
+
+	return res
+}
+
+// marshalQoeBitrateStatsToBitrateStatsResponseBody builds a value of type
+// *BitrateStatsResponseBody from a value of type *qoe.BitrateStats.
+func marshalQoeBitrateStatsToBitrateStatsResponseBody(v *qoe.BitrateStats) *BitrateStatsResponseBody {
+	res := &BitrateStatsResponseBody{
+		AvgBitrate:    v.AvgBitrate,
+		MedianBitrate: v.MedianBitrate,
+		AvgBandwidth:  v.AvgBandwidth,
+		Player:        v.Player,
+		Area:          v.Area,
+		Device:        v.Device,
+		Protocol:      v.Protocol,
+		Time:          v.Time,
+		Reports:       v.Reports,
+	}
+
+	return res
+}
+
+// marshalQoeStartupStatsToStartupStatsResponseBody builds a value of type
+// *StartupStatsResponseBody from a value of type *qoe.StartupStats.
+func marshalQoeStartupStatsToStartupStatsResponseBody(v *qoe.StartupStats) *StartupStatsResponseBody {
+	res := &StartupStatsResponseBody{
+		Starts:           v.Starts,
+		RebufferedStarts: v.RebufferedStarts,
+		RebufferedRatio:  v.RebufferedRatio,
+		AvgRebufMs:       v.AvgRebufMs,
+		Player:           v.Player,
+		Area:             v.Area,
+		Device:           v.Device,
+		Protocol:         v.Protocol,
+		Time:             v.Time,
+		Reports:          v.Reports,
+	}
+
+	return res
+}
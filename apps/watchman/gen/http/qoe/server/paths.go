@@ -0,0 +1,24 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// HTTP request path constructors for the qoe service.
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package server
+
+// RebufferingQoePath returns the URL path to the qoe service rebuffering HTTP endpoint.
+func RebufferingQoePath() string {
+	return "/qoe/rebuffering"
+}
+
+// BitrateQoePath returns the URL path to the qoe service bitrate HTTP endpoint.
+func BitrateQoePath() string {
+	return "/qoe/bitrate"
+}
+
+// StartupQoePath returns the URL path to the qoe service startup HTTP endpoint.
+func StartupQoePath() string {
+	return "/qoe/startup"
+}
@@ -0,0 +1,302 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP server
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+	"goa.design/plugins/v3/cors"
+)
+
+// Server lists the qoe service endpoint HTTP handlers.
+type Server struct {
+	Mounts      []*MountPoint
+	Rebuffering http.Handler
+	Bitrate     http.Handler
+	Startup     http.Handler
+	CORS        http.Handler
+}
+
+// MountPoint holds information about the mounted endpoints.
+type MountPoint struct {
+	// Method is the name of the service method served by the mounted HTTP handler.
+	Method string
+	// Verb is the HTTP method used to match requests to the mounted handler.
+	Verb string
+	// Pattern is the HTTP request path pattern used to match requests to the
+	// mounted handler.
+	Pattern string
+}
+
+// New instantiates HTTP handlers for all the qoe service endpoints using the
+// provided encoder and decoder. The handlers are mounted on the given mux
+// using the HTTP verb and path defined in the design. errhandler is called
+// whenever a response fails to be encoded. formatter is used to format errors
+// returned by the service methods prior to encoding. Both errhandler and
+// formatter are optional and can be nil.
+func New(
+	e *qoe.Endpoints,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) *Server {
+	return &Server{
+		Mounts: []*MountPoint{
+			{"Rebuffering", "GET", "/qoe/rebuffering"},
+			{"Bitrate", "GET", "/qoe/bitrate"},
+			{"Startup", "GET", "/qoe/startup"},
+			{"CORS", "OPTIONS", "/qoe/rebuffering"},
+			{"CORS", "OPTIONS", "/qoe/bitrate"},
+			{"CORS", "OPTIONS", "/qoe/startup"},
+		},
+		Rebuffering: NewRebufferingHandler(e.Rebuffering, mux, decoder, encoder, errhandler, formatter),
+		Bitrate:     NewBitrateHandler(e.Bitrate, mux, decoder, encoder, errhandler, formatter),
+		Startup:     NewStartupHandler(e.Startup, mux, decoder, encoder, errhandler, formatter),
+		CORS:        NewCORSHandler(),
+	}
+}
+
+// Service returns the name of the service served.
+func (s *Server) Service() string { return "qoe" }
+
+// Use wraps the server handlers with the given middleware.
+func (s *Server) Use(m func(http.Handler) http.Handler) {
+	s.Rebuffering = m(s.Rebuffering)
+	s.Bitrate = m(s.Bitrate)
+	s.Startup = m(s.Startup)
+	s.CORS = m(s.CORS)
+}
+
+// MethodNames returns the methods served.
+func (s *Server) MethodNames() []string { return qoe.MethodNames[:] }
+
+// Mount configures the mux to serve the qoe endpoints.
+func Mount(mux goahttp.Muxer, h *Server) {
+	MountRebufferingHandler(mux, h.Rebuffering)
+	MountBitrateHandler(mux, h.Bitrate)
+	MountStartupHandler(mux, h.Startup)
+	MountCORSHandler(mux, h.CORS)
+}
+
+// Mount configures the mux to serve the qoe endpoints.
+func (s *Server) Mount(mux goahttp.Muxer) {
+	Mount(mux, s)
+}
+
+// MountRebufferingHandler configures the mux to serve the "qoe" service
+// "rebuffering" endpoint.
+func MountRebufferingHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleQoeOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("GET", "/qoe/rebuffering", f)
+}
+
+// NewRebufferingHandler creates a HTTP handler which loads the HTTP request
+// and calls the "qoe" service "rebuffering" endpoint.
+func NewRebufferingHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeRebufferingRequest(mux, decoder)
+		encodeResponse = EncodeRebufferingResponse(encoder)
+		encodeError    = EncodeRebufferingError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "rebuffering")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "qoe")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountBitrateHandler configures the mux to serve the "qoe" service "bitrate"
+// endpoint.
+func MountBitrateHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleQoeOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("GET", "/qoe/bitrate", f)
+}
+
+// NewBitrateHandler creates a HTTP handler which loads the HTTP request and
+// calls the "qoe" service "bitrate" endpoint.
+func NewBitrateHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeBitrateRequest(mux, decoder)
+		encodeResponse = EncodeBitrateResponse(encoder)
+		encodeError    = EncodeBitrateError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "bitrate")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "qoe")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountStartupHandler configures the mux to serve the "qoe" service "startup"
+// endpoint.
+func MountStartupHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleQoeOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("GET", "/qoe/startup", f)
+}
+
+// NewStartupHandler creates a HTTP handler which loads the HTTP request and
+// calls the "qoe" service "startup" endpoint.
+func NewStartupHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeStartupRequest(mux, decoder)
+		encodeResponse = EncodeStartupResponse(encoder)
+		encodeError    = EncodeStartupError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "startup")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "qoe")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountCORSHandler configures the mux to serve the CORS endpoints for the
+// service qoe.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
+	h = HandleQoeOrigin(h)
+	mux.Handle("OPTIONS", "/qoe/rebuffering", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/qoe/bitrate", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/qoe/startup", h.ServeHTTP)
+}
+
+// NewCORSHandler creates a HTTP handler which returns a simple 204 response.
+func NewCORSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	})
+}
+
+// HandleQoeOrigin applies the CORS response headers corresponding to the
+// origin for the service qoe.
+func HandleQoeOrigin(h http.Handler) http.Handler {
+	spec0 := regexp.MustCompile("(http:\\/\\/localhost:\\d+)|(https:\\/\\/odysee.com)|(https:\\/\\/.+\\.odysee.com)|(https:\\/\\/.+\\.lbry.tv)")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Not a CORS request
+			h.ServeHTTP(w, r)
+			return
+		}
+		if cors.MatchOriginRegexp(origin, spec0) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Vary", "Origin")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
+				// We are handling a preflight request
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.Header().Set("Access-Control-Allow-Headers", "content-type")
+				w.WriteHeader(204)
+				return
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+		return
+	})
+}
@@ -0,0 +1,270 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe HTTP server types
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package server
+
+import (
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+)
+
+// RebufferingResponseBody is the type of the "qoe" service "rebuffering"
+// endpoint HTTP response body.
+type RebufferingResponseBody struct {
+	Items    []*RebufferingStatsResponseBody `form:"items" json:"items" xml:"items"`
+	Page     int                             `form:"page" json:"page" xml:"page"`
+	PageSize int                             `form:"page_size" json:"page_size" xml:"page_size"`
+	// True when there are more groups on the following pages
+	HasMore bool `form:"has_more" json:"has_more" xml:"has_more"`
+}
+
+// BitrateResponseBody is the type of the "qoe" service "bitrate" endpoint HTTP
+// response body.
+type BitrateResponseBody struct {
+	Items    []*BitrateStatsResponseBody `form:"items" json:"items" xml:"items"`
+	Page     int                         `form:"page" json:"page" xml:"page"`
+	PageSize int                         `form:"page_size" json:"page_size" xml:"page_size"`
+	// True when there are more groups on the following pages
+	HasMore bool `form:"has_more" json:"has_more" xml:"has_more"`
+}
+
+// StartupResponseBody is the type of the "qoe" service "startup" endpoint HTTP
+// response body.
+type StartupResponseBody struct {
+	Items    []*StartupStatsResponseBody `form:"items" json:"items" xml:"items"`
+	Page     int                         `form:"page" json:"page" xml:"page"`
+	PageSize int                         `form:"page_size" json:"page_size" xml:"page_size"`
+	// True when there are more groups on the following pages
+	HasMore bool `form:"has_more" json:"has_more" xml:"has_more"`
+}
+
+// RebufferingMultiFieldErrorResponseBody is the type of the "qoe" service
+// "rebuffering" endpoint HTTP response body for the "multi_field_error" error.
+type RebufferingMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// BitrateMultiFieldErrorResponseBody is the type of the "qoe" service
+// "bitrate" endpoint HTTP response body for the "multi_field_error" error.
+type BitrateMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// StartupMultiFieldErrorResponseBody is the type of the "qoe" service
+// "startup" endpoint HTTP response body for the "multi_field_error" error.
+type StartupMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// RebufferingStatsResponseBody is used to define fields on response body types.
+type RebufferingStatsResponseBody struct {
+	// Total reported playback time
+	PlaybackMs uint64 `form:"playback_ms" json:"playback_ms" xml:"playback_ms"`
+	// Total rebuffering events
+	RebufCount uint64 `form:"rebuf_count" json:"rebuf_count" xml:"rebuf_count"`
+	// Total rebuffering time
+	RebufMs uint64 `form:"rebuf_ms" json:"rebuf_ms" xml:"rebuf_ms"`
+	// Share of playback time spent rebuffering, 0—1
+	RebufRatio float64 `form:"rebuf_ratio" json:"rebuf_ratio" xml:"rebuf_ratio"`
+	Player     *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area       *string `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device     *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol   *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports uint64 `form:"reports" json:"reports" xml:"reports"`
+}
+
+// BitrateStatsResponseBody is used to define fields on response body types.
+type BitrateStatsResponseBody struct {
+	// Average media bitrate, bit/s
+	AvgBitrate float64 `form:"avg_bitrate" json:"avg_bitrate" xml:"avg_bitrate"`
+	// Median media bitrate, bit/s
+	MedianBitrate float64 `form:"median_bitrate" json:"median_bitrate" xml:"median_bitrate"`
+	// Average client bandwidth, bit/s
+	AvgBandwidth float64 `form:"avg_bandwidth" json:"avg_bandwidth" xml:"avg_bandwidth"`
+	Player       *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area         *string `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device       *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol     *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports uint64 `form:"reports" json:"reports" xml:"reports"`
+}
+
+// StartupStatsResponseBody is used to define fields on response body types.
+type StartupStatsResponseBody struct {
+	// Reports covering the first reporting window of a playback
+	Starts uint64 `form:"starts" json:"starts" xml:"starts"`
+	// Starts during which rebuffering happened
+	RebufferedStarts uint64 `form:"rebuffered_starts" json:"rebuffered_starts" xml:"rebuffered_starts"`
+	// Share of starts with rebuffering, 0—1
+	RebufferedRatio float64 `form:"rebuffered_ratio" json:"rebuffered_ratio" xml:"rebuffered_ratio"`
+	// Average rebuffering time during a start
+	AvgRebufMs float64 `form:"avg_rebuf_ms" json:"avg_rebuf_ms" xml:"avg_rebuf_ms"`
+	Player     *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	Area       *string `form:"area,omitempty" json:"area,omitempty" xml:"area,omitempty"`
+	Device     *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+	Protocol   *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Start of the time bucket
+	Time *string `form:"time,omitempty" json:"time,omitempty" xml:"time,omitempty"`
+	// Number of playback reports in the group
+	Reports uint64 `form:"reports" json:"reports" xml:"reports"`
+}
+
+// NewRebufferingResponseBody builds the HTTP response body from the result of
+// the "rebuffering" endpoint of the "qoe" service.
+func NewRebufferingResponseBody(res *qoe.RebufferingPage) *RebufferingResponseBody {
+	body := &RebufferingResponseBody{
+		Page:     res.Page,
+		PageSize: res.PageSize,
+		HasMore:  res.HasMore,
+	}
+	if res.Items != nil {
+		body.Items = make([]*RebufferingStatsResponseBody, len(res.Items))
+		for i, val := range res.Items {
+			if val == nil {
+				body.Items[i] = nil
+				continue
+			}
+			body.Items[i] = marshalQoeRebufferingStatsToRebufferingStatsResponseBody(val)
+		}
+	} else {
+		body.Items = []*RebufferingStatsResponseBody{}
+	}
+	return body
+}
+
+// NewBitrateResponseBody builds the HTTP response body from the result of the
+// "bitrate" endpoint of the "qoe" service.
+func NewBitrateResponseBody(res *qoe.BitratePage) *BitrateResponseBody {
+	body := &BitrateResponseBody{
+		Page:     res.Page,
+		PageSize: res.PageSize,
+		HasMore:  res.HasMore,
+	}
+	if res.Items != nil {
+		body.Items = make([]*BitrateStatsResponseBody, len(res.Items))
+		for i, val := range res.Items {
+			if val == nil {
+				body.Items[i] = nil
+				continue
+			}
+			body.Items[i] = marshalQoeBitrateStatsToBitrateStatsResponseBody(val)
+		}
+	} else {
+		body.Items = []*BitrateStatsResponseBody{}
+	}
+	return body
+}
+
+// NewStartupResponseBody builds the HTTP response body from the result of the
+// "startup" endpoint of the "qoe" service.
+func NewStartupResponseBody(res *qoe.StartupPage) *StartupResponseBody {
+	body := &StartupResponseBody{
+		Page:     res.Page,
+		PageSize: res.PageSize,
+		HasMore:  res.HasMore,
+	}
+	if res.Items != nil {
+		body.Items = make([]*StartupStatsResponseBody, len(res.Items))
+		for i, val := range res.Items {
+			if val == nil {
+				body.Items[i] = nil
+				continue
+			}
+			body.Items[i] = marshalQoeStartupStatsToStartupStatsResponseBody(val)
+		}
+	} else {
+		body.Items = []*StartupStatsResponseBody{}
+	}
+	return body
+}
+
+// NewRebufferingMultiFieldErrorResponseBody builds the HTTP response body from
+// the result of the "rebuffering" endpoint of the "qoe" service.
+func NewRebufferingMultiFieldErrorResponseBody(res *qoe.MultiFieldError) *RebufferingMultiFieldErrorResponseBody {
+	body := &RebufferingMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewBitrateMultiFieldErrorResponseBody builds the HTTP response body from the
+// result of the "bitrate" endpoint of the "qoe" service.
+func NewBitrateMultiFieldErrorResponseBody(res *qoe.MultiFieldError) *BitrateMultiFieldErrorResponseBody {
+	body := &BitrateMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewStartupMultiFieldErrorResponseBody builds the HTTP response body from the
+// result of the "startup" endpoint of the "qoe" service.
+func NewStartupMultiFieldErrorResponseBody(res *qoe.MultiFieldError) *StartupMultiFieldErrorResponseBody {
+	body := &StartupMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewRebufferingAggregateQuery builds a qoe service rebuffering endpoint
+// payload.
+func NewRebufferingAggregateQuery(groupBy []string, bucket string, from *string, to *string, player *string, area *string, device *string, protocol *string, page int, pageSize int, key string) *qoe.AggregateQuery {
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v
+}
+
+// NewBitrateAggregateQuery builds a qoe service bitrate endpoint payload.
+func NewBitrateAggregateQuery(groupBy []string, bucket string, from *string, to *string, player *string, area *string, device *string, protocol *string, page int, pageSize int, key string) *qoe.AggregateQuery {
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v
+}
+
+// NewStartupAggregateQuery builds a qoe service startup endpoint payload.
+func NewStartupAggregateQuery(groupBy []string, bucket string, from *string, to *string, player *string, area *string, device *string, protocol *string, page int, pageSize int, key string) *qoe.AggregateQuery {
+	v := &qoe.AggregateQuery{}
+	v.GroupBy = groupBy
+	v.Bucket = bucket
+	v.From = from
+	v.To = to
+	v.Player = player
+	v.Area = area
+	v.Device = device
+	v.Protocol = protocol
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v
+}
@@ -25,7 +25,7 @@ func BuildAddPayload(reporterAddBody string) (*reporter.PlaybackReport, error) {
 	{
 		err = json.Unmarshal([]byte(reporterAddBody), &body)
 		if err != nil {
-			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"bandwidth\": 64944106,\n      \"bitrate\": 13952061,\n      \"cache\": \"miss\",\n      \"device\": \"ios\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 1045058586,\n      \"protocol\": \"lvs\",\n      \"rebuf_count\": 2095695930,\n      \"rebuf_duration\": 38439,\n      \"rel_position\": 13,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"bandwidth\": 916523488,\n      \"bitrate\": 1993692080,\n      \"cache\": \"miss\",\n      \"device\": \"stb\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 671190817,\n      \"protocol\": \"hls\",\n      \"rebuf_count\": 469799269,\n      \"rebuf_duration\": 34743,\n      \"rel_position\": 94,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
 		}
 		if utf8.RuneCountInString(body.URL) > 512 {
 			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
@@ -96,3 +96,291 @@ func BuildAddPayload(reporterAddBody string) (*reporter.PlaybackReport, error) {
 
 	return v, nil
 }
+
+// BuildStartupPayload builds the payload for the reporter startup endpoint
+// from CLI flags.
+func BuildStartupPayload(reporterStartupBody string) (*reporter.StartupEvent, error) {
+	var err error
+	var body StartupRequestBody
+	{
+		err = json.Unmarshal([]byte(reporterStartupBody), &body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"bitrate\": 547967682,\n      \"device\": \"ios\",\n      \"player\": \"sg-p2\",\n      \"protocol\": \"stb\",\n      \"ttff\": 1200,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+		}
+		if body.Ttff < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.ttff", body.Ttff, 0, true))
+		}
+		if body.Ttff > 600000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.ttff", body.Ttff, 600000, false))
+		}
+		if body.Bitrate != nil {
+			if *body.Bitrate < 0 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("body.bitrate", *body.Bitrate, 0, true))
+			}
+		}
+		if utf8.RuneCountInString(body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
+		}
+		if !(body.Protocol == "stb" || body.Protocol == "hls" || body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+		if utf8.RuneCountInString(body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", body.Player, utf8.RuneCountInString(body.Player), 128, false))
+		}
+		if utf8.RuneCountInString(body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 1, true))
+		}
+		if utf8.RuneCountInString(body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 45, false))
+		}
+		if !(body.Device == "ios" || body.Device == "adr" || body.Device == "web" || body.Device == "dsk" || body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := &reporter.StartupEvent{
+		Ttff:     body.Ttff,
+		Bitrate:  body.Bitrate,
+		URL:      body.URL,
+		Protocol: body.Protocol,
+		Player:   body.Player,
+		UserID:   body.UserID,
+		Device:   body.Device,
+	}
+
+	return v, nil
+}
+
+// BuildPlaybackErrorPayload builds the payload for the reporter playback_error
+// endpoint from CLI flags.
+func BuildPlaybackErrorPayload(reporterPlaybackErrorBody string) (*reporter.ErrorEvent, error) {
+	var err error
+	var body PlaybackErrorRequestBody
+	{
+		err = json.Unmarshal([]byte(reporterPlaybackErrorBody), &body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"code\": \"MEDIA_ERR_NETWORK\",\n      \"device\": \"dsk\",\n      \"fatal\": true,\n      \"message\": \"p25\",\n      \"player\": \"sg-p2\",\n      \"position\": 689136710,\n      \"protocol\": \"lvs\",\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+		}
+		if body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", body.Position, 0, true))
+		}
+		if utf8.RuneCountInString(body.Code) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.code", body.Code, utf8.RuneCountInString(body.Code), 1, true))
+		}
+		if utf8.RuneCountInString(body.Code) > 64 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.code", body.Code, utf8.RuneCountInString(body.Code), 64, false))
+		}
+		if body.Message != nil {
+			if utf8.RuneCountInString(*body.Message) > 1024 {
+				err = goa.MergeErrors(err, goa.InvalidLengthError("body.message", *body.Message, utf8.RuneCountInString(*body.Message), 1024, false))
+			}
+		}
+		if utf8.RuneCountInString(body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
+		}
+		if !(body.Protocol == "stb" || body.Protocol == "hls" || body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+		if utf8.RuneCountInString(body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", body.Player, utf8.RuneCountInString(body.Player), 128, false))
+		}
+		if utf8.RuneCountInString(body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 1, true))
+		}
+		if utf8.RuneCountInString(body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 45, false))
+		}
+		if !(body.Device == "ios" || body.Device == "adr" || body.Device == "web" || body.Device == "dsk" || body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := &reporter.ErrorEvent{
+		Position: body.Position,
+		Code:     body.Code,
+		Message:  body.Message,
+		Fatal:    body.Fatal,
+		URL:      body.URL,
+		Protocol: body.Protocol,
+		Player:   body.Player,
+		UserID:   body.UserID,
+		Device:   body.Device,
+	}
+	{
+		var zero bool
+		if v.Fatal == zero {
+			v.Fatal = false
+		}
+	}
+
+	return v, nil
+}
+
+// BuildSeekPayload builds the payload for the reporter seek endpoint from CLI
+// flags.
+func BuildSeekPayload(reporterSeekBody string) (*reporter.SeekEvent, error) {
+	var err error
+	var body SeekRequestBody
+	{
+		err = json.Unmarshal([]byte(reporterSeekBody), &body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"device\": \"stb\",\n      \"from\": 776748396,\n      \"player\": \"sg-p2\",\n      \"protocol\": \"hls\",\n      \"to\": 668987034,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+		}
+		if body.From < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.from", body.From, 0, true))
+		}
+		if body.To < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.to", body.To, 0, true))
+		}
+		if utf8.RuneCountInString(body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
+		}
+		if !(body.Protocol == "stb" || body.Protocol == "hls" || body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+		if utf8.RuneCountInString(body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", body.Player, utf8.RuneCountInString(body.Player), 128, false))
+		}
+		if utf8.RuneCountInString(body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 1, true))
+		}
+		if utf8.RuneCountInString(body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 45, false))
+		}
+		if !(body.Device == "ios" || body.Device == "adr" || body.Device == "web" || body.Device == "dsk" || body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := &reporter.SeekEvent{
+		From:     body.From,
+		To:       body.To,
+		URL:      body.URL,
+		Protocol: body.Protocol,
+		Player:   body.Player,
+		UserID:   body.UserID,
+		Device:   body.Device,
+	}
+
+	return v, nil
+}
+
+// BuildQualitySwitchPayload builds the payload for the reporter quality_switch
+// endpoint from CLI flags.
+func BuildQualitySwitchPayload(reporterQualitySwitchBody string) (*reporter.QualitySwitchEvent, error) {
+	var err error
+	var body QualitySwitchRequestBody
+	{
+		err = json.Unmarshal([]byte(reporterQualitySwitchBody), &body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"device\": \"web\",\n      \"from_bitrate\": 1631807523,\n      \"player\": \"sg-p2\",\n      \"position\": 1896972294,\n      \"protocol\": \"lvs\",\n      \"reason\": \"manual\",\n      \"to_bitrate\": 1881035567,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+		}
+		if body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", body.Position, 0, true))
+		}
+		if body.FromBitrate < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.from_bitrate", body.FromBitrate, 0, true))
+		}
+		if body.ToBitrate < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.to_bitrate", body.ToBitrate, 0, true))
+		}
+		if !(body.Reason == "auto" || body.Reason == "manual") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.reason", body.Reason, []any{"auto", "manual"}))
+		}
+		if utf8.RuneCountInString(body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
+		}
+		if !(body.Protocol == "stb" || body.Protocol == "hls" || body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+		if utf8.RuneCountInString(body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", body.Player, utf8.RuneCountInString(body.Player), 128, false))
+		}
+		if utf8.RuneCountInString(body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 1, true))
+		}
+		if utf8.RuneCountInString(body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 45, false))
+		}
+		if !(body.Device == "ios" || body.Device == "adr" || body.Device == "web" || body.Device == "dsk" || body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := &reporter.QualitySwitchEvent{
+		Position:    body.Position,
+		FromBitrate: body.FromBitrate,
+		ToBitrate:   body.ToBitrate,
+		Reason:      body.Reason,
+		URL:         body.URL,
+		Protocol:    body.Protocol,
+		Player:      body.Player,
+		UserID:      body.UserID,
+		Device:      body.Device,
+	}
+
+	return v, nil
+}
+
+// BuildSessionEndPayload builds the payload for the reporter session_end
+// endpoint from CLI flags.
+func BuildSessionEndPayload(reporterSessionEndBody string) (*reporter.SessionEndEvent, error) {
+	var err error
+	var body SessionEndRequestBody
+	{
+		err = json.Unmarshal([]byte(reporterSessionEndBody), &body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"device\": \"dsk\",\n      \"player\": \"sg-p2\",\n      \"position\": 1762124359,\n      \"protocol\": \"lvs\",\n      \"reason\": \"completed\",\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\",\n      \"watched\": 1164577066\n   }'")
+		}
+		if body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", body.Position, 0, true))
+		}
+		if body.Watched < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.watched", body.Watched, 0, true))
+		}
+		if !(body.Reason == "completed" || body.Reason == "closed" || body.Reason == "navigated" || body.Reason == "error") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.reason", body.Reason, []any{"completed", "closed", "navigated", "error"}))
+		}
+		if utf8.RuneCountInString(body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
+		}
+		if !(body.Protocol == "stb" || body.Protocol == "hls" || body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+		if utf8.RuneCountInString(body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", body.Player, utf8.RuneCountInString(body.Player), 128, false))
+		}
+		if utf8.RuneCountInString(body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 1, true))
+		}
+		if utf8.RuneCountInString(body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", body.UserID, utf8.RuneCountInString(body.UserID), 45, false))
+		}
+		if !(body.Device == "ios" || body.Device == "adr" || body.Device == "web" || body.Device == "dsk" || body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := &reporter.SessionEndEvent{
+		Position: body.Position,
+		Watched:  body.Watched,
+		Reason:   body.Reason,
+		URL:      body.URL,
+		Protocol: body.Protocol,
+		Player:   body.Player,
+		UserID:   body.UserID,
+		Device:   body.Device,
+	}
+
+	return v, nil
+}
@@ -21,6 +21,25 @@ type Client struct {
 	// Add Doer is the HTTP client used to make requests to the add endpoint.
 	AddDoer goahttp.Doer
 
+	// Startup Doer is the HTTP client used to make requests to the startup
+	// endpoint.
+	StartupDoer goahttp.Doer
+
+	// PlaybackError Doer is the HTTP client used to make requests to the
+	// playback_error endpoint.
+	PlaybackErrorDoer goahttp.Doer
+
+	// Seek Doer is the HTTP client used to make requests to the seek endpoint.
+	SeekDoer goahttp.Doer
+
+	// QualitySwitch Doer is the HTTP client used to make requests to the
+	// quality_switch endpoint.
+	QualitySwitchDoer goahttp.Doer
+
+	// SessionEnd Doer is the HTTP client used to make requests to the session_end
+	// endpoint.
+	SessionEndDoer goahttp.Doer
+
 	// Healthz Doer is the HTTP client used to make requests to the healthz
 	// endpoint.
 	HealthzDoer goahttp.Doer
@@ -49,6 +68,11 @@ func NewClient(
 ) *Client {
 	return &Client{
 		AddDoer:             doer,
+		StartupDoer:         doer,
+		PlaybackErrorDoer:   doer,
+		SeekDoer:            doer,
+		QualitySwitchDoer:   doer,
+		SessionEndDoer:      doer,
 		HealthzDoer:         doer,
 		CORSDoer:            doer,
 		RestoreResponseBody: restoreBody,
@@ -83,6 +107,126 @@ func (c *Client) Add() goa.Endpoint {
 	}
 }
 
+// Startup returns an endpoint that makes HTTP requests to the reporter service
+// startup server.
+func (c *Client) Startup() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeStartupRequest(c.encoder)
+		decodeResponse = DecodeStartupResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildStartupRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.StartupDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "startup", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// PlaybackError returns an endpoint that makes HTTP requests to the reporter
+// service playback_error server.
+func (c *Client) PlaybackError() goa.Endpoint {
+	var (
+		encodeRequest  = EncodePlaybackErrorRequest(c.encoder)
+		decodeResponse = DecodePlaybackErrorResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildPlaybackErrorRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.PlaybackErrorDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "playback_error", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// Seek returns an endpoint that makes HTTP requests to the reporter service
+// seek server.
+func (c *Client) Seek() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeSeekRequest(c.encoder)
+		decodeResponse = DecodeSeekResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildSeekRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.SeekDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "seek", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// QualitySwitch returns an endpoint that makes HTTP requests to the reporter
+// service quality_switch server.
+func (c *Client) QualitySwitch() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeQualitySwitchRequest(c.encoder)
+		decodeResponse = DecodeQualitySwitchResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildQualitySwitchRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.QualitySwitchDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "quality_switch", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
+// SessionEnd returns an endpoint that makes HTTP requests to the reporter
+// service session_end server.
+func (c *Client) SessionEnd() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeSessionEndRequest(c.encoder)
+		decodeResponse = DecodeSessionEndResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v any) (any, error) {
+		req, err := c.BuildSessionEndRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.SessionEndDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "session_end", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
 // Healthz returns an endpoint that makes HTTP requests to the reporter service
 // healthz server.
 func (c *Client) Healthz() goa.Endpoint {
@@ -55,6 +55,7 @@ func EncodeAddRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Re
 // restored after having been read.
 // DecodeAddResponse may return the following errors:
 //   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
 //   - error: internal error
 func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
 	return func(resp *http.Response) (any, error) {
@@ -87,6 +88,16 @@ func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody
 				return nil, goahttp.ErrValidationError("reporter", "add", err)
 			}
 			return nil, NewAddMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "add", err)
+			}
+			return nil, NewAddUnavailable(body)
 		default:
 			body, _ := io.ReadAll(resp.Body)
 			return nil, goahttp.ErrInvalidResponse("reporter", "add", resp.StatusCode, string(body))
@@ -94,6 +105,436 @@ func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody
 	}
 }
 
+// BuildStartupRequest instantiates a HTTP request object with method and path
+// set to call the "reporter" service "startup" endpoint
+func (c *Client) BuildStartupRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: StartupReporterPath()}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "startup", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeStartupRequest returns an encoder for requests sent to the reporter
+// startup server.
+func EncodeStartupRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*reporter.StartupEvent)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "startup", "*reporter.StartupEvent", v)
+		}
+		body := NewStartupRequestBody(p)
+		if err := encoder(req).Encode(&body); err != nil {
+			return goahttp.ErrEncodingError("reporter", "startup", err)
+		}
+		return nil
+	}
+}
+
+// DecodeStartupResponse returns a decoder for responses returned by the
+// reporter startup endpoint. restoreBody controls whether the response body
+// should be restored after having been read.
+// DecodeStartupResponse may return the following errors:
+//   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
+func DecodeStartupResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			return nil, nil
+		case http.StatusBadRequest:
+			var (
+				body StartupMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "startup", err)
+			}
+			err = ValidateStartupMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "startup", err)
+			}
+			return nil, NewStartupMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "startup", err)
+			}
+			return nil, NewStartupUnavailable(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "startup", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildPlaybackErrorRequest instantiates a HTTP request object with method and
+// path set to call the "reporter" service "playback_error" endpoint
+func (c *Client) BuildPlaybackErrorRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: PlaybackErrorReporterPath()}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "playback_error", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodePlaybackErrorRequest returns an encoder for requests sent to the
+// reporter playback_error server.
+func EncodePlaybackErrorRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*reporter.ErrorEvent)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "playback_error", "*reporter.ErrorEvent", v)
+		}
+		body := NewPlaybackErrorRequestBody(p)
+		if err := encoder(req).Encode(&body); err != nil {
+			return goahttp.ErrEncodingError("reporter", "playback_error", err)
+		}
+		return nil
+	}
+}
+
+// DecodePlaybackErrorResponse returns a decoder for responses returned by the
+// reporter playback_error endpoint. restoreBody controls whether the response
+// body should be restored after having been read.
+// DecodePlaybackErrorResponse may return the following errors:
+//   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
+func DecodePlaybackErrorResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			return nil, nil
+		case http.StatusBadRequest:
+			var (
+				body PlaybackErrorMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "playback_error", err)
+			}
+			err = ValidatePlaybackErrorMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "playback_error", err)
+			}
+			return nil, NewPlaybackErrorMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "playback_error", err)
+			}
+			return nil, NewPlaybackErrorUnavailable(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "playback_error", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildSeekRequest instantiates a HTTP request object with method and path set
+// to call the "reporter" service "seek" endpoint
+func (c *Client) BuildSeekRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: SeekReporterPath()}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "seek", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeSeekRequest returns an encoder for requests sent to the reporter seek
+// server.
+func EncodeSeekRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*reporter.SeekEvent)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "seek", "*reporter.SeekEvent", v)
+		}
+		body := NewSeekRequestBody(p)
+		if err := encoder(req).Encode(&body); err != nil {
+			return goahttp.ErrEncodingError("reporter", "seek", err)
+		}
+		return nil
+	}
+}
+
+// DecodeSeekResponse returns a decoder for responses returned by the reporter
+// seek endpoint. restoreBody controls whether the response body should be
+// restored after having been read.
+// DecodeSeekResponse may return the following errors:
+//   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
+func DecodeSeekResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			return nil, nil
+		case http.StatusBadRequest:
+			var (
+				body SeekMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "seek", err)
+			}
+			err = ValidateSeekMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "seek", err)
+			}
+			return nil, NewSeekMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "seek", err)
+			}
+			return nil, NewSeekUnavailable(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "seek", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildQualitySwitchRequest instantiates a HTTP request object with method and
+// path set to call the "reporter" service "quality_switch" endpoint
+func (c *Client) BuildQualitySwitchRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: QualitySwitchReporterPath()}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "quality_switch", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeQualitySwitchRequest returns an encoder for requests sent to the
+// reporter quality_switch server.
+func EncodeQualitySwitchRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*reporter.QualitySwitchEvent)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "quality_switch", "*reporter.QualitySwitchEvent", v)
+		}
+		body := NewQualitySwitchRequestBody(p)
+		if err := encoder(req).Encode(&body); err != nil {
+			return goahttp.ErrEncodingError("reporter", "quality_switch", err)
+		}
+		return nil
+	}
+}
+
+// DecodeQualitySwitchResponse returns a decoder for responses returned by the
+// reporter quality_switch endpoint. restoreBody controls whether the response
+// body should be restored after having been read.
+// DecodeQualitySwitchResponse may return the following errors:
+//   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
+func DecodeQualitySwitchResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			return nil, nil
+		case http.StatusBadRequest:
+			var (
+				body QualitySwitchMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "quality_switch", err)
+			}
+			err = ValidateQualitySwitchMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "quality_switch", err)
+			}
+			return nil, NewQualitySwitchMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "quality_switch", err)
+			}
+			return nil, NewQualitySwitchUnavailable(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "quality_switch", resp.StatusCode, string(body))
+		}
+	}
+}
+
+// BuildSessionEndRequest instantiates a HTTP request object with method and
+// path set to call the "reporter" service "session_end" endpoint
+func (c *Client) BuildSessionEndRequest(ctx context.Context, v any) (*http.Request, error) {
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: SessionEndReporterPath()}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "session_end", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeSessionEndRequest returns an encoder for requests sent to the reporter
+// session_end server.
+func EncodeSessionEndRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, any) error {
+	return func(req *http.Request, v any) error {
+		p, ok := v.(*reporter.SessionEndEvent)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "session_end", "*reporter.SessionEndEvent", v)
+		}
+		body := NewSessionEndRequestBody(p)
+		if err := encoder(req).Encode(&body); err != nil {
+			return goahttp.ErrEncodingError("reporter", "session_end", err)
+		}
+		return nil
+	}
+}
+
+// DecodeSessionEndResponse returns a decoder for responses returned by the
+// reporter session_end endpoint. restoreBody controls whether the response
+// body should be restored after having been read.
+// DecodeSessionEndResponse may return the following errors:
+//   - "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
+func DecodeSessionEndResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
+	return func(resp *http.Response) (any, error) {
+		if restoreBody {
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = io.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			return nil, nil
+		case http.StatusBadRequest:
+			var (
+				body SessionEndMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "session_end", err)
+			}
+			err = ValidateSessionEndMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "session_end", err)
+			}
+			return nil, NewSessionEndMultiFieldError(&body)
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "session_end", err)
+			}
+			return nil, NewSessionEndUnavailable(body)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "session_end", resp.StatusCode, string(body))
+		}
+	}
+}
+
 // BuildHealthzRequest instantiates a HTTP request object with method and path
 // set to call the "reporter" service "healthz" endpoint
 func (c *Client) BuildHealthzRequest(ctx context.Context, v any) (*http.Request, error) {
@@ -112,6 +553,9 @@ func (c *Client) BuildHealthzRequest(ctx context.Context, v any) (*http.Request,
 // DecodeHealthzResponse returns a decoder for responses returned by the
 // reporter healthz endpoint. restoreBody controls whether the response body
 // should be restored after having been read.
+// DecodeHealthzResponse may return the following errors:
+//   - "unavailable" (type reporter.Unavailable): http.StatusServiceUnavailable
+//   - error: internal error
 func DecodeHealthzResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (any, error) {
 	return func(resp *http.Response) (any, error) {
 		if restoreBody {
@@ -137,6 +581,16 @@ func DecodeHealthzResponse(decoder func(*http.Response) goahttp.Decoder, restore
 				return nil, goahttp.ErrDecodingError("reporter", "healthz", err)
 			}
 			return body, nil
+		case http.StatusServiceUnavailable:
+			var (
+				body string
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "healthz", err)
+			}
+			return nil, NewHealthzUnavailable(body)
 		default:
 			body, _ := io.ReadAll(resp.Body)
 			return nil, goahttp.ErrInvalidResponse("reporter", "healthz", resp.StatusCode, string(body))
@@ -45,12 +45,149 @@ type AddRequestBody struct {
 	Device string `form:"device" json:"device" xml:"device"`
 }
 
+// StartupRequestBody is the type of the "reporter" service "startup" endpoint
+// HTTP request body.
+type StartupRequestBody struct {
+	// Time to first frame since the playback was requested, ms
+	Ttff int32 `form:"ttff" json:"ttff" xml:"ttff"`
+	// Media bitrate of the initial rendition, bit/s
+	Bitrate *int32 `form:"bitrate,omitempty" json:"bitrate,omitempty" xml:"bitrate,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL string `form:"url" json:"url" xml:"url"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string `form:"protocol" json:"protocol" xml:"protocol"`
+	// Player server name
+	Player string `form:"player" json:"player" xml:"player"`
+	// User ID
+	UserID string `form:"user_id" json:"user_id" xml:"user_id"`
+	// Client device
+	Device string `form:"device" json:"device" xml:"device"`
+}
+
+// PlaybackErrorRequestBody is the type of the "reporter" service
+// "playback_error" endpoint HTTP request body.
+type PlaybackErrorRequestBody struct {
+	// Stream position the error happened at, ms
+	Position int32 `form:"position" json:"position" xml:"position"`
+	// Player error code
+	Code string `form:"code" json:"code" xml:"code"`
+	// Error message
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+	// Whether the error stopped the playback
+	Fatal bool `form:"fatal" json:"fatal" xml:"fatal"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL string `form:"url" json:"url" xml:"url"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string `form:"protocol" json:"protocol" xml:"protocol"`
+	// Player server name
+	Player string `form:"player" json:"player" xml:"player"`
+	// User ID
+	UserID string `form:"user_id" json:"user_id" xml:"user_id"`
+	// Client device
+	Device string `form:"device" json:"device" xml:"device"`
+}
+
+// SeekRequestBody is the type of the "reporter" service "seek" endpoint HTTP
+// request body.
+type SeekRequestBody struct {
+	// Stream position before the seek, ms
+	From int32 `form:"from" json:"from" xml:"from"`
+	// Stream position after the seek, ms
+	To int32 `form:"to" json:"to" xml:"to"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL string `form:"url" json:"url" xml:"url"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string `form:"protocol" json:"protocol" xml:"protocol"`
+	// Player server name
+	Player string `form:"player" json:"player" xml:"player"`
+	// User ID
+	UserID string `form:"user_id" json:"user_id" xml:"user_id"`
+	// Client device
+	Device string `form:"device" json:"device" xml:"device"`
+}
+
+// QualitySwitchRequestBody is the type of the "reporter" service
+// "quality_switch" endpoint HTTP request body.
+type QualitySwitchRequestBody struct {
+	// Stream position the switch happened at, ms
+	Position int32 `form:"position" json:"position" xml:"position"`
+	// Media bitrate before the switch, bit/s
+	FromBitrate int32 `form:"from_bitrate" json:"from_bitrate" xml:"from_bitrate"`
+	// Media bitrate after the switch, bit/s
+	ToBitrate int32 `form:"to_bitrate" json:"to_bitrate" xml:"to_bitrate"`
+	// Whether the switch was made by adaptive bitrate logic or by the user
+	Reason string `form:"reason" json:"reason" xml:"reason"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL string `form:"url" json:"url" xml:"url"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string `form:"protocol" json:"protocol" xml:"protocol"`
+	// Player server name
+	Player string `form:"player" json:"player" xml:"player"`
+	// User ID
+	UserID string `form:"user_id" json:"user_id" xml:"user_id"`
+	// Client device
+	Device string `form:"device" json:"device" xml:"device"`
+}
+
+// SessionEndRequestBody is the type of the "reporter" service "session_end"
+// endpoint HTTP request body.
+type SessionEndRequestBody struct {
+	// Stream position at the end of the session, ms
+	Position int32 `form:"position" json:"position" xml:"position"`
+	// Total time spent playing during the session, ms
+	Watched int32 `form:"watched" json:"watched" xml:"watched"`
+	// Why the session ended
+	Reason string `form:"reason" json:"reason" xml:"reason"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL string `form:"url" json:"url" xml:"url"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string `form:"protocol" json:"protocol" xml:"protocol"`
+	// Player server name
+	Player string `form:"player" json:"player" xml:"player"`
+	// User ID
+	UserID string `form:"user_id" json:"user_id" xml:"user_id"`
+	// Client device
+	Device string `form:"device" json:"device" xml:"device"`
+}
+
 // AddMultiFieldErrorResponseBody is the type of the "reporter" service "add"
 // endpoint HTTP response body for the "multi_field_error" error.
 type AddMultiFieldErrorResponseBody struct {
 	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
 }
 
+// StartupMultiFieldErrorResponseBody is the type of the "reporter" service
+// "startup" endpoint HTTP response body for the "multi_field_error" error.
+type StartupMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// PlaybackErrorMultiFieldErrorResponseBody is the type of the "reporter"
+// service "playback_error" endpoint HTTP response body for the
+// "multi_field_error" error.
+type PlaybackErrorMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// SeekMultiFieldErrorResponseBody is the type of the "reporter" service "seek"
+// endpoint HTTP response body for the "multi_field_error" error.
+type SeekMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// QualitySwitchMultiFieldErrorResponseBody is the type of the "reporter"
+// service "quality_switch" endpoint HTTP response body for the
+// "multi_field_error" error.
+type QualitySwitchMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// SessionEndMultiFieldErrorResponseBody is the type of the "reporter" service
+// "session_end" endpoint HTTP response body for the "multi_field_error" error.
+type SessionEndMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
 // NewAddRequestBody builds the HTTP request body from the payload of the "add"
 // endpoint of the "reporter" service.
 func NewAddRequestBody(p *reporter.PlaybackReport) *AddRequestBody {
@@ -72,6 +209,92 @@ func NewAddRequestBody(p *reporter.PlaybackReport) *AddRequestBody {
 	return body
 }
 
+// NewStartupRequestBody builds the HTTP request body from the payload of the
+// "startup" endpoint of the "reporter" service.
+func NewStartupRequestBody(p *reporter.StartupEvent) *StartupRequestBody {
+	body := &StartupRequestBody{
+		Ttff:     p.Ttff,
+		Bitrate:  p.Bitrate,
+		URL:      p.URL,
+		Protocol: p.Protocol,
+		Player:   p.Player,
+		UserID:   p.UserID,
+		Device:   p.Device,
+	}
+	return body
+}
+
+// NewPlaybackErrorRequestBody builds the HTTP request body from the payload of
+// the "playback_error" endpoint of the "reporter" service.
+func NewPlaybackErrorRequestBody(p *reporter.ErrorEvent) *PlaybackErrorRequestBody {
+	body := &PlaybackErrorRequestBody{
+		Position: p.Position,
+		Code:     p.Code,
+		Message:  p.Message,
+		Fatal:    p.Fatal,
+		URL:      p.URL,
+		Protocol: p.Protocol,
+		Player:   p.Player,
+		UserID:   p.UserID,
+		Device:   p.Device,
+	}
+	{
+		var zero bool
+		if body.Fatal == zero {
+			body.Fatal = false
+		}
+	}
+	return body
+}
+
+// NewSeekRequestBody builds the HTTP request body from the payload of the
+// "seek" endpoint of the "reporter" service.
+func NewSeekRequestBody(p *reporter.SeekEvent) *SeekRequestBody {
+	body := &SeekRequestBody{
+		From:     p.From,
+		To:       p.To,
+		URL:      p.URL,
+		Protocol: p.Protocol,
+		Player:   p.Player,
+		UserID:   p.UserID,
+		Device:   p.Device,
+	}
+	return body
+}
+
+// NewQualitySwitchRequestBody builds the HTTP request body from the payload of
+// the "quality_switch" endpoint of the "reporter" service.
+func NewQualitySwitchRequestBody(p *reporter.QualitySwitchEvent) *QualitySwitchRequestBody {
+	body := &QualitySwitchRequestBody{
+		Position:    p.Position,
+		FromBitrate: p.FromBitrate,
+		ToBitrate:   p.ToBitrate,
+		Reason:      p.Reason,
+		URL:         p.URL,
+		Protocol:    p.Protocol,
+		Player:      p.Player,
+		UserID:      p.UserID,
+		Device:      p.Device,
+	}
+	return body
+}
+
+// NewSessionEndRequestBody builds the HTTP request body from the payload of
+// the "session_end" endpoint of the "reporter" service.
+func NewSessionEndRequestBody(p *reporter.SessionEndEvent) *SessionEndRequestBody {
+	body := &SessionEndRequestBody{
+		Position: p.Position,
+		Watched:  p.Watched,
+		Reason:   p.Reason,
+		URL:      p.URL,
+		Protocol: p.Protocol,
+		Player:   p.Player,
+		UserID:   p.UserID,
+		Device:   p.Device,
+	}
+	return body
+}
+
 // NewAddMultiFieldError builds a reporter service add endpoint
 // multi_field_error error.
 func NewAddMultiFieldError(body *AddMultiFieldErrorResponseBody) *reporter.MultiFieldError {
@@ -82,6 +305,110 @@ func NewAddMultiFieldError(body *AddMultiFieldErrorResponseBody) *reporter.Multi
 	return v
 }
 
+// NewAddUnavailable builds a reporter service add endpoint unavailable error.
+func NewAddUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewStartupMultiFieldError builds a reporter service startup endpoint
+// multi_field_error error.
+func NewStartupMultiFieldError(body *StartupMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewStartupUnavailable builds a reporter service startup endpoint unavailable
+// error.
+func NewStartupUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewPlaybackErrorMultiFieldError builds a reporter service playback_error
+// endpoint multi_field_error error.
+func NewPlaybackErrorMultiFieldError(body *PlaybackErrorMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewPlaybackErrorUnavailable builds a reporter service playback_error
+// endpoint unavailable error.
+func NewPlaybackErrorUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewSeekMultiFieldError builds a reporter service seek endpoint
+// multi_field_error error.
+func NewSeekMultiFieldError(body *SeekMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewSeekUnavailable builds a reporter service seek endpoint unavailable error.
+func NewSeekUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewQualitySwitchMultiFieldError builds a reporter service quality_switch
+// endpoint multi_field_error error.
+func NewQualitySwitchMultiFieldError(body *QualitySwitchMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewQualitySwitchUnavailable builds a reporter service quality_switch
+// endpoint unavailable error.
+func NewQualitySwitchUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewSessionEndMultiFieldError builds a reporter service session_end endpoint
+// multi_field_error error.
+func NewSessionEndMultiFieldError(body *SessionEndMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewSessionEndUnavailable builds a reporter service session_end endpoint
+// unavailable error.
+func NewSessionEndUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
+// NewHealthzUnavailable builds a reporter service healthz endpoint unavailable
+// error.
+func NewHealthzUnavailable(body string) reporter.Unavailable {
+	v := reporter.Unavailable(body)
+
+	return v
+}
+
 // ValidateAddMultiFieldErrorResponseBody runs the validations defined on
 // add_multi_field_error_response_body
 func ValidateAddMultiFieldErrorResponseBody(body *AddMultiFieldErrorResponseBody) (err error) {
@@ -90,3 +417,48 @@ func ValidateAddMultiFieldErrorResponseBody(body *AddMultiFieldErrorResponseBody
 	}
 	return
 }
+
+// ValidateStartupMultiFieldErrorResponseBody runs the validations defined on
+// startup_multi_field_error_response_body
+func ValidateStartupMultiFieldErrorResponseBody(body *StartupMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidatePlaybackErrorMultiFieldErrorResponseBody runs the validations
+// defined on playback_error_multi_field_error_response_body
+func ValidatePlaybackErrorMultiFieldErrorResponseBody(body *PlaybackErrorMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateSeekMultiFieldErrorResponseBody runs the validations defined on
+// seek_multi_field_error_response_body
+func ValidateSeekMultiFieldErrorResponseBody(body *SeekMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateQualitySwitchMultiFieldErrorResponseBody runs the validations
+// defined on quality_switch_multi_field_error_response_body
+func ValidateQualitySwitchMultiFieldErrorResponseBody(body *QualitySwitchMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateSessionEndMultiFieldErrorResponseBody runs the validations defined
+// on session_end_multi_field_error_response_body
+func ValidateSessionEndMultiFieldErrorResponseBody(body *SessionEndMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
@@ -13,6 +13,31 @@ func AddReporterPath() string {
 	return "/reports/playback"
 }
 
+// StartupReporterPath returns the URL path to the reporter service startup HTTP endpoint.
+func StartupReporterPath() string {
+	return "/reports/startup"
+}
+
+// PlaybackErrorReporterPath returns the URL path to the reporter service playback_error HTTP endpoint.
+func PlaybackErrorReporterPath() string {
+	return "/reports/error"
+}
+
+// SeekReporterPath returns the URL path to the reporter service seek HTTP endpoint.
+func SeekReporterPath() string {
+	return "/reports/seek"
+}
+
+// QualitySwitchReporterPath returns the URL path to the reporter service quality_switch HTTP endpoint.
+func QualitySwitchReporterPath() string {
+	return "/reports/quality"
+}
+
+// SessionEndReporterPath returns the URL path to the reporter service session_end HTTP endpoint.
+func SessionEndReporterPath() string {
+	return "/reports/end"
+}
+
 // HealthzReporterPath returns the URL path to the reporter service healthz HTTP endpoint.
 func HealthzReporterPath() string {
 	return "/healthz"
@@ -21,10 +21,15 @@ import (
 
 // Server lists the reporter service endpoint HTTP handlers.
 type Server struct {
-	Mounts  []*MountPoint
-	Add     http.Handler
-	Healthz http.Handler
-	CORS    http.Handler
+	Mounts        []*MountPoint
+	Add           http.Handler
+	Startup       http.Handler
+	PlaybackError http.Handler
+	Seek          http.Handler
+	QualitySwitch http.Handler
+	SessionEnd    http.Handler
+	Healthz       http.Handler
+	CORS          http.Handler
 }
 
 // MountPoint holds information about the mounted endpoints.
@@ -55,13 +60,28 @@ func New(
 	return &Server{
 		Mounts: []*MountPoint{
 			{"Add", "POST", "/reports/playback"},
+			{"Startup", "POST", "/reports/startup"},
+			{"PlaybackError", "POST", "/reports/error"},
+			{"Seek", "POST", "/reports/seek"},
+			{"QualitySwitch", "POST", "/reports/quality"},
+			{"SessionEnd", "POST", "/reports/end"},
 			{"Healthz", "GET", "/healthz"},
 			{"CORS", "OPTIONS", "/reports/playback"},
+			{"CORS", "OPTIONS", "/reports/startup"},
+			{"CORS", "OPTIONS", "/reports/error"},
+			{"CORS", "OPTIONS", "/reports/seek"},
+			{"CORS", "OPTIONS", "/reports/quality"},
+			{"CORS", "OPTIONS", "/reports/end"},
 			{"CORS", "OPTIONS", "/healthz"},
 		},
-		Add:     NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
-		Healthz: NewHealthzHandler(e.Healthz, mux, decoder, encoder, errhandler, formatter),
-		CORS:    NewCORSHandler(),
+		Add:           NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
+		Startup:       NewStartupHandler(e.Startup, mux, decoder, encoder, errhandler, formatter),
+		PlaybackError: NewPlaybackErrorHandler(e.PlaybackError, mux, decoder, encoder, errhandler, formatter),
+		Seek:          NewSeekHandler(e.Seek, mux, decoder, encoder, errhandler, formatter),
+		QualitySwitch: NewQualitySwitchHandler(e.QualitySwitch, mux, decoder, encoder, errhandler, formatter),
+		SessionEnd:    NewSessionEndHandler(e.SessionEnd, mux, decoder, encoder, errhandler, formatter),
+		Healthz:       NewHealthzHandler(e.Healthz, mux, decoder, encoder, errhandler, formatter),
+		CORS:          NewCORSHandler(),
 	}
 }
 
@@ -71,6 +91,11 @@ func (s *Server) Service() string { return "reporter" }
 // Use wraps the server handlers with the given middleware.
 func (s *Server) Use(m func(http.Handler) http.Handler) {
 	s.Add = m(s.Add)
+	s.Startup = m(s.Startup)
+	s.PlaybackError = m(s.PlaybackError)
+	s.Seek = m(s.Seek)
+	s.QualitySwitch = m(s.QualitySwitch)
+	s.SessionEnd = m(s.SessionEnd)
 	s.Healthz = m(s.Healthz)
 	s.CORS = m(s.CORS)
 }
@@ -81,6 +106,11 @@ func (s *Server) MethodNames() []string { return reporter.MethodNames[:] }
 // Mount configures the mux to serve the reporter endpoints.
 func Mount(mux goahttp.Muxer, h *Server) {
 	MountAddHandler(mux, h.Add)
+	MountStartupHandler(mux, h.Startup)
+	MountPlaybackErrorHandler(mux, h.PlaybackError)
+	MountSeekHandler(mux, h.Seek)
+	MountQualitySwitchHandler(mux, h.QualitySwitch)
+	MountSessionEndHandler(mux, h.SessionEnd)
 	MountHealthzHandler(mux, h.Healthz)
 	MountCORSHandler(mux, h.CORS)
 }
@@ -143,6 +173,271 @@ func NewAddHandler(
 	})
 }
 
+// MountStartupHandler configures the mux to serve the "reporter" service
+// "startup" endpoint.
+func MountStartupHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("POST", "/reports/startup", f)
+}
+
+// NewStartupHandler creates a HTTP handler which loads the HTTP request and
+// calls the "reporter" service "startup" endpoint.
+func NewStartupHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeStartupRequest(mux, decoder)
+		encodeResponse = EncodeStartupResponse(encoder)
+		encodeError    = EncodeStartupError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "startup")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountPlaybackErrorHandler configures the mux to serve the "reporter" service
+// "playback_error" endpoint.
+func MountPlaybackErrorHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("POST", "/reports/error", f)
+}
+
+// NewPlaybackErrorHandler creates a HTTP handler which loads the HTTP request
+// and calls the "reporter" service "playback_error" endpoint.
+func NewPlaybackErrorHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodePlaybackErrorRequest(mux, decoder)
+		encodeResponse = EncodePlaybackErrorResponse(encoder)
+		encodeError    = EncodePlaybackErrorError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "playback_error")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountSeekHandler configures the mux to serve the "reporter" service "seek"
+// endpoint.
+func MountSeekHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("POST", "/reports/seek", f)
+}
+
+// NewSeekHandler creates a HTTP handler which loads the HTTP request and calls
+// the "reporter" service "seek" endpoint.
+func NewSeekHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeSeekRequest(mux, decoder)
+		encodeResponse = EncodeSeekResponse(encoder)
+		encodeError    = EncodeSeekError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "seek")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountQualitySwitchHandler configures the mux to serve the "reporter" service
+// "quality_switch" endpoint.
+func MountQualitySwitchHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("POST", "/reports/quality", f)
+}
+
+// NewQualitySwitchHandler creates a HTTP handler which loads the HTTP request
+// and calls the "reporter" service "quality_switch" endpoint.
+func NewQualitySwitchHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeQualitySwitchRequest(mux, decoder)
+		encodeResponse = EncodeQualitySwitchResponse(encoder)
+		encodeError    = EncodeQualitySwitchError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "quality_switch")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
+// MountSessionEndHandler configures the mux to serve the "reporter" service
+// "session_end" endpoint.
+func MountSessionEndHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("POST", "/reports/end", f)
+}
+
+// NewSessionEndHandler creates a HTTP handler which loads the HTTP request and
+// calls the "reporter" service "session_end" endpoint.
+func NewSessionEndHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(ctx context.Context, err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeSessionEndRequest(mux, decoder)
+		encodeResponse = EncodeSessionEndResponse(encoder)
+		encodeError    = EncodeSessionEndError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "session_end")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil && errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			if errhandler != nil {
+				errhandler(ctx, w, err)
+			}
+		}
+	})
+}
+
 // MountHealthzHandler configures the mux to serve the "reporter" service
 // "healthz" endpoint.
 func MountHealthzHandler(mux goahttp.Muxer, h http.Handler) {
@@ -167,7 +462,7 @@ func NewHealthzHandler(
 ) http.Handler {
 	var (
 		encodeResponse = EncodeHealthzResponse(encoder)
-		encodeError    = goahttp.ErrorEncoder(encoder, formatter)
+		encodeError    = EncodeHealthzError(encoder, formatter)
 	)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
@@ -194,6 +489,11 @@ func NewHealthzHandler(
 func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
 	h = HandleReporterOrigin(h)
 	mux.Handle("OPTIONS", "/reports/playback", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/reports/startup", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/reports/error", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/reports/seek", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/reports/quality", h.ServeHTTP)
+	mux.Handle("OPTIONS", "/reports/end", h.ServeHTTP)
 	mux.Handle("OPTIONS", "/healthz", h.ServeHTTP)
 }
 
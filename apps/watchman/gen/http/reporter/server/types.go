@@ -47,12 +47,149 @@ type AddRequestBody struct {
 	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
 }
 
+// StartupRequestBody is the type of the "reporter" service "startup" endpoint
+// HTTP request body.
+type StartupRequestBody struct {
+	// Time to first frame since the playback was requested, ms
+	Ttff *int32 `form:"ttff,omitempty" json:"ttff,omitempty" xml:"ttff,omitempty"`
+	// Media bitrate of the initial rendition, bit/s
+	Bitrate *int32 `form:"bitrate,omitempty" json:"bitrate,omitempty" xml:"bitrate,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL *string `form:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Player server name
+	Player *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	// User ID
+	UserID *string `form:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty"`
+	// Client device
+	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+}
+
+// PlaybackErrorRequestBody is the type of the "reporter" service
+// "playback_error" endpoint HTTP request body.
+type PlaybackErrorRequestBody struct {
+	// Stream position the error happened at, ms
+	Position *int32 `form:"position,omitempty" json:"position,omitempty" xml:"position,omitempty"`
+	// Player error code
+	Code *string `form:"code,omitempty" json:"code,omitempty" xml:"code,omitempty"`
+	// Error message
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+	// Whether the error stopped the playback
+	Fatal *bool `form:"fatal,omitempty" json:"fatal,omitempty" xml:"fatal,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL *string `form:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Player server name
+	Player *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	// User ID
+	UserID *string `form:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty"`
+	// Client device
+	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+}
+
+// SeekRequestBody is the type of the "reporter" service "seek" endpoint HTTP
+// request body.
+type SeekRequestBody struct {
+	// Stream position before the seek, ms
+	From *int32 `form:"from,omitempty" json:"from,omitempty" xml:"from,omitempty"`
+	// Stream position after the seek, ms
+	To *int32 `form:"to,omitempty" json:"to,omitempty" xml:"to,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL *string `form:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Player server name
+	Player *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	// User ID
+	UserID *string `form:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty"`
+	// Client device
+	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+}
+
+// QualitySwitchRequestBody is the type of the "reporter" service
+// "quality_switch" endpoint HTTP request body.
+type QualitySwitchRequestBody struct {
+	// Stream position the switch happened at, ms
+	Position *int32 `form:"position,omitempty" json:"position,omitempty" xml:"position,omitempty"`
+	// Media bitrate before the switch, bit/s
+	FromBitrate *int32 `form:"from_bitrate,omitempty" json:"from_bitrate,omitempty" xml:"from_bitrate,omitempty"`
+	// Media bitrate after the switch, bit/s
+	ToBitrate *int32 `form:"to_bitrate,omitempty" json:"to_bitrate,omitempty" xml:"to_bitrate,omitempty"`
+	// Whether the switch was made by adaptive bitrate logic or by the user
+	Reason *string `form:"reason,omitempty" json:"reason,omitempty" xml:"reason,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL *string `form:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Player server name
+	Player *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	// User ID
+	UserID *string `form:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty"`
+	// Client device
+	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+}
+
+// SessionEndRequestBody is the type of the "reporter" service "session_end"
+// endpoint HTTP request body.
+type SessionEndRequestBody struct {
+	// Stream position at the end of the session, ms
+	Position *int32 `form:"position,omitempty" json:"position,omitempty" xml:"position,omitempty"`
+	// Total time spent playing during the session, ms
+	Watched *int32 `form:"watched,omitempty" json:"watched,omitempty" xml:"watched,omitempty"`
+	// Why the session ended
+	Reason *string `form:"reason,omitempty" json:"reason,omitempty" xml:"reason,omitempty"`
+	// LBRY URL (lbry://... without the protocol part)
+	URL *string `form:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol *string `form:"protocol,omitempty" json:"protocol,omitempty" xml:"protocol,omitempty"`
+	// Player server name
+	Player *string `form:"player,omitempty" json:"player,omitempty" xml:"player,omitempty"`
+	// User ID
+	UserID *string `form:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty"`
+	// Client device
+	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
+}
+
 // AddMultiFieldErrorResponseBody is the type of the "reporter" service "add"
 // endpoint HTTP response body for the "multi_field_error" error.
 type AddMultiFieldErrorResponseBody struct {
 	Message string `form:"message" json:"message" xml:"message"`
 }
 
+// StartupMultiFieldErrorResponseBody is the type of the "reporter" service
+// "startup" endpoint HTTP response body for the "multi_field_error" error.
+type StartupMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// PlaybackErrorMultiFieldErrorResponseBody is the type of the "reporter"
+// service "playback_error" endpoint HTTP response body for the
+// "multi_field_error" error.
+type PlaybackErrorMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// SeekMultiFieldErrorResponseBody is the type of the "reporter" service "seek"
+// endpoint HTTP response body for the "multi_field_error" error.
+type SeekMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// QualitySwitchMultiFieldErrorResponseBody is the type of the "reporter"
+// service "quality_switch" endpoint HTTP response body for the
+// "multi_field_error" error.
+type QualitySwitchMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// SessionEndMultiFieldErrorResponseBody is the type of the "reporter" service
+// "session_end" endpoint HTTP response body for the "multi_field_error" error.
+type SessionEndMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
 // NewAddMultiFieldErrorResponseBody builds the HTTP response body from the
 // result of the "add" endpoint of the "reporter" service.
 func NewAddMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *AddMultiFieldErrorResponseBody {
@@ -62,6 +199,51 @@ func NewAddMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *AddMultiF
 	return body
 }
 
+// NewStartupMultiFieldErrorResponseBody builds the HTTP response body from the
+// result of the "startup" endpoint of the "reporter" service.
+func NewStartupMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *StartupMultiFieldErrorResponseBody {
+	body := &StartupMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewPlaybackErrorMultiFieldErrorResponseBody builds the HTTP response body
+// from the result of the "playback_error" endpoint of the "reporter" service.
+func NewPlaybackErrorMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *PlaybackErrorMultiFieldErrorResponseBody {
+	body := &PlaybackErrorMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewSeekMultiFieldErrorResponseBody builds the HTTP response body from the
+// result of the "seek" endpoint of the "reporter" service.
+func NewSeekMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *SeekMultiFieldErrorResponseBody {
+	body := &SeekMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewQualitySwitchMultiFieldErrorResponseBody builds the HTTP response body
+// from the result of the "quality_switch" endpoint of the "reporter" service.
+func NewQualitySwitchMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *QualitySwitchMultiFieldErrorResponseBody {
+	body := &QualitySwitchMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewSessionEndMultiFieldErrorResponseBody builds the HTTP response body from
+// the result of the "session_end" endpoint of the "reporter" service.
+func NewSessionEndMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *SessionEndMultiFieldErrorResponseBody {
+	body := &SessionEndMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
 // NewAddPlaybackReport builds a reporter service add endpoint payload.
 func NewAddPlaybackReport(body *AddRequestBody) *reporter.PlaybackReport {
 	v := &reporter.PlaybackReport{
@@ -83,6 +265,93 @@ func NewAddPlaybackReport(body *AddRequestBody) *reporter.PlaybackReport {
 	return v
 }
 
+// NewStartupEvent builds a reporter service startup endpoint payload.
+func NewStartupEvent(body *StartupRequestBody) *reporter.StartupEvent {
+	v := &reporter.StartupEvent{
+		Ttff:     *body.Ttff,
+		Bitrate:  body.Bitrate,
+		URL:      *body.URL,
+		Protocol: *body.Protocol,
+		Player:   *body.Player,
+		UserID:   *body.UserID,
+		Device:   *body.Device,
+	}
+
+	return v
+}
+
+// NewPlaybackErrorErrorEvent builds a reporter service playback_error endpoint
+// payload.
+func NewPlaybackErrorErrorEvent(body *PlaybackErrorRequestBody) *reporter.ErrorEvent {
+	v := &reporter.ErrorEvent{
+		Position: *body.Position,
+		Code:     *body.Code,
+		Message:  body.Message,
+		URL:      *body.URL,
+		Protocol: *body.Protocol,
+		Player:   *body.Player,
+		UserID:   *body.UserID,
+		Device:   *body.Device,
+	}
+	if body.Fatal != nil {
+		v.Fatal = *body.Fatal
+	}
+	if body.Fatal == nil {
+		v.Fatal = false
+	}
+
+	return v
+}
+
+// NewSeekEvent builds a reporter service seek endpoint payload.
+func NewSeekEvent(body *SeekRequestBody) *reporter.SeekEvent {
+	v := &reporter.SeekEvent{
+		From:     *body.From,
+		To:       *body.To,
+		URL:      *body.URL,
+		Protocol: *body.Protocol,
+		Player:   *body.Player,
+		UserID:   *body.UserID,
+		Device:   *body.Device,
+	}
+
+	return v
+}
+
+// NewQualitySwitchEvent builds a reporter service quality_switch endpoint
+// payload.
+func NewQualitySwitchEvent(body *QualitySwitchRequestBody) *reporter.QualitySwitchEvent {
+	v := &reporter.QualitySwitchEvent{
+		Position:    *body.Position,
+		FromBitrate: *body.FromBitrate,
+		ToBitrate:   *body.ToBitrate,
+		Reason:      *body.Reason,
+		URL:         *body.URL,
+		Protocol:    *body.Protocol,
+		Player:      *body.Player,
+		UserID:      *body.UserID,
+		Device:      *body.Device,
+	}
+
+	return v
+}
+
+// NewSessionEndEvent builds a reporter service session_end endpoint payload.
+func NewSessionEndEvent(body *SessionEndRequestBody) *reporter.SessionEndEvent {
+	v := &reporter.SessionEndEvent{
+		Position: *body.Position,
+		Watched:  *body.Watched,
+		Reason:   *body.Reason,
+		URL:      *body.URL,
+		Protocol: *body.Protocol,
+		Player:   *body.Player,
+		UserID:   *body.UserID,
+		Device:   *body.Device,
+	}
+
+	return v
+}
+
 // ValidateAddRequestBody runs the validations defined on AddRequestBody
 func ValidateAddRequestBody(body *AddRequestBody) (err error) {
 	if body.URL == nil {
@@ -192,3 +461,372 @@ func ValidateAddRequestBody(body *AddRequestBody) (err error) {
 	}
 	return
 }
+
+// ValidateStartupRequestBody runs the validations defined on StartupRequestBody
+func ValidateStartupRequestBody(body *StartupRequestBody) (err error) {
+	if body.Ttff == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("ttff", "body"))
+	}
+	if body.URL == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("url", "body"))
+	}
+	if body.Protocol == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("protocol", "body"))
+	}
+	if body.Player == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("player", "body"))
+	}
+	if body.UserID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("user_id", "body"))
+	}
+	if body.Device == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("device", "body"))
+	}
+	if body.Ttff != nil {
+		if *body.Ttff < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.ttff", *body.Ttff, 0, true))
+		}
+	}
+	if body.Ttff != nil {
+		if *body.Ttff > 600000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.ttff", *body.Ttff, 600000, false))
+		}
+	}
+	if body.Bitrate != nil {
+		if *body.Bitrate < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.bitrate", *body.Bitrate, 0, true))
+		}
+	}
+	if body.URL != nil {
+		if utf8.RuneCountInString(*body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", *body.URL, utf8.RuneCountInString(*body.URL), 512, false))
+		}
+	}
+	if body.Protocol != nil {
+		if !(*body.Protocol == "stb" || *body.Protocol == "hls" || *body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", *body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+	}
+	if body.Player != nil {
+		if utf8.RuneCountInString(*body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", *body.Player, utf8.RuneCountInString(*body.Player), 128, false))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 1, true))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 45, false))
+		}
+	}
+	if body.Device != nil {
+		if !(*body.Device == "ios" || *body.Device == "adr" || *body.Device == "web" || *body.Device == "dsk" || *body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", *body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+	}
+	return
+}
+
+// ValidatePlaybackErrorRequestBody runs the validations defined on
+// playback_error_request_body
+func ValidatePlaybackErrorRequestBody(body *PlaybackErrorRequestBody) (err error) {
+	if body.Position == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("position", "body"))
+	}
+	if body.Code == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("code", "body"))
+	}
+	if body.URL == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("url", "body"))
+	}
+	if body.Protocol == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("protocol", "body"))
+	}
+	if body.Player == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("player", "body"))
+	}
+	if body.UserID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("user_id", "body"))
+	}
+	if body.Device == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("device", "body"))
+	}
+	if body.Position != nil {
+		if *body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", *body.Position, 0, true))
+		}
+	}
+	if body.Code != nil {
+		if utf8.RuneCountInString(*body.Code) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.code", *body.Code, utf8.RuneCountInString(*body.Code), 1, true))
+		}
+	}
+	if body.Code != nil {
+		if utf8.RuneCountInString(*body.Code) > 64 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.code", *body.Code, utf8.RuneCountInString(*body.Code), 64, false))
+		}
+	}
+	if body.Message != nil {
+		if utf8.RuneCountInString(*body.Message) > 1024 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.message", *body.Message, utf8.RuneCountInString(*body.Message), 1024, false))
+		}
+	}
+	if body.URL != nil {
+		if utf8.RuneCountInString(*body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", *body.URL, utf8.RuneCountInString(*body.URL), 512, false))
+		}
+	}
+	if body.Protocol != nil {
+		if !(*body.Protocol == "stb" || *body.Protocol == "hls" || *body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", *body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+	}
+	if body.Player != nil {
+		if utf8.RuneCountInString(*body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", *body.Player, utf8.RuneCountInString(*body.Player), 128, false))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 1, true))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 45, false))
+		}
+	}
+	if body.Device != nil {
+		if !(*body.Device == "ios" || *body.Device == "adr" || *body.Device == "web" || *body.Device == "dsk" || *body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", *body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+	}
+	return
+}
+
+// ValidateSeekRequestBody runs the validations defined on SeekRequestBody
+func ValidateSeekRequestBody(body *SeekRequestBody) (err error) {
+	if body.From == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("from", "body"))
+	}
+	if body.To == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("to", "body"))
+	}
+	if body.URL == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("url", "body"))
+	}
+	if body.Protocol == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("protocol", "body"))
+	}
+	if body.Player == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("player", "body"))
+	}
+	if body.UserID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("user_id", "body"))
+	}
+	if body.Device == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("device", "body"))
+	}
+	if body.From != nil {
+		if *body.From < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.from", *body.From, 0, true))
+		}
+	}
+	if body.To != nil {
+		if *body.To < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.to", *body.To, 0, true))
+		}
+	}
+	if body.URL != nil {
+		if utf8.RuneCountInString(*body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", *body.URL, utf8.RuneCountInString(*body.URL), 512, false))
+		}
+	}
+	if body.Protocol != nil {
+		if !(*body.Protocol == "stb" || *body.Protocol == "hls" || *body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", *body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+	}
+	if body.Player != nil {
+		if utf8.RuneCountInString(*body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", *body.Player, utf8.RuneCountInString(*body.Player), 128, false))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 1, true))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 45, false))
+		}
+	}
+	if body.Device != nil {
+		if !(*body.Device == "ios" || *body.Device == "adr" || *body.Device == "web" || *body.Device == "dsk" || *body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", *body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+	}
+	return
+}
+
+// ValidateQualitySwitchRequestBody runs the validations defined on
+// quality_switch_request_body
+func ValidateQualitySwitchRequestBody(body *QualitySwitchRequestBody) (err error) {
+	if body.Position == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("position", "body"))
+	}
+	if body.FromBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("from_bitrate", "body"))
+	}
+	if body.ToBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("to_bitrate", "body"))
+	}
+	if body.Reason == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("reason", "body"))
+	}
+	if body.URL == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("url", "body"))
+	}
+	if body.Protocol == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("protocol", "body"))
+	}
+	if body.Player == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("player", "body"))
+	}
+	if body.UserID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("user_id", "body"))
+	}
+	if body.Device == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("device", "body"))
+	}
+	if body.Position != nil {
+		if *body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", *body.Position, 0, true))
+		}
+	}
+	if body.FromBitrate != nil {
+		if *body.FromBitrate < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.from_bitrate", *body.FromBitrate, 0, true))
+		}
+	}
+	if body.ToBitrate != nil {
+		if *body.ToBitrate < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.to_bitrate", *body.ToBitrate, 0, true))
+		}
+	}
+	if body.Reason != nil {
+		if !(*body.Reason == "auto" || *body.Reason == "manual") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.reason", *body.Reason, []any{"auto", "manual"}))
+		}
+	}
+	if body.URL != nil {
+		if utf8.RuneCountInString(*body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", *body.URL, utf8.RuneCountInString(*body.URL), 512, false))
+		}
+	}
+	if body.Protocol != nil {
+		if !(*body.Protocol == "stb" || *body.Protocol == "hls" || *body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", *body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+	}
+	if body.Player != nil {
+		if utf8.RuneCountInString(*body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", *body.Player, utf8.RuneCountInString(*body.Player), 128, false))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 1, true))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 45, false))
+		}
+	}
+	if body.Device != nil {
+		if !(*body.Device == "ios" || *body.Device == "adr" || *body.Device == "web" || *body.Device == "dsk" || *body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", *body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+	}
+	return
+}
+
+// ValidateSessionEndRequestBody runs the validations defined on
+// session_end_request_body
+func ValidateSessionEndRequestBody(body *SessionEndRequestBody) (err error) {
+	if body.Position == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("position", "body"))
+	}
+	if body.Watched == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("watched", "body"))
+	}
+	if body.Reason == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("reason", "body"))
+	}
+	if body.URL == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("url", "body"))
+	}
+	if body.Protocol == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("protocol", "body"))
+	}
+	if body.Player == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("player", "body"))
+	}
+	if body.UserID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("user_id", "body"))
+	}
+	if body.Device == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("device", "body"))
+	}
+	if body.Position != nil {
+		if *body.Position < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.position", *body.Position, 0, true))
+		}
+	}
+	if body.Watched != nil {
+		if *body.Watched < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("body.watched", *body.Watched, 0, true))
+		}
+	}
+	if body.Reason != nil {
+		if !(*body.Reason == "completed" || *body.Reason == "closed" || *body.Reason == "navigated" || *body.Reason == "error") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.reason", *body.Reason, []any{"completed", "closed", "navigated", "error"}))
+		}
+	}
+	if body.URL != nil {
+		if utf8.RuneCountInString(*body.URL) > 512 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", *body.URL, utf8.RuneCountInString(*body.URL), 512, false))
+		}
+	}
+	if body.Protocol != nil {
+		if !(*body.Protocol == "stb" || *body.Protocol == "hls" || *body.Protocol == "lvs") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.protocol", *body.Protocol, []any{"stb", "hls", "lvs"}))
+		}
+	}
+	if body.Player != nil {
+		if utf8.RuneCountInString(*body.Player) > 128 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.player", *body.Player, utf8.RuneCountInString(*body.Player), 128, false))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 1, true))
+		}
+	}
+	if body.UserID != nil {
+		if utf8.RuneCountInString(*body.UserID) > 45 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError("body.user_id", *body.UserID, utf8.RuneCountInString(*body.UserID), 45, false))
+		}
+	}
+	if body.Device != nil {
+		if !(*body.Device == "ios" || *body.Device == "adr" || *body.Device == "web" || *body.Device == "dsk" || *body.Device == "stb") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.device", *body.Device, []any{"ios", "adr", "web", "dsk", "stb"}))
+		}
+	}
+	return
+}
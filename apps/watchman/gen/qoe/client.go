@@ -0,0 +1,76 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe client
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package qoe
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "qoe" service client.
+type Client struct {
+	RebufferingEndpoint goa.Endpoint
+	BitrateEndpoint     goa.Endpoint
+	StartupEndpoint     goa.Endpoint
+}
+
+// NewClient initializes a "qoe" service client given the endpoints.
+func NewClient(rebuffering, bitrate, startup goa.Endpoint) *Client {
+	return &Client{
+		RebufferingEndpoint: rebuffering,
+		BitrateEndpoint:     bitrate,
+		StartupEndpoint:     startup,
+	}
+}
+
+// Rebuffering calls the "rebuffering" endpoint of the "qoe" service.
+// Rebuffering may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unauthorized" (type Unauthorized)
+//   - "not_implemented" (type NotImplemented)
+//   - error: internal error
+func (c *Client) Rebuffering(ctx context.Context, p *AggregateQuery) (res *RebufferingPage, err error) {
+	var ires any
+	ires, err = c.RebufferingEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*RebufferingPage), nil
+}
+
+// Bitrate calls the "bitrate" endpoint of the "qoe" service.
+// Bitrate may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unauthorized" (type Unauthorized)
+//   - "not_implemented" (type NotImplemented)
+//   - error: internal error
+func (c *Client) Bitrate(ctx context.Context, p *AggregateQuery) (res *BitratePage, err error) {
+	var ires any
+	ires, err = c.BitrateEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*BitratePage), nil
+}
+
+// Startup calls the "startup" endpoint of the "qoe" service.
+// Startup may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unauthorized" (type Unauthorized)
+//   - "not_implemented" (type NotImplemented)
+//   - error: internal error
+func (c *Client) Startup(ctx context.Context, p *AggregateQuery) (res *StartupPage, err error) {
+	var ires any
+	ires, err = c.StartupEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*StartupPage), nil
+}
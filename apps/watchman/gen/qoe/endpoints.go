@@ -0,0 +1,98 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe endpoints
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package qoe
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+	"goa.design/goa/v3/security"
+)
+
+// Endpoints wraps the "qoe" service endpoints.
+type Endpoints struct {
+	Rebuffering goa.Endpoint
+	Bitrate     goa.Endpoint
+	Startup     goa.Endpoint
+}
+
+// NewEndpoints wraps the methods of the "qoe" service with endpoints.
+func NewEndpoints(s Service) *Endpoints {
+	// Casting service to Auther interface
+	a := s.(Auther)
+	return &Endpoints{
+		Rebuffering: NewRebufferingEndpoint(s, a.APIKeyAuth),
+		Bitrate:     NewBitrateEndpoint(s, a.APIKeyAuth),
+		Startup:     NewStartupEndpoint(s, a.APIKeyAuth),
+	}
+}
+
+// Use applies the given middleware to all the "qoe" service endpoints.
+func (e *Endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
+	e.Rebuffering = m(e.Rebuffering)
+	e.Bitrate = m(e.Bitrate)
+	e.Startup = m(e.Startup)
+}
+
+// NewRebufferingEndpoint returns an endpoint function that calls the method
+// "rebuffering" of service "qoe".
+func NewRebufferingEndpoint(s Service, authAPIKeyFn security.AuthAPIKeyFunc) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*AggregateQuery)
+		var err error
+		sc := security.APIKeyScheme{
+			Name:           "api_key",
+			Scopes:         []string{},
+			RequiredScopes: []string{},
+		}
+		ctx, err = authAPIKeyFn(ctx, p.Key, &sc)
+		if err != nil {
+			return nil, err
+		}
+		return s.Rebuffering(ctx, p)
+	}
+}
+
+// NewBitrateEndpoint returns an endpoint function that calls the method
+// "bitrate" of service "qoe".
+func NewBitrateEndpoint(s Service, authAPIKeyFn security.AuthAPIKeyFunc) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*AggregateQuery)
+		var err error
+		sc := security.APIKeyScheme{
+			Name:           "api_key",
+			Scopes:         []string{},
+			RequiredScopes: []string{},
+		}
+		ctx, err = authAPIKeyFn(ctx, p.Key, &sc)
+		if err != nil {
+			return nil, err
+		}
+		return s.Bitrate(ctx, p)
+	}
+}
+
+// NewStartupEndpoint returns an endpoint function that calls the method
+// "startup" of service "qoe".
+func NewStartupEndpoint(s Service, authAPIKeyFn security.AuthAPIKeyFunc) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*AggregateQuery)
+		var err error
+		sc := security.APIKeyScheme{
+			Name:           "api_key",
+			Scopes:         []string{},
+			RequiredScopes: []string{},
+		}
+		ctx, err = authAPIKeyFn(ctx, p.Key, &sc)
+		if err != nil {
+			return nil, err
+		}
+		return s.Startup(ctx, p)
+	}
+}
@@ -0,0 +1,223 @@
+// Code generated by goa, DO NOT EDIT.
+//
+// qoe service
+//
+// Command:
+// $ goa gen github.com/OdyseeTeam/odysee-api/apps/watchman/design -o
+// apps/watchman
+
+package qoe
+
+import (
+	"context"
+
+	"goa.design/goa/v3/security"
+)
+
+// Quality of experience aggregates over playback reports.
+// Reports are grouped by any combination of player, area, device, protocol and
+// time bucket,
+// groups are ordered by their key and returned in pages.
+type Service interface {
+	// Rebuffering events and the share of playback time spent rebuffering
+	Rebuffering(context.Context, *AggregateQuery) (res *RebufferingPage, err error)
+	// Media bitrate and client bandwidth
+	Bitrate(context.Context, *AggregateQuery) (res *BitratePage, err error)
+	// Playback starts, counted from reports covering the beginning of a stream,
+	// and rebuffering during them
+	Startup(context.Context, *AggregateQuery) (res *StartupPage, err error)
+}
+
+// Auther defines the authorization functions to be implemented by the service.
+type Auther interface {
+	// APIKeyAuth implements the authorization logic for the APIKey security scheme.
+	APIKeyAuth(ctx context.Context, key string, schema *security.APIKeyScheme) (context.Context, error)
+}
+
+// APIName is the name of the API as defined in the design.
+const APIName = "watchman"
+
+// APIVersion is the version of the API as defined in the design.
+const APIVersion = "0.0.1"
+
+// ServiceName is the name of the service as defined in the design. This is the
+// same value that is set in the endpoint request contexts under the ServiceKey
+// key.
+const ServiceName = "qoe"
+
+// MethodNames lists the service method names as defined in the design. These
+// are the same values that are set in the endpoint request contexts under the
+// MethodKey key.
+var MethodNames = [3]string{"rebuffering", "bitrate", "startup"}
+
+// AggregateQuery is the payload type of the qoe service rebuffering method.
+type AggregateQuery struct {
+	// API key
+	Key string
+	// Fields to group reports by, all reports in the time range are aggregated
+	// together when empty
+	GroupBy []string
+	// Time bucket size used when grouping by time
+	Bucket string
+	// Start of the time range, 24 hours before its end by default
+	From *string
+	// End of the time range, now by default
+	To *string
+	// Only include reports from this player server
+	Player *string
+	// Only include reports from this area (country code)
+	Area *string
+	// Only include reports from this client device
+	Device *string
+	// Only include reports for this delivery protocol
+	Protocol *string
+	// Page number
+	Page int
+	// Number of groups per page
+	PageSize int
+}
+
+// BitratePage is the result type of the qoe service bitrate method.
+type BitratePage struct {
+	Items    []*BitrateStats
+	Page     int
+	PageSize int
+	// True when there are more groups on the following pages
+	HasMore bool
+}
+
+type BitrateStats struct {
+	// Average media bitrate, bit/s
+	AvgBitrate float64
+	// Median media bitrate, bit/s
+	MedianBitrate float64
+	// Average client bandwidth, bit/s
+	AvgBandwidth float64
+	Player       *string
+	Area         *string
+	Device       *string
+	Protocol     *string
+	// Start of the time bucket
+	Time *string
+	// Number of playback reports in the group
+	Reports uint64
+}
+
+// MultiFieldError is the error returned when several fields failed a
+// validation rule.
+type MultiFieldError struct {
+	Message string
+}
+
+// RebufferingPage is the result type of the qoe service rebuffering method.
+type RebufferingPage struct {
+	Items    []*RebufferingStats
+	Page     int
+	PageSize int
+	// True when there are more groups on the following pages
+	HasMore bool
+}
+
+type RebufferingStats struct {
+	// Total reported playback time
+	PlaybackMs uint64
+	// Total rebuffering events
+	RebufCount uint64
+	// Total rebuffering time
+	RebufMs uint64
+	// Share of playback time spent rebuffering, 0—1
+	RebufRatio float64
+	Player     *string
+	Area       *string
+	Device     *string
+	Protocol   *string
+	// Start of the time bucket
+	Time *string
+	// Number of playback reports in the group
+	Reports uint64
+}
+
+// StartupPage is the result type of the qoe service startup method.
+type StartupPage struct {
+	Items    []*StartupStats
+	Page     int
+	PageSize int
+	// True when there are more groups on the following pages
+	HasMore bool
+}
+
+type StartupStats struct {
+	// Reports covering the first reporting window of a playback
+	Starts uint64
+	// Starts during which rebuffering happened
+	RebufferedStarts uint64
+	// Share of starts with rebuffering, 0—1
+	RebufferedRatio float64
+	// Average rebuffering time during a start
+	AvgRebufMs float64
+	Player     *string
+	Area       *string
+	Device     *string
+	Protocol   *string
+	// Start of the time bucket
+	Time *string
+	// Number of playback reports in the group
+	Reports uint64
+}
+
+// Configured storage doesn't support aggregate reads
+type NotImplemented string
+
+// API key is missing or invalid
+type Unauthorized string
+
+// Error returns an error description.
+func (e *MultiFieldError) Error() string {
+	return "MultiFieldError is the error returned when several fields failed a validation rule."
+}
+
+// ErrorName returns the error name.
+//
+// Deprecated: Use GoaErrorName - https://github.com/goadesign/goa/issues/3105
+func (e *MultiFieldError) ErrorName() string {
+	return e.GoaErrorName()
+}
+
+// GoaErrorName returns the error name.
+func (e *MultiFieldError) GoaErrorName() string {
+	return "multi_field_error"
+}
+
+// Error returns an error description.
+func (e NotImplemented) Error() string {
+	return "Configured storage doesn't support aggregate reads"
+}
+
+// ErrorName returns the error name.
+//
+// Deprecated: Use GoaErrorName - https://github.com/goadesign/goa/issues/3105
+func (e NotImplemented) ErrorName() string {
+	return e.GoaErrorName()
+}
+
+// GoaErrorName returns the error name.
+func (e NotImplemented) GoaErrorName() string {
+	return "not_implemented"
+}
+
+// Error returns an error description.
+func (e Unauthorized) Error() string {
+	return "API key is missing or invalid"
+}
+
+// ErrorName returns the error name.
+//
+// Deprecated: Use GoaErrorName - https://github.com/goadesign/goa/issues/3105
+func (e Unauthorized) ErrorName() string {
+	return e.GoaErrorName()
+}
+
+// GoaErrorName returns the error name.
+func (e Unauthorized) GoaErrorName() string {
+	return "unauthorized"
+}
@@ -16,30 +16,92 @@ import (
 
 // Client is the "reporter" service client.
 type Client struct {
-	AddEndpoint     goa.Endpoint
-	HealthzEndpoint goa.Endpoint
+	AddEndpoint           goa.Endpoint
+	StartupEndpoint       goa.Endpoint
+	PlaybackErrorEndpoint goa.Endpoint
+	SeekEndpoint          goa.Endpoint
+	QualitySwitchEndpoint goa.Endpoint
+	SessionEndEndpoint    goa.Endpoint
+	HealthzEndpoint       goa.Endpoint
 }
 
 // NewClient initializes a "reporter" service client given the endpoints.
-func NewClient(add, healthz goa.Endpoint) *Client {
+func NewClient(add, startup, playbackError, seek, qualitySwitch, sessionEnd, healthz goa.Endpoint) *Client {
 	return &Client{
-		AddEndpoint:     add,
-		HealthzEndpoint: healthz,
+		AddEndpoint:           add,
+		StartupEndpoint:       startup,
+		PlaybackErrorEndpoint: playbackError,
+		SeekEndpoint:          seek,
+		QualitySwitchEndpoint: qualitySwitch,
+		SessionEndEndpoint:    sessionEnd,
+		HealthzEndpoint:       healthz,
 	}
 }
 
 // Add calls the "add" endpoint of the "reporter" service.
 // Add may return the following errors:
 //   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
 //   - error: internal error
 func (c *Client) Add(ctx context.Context, p *PlaybackReport) (err error) {
 	_, err = c.AddEndpoint(ctx, p)
 	return
 }
 
+// Startup calls the "startup" endpoint of the "reporter" service.
+// Startup may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
+//   - error: internal error
+func (c *Client) Startup(ctx context.Context, p *StartupEvent) (err error) {
+	_, err = c.StartupEndpoint(ctx, p)
+	return
+}
+
+// PlaybackError calls the "playback_error" endpoint of the "reporter" service.
+// PlaybackError may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
+//   - error: internal error
+func (c *Client) PlaybackError(ctx context.Context, p *ErrorEvent) (err error) {
+	_, err = c.PlaybackErrorEndpoint(ctx, p)
+	return
+}
+
+// Seek calls the "seek" endpoint of the "reporter" service.
+// Seek may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
+//   - error: internal error
+func (c *Client) Seek(ctx context.Context, p *SeekEvent) (err error) {
+	_, err = c.SeekEndpoint(ctx, p)
+	return
+}
+
+// QualitySwitch calls the "quality_switch" endpoint of the "reporter" service.
+// QualitySwitch may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
+//   - error: internal error
+func (c *Client) QualitySwitch(ctx context.Context, p *QualitySwitchEvent) (err error) {
+	_, err = c.QualitySwitchEndpoint(ctx, p)
+	return
+}
+
+// SessionEnd calls the "session_end" endpoint of the "reporter" service.
+// SessionEnd may return the following errors:
+//   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
+//   - error: internal error
+func (c *Client) SessionEnd(ctx context.Context, p *SessionEndEvent) (err error) {
+	_, err = c.SessionEndEndpoint(ctx, p)
+	return
+}
+
 // Healthz calls the "healthz" endpoint of the "reporter" service.
 // Healthz may return the following errors:
 //   - "multi_field_error" (type *MultiFieldError)
+//   - "unavailable" (type Unavailable)
 //   - error: internal error
 func (c *Client) Healthz(ctx context.Context) (res string, err error) {
 	var ires any
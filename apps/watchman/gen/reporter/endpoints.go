@@ -16,21 +16,36 @@ import (
 
 // Endpoints wraps the "reporter" service endpoints.
 type Endpoints struct {
-	Add     goa.Endpoint
-	Healthz goa.Endpoint
+	Add           goa.Endpoint
+	Startup       goa.Endpoint
+	PlaybackError goa.Endpoint
+	Seek          goa.Endpoint
+	QualitySwitch goa.Endpoint
+	SessionEnd    goa.Endpoint
+	Healthz       goa.Endpoint
 }
 
 // NewEndpoints wraps the methods of the "reporter" service with endpoints.
 func NewEndpoints(s Service) *Endpoints {
 	return &Endpoints{
-		Add:     NewAddEndpoint(s),
-		Healthz: NewHealthzEndpoint(s),
+		Add:           NewAddEndpoint(s),
+		Startup:       NewStartupEndpoint(s),
+		PlaybackError: NewPlaybackErrorEndpoint(s),
+		Seek:          NewSeekEndpoint(s),
+		QualitySwitch: NewQualitySwitchEndpoint(s),
+		SessionEnd:    NewSessionEndEndpoint(s),
+		Healthz:       NewHealthzEndpoint(s),
 	}
 }
 
 // Use applies the given middleware to all the "reporter" service endpoints.
 func (e *Endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
 	e.Add = m(e.Add)
+	e.Startup = m(e.Startup)
+	e.PlaybackError = m(e.PlaybackError)
+	e.Seek = m(e.Seek)
+	e.QualitySwitch = m(e.QualitySwitch)
+	e.SessionEnd = m(e.SessionEnd)
 	e.Healthz = m(e.Healthz)
 }
 
@@ -43,6 +58,51 @@ func NewAddEndpoint(s Service) goa.Endpoint {
 	}
 }
 
+// NewStartupEndpoint returns an endpoint function that calls the method
+// "startup" of service "reporter".
+func NewStartupEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*StartupEvent)
+		return nil, s.Startup(ctx, p)
+	}
+}
+
+// NewPlaybackErrorEndpoint returns an endpoint function that calls the method
+// "playback_error" of service "reporter".
+func NewPlaybackErrorEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*ErrorEvent)
+		return nil, s.PlaybackError(ctx, p)
+	}
+}
+
+// NewSeekEndpoint returns an endpoint function that calls the method "seek" of
+// service "reporter".
+func NewSeekEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*SeekEvent)
+		return nil, s.Seek(ctx, p)
+	}
+}
+
+// NewQualitySwitchEndpoint returns an endpoint function that calls the method
+// "quality_switch" of service "reporter".
+func NewQualitySwitchEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*QualitySwitchEvent)
+		return nil, s.QualitySwitch(ctx, p)
+	}
+}
+
+// NewSessionEndEndpoint returns an endpoint function that calls the method
+// "session_end" of service "reporter".
+func NewSessionEndEndpoint(s Service) goa.Endpoint {
+	return func(ctx context.Context, req any) (any, error) {
+		p := req.(*SessionEndEvent)
+		return nil, s.SessionEnd(ctx, p)
+	}
+}
+
 // NewHealthzEndpoint returns an endpoint function that calls the method
 // "healthz" of service "reporter".
 func NewHealthzEndpoint(s Service) goa.Endpoint {
@@ -16,6 +16,16 @@ import (
 type Service interface {
 	// Add implements add.
 	Add(context.Context, *PlaybackReport) (err error)
+	// Playback startup, sent once the first frame is rendered
+	Startup(context.Context, *StartupEvent) (err error)
+	// Player error, fatal ones stop the playback
+	PlaybackError(context.Context, *ErrorEvent) (err error)
+	// Seek to another stream position
+	Seek(context.Context, *SeekEvent) (err error)
+	// Switch to another media rendition
+	QualitySwitch(context.Context, *QualitySwitchEvent) (err error)
+	// End of a playback session
+	SessionEnd(context.Context, *SessionEndEvent) (err error)
 	// Healthz implements healthz.
 	Healthz(context.Context) (res string, err error)
 }
@@ -34,7 +44,29 @@ const ServiceName = "reporter"
 // MethodNames lists the service method names as defined in the design. These
 // are the same values that are set in the endpoint request contexts under the
 // MethodKey key.
-var MethodNames = [2]string{"add", "healthz"}
+var MethodNames = [7]string{"add", "startup", "playback_error", "seek", "quality_switch", "session_end", "healthz"}
+
+// ErrorEvent is the payload type of the reporter service playback_error method.
+type ErrorEvent struct {
+	// Stream position the error happened at, ms
+	Position int32
+	// Player error code
+	Code string
+	// Error message
+	Message *string
+	// Whether the error stopped the playback
+	Fatal bool
+	// LBRY URL (lbry://... without the protocol part)
+	URL string
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string
+	// Player server name
+	Player string
+	// User ID
+	UserID string
+	// Client device
+	Device string
+}
 
 // MultiFieldError is the error returned when several fields failed a
 // validation rule.
@@ -73,6 +105,89 @@ type PlaybackReport struct {
 	Device string
 }
 
+// QualitySwitchEvent is the payload type of the reporter service
+// quality_switch method.
+type QualitySwitchEvent struct {
+	// Stream position the switch happened at, ms
+	Position int32
+	// Media bitrate before the switch, bit/s
+	FromBitrate int32
+	// Media bitrate after the switch, bit/s
+	ToBitrate int32
+	// Whether the switch was made by adaptive bitrate logic or by the user
+	Reason string
+	// LBRY URL (lbry://... without the protocol part)
+	URL string
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string
+	// Player server name
+	Player string
+	// User ID
+	UserID string
+	// Client device
+	Device string
+}
+
+// SeekEvent is the payload type of the reporter service seek method.
+type SeekEvent struct {
+	// Stream position before the seek, ms
+	From int32
+	// Stream position after the seek, ms
+	To int32
+	// LBRY URL (lbry://... without the protocol part)
+	URL string
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string
+	// Player server name
+	Player string
+	// User ID
+	UserID string
+	// Client device
+	Device string
+}
+
+// SessionEndEvent is the payload type of the reporter service session_end
+// method.
+type SessionEndEvent struct {
+	// Stream position at the end of the session, ms
+	Position int32
+	// Total time spent playing during the session, ms
+	Watched int32
+	// Why the session ended
+	Reason string
+	// LBRY URL (lbry://... without the protocol part)
+	URL string
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string
+	// Player server name
+	Player string
+	// User ID
+	UserID string
+	// Client device
+	Device string
+}
+
+// StartupEvent is the payload type of the reporter service startup method.
+type StartupEvent struct {
+	// Time to first frame since the playback was requested, ms
+	Ttff int32
+	// Media bitrate of the initial rendition, bit/s
+	Bitrate *int32
+	// LBRY URL (lbry://... without the protocol part)
+	URL string
+	// Standard binary stream (`stb`), HLS (`hls`) or live stream (`lvs`)
+	Protocol string
+	// Player server name
+	Player string
+	// User ID
+	UserID string
+	// Client device
+	Device string
+}
+
+// Reports can't be accepted right now, retry later
+type Unavailable string
+
 // Error returns an error description.
 func (e *MultiFieldError) Error() string {
 	return "MultiFieldError is the error returned when several fields failed a validation rule."
@@ -89,3 +204,20 @@ func (e *MultiFieldError) ErrorName() string {
 func (e *MultiFieldError) GoaErrorName() string {
 	return "multi_field_error"
 }
+
+// Error returns an error description.
+func (e Unavailable) Error() string {
+	return "Reports can't be accepted right now, retry later"
+}
+
+// ErrorName returns the error name.
+//
+// Deprecated: Use GoaErrorName - https://github.com/goadesign/goa/issues/3105
+func (e Unavailable) ErrorName() string {
+	return e.GoaErrorName()
+}
+
+// GoaErrorName returns the error name.
+func (e Unavailable) GoaErrorName() string {
+	return "unavailable"
+}
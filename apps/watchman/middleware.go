@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	hmw "goa.design/goa/v3/http/middleware"
@@ -37,7 +38,9 @@ func RemoteAddressMiddleware() func(http.Handler) http.Handler {
 // from makes a best effort to compute the request client IP.
 func from(req *http.Request) string {
 	if f := req.Header.Get("X-Forwarded-For"); f != "" {
-		return f
+		// The client comes first, followed by proxies it went through.
+		client, _, _ := strings.Cut(f, ",")
+		return strings.TrimSpace(client)
 	}
 	f := req.RemoteAddr
 	ip, _, err := net.SplitHostPort(f)
@@ -0,0 +1,290 @@
+package olapdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	GroupPlayer   = "player"
+	GroupArea     = "area"
+	GroupDevice   = "device"
+	GroupProtocol = "protocol"
+	GroupTime     = "time"
+
+	BucketMinute = "minute"
+	BucketHour   = "hour"
+	BucketDay    = "day"
+)
+
+// groupFields lists fields reports can be grouped by in the order they appear in results.
+var groupFields = []string{GroupPlayer, GroupArea, GroupDevice, GroupProtocol, GroupTime}
+
+var groupColumns = map[string]string{
+	GroupPlayer:   "Player",
+	GroupArea:     "Area",
+	GroupDevice:   "Device",
+	GroupProtocol: "Protocol",
+}
+
+// Aggregate selects playback reports and describes how to group them.
+// Only whitelisted fields end up in the query text, all filter values are passed as query arguments.
+type Aggregate struct {
+	GroupBy []string
+	Bucket  string
+	From    time.Time
+	To      time.Time
+
+	Player   string
+	Area     string
+	Device   string
+	Protocol string
+
+	Limit  int
+	Offset int
+}
+
+// AggregateGroup holds values of the grouping fields, fields not grouped by are left empty.
+type AggregateGroup struct {
+	Player   string
+	Area     string
+	Device   string
+	Protocol string
+	Time     time.Time
+	Reports  uint64
+}
+
+type RebufferingRow struct {
+	AggregateGroup
+	PlaybackMs uint64
+	RebufCount uint64
+	RebufMs    uint64
+}
+
+type BitrateRow struct {
+	AggregateGroup
+	AvgBitrate    float64
+	MedianBitrate float64
+	AvgBandwidth  float64
+}
+
+type StartupRow struct {
+	AggregateGroup
+	RebufferedStarts uint64
+	AvgRebufMs       float64
+}
+
+// metric is an aggregate expression and the destination its value is scanned into.
+type metric struct {
+	expr string
+	dest any
+}
+
+// dialect renders parts of aggregate queries which differ between SQL storages.
+type dialect interface {
+	// table returns a qualified table name.
+	table(name string) string
+	// bucket returns the expression truncating Timestamp to the bucket.
+	bucket(bucket string) (string, bool)
+	// timeArg converts a time into a value comparable with Timestamp.
+	timeArg(t time.Time) any
+	countIf(cond string) string
+	// sum, avg, avgIf and medianIf evaluate to 0 when there are no values.
+	sum(col string) string
+	avg(col string) string
+	avgIf(col, cond string) string
+	medianIf(col, cond string) string
+}
+
+func queryRebuffering(ctx context.Context, db *sql.DB, d dialect, a Aggregate) ([]RebufferingRow, error) {
+	return aggregate(ctx, db, d, a, nil, func(r *RebufferingRow) (*AggregateGroup, []metric) {
+		return &r.AggregateGroup, []metric{
+			{d.sum("Duration"), &r.PlaybackMs},
+			{d.sum("RebufCount"), &r.RebufCount},
+			{d.sum("RebufDuration"), &r.RebufMs},
+		}
+	})
+}
+
+// queryBitrate ignores reports where bitrate or bandwidth weren't measured.
+func queryBitrate(ctx context.Context, db *sql.DB, d dialect, a Aggregate) ([]BitrateRow, error) {
+	return aggregate(ctx, db, d, a, nil, func(r *BitrateRow) (*AggregateGroup, []metric) {
+		return &r.AggregateGroup, []metric{
+			{d.avgIf("Bitrate", "Bitrate > 0"), &r.AvgBitrate},
+			{d.medianIf("Bitrate", "Bitrate > 0"), &r.MedianBitrate},
+			{d.avgIf("Bandwidth", "Bandwidth > 0"), &r.AvgBandwidth},
+		}
+	})
+}
+
+// queryStartup aggregates reports covering the beginning of a playback,
+// which are those sent before the position went past the reporting window.
+func queryStartup(ctx context.Context, db *sql.DB, d dialect, a Aggregate) ([]StartupRow, error) {
+	return aggregate(ctx, db, d, a, []string{"Position <= Duration"}, func(r *StartupRow) (*AggregateGroup, []metric) {
+		return &r.AggregateGroup, []metric{
+			{d.countIf("RebufCount > 0"), &r.RebufferedStarts},
+			{d.avg("RebufDuration"), &r.AvgRebufMs},
+		}
+	})
+}
+
+// aggregate runs the query for metrics returned by fields, which is called once per row
+// to get scan destinations and once upfront to get the expressions.
+func aggregate[T any](ctx context.Context, db *sql.DB, d dialect, a Aggregate, where []string, fields func(*T) (*AggregateGroup, []metric)) ([]T, error) {
+	var (
+		zero  T
+		exprs []string
+	)
+	_, metrics := fields(&zero)
+	for _, m := range metrics {
+		exprs = append(exprs, m.expr)
+	}
+	query, args, err := a.build(d, exprs, where)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var res []T
+	for rows.Next() {
+		var r T
+		g, metrics := fields(&r)
+		dest := a.groupDest(g)
+		for _, m := range metrics {
+			dest = append(dest, m.dest)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("cannot scan aggregates: %w", err)
+		}
+		g.trim()
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+// groups returns requested grouping fields in result order, rejecting unknown ones.
+func (a Aggregate) groups() ([]string, error) {
+	requested := map[string]bool{}
+	for _, f := range a.GroupBy {
+		if _, ok := groupColumns[f]; !ok && f != GroupTime {
+			return nil, fmt.Errorf("cannot group by %q", f)
+		}
+		requested[f] = true
+	}
+	var groups []string
+	for _, f := range groupFields {
+		if requested[f] {
+			groups = append(groups, f)
+		}
+	}
+	return groups, nil
+}
+
+// build returns the query text and its arguments. Group values are selected first in groupFields order,
+// followed by the report count and metric expressions.
+func (a Aggregate) build(d dialect, metrics []string, where []string) (string, []any, error) {
+	groups, err := a.groups()
+	if err != nil {
+		return "", nil, err
+	}
+	var keys []string
+	for _, f := range groups {
+		if f != GroupTime {
+			keys = append(keys, groupColumns[f])
+			continue
+		}
+		bucket := a.Bucket
+		if bucket == "" {
+			bucket = BucketHour
+		}
+		expr, ok := d.bucket(bucket)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown time bucket %q", a.Bucket)
+		}
+		keys = append(keys, expr)
+	}
+	if !a.From.Before(a.To) {
+		return "", nil, fmt.Errorf("time range start must be before its end")
+	}
+
+	conds := []string{"Timestamp >= ?", "Timestamp < ?"}
+	args := []any{d.timeArg(a.From), d.timeArg(a.To)}
+	for _, f := range []struct {
+		column, value string
+	}{
+		{"Player", a.Player},
+		{"Area", strings.ToLower(a.Area)},
+		{"Device", a.Device},
+		{"Protocol", a.Protocol},
+	} {
+		if f.value != "" {
+			conds = append(conds, f.column+" = ?")
+			args = append(args, f.value)
+		}
+	}
+	conds = append(conds, where...)
+
+	q := &strings.Builder{}
+	fmt.Fprintf(q, "SELECT %s\nFROM %s\nWHERE %s",
+		strings.Join(append(append(keys, "count(*)"), metrics...), ", "), d.table(tablePlayback), strings.Join(conds, " AND "))
+	if len(keys) > 0 {
+		fmt.Fprintf(q, "\nGROUP BY %[1]s\nORDER BY %[1]s", strings.Join(keys, ", "))
+	}
+	if a.Limit > 0 {
+		q.WriteString("\nLIMIT ? OFFSET ?")
+		args = append(args, a.Limit, a.Offset)
+	}
+	return q.String(), args, nil
+}
+
+// groupDest returns scan destinations for group values and the report count.
+func (a Aggregate) groupDest(g *AggregateGroup) []any {
+	groups, _ := a.groups()
+	var dest []any
+	for _, f := range groups {
+		switch f {
+		case GroupPlayer:
+			dest = append(dest, &g.Player)
+		case GroupArea:
+			dest = append(dest, &g.Area)
+		case GroupDevice:
+			dest = append(dest, &g.Device)
+		case GroupProtocol:
+			dest = append(dest, &g.Protocol)
+		case GroupTime:
+			dest = append(dest, timeScanner{&g.Time})
+		}
+	}
+	return append(dest, &g.Reports)
+}
+
+// trim removes zero padding ClickHouse returns FixedString values with.
+func (g *AggregateGroup) trim() {
+	for _, s := range []*string{&g.Player, &g.Area, &g.Device, &g.Protocol} {
+		*s = strings.TrimRight(*s, "\x00")
+	}
+}
+
+// timeScanner scans time bucket values, which come as times from ClickHouse and as Unix timestamps from SQLite.
+type timeScanner struct {
+	t *time.Time
+}
+
+func (s timeScanner) Scan(v any) error {
+	switch v := v.(type) {
+	case time.Time:
+		*s.t = v
+	case int64:
+		*s.t = time.Unix(v, 0).UTC()
+	default:
+		return fmt.Errorf("cannot scan %T into time", v)
+	}
+	return nil
+}
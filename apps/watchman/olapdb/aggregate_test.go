@@ -0,0 +1,120 @@
+package olapdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateBuild(t *testing.T) {
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	cases := []struct {
+		name  string
+		agg   Aggregate
+		query string
+		args  []any
+		err   string
+	}{
+		{
+			name:  "no groups",
+			agg:   Aggregate{From: from, To: to},
+			query: "SELECT count(*), sum(Duration)\nFROM db.playback\nWHERE Timestamp >= ? AND Timestamp < ?",
+			args:  []any{from, to},
+		},
+		{
+			name: "groups in result order",
+			agg: Aggregate{
+				GroupBy: []string{GroupTime, GroupArea, GroupPlayer, GroupArea}, Bucket: BucketDay,
+				From: from, To: to, Area: "US", Device: "web", Limit: 11, Offset: 20,
+			},
+			query: "SELECT Player, Area, toStartOfDay(Timestamp), count(*), sum(Duration)\nFROM db.playback\n" +
+				"WHERE Timestamp >= ? AND Timestamp < ? AND Area = ? AND Device = ?\n" +
+				"GROUP BY Player, Area, toStartOfDay(Timestamp)\nORDER BY Player, Area, toStartOfDay(Timestamp)\n" +
+				"LIMIT ? OFFSET ?",
+			args: []any{from, to, "us", "web", 11, 20},
+		},
+		{
+			name:  "hour bucket by default",
+			agg:   Aggregate{GroupBy: []string{GroupTime}, From: from, To: to},
+			query: "SELECT toStartOfHour(Timestamp), count(*), sum(Duration)\nFROM db.playback\nWHERE Timestamp >= ? AND Timestamp < ?\nGROUP BY toStartOfHour(Timestamp)\nORDER BY toStartOfHour(Timestamp)",
+			args:  []any{from, to},
+		},
+		{
+			name: "unknown group",
+			agg:  Aggregate{GroupBy: []string{"URL; DROP TABLE playback"}, From: from, To: to},
+			err:  "cannot group by",
+		},
+		{
+			name: "unknown bucket",
+			agg:  Aggregate{GroupBy: []string{GroupTime}, Bucket: "week", From: from, To: to},
+			err:  "unknown time bucket",
+		},
+		{
+			name: "empty range",
+			agg:  Aggregate{From: to, To: to},
+			err:  "time range",
+		},
+	}
+
+	database = "db"
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, args, err := c.agg.build(clickhouseDialect{}, []string{"sum(Duration)"}, nil)
+			if c.err != "" {
+				require.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.query, query)
+			assert.Equal(t, c.args, args)
+		})
+	}
+}
+
+func (s *olapdbSuite) TestAggregates() {
+	ctx := context.Background()
+	now := time.Now()
+	for i := range 5 {
+		r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+		r.Player = "aggregate-test"
+		r.Device = "ios"
+		r.Duration = 30000
+		r.Position = int32(i * 10000)
+		r.RebufCount = int32(i % 2)
+		r.RebufDuration = int32(i%2) * 1000
+		r.Bitrate = nil
+		s.Require().NoError(WriteOne(r, Locate("81.2.69.142"), now.Format(time.RFC1123Z)))
+	}
+
+	agg := Aggregate{
+		GroupBy: []string{GroupPlayer, GroupDevice},
+		From:    now.Add(-time.Hour), To: now.Add(time.Hour),
+		Player: "aggregate-test",
+	}
+	rebuf, err := Rebuffering(ctx, agg)
+	s.Require().NoError(err)
+	s.Require().Len(rebuf, 1)
+	s.Equal("aggregate-test", rebuf[0].Player)
+	s.Equal("ios", rebuf[0].Device)
+	s.EqualValues(5, rebuf[0].Reports)
+	s.EqualValues(150000, rebuf[0].PlaybackMs)
+	s.EqualValues(2, rebuf[0].RebufCount)
+	s.EqualValues(2000, rebuf[0].RebufMs)
+
+	bitrate, err := Bitrate(ctx, agg)
+	s.Require().NoError(err)
+	s.Require().Len(bitrate, 1)
+	s.Zero(bitrate[0].AvgBitrate)
+
+	startup, err := Startup(ctx, agg)
+	s.Require().NoError(err)
+	s.Require().Len(startup, 1)
+	s.EqualValues(4, startup[0].Reports)
+	s.EqualValues(2, startup[0].RebufferedStarts)
+}
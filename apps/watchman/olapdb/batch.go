@@ -2,8 +2,8 @@ package olapdb
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
@@ -11,93 +11,342 @@ import (
 	"github.com/pkg/errors"
 )
 
+// ErrQueueFull is returned when the batch writer can't accept more rows until pending ones are written.
+var ErrQueueFull = errors.New("batch queue is full")
+
+const (
+	defaultQueueSize  = 100000
+	defaultMaxBackoff = time.Minute
+
+	dropQueueFull   = "queue_full"
+	dropWriteFailed = "write_failed"
+)
+
+// batchRow is a row of values for tableColumns[table].
+type batchRow struct {
+	table string
+	args  []interface{}
+}
+
+// BatchWriter writes rows to ClickHouse in batches every interval.
+//
+// No more than queue size rows are kept in memory, further writes fail with ErrQueueFull.
+// Failed batches are retried with exponential backoff. If a spill directory is set,
+// they are written to disk instead of being kept in memory and replayed once writes succeed again.
 type BatchWriter struct {
-	width, capacity int
-	interval        time.Duration
+	capacity   int
+	interval   time.Duration
+	maxBackoff time.Duration
+	spillDir   string
+	spillSize  int64
 
-	batch    [][]interface{}
-	rcvChan  chan []interface{}
+	batch    map[string][][]interface{}
+	pending  atomic.Int64
+	rcvChan  chan batchRow
+	done     chan struct{}
 	stopChan chan bool
+	// stopMu makes sure no rows are enqueued after Stop, so the final drain gets all of them.
+	stopMu  sync.RWMutex
+	stopped bool
+
+	spill         *spill
+	replayStopped bool
+	backoff       time.Duration
+	retryAt       time.Time
+	write         func(table string, rows [][]interface{}) error
+	failures      atomic.Uint64
+	dropped       map[string]*atomic.Uint64
+}
+
+type BatchWriterOption func(b *BatchWriter)
+
+// WithQueueSize sets the maximum number of rows waiting to be written, zero keeps the default.
+func WithQueueSize(size int) BatchWriterOption {
+	return func(b *BatchWriter) {
+		if size > 0 {
+			b.capacity = size
+		}
+	}
+}
+
+// WithSpillDir enables spilling batches which couldn't be written into the directory.
+func WithSpillDir(dir string) BatchWriterOption {
+	return func(b *BatchWriter) {
+		b.spillDir = dir
+	}
+}
+
+// WithMaxSpillSize limits the total size of spilled batches in bytes, zero means no limit.
+func WithMaxSpillSize(size int64) BatchWriterOption {
+	return func(b *BatchWriter) {
+		b.spillSize = size
+	}
+}
+
+// WithMaxBackoff sets the longest delay between retries of failed writes, zero keeps the default.
+func WithMaxBackoff(d time.Duration) BatchWriterOption {
+	return func(b *BatchWriter) {
+		if d > 0 {
+			b.maxBackoff = d
+		}
+	}
 }
 
-func NewBatchWriter(interval time.Duration, width int) *BatchWriter {
-	capacity := 100000
-	b := BatchWriter{
-		width:    width,
-		interval: interval,
-		capacity: capacity,
-		batch:    [][]interface{}{},
-		rcvChan:  make(chan []interface{}, capacity*3),
-		stopChan: make(chan bool, 1),
+func NewBatchWriter(interval time.Duration, options ...BatchWriterOption) (*BatchWriter, error) {
+	b := &BatchWriter{
+		interval:   interval,
+		capacity:   defaultQueueSize,
+		maxBackoff: defaultMaxBackoff,
+		batch:      map[string][][]interface{}{},
+		done:       make(chan struct{}),
+		stopChan:   make(chan bool, 1),
+		write:      writeRows,
+		dropped: map[string]*atomic.Uint64{
+			dropQueueFull:   {},
+			dropWriteFailed: {},
+		},
+	}
+	for _, o := range options {
+		o(b)
+	}
+	b.rcvChan = make(chan batchRow, b.capacity)
+	if b.spillDir != "" {
+		s, err := newSpill(b.spillDir, b.spillSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot open spill directory")
+		}
+		b.spill = s
 	}
-	return &b
+	return b, nil
 }
 
 func (b *BatchWriter) Start() {
-	var counter int
-	var stop bool
 	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
 
-	for !stop {
+	for {
+		// Stop receiving when the batch is full, so that the queue fills up and writers get ErrQueueFull.
+		rcvChan := b.rcvChan
+		if b.pending.Load() >= int64(b.capacity) {
+			rcvChan = nil
+		}
 		select {
-		case el := <-b.rcvChan:
-			if el == nil {
-				continue
-			}
-			log.Log.Debugw("batch element received", "el", el, "count", counter)
-			b.batch = append(b.batch, el)
-			counter++
+		case el := <-rcvChan:
+			log.Log.Debugw("batch element received", "table", el.table, "el", el.args, "count", b.pending.Load())
+			b.batch[el.table] = append(b.batch[el.table], el.args)
+			b.pending.Add(1)
 		case <-ticker.C:
-			if counter == 0 {
-				log.Log.Info("no elements in the current batch")
-				continue
+			b.flush()
+		case <-b.done:
+			b.stop()
+			return
+		}
+	}
+}
+
+// flush writes the current batch unless writes are being backed off, then replays spilled batches.
+// Batches which can't be written are spilled if possible, otherwise kept for the next attempt.
+func (b *BatchWriter) flush() {
+	count := b.pending.Load()
+	if time.Now().Before(b.retryAt) {
+		if count > 0 && b.spill != nil {
+			b.spillBatch()
+		}
+		return
+	}
+
+	if count == 0 {
+		log.Log.Debug("no elements in the current batch")
+	} else {
+		log.Log.Infow("preparing a batch write", "count", count)
+		if err := b.writeBatch(b.batch); err != nil {
+			b.fail(err)
+			b.pending.Store(countRows(b.batch))
+			if b.spill != nil {
+				b.spillBatch()
+			}
+			return
+		}
+		log.Log.Infow("batch written", "count", count)
+		b.resetBatch()
+	}
+	b.backoff = 0
+	b.replay()
+}
+
+// replay writes spilled batches, oldest first, for no longer than an interval.
+func (b *BatchWriter) replay() {
+	if b.spill == nil || b.replayStopped || b.spill.segments.Load() == 0 {
+		return
+	}
+	names, err := b.spill.list()
+	if err != nil {
+		log.Log.Warnw("cannot list spilled batches", "err", err)
+		return
+	}
+	deadline := time.Now().Add(b.interval)
+	for _, name := range names {
+		if time.Now().After(deadline) {
+			return
+		}
+		batch, err := b.spill.read(name)
+		if err != nil {
+			log.Log.Errorw("cannot read spilled batch, moving it aside", "segment", name, "err", err)
+			if err := b.spill.quarantine(name); err != nil {
+				log.Log.Errorw("cannot move spilled batch", "segment", name, "err", err)
+				return
 			}
-			log.Log.Infow("preparing a batch write", "count", counter)
-			err := b.writeBatch()
-			if err != nil {
-				log.Log.Warnw("could not write a batch", "err", err)
-			} else {
-				log.Log.Infow("batch written", "count", counter)
+			continue
+		}
+		tables := len(batch)
+		if err := b.writeBatch(batch); err != nil {
+			b.fail(err)
+			// Keep only tables which weren't written, so they aren't duplicated on the next replay.
+			if len(batch) < tables {
+				if err := b.spill.write(batch); err == nil {
+					b.spill.remove(name)
+				}
 			}
-			counter = 0
+			return
+		}
+		if err := b.spill.remove(name); err != nil {
+			// Leaving the segment would write it again, so stop until it's dealt with.
+			log.Log.Errorw("cannot remove replayed batch, replay is stopped", "segment", name, "err", err)
+			b.replayStopped = true
+			return
 		}
+		log.Log.Infow("spilled batch replayed", "segment", name)
+	}
+}
+
+// fail schedules the next write attempt with exponential backoff.
+func (b *BatchWriter) fail(err error) {
+	b.failures.Add(1)
+	if b.backoff == 0 {
+		b.backoff = b.interval
+	} else {
+		b.backoff = min(b.backoff*2, b.maxBackoff)
 	}
+	b.retryAt = time.Now().Add(b.backoff)
+	log.Log.Warnw("could not write a batch", "err", err, "retry_in", b.backoff)
+}
+
+// spillBatch moves the current batch to disk, keeping it in memory if that fails.
+func (b *BatchWriter) spillBatch() {
+	count := b.pending.Load()
+	if err := b.spill.write(b.batch); err != nil {
+		log.Log.Warnw("could not spill a batch", "count", count, "err", err)
+		return
+	}
+	log.Log.Infow("batch spilled", "count", count)
+	b.resetBatch()
+}
 
-	// Write out the remaining records after Stop() has been called.
-	if counter > 0 {
-		err := b.writeBatch()
+// stop writes out or spills the remaining rows after Stop() has been called.
+func (b *BatchWriter) stop() {
+	for drained := false; !drained; {
+		select {
+		case el := <-b.rcvChan:
+			b.batch[el.table] = append(b.batch[el.table], el.args)
+			b.pending.Add(1)
+		default:
+			drained = true
+		}
+	}
+	if count := b.pending.Load(); count > 0 {
+		err := b.writeBatch(b.batch)
+		if err != nil && b.spill != nil {
+			err = b.spill.write(b.batch)
+		}
 		if err != nil {
-			log.Log.Warnw("could not write the last batch", "err", err)
+			count = countRows(b.batch)
+			log.Log.Errorw("could not write the last batch", "count", count, "err", err)
+			b.dropped[dropWriteFailed].Add(uint64(count))
 		}
+		b.resetBatch()
 	}
 	b.stopChan <- true
 }
 
+func countRows(batch map[string][][]interface{}) int64 {
+	var n int
+	for _, rows := range batch {
+		n += len(rows)
+	}
+	return int64(n)
+}
+
+func (b *BatchWriter) resetBatch() {
+	b.batch = map[string][][]interface{}{}
+	b.pending.Store(0)
+}
+
+// Stop makes the writer flush remaining rows and exit, rows written afterwards are rejected with ErrQueueFull.
+// The receiving channel is left open, so writes racing with Stop can't panic.
 func (b *BatchWriter) Stop() {
-	close(b.rcvChan)
+	b.stopMu.Lock()
+	defer b.stopMu.Unlock()
+	if b.stopped {
+		return
+	}
+	b.stopped = true
+	close(b.done)
 }
 
-func (b *BatchWriter) Write(r *reporter.PlaybackReport, addr string, ts string) error {
-	args, err := prepareArgs(r, addr, ts)
+func (b *BatchWriter) Write(r *reporter.PlaybackReport, loc Location, ts string) error {
+	args, err := prepareArgs(r, loc, ts)
 	if err != nil {
 		return err
 	}
-	b.rcvChan <- args
-	return nil
+	return b.enqueue(batchRow{tablePlayback, args})
+}
+
+// WriteEvent queues a playback event, see newEvent for supported payload types.
+func (b *BatchWriter) WriteEvent(e any, loc Location, ts string) error {
+	args, err := prepareEventArgs(e, loc, ts)
+	if err != nil {
+		return err
+	}
+	return b.enqueue(batchRow{tableEvents, args})
+}
+
+func (b *BatchWriter) enqueue(row batchRow) error {
+	b.stopMu.RLock()
+	defer b.stopMu.RUnlock()
+	if b.stopped {
+		b.dropped[dropQueueFull].Add(1)
+		return ErrQueueFull
+	}
+	select {
+	case b.rcvChan <- row:
+		return nil
+	default:
+		b.dropped[dropQueueFull].Add(1)
+		return ErrQueueFull
+	}
 }
 
-func (b *BatchWriter) writeBatch() error {
-	ph := fmt.Sprintf("(%s)", "?"+strings.Repeat(", ?", b.width-1))
+// writeBatch writes rows for each table in a separate transaction.
+// Tables already written are removed from the batch, so a retry doesn't write them again.
+func (b *BatchWriter) writeBatch(batch map[string][][]interface{}) error {
+	for table, rows := range batch {
+		if err := b.write(table, rows); err != nil {
+			return errors.Wrapf(err, "cannot write %s rows", table)
+		}
+		delete(batch, table)
+	}
+	return nil
+}
 
+func writeRows(table string, rows [][]interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	defer func() { b.batch = [][]interface{}{} }()
 
 	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "cannot begin")
 	}
-	q := prepareInsertQuery(ph)
+	q := insertQuery(table)
 	stmt, err := tx.Prepare(q)
 	if err != nil {
 		return errors.Wrap(err, "cannot prepare")
@@ -105,7 +354,7 @@ func (b *BatchWriter) writeBatch() error {
 	log.Log.Debugw("insert query prepared", "q", q)
 	defer stmt.Close()
 
-	for i, row := range b.batch {
+	for i, row := range rows {
 		_, err := stmt.Exec(row...)
 		if err != nil {
 			return err
@@ -1,14 +1,22 @@
 package olapdb
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
 	"github.com/Pallinder/go-randomdata"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -25,13 +33,14 @@ func (s *batchWriterSuite) TestBatch() {
 	days := 14 * 24 * time.Hour
 	number := 1000
 	reports := []*reporter.PlaybackReport{}
-	bw := NewBatchWriter(100*time.Millisecond, 16)
+	bw, err := NewBatchWriter(100 * time.Millisecond)
+	s.Require().NoError(err)
 	go bw.Start()
 
 	for t := range timeSeries(number, time.Now().Add(-days)) {
 		r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
 		ts := t.Format(time.RFC1123Z)
-		err := bw.Write(r, randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address()), ts)
+		err := bw.Write(r, Locate(randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address())), ts)
 		s.Require().NoError(err)
 		reports = append(reports, r)
 	}
@@ -72,3 +81,249 @@ func (s *batchWriterSuite) TestBatch() {
 		s.Equal(reports[i].Duration, retDurations[i].Duration)
 	}
 }
+
+// fakeClickHouse records rows written by a batch writer and fails writes while it's down.
+type fakeClickHouse struct {
+	mu   sync.Mutex
+	down bool
+	rows map[string][][]any
+}
+
+func (f *fakeClickHouse) write(table string, rows [][]any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("connection refused")
+	}
+	f.rows[table] = append(f.rows[table], rows...)
+	return nil
+}
+
+func (f *fakeClickHouse) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeClickHouse) count(table string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.rows[table])
+}
+
+func newTestBatchWriter(t *testing.T, options ...BatchWriterOption) (*BatchWriter, *fakeClickHouse) {
+	t.Helper()
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+	b, err := NewBatchWriter(10*time.Millisecond, options...)
+	require.NoError(t, err)
+	f := &fakeClickHouse{rows: map[string][][]any{}}
+	b.write = f.write
+	return b, f
+}
+
+// gatherBatchMetrics returns metric values keyed by metric name and the reason label if present.
+func gatherBatchMetrics(t *testing.T, b *BatchWriter) map[string]float64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(b))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	res := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			name := f.GetName()
+			for _, l := range m.GetLabel() {
+				name += "{" + l.GetValue() + "}"
+			}
+			if m.GetCounter() != nil {
+				res[name] = m.GetCounter().GetValue()
+			} else {
+				res[name] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	return res
+}
+
+func TestBatchWriterQueueFull(t *testing.T) {
+	b, _ := newTestBatchWriter(t, WithQueueSize(2))
+
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	ts := time.Now().Format(time.RFC1123Z)
+	require.NoError(t, b.Write(r, Locate("81.2.69.142"), ts))
+	require.NoError(t, b.WriteEvent(&reporter.StartupEvent{URL: r.URL, Ttff: 100}, Locate("81.2.69.142"), ts))
+	require.ErrorIs(t, b.Write(r, Locate("81.2.69.142"), ts), ErrQueueFull)
+	require.ErrorIs(t, b.WriteEvent(&reporter.StartupEvent{URL: r.URL, Ttff: 100}, Locate("81.2.69.142"), ts), ErrQueueFull)
+
+	m := gatherBatchMetrics(t, b)
+	assert.Equal(t, 2.0, m["watchman_batch_queue_depth"])
+	assert.Equal(t, 2.0, m["watchman_batch_queue_capacity"])
+	assert.Equal(t, 2.0, m["watchman_batch_dropped_total{queue_full}"])
+	assert.Zero(t, m["watchman_batch_dropped_total{write_failed}"])
+	assert.NotContains(t, m, "watchman_batch_spill_bytes")
+}
+
+func TestBatchWriterWriteAfterStop(t *testing.T) {
+	b, f := newTestBatchWriter(t)
+	go b.Start()
+
+	ts := time.Now().Format(time.RFC1123Z)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+			for range 200 {
+				err := b.Write(r, Locate("81.2.69.142"), ts)
+				if err != nil {
+					assert.ErrorIs(t, err, ErrQueueFull)
+				}
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	b.Stop()
+	b.Stop()
+	<-b.stopChan
+	wg.Wait()
+
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	require.ErrorIs(t, b.Write(r, Locate("81.2.69.142"), ts), ErrQueueFull)
+	// Every accepted row is written, the last write is counted as dropped.
+	m := gatherBatchMetrics(t, b)
+	assert.EqualValues(t, 800, float64(f.count(tablePlayback))+m["watchman_batch_dropped_total{queue_full}"]-1)
+}
+
+func TestBatchWriterBackoff(t *testing.T) {
+	b, _ := newTestBatchWriter(t, WithMaxBackoff(50*time.Millisecond))
+	var backoffs []time.Duration
+	for range 5 {
+		b.fail(errors.New("timeout"))
+		backoffs = append(backoffs, b.backoff)
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond,
+	}, backoffs)
+	assert.WithinDuration(t, time.Now().Add(50*time.Millisecond), b.retryAt, 10*time.Millisecond)
+	assert.EqualValues(t, 5, b.failures.Load())
+}
+
+func TestBatchWriterSpillAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	b, f := newTestBatchWriter(t, WithSpillDir(dir), WithMaxBackoff(20*time.Millisecond))
+	f.setDown(true)
+	go b.Start()
+
+	ts := time.Now().Format(time.RFC1123Z)
+	reports := []*reporter.PlaybackReport{}
+	for range 5 {
+		r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+		require.NoError(t, b.Write(r, Locate("81.2.69.142"), ts))
+		reports = append(reports, r)
+	}
+	require.NoError(t, b.WriteEvent(&reporter.SeekEvent{URL: reports[0].URL, From: 1, To: 2}, Locate("81.2.69.142"), ts))
+
+	require.Eventually(t, func() bool {
+		m := gatherBatchMetrics(t, b)
+		return m["watchman_batch_queue_depth"] == 0 && m["watchman_batch_spill_segments"] > 0
+	}, 5*time.Second, 10*time.Millisecond, "rows weren't spilled")
+	m := gatherBatchMetrics(t, b)
+	assert.Positive(t, m["watchman_batch_spill_bytes"])
+	assert.Positive(t, m["watchman_batch_write_failures_total"])
+	assert.Zero(t, f.count(tablePlayback))
+
+	f.setDown(false)
+	require.Eventually(t, func() bool {
+		return f.count(tablePlayback) == len(reports) && f.count(tableEvents) == 1
+	}, 5*time.Second, 10*time.Millisecond, "spilled rows weren't replayed")
+
+	b.Stop()
+	<-b.stopChan
+	m = gatherBatchMetrics(t, b)
+	assert.Zero(t, m["watchman_batch_spill_segments"])
+	assert.Zero(t, m["watchman_batch_spill_bytes"])
+	assert.Zero(t, m["watchman_batch_dropped_total{write_failed}"])
+
+	// Replayed rows keep their column types.
+	written := map[string][]any{}
+	for _, row := range f.rows[tablePlayback] {
+		written[row[0].(string)] = row
+	}
+	for _, r := range reports {
+		args, err := prepareArgs(r, Locate("81.2.69.142"), ts)
+		require.NoError(t, err)
+		assert.Equal(t, args, written[r.URL])
+	}
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpillLegacyIP(t *testing.T) {
+	s, err := newSpill(t.TempDir(), 0)
+	require.NoError(t, err)
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	args, err := prepareArgs(r, Locate("81.2.69.142"), time.Now().Format(time.RFC1123Z))
+	require.NoError(t, err)
+	asn := slices.Index(tableColumns[tablePlayback], "ASN")
+	legacy := slices.Insert(slices.Clone(args), asn+1, any("81.2.69.0"))
+	require.NoError(t, s.write(map[string][][]any{tablePlayback: {legacy}}))
+
+	names, err := s.list()
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	batch, err := s.read(names[0])
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{args}, batch[tablePlayback])
+}
+
+func TestBatchWriterSpillOnStop(t *testing.T) {
+	dir := t.TempDir()
+	b, f := newTestBatchWriter(t, WithSpillDir(dir))
+	f.setDown(true)
+	go b.Start()
+
+	ts := time.Now().Format(time.RFC1123Z)
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	require.NoError(t, b.Write(r, Locate("81.2.69.142"), ts))
+	b.Stop()
+	<-b.stopChan
+	assert.Zero(t, gatherBatchMetrics(t, b)["watchman_batch_dropped_total{write_failed}"])
+
+	// A corrupted segment is moved aside while the rest are replayed after a restart.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "segment-0"+segmentExt), []byte("garbage"), 0o644))
+	b, f = newTestBatchWriter(t, WithSpillDir(dir))
+	assert.EqualValues(t, 2, b.spill.segments.Load())
+	go b.Start()
+	require.Eventually(t, func() bool {
+		return f.count(tablePlayback) == 1 && b.spill.segments.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond, "spilled rows weren't replayed")
+	b.Stop()
+	<-b.stopChan
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "segment-0"+segmentExt+".corrupt")}, files)
+}
+
+func TestBatchWriterSpillFull(t *testing.T) {
+	b, f := newTestBatchWriter(t, WithSpillDir(t.TempDir()), WithMaxSpillSize(1), WithQueueSize(3))
+	f.setDown(true)
+	go b.Start()
+
+	ts := time.Now().Format(time.RFC1123Z)
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	// Rows are kept in memory when they can't be spilled, so the queue eventually fills up.
+	require.Eventually(t, func() bool {
+		return errors.Is(b.Write(r, Locate("81.2.69.142"), ts), ErrQueueFull)
+	}, 5*time.Second, time.Millisecond)
+	assert.Zero(t, b.spill.segments.Load())
+
+	b.Stop()
+	<-b.stopChan
+	m := gatherBatchMetrics(t, b)
+	assert.Equal(t, 6.0, m["watchman_batch_dropped_total{write_failed}"])
+	assert.Positive(t, m["watchman_batch_dropped_total{queue_full}"])
+}
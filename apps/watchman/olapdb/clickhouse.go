@@ -0,0 +1,95 @@
+package olapdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClickHouse is the storage backed by the connection and batch writer set up by Connect.
+type ClickHouse struct{}
+
+func (ClickHouse) Name() string {
+	return StorageClickHouse
+}
+
+func (ClickHouse) WriteReport(r *reporter.PlaybackReport, loc Location, ts string) error {
+	return batchWriter.Write(r, loc, ts)
+}
+
+func (ClickHouse) WriteEvent(e any, loc Location, ts string) error {
+	return batchWriter.WriteEvent(e, loc, ts)
+}
+
+// Describe implements prometheus.Collector for the batch writer.
+func (ClickHouse) Describe(ch chan<- *prometheus.Desc) {
+	batchWriter.Describe(ch)
+}
+
+// Collect implements prometheus.Collector for the batch writer.
+func (ClickHouse) Collect(ch chan<- prometheus.Metric) {
+	batchWriter.Collect(ch)
+}
+
+// Close writes out the current batch.
+func (ClickHouse) Close() error {
+	batchWriter.Stop()
+	<-batchWriter.stopChan
+	return nil
+}
+
+func (ClickHouse) Rebuffering(ctx context.Context, a Aggregate) ([]RebufferingRow, error) {
+	return queryRebuffering(ctx, conn, clickhouseDialect{}, a)
+}
+
+func (ClickHouse) Bitrate(ctx context.Context, a Aggregate) ([]BitrateRow, error) {
+	return queryBitrate(ctx, conn, clickhouseDialect{}, a)
+}
+
+func (ClickHouse) Startup(ctx context.Context, a Aggregate) ([]StartupRow, error) {
+	return queryStartup(ctx, conn, clickhouseDialect{}, a)
+}
+
+type clickhouseDialect struct{}
+
+var clickhouseBuckets = map[string]string{
+	BucketMinute: "toStartOfMinute(Timestamp)",
+	BucketHour:   "toStartOfHour(Timestamp)",
+	BucketDay:    "toStartOfDay(Timestamp)",
+}
+
+func (clickhouseDialect) table(name string) string {
+	return database + "." + name
+}
+
+func (clickhouseDialect) bucket(bucket string) (string, bool) {
+	expr, ok := clickhouseBuckets[bucket]
+	return expr, ok
+}
+
+func (clickhouseDialect) timeArg(t time.Time) any {
+	return t
+}
+
+func (clickhouseDialect) countIf(cond string) string {
+	return "countIf(" + cond + ")"
+}
+
+func (clickhouseDialect) sum(col string) string {
+	return "sum(" + col + ")"
+}
+
+func (clickhouseDialect) avg(col string) string {
+	return "ifNotFinite(avg(" + col + "), 0)"
+}
+
+func (clickhouseDialect) avgIf(col, cond string) string {
+	return "ifNotFinite(avgIf(" + col + ", " + cond + "), 0)"
+}
+
+func (clickhouseDialect) medianIf(col, cond string) string {
+	return "ifNotFinite(quantileIf(0.5)(" + col + ", " + cond + "), 0)"
+}
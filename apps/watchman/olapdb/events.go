@@ -0,0 +1,110 @@
+package olapdb
+
+import (
+	"fmt"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+)
+
+// Kinds of playback events, stored in the Kind column of the events table.
+const (
+	EventStartup       = "startup"
+	EventError         = "error"
+	EventSeek          = "seek"
+	EventQualitySwitch = "quality"
+	EventSessionEnd    = "end"
+)
+
+// event is a row of the events table. Columns not relevant to the event kind are left zero.
+type event struct {
+	kind                                  string
+	url, protocol, player, userID, device string
+
+	position     uint32
+	ttff         uint32
+	bitrate      uint32
+	errorCode    string
+	errorMessage string
+	fatal        uint8
+	seekTo       uint32
+	toBitrate    uint32
+	reason       string
+	watched      uint32
+}
+
+// newEvent maps a playback event payload onto an events table row.
+func newEvent(e any) (*event, error) {
+	var ev *event
+	switch e := e.(type) {
+	case *reporter.StartupEvent:
+		ev = &event{kind: EventStartup, url: e.URL, protocol: e.Protocol, player: e.Player, userID: e.UserID, device: e.Device}
+		ev.ttff = uint32(e.Ttff)
+		if e.Bitrate != nil {
+			ev.bitrate = uint32(*e.Bitrate)
+		}
+	case *reporter.ErrorEvent:
+		ev = &event{kind: EventError, url: e.URL, protocol: e.Protocol, player: e.Player, userID: e.UserID, device: e.Device}
+		ev.position = uint32(e.Position)
+		ev.errorCode = e.Code
+		if e.Message != nil {
+			ev.errorMessage = *e.Message
+		}
+		if e.Fatal {
+			ev.fatal = 1
+		}
+	case *reporter.SeekEvent:
+		ev = &event{kind: EventSeek, url: e.URL, protocol: e.Protocol, player: e.Player, userID: e.UserID, device: e.Device}
+		ev.position = uint32(e.From)
+		ev.seekTo = uint32(e.To)
+	case *reporter.QualitySwitchEvent:
+		ev = &event{kind: EventQualitySwitch, url: e.URL, protocol: e.Protocol, player: e.Player, userID: e.UserID, device: e.Device}
+		ev.position = uint32(e.Position)
+		ev.bitrate = uint32(e.FromBitrate)
+		ev.toBitrate = uint32(e.ToBitrate)
+		ev.reason = e.Reason
+	case *reporter.SessionEndEvent:
+		ev = &event{kind: EventSessionEnd, url: e.URL, protocol: e.Protocol, player: e.Player, userID: e.UserID, device: e.Device}
+		ev.position = uint32(e.Position)
+		ev.watched = uint32(e.Watched)
+		ev.reason = e.Reason
+	default:
+		return nil, fmt.Errorf("unknown playback event type %T", e)
+	}
+	ev.player = playerRe.ReplaceAllLiteralString(ev.player, "")
+	return ev, nil
+}
+
+// prepareEventArgs returns values for tableColumns[tableEvents].
+func prepareEventArgs(e any, loc Location, ts string) ([]interface{}, error) {
+	ev, err := newEvent(e)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTimestamp(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		ev.kind,
+		ev.url,
+		t,
+		ev.protocol,
+		ev.player,
+		ev.userID,
+		ev.device,
+		loc.Area,
+		loc.SubArea,
+		loc.ASN,
+		ev.position,
+		ev.ttff,
+		ev.bitrate,
+		ev.errorCode,
+		ev.errorMessage,
+		ev.fatal,
+		ev.seekTo,
+		ev.toBitrate,
+		ev.reason,
+		ev.watched,
+	}, nil
+}
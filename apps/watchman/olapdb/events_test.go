@@ -0,0 +1,85 @@
+package olapdb
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareEventArgs(t *testing.T) {
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+
+	base := reporter.StartupEvent{URL: "what#1", Protocol: "hls", Player: "sg-p2,proxy", UserID: "123", Device: "web"}
+	bitrate := int32(2_000_000)
+	message := "network failure"
+	ts := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		event  any
+		values map[string]any
+	}{
+		{
+			&reporter.StartupEvent{URL: base.URL, Protocol: base.Protocol, Player: base.Player, UserID: base.UserID, Device: base.Device, Ttff: 1200, Bitrate: &bitrate},
+			map[string]any{"Kind": EventStartup, "TTFF": uint32(1200), "Bitrate": uint32(2_000_000), "Position": uint32(0)},
+		},
+		{
+			&reporter.ErrorEvent{URL: base.URL, Protocol: base.Protocol, Player: base.Player, UserID: base.UserID, Device: base.Device, Position: 500, Code: "MEDIA_ERR_NETWORK", Message: &message, Fatal: true},
+			map[string]any{"Kind": EventError, "Position": uint32(500), "ErrorCode": "MEDIA_ERR_NETWORK", "ErrorMessage": message, "Fatal": uint8(1)},
+		},
+		{
+			&reporter.SeekEvent{URL: base.URL, Protocol: base.Protocol, Player: base.Player, UserID: base.UserID, Device: base.Device, From: 1000, To: 60000},
+			map[string]any{"Kind": EventSeek, "Position": uint32(1000), "SeekTo": uint32(60000)},
+		},
+		{
+			&reporter.QualitySwitchEvent{URL: base.URL, Protocol: base.Protocol, Player: base.Player, UserID: base.UserID, Device: base.Device, Position: 3000, FromBitrate: 800_000, ToBitrate: 2_400_000, Reason: "auto"},
+			map[string]any{"Kind": EventQualitySwitch, "Bitrate": uint32(800_000), "ToBitrate": uint32(2_400_000), "Reason": "auto"},
+		},
+		{
+			&reporter.SessionEndEvent{URL: base.URL, Protocol: base.Protocol, Player: base.Player, UserID: base.UserID, Device: base.Device, Position: 90000, Watched: 85000, Reason: "closed"},
+			map[string]any{"Kind": EventSessionEnd, "Position": uint32(90000), "Watched": uint32(85000), "Reason": "closed"},
+		},
+	}
+
+	columns := tableColumns[tableEvents]
+	for _, c := range cases {
+		args, err := prepareEventArgs(c.event, Locate("81.2.69.142"), ts.Format(time.RFC1123Z))
+		require.NoError(t, err)
+		require.Len(t, args, len(columns))
+		row := map[string]any{}
+		for i, col := range columns {
+			row[col] = args[i]
+		}
+		kind := c.values["Kind"]
+		assert.Equal(t, "what#1", row["URL"], kind)
+		assert.Equal(t, "sg-p2", row["Player"], kind)
+		assert.Equal(t, "gb", row["Area"], kind)
+		assert.Equal(t, "eng", row["SubArea"], kind)
+		assert.True(t, ts.Equal(row["Timestamp"].(time.Time)), kind)
+		for col, v := range c.values {
+			assert.Equal(t, v, row[col], "%s %s", kind, col)
+		}
+	}
+
+	_, err := prepareEventArgs(&reporter.PlaybackReport{}, Location{}, "")
+	assert.ErrorContains(t, err, "unknown playback event type")
+}
+
+func TestInsertQuery(t *testing.T) {
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+	database = "db"
+	for table, columns := range tableColumns {
+		q := insertQuery(table)
+		assert.Equal(t, len(columns), strings.Count(q, "?"), table)
+		assert.True(t, strings.HasPrefix(q, "INSERT INTO db."+table+" ("+columns[0]+", "), q)
+	}
+	args, err := prepareArgs(PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport), Locate("81.2.69.142"), "")
+	require.NoError(t, err)
+	assert.Len(t, args, len(tableColumns[tablePlayback]))
+}
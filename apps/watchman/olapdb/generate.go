@@ -71,7 +71,7 @@ func Generate(number int, days int) {
 	for t := range timeSeries(number, time.Now().Add(time.Duration(-days)*24*time.Hour)) {
 		r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
 		ts := t.Format(time.RFC1123Z)
-		err := Write(stmt, r, randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address()), ts)
+		err := Write(stmt, r, Locate(randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address())), ts)
 		if err != nil {
 			l.Fatal(err)
 		}
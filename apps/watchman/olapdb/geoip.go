@@ -7,7 +7,10 @@ import (
 	"github.com/oschwald/geoip2-golang"
 )
 
-var geodb *geoip2.Reader
+var (
+	geodb *geoip2.Reader
+	asndb *geoip2.Reader
+)
 
 func OpenGeoDB(file string) error {
 	var err error
@@ -18,17 +21,45 @@ func OpenGeoDB(file string) error {
 	return nil
 }
 
-func getArea(ip string) (string, string) {
-	var area, subarea string
-
-	record, err := geodb.City(net.ParseIP(ip))
+// OpenASNDB opens a GeoLite2 ASN database, without it autonomous system numbers are stored as 0.
+func OpenASNDB(file string) error {
+	var err error
+	asndb, err = geoip2.Open(file)
 	if err != nil {
-		return "", ""
+		return err
 	}
+	return nil
+}
+
+// Location is all that's stored about where a report came from.
+type Location struct {
+	// Area is a lowercase ISO country code.
+	Area string
+	// SubArea is a lowercase ISO code of the top-level subdivision (state, region or province) of the country.
+	SubArea string
+	// ASN is the autonomous system number of the network.
+	ASN uint32
+}
 
-	area = record.Country.IsoCode
-	if len(record.Subdivisions) >= 2 {
-		subarea = record.Subdivisions[1].IsoCode
+// Locate looks up the location of the IP address, fields are empty for unknown IPs or databases that aren't open.
+func Locate(ip string) Location {
+	var loc Location
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return loc
+	}
+	if geodb != nil {
+		if record, err := geodb.City(addr); err == nil {
+			loc.Area = strings.ToLower(record.Country.IsoCode)
+			if len(record.Subdivisions) > 0 {
+				loc.SubArea = strings.ToLower(record.Subdivisions[0].IsoCode)
+			}
+		}
+	}
+	if asndb != nil {
+		if record, err := asndb.ASN(addr); err == nil {
+			loc.ASN = uint32(record.AutonomousSystemNumber)
+		}
 	}
-	return strings.ToLower(area), strings.ToLower(subarea)
+	return loc
 }
@@ -8,14 +8,20 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func Test_getArea(t *testing.T) {
+func TestLocate(t *testing.T) {
 	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
 	err := OpenGeoDB(p)
 	require.NoError(t, err)
-	a, s := getArea("81.2.69.142")
-	assert.Equal(t, "gb", a)
-	assert.Equal(t, "", s)
-	a, s = getArea("2001:41d0:303:df3e::")
-	assert.Equal(t, "", a)
-	assert.Equal(t, "", s)
+	p, _ = filepath.Abs(filepath.Join("./testdata", "GeoLite2-ASN-Test.mmdb"))
+	require.NoError(t, OpenASNDB(p))
+
+	assert.Equal(t, Location{"gb", "eng", 20712}, Locate("81.2.69.142"))
+	// The test city database has no data for 81.2.69.0/25, the ASN database covers the whole /24.
+	assert.Equal(t, Location{ASN: 20712}, Locate("81.2.69.0"))
+	assert.Equal(t, Location{}, Locate("2001:41d0:303:df3e::"))
+	assert.Equal(t, Location{ASN: 64496}, Locate("2001:db8::"))
+	assert.Equal(t, Location{}, Locate(""))
+
+	asndb = nil
+	assert.Equal(t, Location{"gb", "eng", 0}, Locate("81.2.69.142"))
 }
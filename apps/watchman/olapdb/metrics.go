@@ -0,0 +1,44 @@
+package olapdb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueDepthDesc = prometheus.NewDesc(
+		"watchman_batch_queue_depth", "Rows waiting to be written to ClickHouse", nil, nil)
+	queueCapacityDesc = prometheus.NewDesc(
+		"watchman_batch_queue_capacity", "Maximum number of rows waiting to be written before new ones are rejected", nil, nil)
+	spillBytesDesc = prometheus.NewDesc(
+		"watchman_batch_spill_bytes", "Size of batches spilled to disk and waiting to be replayed", nil, nil)
+	spillSegmentsDesc = prometheus.NewDesc(
+		"watchman_batch_spill_segments", "Number of batches spilled to disk and waiting to be replayed", nil, nil)
+	writeFailuresDesc = prometheus.NewDesc(
+		"watchman_batch_write_failures_total", "Failed ClickHouse batch writes", nil, nil)
+	droppedDesc = prometheus.NewDesc(
+		"watchman_batch_dropped_total", "Rows which were not written, by reason", []string{"reason"}, nil)
+)
+
+// Describe implements prometheus.Collector.
+func (b *BatchWriter) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		queueDepthDesc, queueCapacityDesc, spillBytesDesc, spillSegmentsDesc, writeFailuresDesc, droppedDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. Spill metrics are only exported when spilling is enabled.
+func (b *BatchWriter) Collect(ch chan<- prometheus.Metric) {
+	depth := len(b.rcvChan) + int(b.pending.Load())
+	ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(depth))
+	ch <- prometheus.MustNewConstMetric(queueCapacityDesc, prometheus.GaugeValue, float64(b.capacity))
+	if b.spill != nil {
+		ch <- prometheus.MustNewConstMetric(spillBytesDesc, prometheus.GaugeValue, float64(b.spill.size.Load()))
+		ch <- prometheus.MustNewConstMetric(spillSegmentsDesc, prometheus.GaugeValue, float64(b.spill.segments.Load()))
+	}
+	ch <- prometheus.MustNewConstMetric(writeFailuresDesc, prometheus.CounterValue, float64(b.failures.Load()))
+	for reason, n := range b.dropped {
+		ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue, float64(n.Load()), reason)
+	}
+}
@@ -0,0 +1,179 @@
+package olapdb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+)
+
+const (
+	defaultMaxFileSize = 100 << 20
+	defaultMaxFileAge  = time.Hour
+)
+
+// NDJSON is a write-only storage appending rows as JSON lines to files in a directory,
+// one file per table at a time. Files are rotated when they exceed the maximum size or age,
+// closed files are ready to be shipped elsewhere.
+type NDJSON struct {
+	dir         string
+	maxFileSize int64
+	maxFileAge  time.Duration
+	now         func() time.Time
+
+	mu    sync.Mutex
+	files map[string]*ndjsonFile
+}
+
+type ndjsonFile struct {
+	f       *os.File
+	w       *bufio.Writer
+	size    int64
+	created time.Time
+}
+
+type NDJSONOption func(s *NDJSON)
+
+// WithMaxFileSize sets the size in bytes after which a file is rotated, zero keeps the default.
+func WithMaxFileSize(size int64) NDJSONOption {
+	return func(s *NDJSON) {
+		if size > 0 {
+			s.maxFileSize = size
+		}
+	}
+}
+
+// WithMaxFileAge sets the age after which a file is rotated, zero keeps the default.
+func WithMaxFileAge(age time.Duration) NDJSONOption {
+	return func(s *NDJSON) {
+		if age > 0 {
+			s.maxFileAge = age
+		}
+	}
+}
+
+// NewNDJSON creates the directory if it doesn't exist.
+func NewNDJSON(dir string, options ...NDJSONOption) (*NDJSON, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("ndjson directory path is missing")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &NDJSON{
+		dir:         dir,
+		maxFileSize: defaultMaxFileSize,
+		maxFileAge:  defaultMaxFileAge,
+		now:         time.Now,
+		files:       map[string]*ndjsonFile{},
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s, nil
+}
+
+func (s *NDJSON) Name() string {
+	return StorageNDJSON
+}
+
+func (s *NDJSON) WriteReport(r *reporter.PlaybackReport, loc Location, ts string) error {
+	args, err := prepareArgs(r, loc, ts)
+	if err != nil {
+		return err
+	}
+	return s.write(tablePlayback, args)
+}
+
+func (s *NDJSON) WriteEvent(e any, loc Location, ts string) error {
+	args, err := prepareEventArgs(e, loc, ts)
+	if err != nil {
+		return err
+	}
+	return s.write(tableEvents, args)
+}
+
+func (s *NDJSON) write(table string, args []any) error {
+	row := map[string]any{}
+	for i, c := range tableColumns[table] {
+		if t, ok := args[i].(time.Time); ok {
+			args[i] = t.UTC().Format(time.RFC3339)
+		}
+		row[c] = args[i]
+	}
+	line, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.file(table)
+	if err != nil {
+		return err
+	}
+	n, err := f.w.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		return err
+	}
+	// Flushing every line keeps files complete for anyone tailing them.
+	return f.w.Flush()
+}
+
+// file returns the current file for the table, rotating it if it's due.
+func (s *NDJSON) file(table string) (*ndjsonFile, error) {
+	now := s.now()
+	f := s.files[table]
+	if f != nil && f.size < s.maxFileSize && now.Sub(f.created) < s.maxFileAge {
+		return f, nil
+	}
+	if f != nil {
+		delete(s.files, table)
+		if err := f.close(); err != nil {
+			return nil, err
+		}
+	}
+
+	base := fmt.Sprintf("%s-%s", table, now.UTC().Format("20060102T150405Z"))
+	name := filepath.Join(s.dir, base+".ndjson")
+	for i := 1; ; i++ {
+		fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			name = filepath.Join(s.dir, fmt.Sprintf("%s-%d.ndjson", base, i))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		f = &ndjsonFile{f: fh, w: bufio.NewWriter(fh), created: now}
+		s.files[table] = f
+		return f, nil
+	}
+}
+
+func (f *ndjsonFile) close() error {
+	if err := f.w.Flush(); err != nil {
+		f.f.Close()
+		return err
+	}
+	return f.f.Close()
+}
+
+func (s *NDJSON) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for table, f := range s.files {
+		errs = append(errs, f.close())
+		delete(s.files, table)
+	}
+	return errors.Join(errs...)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
@@ -21,7 +22,27 @@ var (
 	playerRe    = regexp.MustCompile(`,.*$`)
 )
 
-func Connect(url string, dbName string) error {
+const (
+	tablePlayback = "playback"
+	tableEvents   = "events"
+)
+
+// tableColumns lists columns in the order rows for each table are prepared in.
+// Client addresses aren't stored, only their Location is.
+var tableColumns = map[string][]string{
+	tablePlayback: {
+		"URL", "Duration", "Timestamp", "Position", "RelPosition", "RebufCount",
+		"RebufDuration", "Protocol", "Cache", "Player", "UserID", "Bandwidth", "Bitrate", "Device", "Area", "SubArea", "ASN",
+	},
+	tableEvents: {
+		"Kind", "URL", "Timestamp", "Protocol", "Player", "UserID", "Device", "Area", "SubArea", "ASN",
+		"Position", "TTFF", "Bitrate", "ErrorCode", "ErrorMessage", "Fatal", "SeekTo",
+		"ToBitrate", "Reason", "Watched",
+	},
+}
+
+// Connect opens the ClickHouse database, creating or migrating its schema, and starts a batch writer for it.
+func Connect(url string, dbName string, options ...BatchWriterOption) error {
 	var err error
 	if dbName == "" {
 		dbName = database
@@ -34,31 +55,33 @@ func Connect(url string, dbName string) error {
 
 	go ping()
 
-	MigrateUp(dbName)
+	// ClickHouse might be unavailable yet, rows are queued until it's up.
+	if err := MigrateUp(dbName); err != nil {
+		log.Log.Named("clickhouse").Errorw("cannot migrate database", "database", dbName, "err", err)
+	}
 
-	batchWriter = NewBatchWriter(2*time.Second, 16)
+	batchWriter, err = NewBatchWriter(2*time.Second, options...)
+	if err != nil {
+		return err
+	}
 	go batchWriter.Start()
+	store = ClickHouse{}
 
 	log.Log.Named("clickhouse").Infof("connected to clickhouse server %v (database=%v)", url, dbName)
 	return nil
 }
 
-func prepareArgs(r *reporter.PlaybackReport, addr string, ts string) ([]interface{}, error) {
+func prepareArgs(r *reporter.PlaybackReport, loc Location, ts string) ([]interface{}, error) {
 	var (
 		t                  time.Time
 		err                error
 		bandwidth, bitrate uint32
 		cache              string
 	)
-	if ts != "" {
-		t, err = time.Parse(time.RFC1123Z, ts)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		t = time.Now()
+	t, err = parseTimestamp(ts)
+	if err != nil {
+		return nil, err
 	}
-	area, subarea := getArea(addr)
 
 	if r.Bandwidth != nil {
 		bandwidth = uint32(*r.Bandwidth)
@@ -88,14 +111,22 @@ func prepareArgs(r *reporter.PlaybackReport, addr string, ts string) ([]interfac
 		bandwidth,
 		bitrate,
 		r.Device,
-		area,
-		subarea,
-		addr,
+		loc.Area,
+		loc.SubArea,
+		loc.ASN,
 	}, nil
 }
 
-func Write(stmt *sql.Stmt, r *reporter.PlaybackReport, addr string, ts string) error {
-	args, err := prepareArgs(r, addr, ts)
+// parseTimestamp parses RFC1123Z report timestamps, returning the current time for empty ones.
+func parseTimestamp(ts string) (time.Time, error) {
+	if ts == "" {
+		return time.Now(), nil
+	}
+	return time.Parse(time.RFC1123Z, ts)
+}
+
+func Write(stmt *sql.Stmt, r *reporter.PlaybackReport, loc Location, ts string) error {
+	args, err := prepareArgs(r, loc, ts)
 	if err != nil {
 		return err
 	}
@@ -105,11 +136,11 @@ func Write(stmt *sql.Stmt, r *reporter.PlaybackReport, addr string, ts string) e
 	}
 	log.Log.Named("clickhouse").Debugw(
 		"playback record written",
-		"user_id", r.UserID, "url", r.URL, "rebuf_count", r.RebufCount, "ip", addr, "ts", args[2])
+		"user_id", r.UserID, "url", r.URL, "rebuf_count", r.RebufCount, "area", loc.Area, "ts", args[2])
 	return nil
 }
 
-func WriteOne(r *reporter.PlaybackReport, addr string, ts string) error {
+func WriteOne(r *reporter.PlaybackReport, loc Location, ts string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 	tx, err := conn.BeginTx(ctx, nil)
@@ -122,7 +153,7 @@ func WriteOne(r *reporter.PlaybackReport, addr string, ts string) error {
 	}
 	defer stmt.Close()
 
-	if err := Write(stmt, r, addr, ts); err != nil {
+	if err := Write(stmt, r, loc, ts); err != nil {
 		return errors.Wrap(err, "cannot exec")
 	}
 	if err := tx.Commit(); err != nil {
@@ -131,12 +162,8 @@ func WriteOne(r *reporter.PlaybackReport, addr string, ts string) error {
 	return nil
 }
 
-func BatchWrite(r *reporter.PlaybackReport, addr string, ts string) error {
-	return batchWriter.Write(r, addr, ts)
-}
-
 func prepareWrite(tx *sql.Tx) (*sql.Stmt, error) {
-	return tx.Prepare(prepareInsertQuery("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"))
+	return tx.Prepare(insertQuery(tablePlayback))
 }
 
 func ping() {
@@ -148,11 +175,10 @@ func ping() {
 	}
 }
 
-func prepareInsertQuery(values string) string {
-	return fmt.Sprintf(`
-		INSERT INTO %v.playback
-			(URL, Duration, Timestamp, Position, RelPosition, RebufCount,
-				RebufDuration, Protocol, Cache, Player, UserID, Bandwidth, Bitrate, Device, Area, SubArea, IP)
-		VALUES %v
-	`, database, values)
+// insertQuery returns a single row insert statement for the table, with placeholders for all its columns.
+func insertQuery(table string) string {
+	columns := tableColumns[table]
+	return fmt.Sprintf(
+		"INSERT INTO %v.%v (%v) VALUES (?%v)",
+		database, table, strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1))
 }
@@ -47,7 +47,7 @@ func (s *BaseOlapdbSuite) SetupSuite() {
 func (s *olapdbSuite) TestWriteOne() {
 	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
 	ts := time.Now().Format(time.RFC1123Z)
-	err := WriteOne(r, randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address()), ts)
+	err := WriteOne(r, Locate(randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address())), ts)
 	s.Require().NoError(err)
 
 	var (
@@ -68,7 +68,7 @@ func (s *olapdbSuite) TestWriteGarbled() {
 	cleanPlayer := r.Player
 	r.Player += ", some-nonsense-at-the-end-abcbcbdagadwsedaddff"
 	ts := time.Now().Format(time.RFC1123Z)
-	err := WriteOne(r, randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address()), ts)
+	err := WriteOne(r, Locate(randomdata.StringSample(randomdata.IpV4Address(), randomdata.IpV6Address())), ts)
 	s.Require().NoError(err)
 
 	var (
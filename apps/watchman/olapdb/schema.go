@@ -3,9 +3,154 @@ package olapdb
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
 )
 
+// Retention sets how long data is kept at each level of detail.
+// Reports and events are rolled up into hourly and daily tables as they are inserted,
+// rollups don't have URLs, user IDs or IPs.
+type Retention struct {
+	// Raw is how long reports and events are kept as they were received.
+	Raw time.Duration
+	// Hourly is how long hourly rollups are kept.
+	Hourly time.Duration
+	// Daily is how long daily rollups are kept.
+	Daily time.Duration
+}
+
+var DefaultRetention = Retention{
+	Raw:    15 * 24 * time.Hour,
+	Hourly: 90 * 24 * time.Hour,
+	Daily:  2 * 365 * 24 * time.Hour,
+}
+
+// retention is used by MigrateUp and the SQLite storage, set by SetRetention.
+var retention = DefaultRetention
+
+// SetRetention validates and sets retention for storages opened afterwards. Zero values keep defaults.
+func SetRetention(r Retention) error {
+	if r.Raw == 0 {
+		r.Raw = DefaultRetention.Raw
+	}
+	if r.Hourly == 0 {
+		r.Hourly = DefaultRetention.Hourly
+	}
+	if r.Daily == 0 {
+		r.Daily = DefaultRetention.Daily
+	}
+	for _, d := range []time.Duration{r.Raw, r.Hourly, r.Daily} {
+		if d < time.Hour || d%time.Hour != 0 {
+			return fmt.Errorf("retention must be a positive number of hours, got %v", d)
+		}
+	}
+	if r.Hourly < r.Raw || r.Daily < r.Hourly {
+		return fmt.Errorf("rollups must be kept longer than the data they aggregate: %+v", r)
+	}
+	retention = r
+	return nil
+}
+
+// ttl returns a table TTL clause for the column and the normalized form ClickHouse reports it in.
+func ttl(column string, d time.Duration) (string, string) {
+	hours := int(d / time.Hour)
+	return fmt.Sprintf("TTL %s + INTERVAL %d HOUR", column, hours),
+		fmt.Sprintf("TTL %s + toIntervalHour(%d)", column, hours)
+}
+
+// rollup is an aggregated copy of a raw table, filled by a materialized view.
+type rollup struct {
+	table, source string
+	bucket        string
+	retention     time.Duration
+	columns       string
+	keys          string
+	selects       string
+}
+
+// rollupKeys are dimensions rollups keep, in addition to the time bucket.
+const rollupKeys = "Player, Area, SubArea, ASN, Device, Protocol"
+
+const playbackRollupColumns = `
+		"Reports" UInt64,
+		"PlaybackMs" UInt64,
+		"Rebuffers" UInt64,
+		"RebufMs" UInt64,
+		"BitrateSum" UInt64 COMMENT 'Sum of bitrates reported, for reports with a known bitrate',
+		"BitrateReports" UInt64,
+		"BandwidthSum" UInt64 COMMENT 'Sum of bandwidths reported, for reports with a known bandwidth',
+		"BandwidthReports" UInt64,
+		"Starts" UInt64 COMMENT 'Reports covering the beginning of a playback',
+		"RebufferedStarts" UInt64,
+		"StartRebufMs" UInt64`
+
+const playbackRollupSelects = `
+		count() AS Reports,
+		sum(Duration) AS PlaybackMs,
+		sum(RebufCount) AS Rebuffers,
+		sum(RebufDuration) AS RebufMs,
+		sumIf(Bitrate, Bitrate > 0) AS BitrateSum,
+		countIf(Bitrate > 0) AS BitrateReports,
+		sumIf(Bandwidth, Bandwidth > 0) AS BandwidthSum,
+		countIf(Bandwidth > 0) AS BandwidthReports,
+		countIf(Position <= Duration) AS Starts,
+		countIf(Position <= Duration AND RebufCount > 0) AS RebufferedStarts,
+		sumIf(RebufDuration, Position <= Duration) AS StartRebufMs`
+
+const eventsRollupColumns = `
+		"Events" UInt64,
+		"FatalErrors" UInt64,
+		"TTFFMs" UInt64 COMMENT 'Sum of time to first frame, startups only',
+		"WatchedMs" UInt64 COMMENT 'Sum of time spent playing, session ends only'`
+
+const eventsRollupSelects = `
+		count() AS Events,
+		sum(Fatal) AS FatalErrors,
+		sum(TTFF) AS TTFFMs,
+		sum(Watched) AS WatchedMs`
+
+func rollups(r Retention) []rollup {
+	return []rollup{
+		{tablePlayback + "_hourly", tablePlayback, "toStartOfHour", r.Hourly, playbackRollupColumns, rollupKeys, playbackRollupSelects},
+		{tablePlayback + "_daily", tablePlayback, "toStartOfDay", r.Daily, playbackRollupColumns, rollupKeys, playbackRollupSelects},
+		{tableEvents + "_hourly", tableEvents, "toStartOfHour", r.Hourly, eventsRollupColumns, "Kind, " + rollupKeys, eventsRollupSelects},
+		{tableEvents + "_daily", tableEvents, "toStartOfDay", r.Daily, eventsRollupColumns, "Kind, " + rollupKeys, eventsRollupSelects},
+	}
+}
+
+func (r rollup) create(dbName string) []string {
+	ttlClause, _ := ttl("Time", r.retention)
+	keyColumns := map[string]string{
+		"Kind": `"Kind" LowCardinality(String)`, "Player": `"Player" FixedString(16)`, "Area": `"Area" FixedString(2)`,
+		"SubArea": `"SubArea" FixedString(3)`, "ASN": `"ASN" UInt32`,
+		"Device": `"Device" FixedString(3)`, "Protocol": `"Protocol" FixedString(3)`,
+	}
+	keys := strings.Split(r.keys, ", ")
+	columns := []string{`"Time" DateTime`}
+	for _, k := range keys {
+		columns = append(columns, keyColumns[k])
+	}
+	return []string{
+		fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %[1]s.%[2]s
+	(
+		%[3]s,%[4]s
+	)
+	ENGINE = SummingMergeTree
+	ORDER BY (Time, %[5]s)
+	%[6]s`, dbName, r.table, strings.Join(columns, ",\n\t\t"), r.columns, r.keys, ttlClause),
+		fmt.Sprintf(`
+	CREATE MATERIALIZED VIEW IF NOT EXISTS %[1]s.%[2]s_mv TO %[1]s.%[2]s AS
+	SELECT
+		%[3]s(Timestamp) AS Time,
+		%[4]s,%[5]s
+	FROM %[1]s.%[6]s
+	GROUP BY Time, %[4]s`, dbName, r.table, r.bucket, r.keys, r.selects, r.source),
+	}
+}
+
 func MigrateUp(dbName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -13,6 +158,7 @@ func MigrateUp(dbName string) error {
 	if err != nil {
 		return err
 	}
+	rawTTL, _ := ttl("Timestamp", retention.Raw)
 	_, err = conn.Exec(fmt.Sprintf(`
 	CREATE TABLE IF NOT EXISTS %v.playback
 	(
@@ -32,16 +178,98 @@ func MigrateUp(dbName string) error {
 		"Device" FixedString(3),
 		"Area" FixedString(2),
 		"SubArea" FixedString(3),
-		"IP" IPv6
+		"ASN" UInt32
 	)
 	ENGINE = MergeTree
 	ORDER BY (Timestamp, UserID, URL)
-	TTL Timestamp + INTERVAL 15 DAY`, dbName))
+	%v`, dbName, rawTTL))
 	if err != nil {
 		return err
 	}
+	_, err = conn.Exec(fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %v.events
+	(
+		"Kind" LowCardinality(String),
+		"URL" String,
+		"Timestamp" DateTime,
+		"Protocol" FixedString(3),
+		"Player" FixedString(16),
+		"UserID" String,
+		"Device" FixedString(3),
+		"Area" FixedString(2),
+		"SubArea" FixedString(3),
+		"ASN" UInt32,
+		"Position" UInt32 COMMENT 'Stream position, ms, seek start for seeks',
+		"TTFF" UInt32 COMMENT 'Time to first frame, ms, startup only',
+		"Bitrate" UInt32 COMMENT 'Initial bitrate for startups, bitrate before the switch for quality switches',
+		"ErrorCode" LowCardinality(String),
+		"ErrorMessage" String,
+		"Fatal" UInt8,
+		"SeekTo" UInt32,
+		"ToBitrate" UInt32,
+		"Reason" LowCardinality(String) COMMENT 'Quality switch or session end reason',
+		"Watched" UInt32 COMMENT 'Time spent playing during the session, ms, session end only'
+	)
+	ENGINE = MergeTree
+	ORDER BY (Kind, Timestamp, UserID, URL)
+	%v`, dbName, rawTTL))
+	if err != nil {
+		return err
+	}
+	// Tables created before ASNs were stored and while client IPs still were.
+	for _, table := range []string{tablePlayback, tableEvents} {
+		_, err = conn.Exec(fmt.Sprintf(
+			`ALTER TABLE %v.%v ADD COLUMN IF NOT EXISTS "ASN" UInt32 AFTER "SubArea"`, dbName, table))
+		if err != nil {
+			return err
+		}
+		_, err = conn.Exec(fmt.Sprintf(`ALTER TABLE %v.%v DROP COLUMN IF EXISTS "IP"`, dbName, table))
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range rollups(retention) {
+		for _, q := range r.create(dbName) {
+			if _, err := conn.Exec(q); err != nil {
+				return fmt.Errorf("cannot create %s: %w", r.table, err)
+			}
+		}
+	}
+	return updateTTLs(dbName)
+}
+
+// updateTTLs applies retention changes to existing tables. TTLs are only modified when they differ,
+// since that rewrites expired parts.
+func updateTTLs(dbName string) error {
+	tables := map[string]string{}
+	for _, table := range []string{tablePlayback, tableEvents} {
+		tables[table] = "Timestamp"
+	}
+	durations := map[string]time.Duration{tablePlayback: retention.Raw, tableEvents: retention.Raw}
+	for _, r := range rollups(retention) {
+		tables[r.table] = "Time"
+		durations[r.table] = r.retention
+	}
+	for table, column := range tables {
+		var engine string
+		err := conn.QueryRow(
+			"SELECT engine_full FROM system.tables WHERE database = ? AND name = ?", dbName, table,
+		).Scan(&engine)
+		if err != nil {
+			return fmt.Errorf("cannot get %s engine: %w", table, err)
+		}
+		clause, normalized := ttl(column, durations[table])
+		if strings.Contains(engine, normalized) {
+			continue
+		}
+		log.Log.Named("clickhouse").Infow("updating table retention", "table", table, "ttl", clause)
+		if _, err := conn.Exec(fmt.Sprintf("ALTER TABLE %v.%v MODIFY %v", dbName, table, clause)); err != nil {
+			return fmt.Errorf("cannot update %s retention: %w", table, err)
+		}
+	}
 	return nil
 }
+
 func MigrateDown(dbName string) error {
 	_, err := conn.Exec(fmt.Sprintf(`DROP DATABASE %v`, dbName))
 	if err != nil {
@@ -0,0 +1,68 @@
+package olapdb
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRetention(t *testing.T) {
+	defer func() { retention = DefaultRetention }()
+
+	require.NoError(t, SetRetention(Retention{}))
+	assert.Equal(t, DefaultRetention, retention)
+
+	require.NoError(t, SetRetention(Retention{Raw: 72 * time.Hour}))
+	assert.Equal(t, Retention{72 * time.Hour, DefaultRetention.Hourly, DefaultRetention.Daily}, retention)
+
+	for _, r := range []Retention{
+		{Raw: 30 * time.Minute},
+		{Raw: 90 * time.Minute},
+		{Raw: -time.Hour},
+		{Raw: 200 * 24 * time.Hour},
+		{Hourly: 10 * 365 * 24 * time.Hour},
+	} {
+		assert.Error(t, SetRetention(r), r)
+	}
+	assert.Equal(t, 72*time.Hour, retention.Raw)
+}
+
+func TestTTL(t *testing.T) {
+	clause, normalized := ttl("Timestamp", 15*24*time.Hour)
+	assert.Equal(t, "TTL Timestamp + INTERVAL 360 HOUR", clause)
+	assert.Equal(t, "TTL Timestamp + toIntervalHour(360)", normalized)
+}
+
+func TestRollups(t *testing.T) {
+	identifier := regexp.MustCompile(`\b[A-Z][a-z][A-Za-z]*\b`)
+	for _, r := range rollups(DefaultRetention) {
+		queries := r.create("watchman")
+		require.Len(t, queries, 2)
+		assert.Contains(t, queries[0], "CREATE TABLE IF NOT EXISTS watchman."+r.table)
+		assert.Contains(t, queries[1], "TO watchman."+r.table+" AS")
+
+		ttlClause, _ := ttl("Time", r.retention)
+		assert.Contains(t, queries[0], ttlClause, r.table)
+
+		// Rollup keys and columns the view reads must exist in the source table.
+		for _, k := range strings.Split(r.keys, ", ") {
+			assert.Contains(t, tableColumns[r.source], k, r.table)
+			assert.Contains(t, queries[0], `"`+k+`"`, r.table)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(r.selects), "\n") {
+			expr, alias, ok := strings.Cut(strings.TrimSpace(line), " AS ")
+			require.True(t, ok, line)
+			alias = strings.TrimSuffix(alias, ",")
+			assert.Contains(t, r.columns, `"`+alias+`"`, r.table)
+			assert.NotContains(t, tableColumns[r.source], alias, "%s: alias %s shadows a source column", r.table, alias)
+			for _, col := range identifier.FindAllString(expr, -1) {
+				assert.True(t, slices.Contains(tableColumns[r.source], col), "%s: unknown column %s", r.table, col)
+			}
+		}
+	}
+}
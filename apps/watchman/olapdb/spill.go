@@ -0,0 +1,170 @@
+package olapdb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const segmentExt = ".wal"
+
+var errSpillFull = errors.New("spill directory is full")
+
+func init() {
+	// Row values are basic types, which gob registers itself, and timestamps.
+	gob.Register(time.Time{})
+}
+
+// spill keeps batches which couldn't be written to ClickHouse in segment files, one batch per file,
+// until they're replayed. Segments are written to a temporary file and renamed,
+// so a crash never leaves a partial segment behind.
+type spill struct {
+	dir     string
+	maxSize int64
+
+	size     atomic.Int64
+	segments atomic.Int64
+}
+
+// segment is the on-disk form of a batch.
+type segment struct {
+	Tables map[string][][]any
+}
+
+func newSpill(dir string, maxSize int64) (*spill, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &spill{dir: dir, maxSize: maxSize}
+	names, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	// Segments left by a previous run are replayed too.
+	for _, n := range names {
+		fi, err := os.Stat(n)
+		if err != nil {
+			return nil, err
+		}
+		s.size.Add(fi.Size())
+		s.segments.Add(1)
+	}
+	return s, nil
+}
+
+// write stores the batch in a new segment, failing with errSpillFull if it would exceed the maximum size.
+func (s *spill) write(batch map[string][][]any) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(segment{batch}); err != nil {
+		return err
+	}
+	if s.maxSize > 0 && s.size.Load()+int64(buf.Len()) > s.maxSize {
+		return errSpillFull
+	}
+
+	name := filepath.Join(s.dir, fmt.Sprintf("segment-%020d%s", time.Now().UnixNano(), segmentExt))
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.size.Add(int64(buf.Len()))
+	s.segments.Add(1)
+	return nil
+}
+
+// list returns segment file names, oldest first.
+func (s *spill) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), segmentExt) {
+			names = append(names, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *spill) read(name string) (map[string][][]any, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var seg segment
+	if err := gob.NewDecoder(f).Decode(&seg); err != nil {
+		return nil, fmt.Errorf("cannot decode segment %s: %w", name, err)
+	}
+	dropLegacyIP(seg.Tables)
+	return seg.Tables, nil
+}
+
+// dropLegacyIP removes client IPs from rows spilled while they were still stored right after ASN.
+func dropLegacyIP(tables map[string][][]any) {
+	for table, rows := range tables {
+		columns := tableColumns[table]
+		ip := slices.Index(columns, "ASN") + 1
+		for i, row := range rows {
+			if ip > 0 && len(row) == len(columns)+1 {
+				rows[i] = slices.Delete(row, ip, ip+1)
+			}
+		}
+	}
+}
+
+func (s *spill) remove(name string) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+	s.size.Add(-fi.Size())
+	s.segments.Add(-1)
+	return nil
+}
+
+// quarantine moves an unreadable segment out of the replay queue, keeping it for inspection.
+func (s *spill) quarantine(name string) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(name, name+".corrupt"); err != nil {
+		return err
+	}
+	s.size.Add(-fi.Size())
+	s.segments.Add(-1)
+	return nil
+}
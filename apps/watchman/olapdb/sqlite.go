@@ -0,0 +1,289 @@
+package olapdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is sqlite3 with the median aggregate function registered on every connection.
+const sqliteDriver = "sqlite3_watchman"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(c *sqlite3.SQLiteConn) error {
+			return c.RegisterAggregator("median", newMedian, true)
+		},
+	})
+}
+
+// SQLite is an embedded storage for running watchman locally and in tests.
+// Reports are written synchronously and timestamps are stored as Unix seconds.
+// Rows older than the raw data retention are deleted every hour, there are no rollups.
+type SQLite struct {
+	db   *sql.DB
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSQLite opens or creates the database file at path, ":memory:" keeps it in memory.
+func NewSQLite(path string) (*SQLite, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is missing")
+	}
+	dsn := path
+	if path != ":memory:" {
+		dsn = "file:" + path + "?_journal_mode=WAL&_busy_timeout=5000"
+	}
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection avoids lock contention between writers and keeps in-memory databases shared.
+	db.SetMaxOpenConns(1)
+	s := &SQLite{db: db, done: make(chan struct{})}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create sqlite schema: %w", err)
+	}
+	s.wg.Add(1)
+	go s.purge(retention.Raw, time.Hour)
+	return s, nil
+}
+
+// purge deletes rows older than retention every interval until the storage is closed.
+func (s *SQLite) purge(retention, interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.deleteBefore(time.Now().Add(-retention)); err != nil {
+			log.Log.Named("sqlite").Warnw("cannot delete expired rows", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *SQLite) deleteBefore(t time.Time) error {
+	for _, table := range []string{tablePlayback, tableEvents} {
+		if _, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE Timestamp < ?", table), t.Unix()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS playback (
+		URL TEXT NOT NULL,
+		Duration INTEGER NOT NULL,
+		Timestamp INTEGER NOT NULL,
+		Position INTEGER NOT NULL,
+		RelPosition INTEGER NOT NULL,
+		RebufCount INTEGER NOT NULL,
+		RebufDuration INTEGER NOT NULL,
+		Protocol TEXT NOT NULL,
+		Cache TEXT NOT NULL,
+		Player TEXT NOT NULL,
+		UserID TEXT NOT NULL,
+		Bandwidth INTEGER NOT NULL,
+		Bitrate INTEGER NOT NULL,
+		Device TEXT NOT NULL,
+		Area TEXT NOT NULL,
+		SubArea TEXT NOT NULL,
+		ASN INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS playback_timestamp ON playback (Timestamp);
+
+	CREATE TABLE IF NOT EXISTS events (
+		Kind TEXT NOT NULL,
+		URL TEXT NOT NULL,
+		Timestamp INTEGER NOT NULL,
+		Protocol TEXT NOT NULL,
+		Player TEXT NOT NULL,
+		UserID TEXT NOT NULL,
+		Device TEXT NOT NULL,
+		Area TEXT NOT NULL,
+		SubArea TEXT NOT NULL,
+		ASN INTEGER NOT NULL,
+		Position INTEGER NOT NULL,
+		TTFF INTEGER NOT NULL,
+		Bitrate INTEGER NOT NULL,
+		ErrorCode TEXT NOT NULL,
+		ErrorMessage TEXT NOT NULL,
+		Fatal INTEGER NOT NULL,
+		SeekTo INTEGER NOT NULL,
+		ToBitrate INTEGER NOT NULL,
+		Reason TEXT NOT NULL,
+		Watched INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS events_kind_timestamp ON events (Kind, Timestamp);`)
+	if err != nil {
+		return err
+	}
+	// Databases created before ASNs were stored and while client IPs still were.
+	for _, table := range []string{tablePlayback, tableEvents} {
+		hasASN, err := s.hasColumn(table, "ASN")
+		if err != nil {
+			return err
+		}
+		if !hasASN {
+			if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN ASN INTEGER NOT NULL DEFAULT 0", table)); err != nil {
+				return err
+			}
+		}
+		hasIP, err := s.hasColumn(table, "IP")
+		if err != nil {
+			return err
+		}
+		if hasIP {
+			if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN IP", table)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) hasColumn(table, column string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM pragma_table_info('%s') WHERE name = '%s'", table, column)).Scan(&n)
+	return n > 0, err
+}
+
+func (s *SQLite) Name() string {
+	return StorageSQLite
+}
+
+func (s *SQLite) WriteReport(r *reporter.PlaybackReport, loc Location, ts string) error {
+	args, err := prepareArgs(r, loc, ts)
+	if err != nil {
+		return err
+	}
+	return s.insert(tablePlayback, args)
+}
+
+func (s *SQLite) WriteEvent(e any, loc Location, ts string) error {
+	args, err := prepareEventArgs(e, loc, ts)
+	if err != nil {
+		return err
+	}
+	return s.insert(tableEvents, args)
+}
+
+func (s *SQLite) insert(table string, args []any) error {
+	for i, a := range args {
+		if t, ok := a.(time.Time); ok {
+			args[i] = t.Unix()
+		}
+	}
+	columns := tableColumns[table]
+	_, err := s.db.Exec(fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (?%s)",
+		table, strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1)), args...)
+	return err
+}
+
+func (s *SQLite) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return s.db.Close()
+}
+
+func (s *SQLite) Rebuffering(ctx context.Context, a Aggregate) ([]RebufferingRow, error) {
+	return queryRebuffering(ctx, s.db, sqliteDialect{}, a)
+}
+
+func (s *SQLite) Bitrate(ctx context.Context, a Aggregate) ([]BitrateRow, error) {
+	return queryBitrate(ctx, s.db, sqliteDialect{}, a)
+}
+
+func (s *SQLite) Startup(ctx context.Context, a Aggregate) ([]StartupRow, error) {
+	return queryStartup(ctx, s.db, sqliteDialect{}, a)
+}
+
+type sqliteDialect struct{}
+
+var sqliteBuckets = map[string]int{
+	BucketMinute: 60,
+	BucketHour:   3600,
+	BucketDay:    86400,
+}
+
+func (sqliteDialect) table(name string) string {
+	return name
+}
+
+func (sqliteDialect) bucket(bucket string) (string, bool) {
+	sec, ok := sqliteBuckets[bucket]
+	return fmt.Sprintf("(Timestamp / %[1]d) * %[1]d", sec), ok
+}
+
+func (sqliteDialect) timeArg(t time.Time) any {
+	return t.Unix()
+}
+
+func (sqliteDialect) countIf(cond string) string {
+	return "count(CASE WHEN " + cond + " THEN 1 END)"
+}
+
+func (sqliteDialect) sum(col string) string {
+	return "coalesce(sum(" + col + "), 0)"
+}
+
+func (sqliteDialect) avg(col string) string {
+	return "coalesce(avg(" + col + "), 0)"
+}
+
+func (sqliteDialect) avgIf(col, cond string) string {
+	return "coalesce(avg(CASE WHEN " + cond + " THEN " + col + " END), 0)"
+}
+
+func (sqliteDialect) medianIf(col, cond string) string {
+	return "median(CASE WHEN " + cond + " THEN " + col + " END)"
+}
+
+// median is an SQLite aggregate function returning the median of non-null values or 0 if there are none.
+// With an even number of values it interpolates between the middle two, same as ClickHouse quantile.
+type median struct {
+	values []float64
+}
+
+func newMedian() *median {
+	return &median{}
+}
+
+func (m *median) Step(v any) {
+	switch v := v.(type) {
+	case int64:
+		m.values = append(m.values, float64(v))
+	case float64:
+		m.values = append(m.values, v)
+	}
+}
+
+func (m *median) Done() float64 {
+	n := len(m.values)
+	if n == 0 {
+		return 0
+	}
+	sort.Float64s(m.values)
+	if n%2 == 1 {
+		return m.values[n/2]
+	}
+	return (m.values[n/2-1] + m.values[n/2]) / 2
+}
@@ -0,0 +1,138 @@
+package olapdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	StorageClickHouse = "clickhouse"
+	StorageSQLite     = "sqlite"
+	StorageNDJSON     = "ndjson"
+)
+
+// ErrAggregatesUnsupported is returned by aggregate reads when the storage only supports writing.
+var ErrAggregatesUnsupported = errors.New("storage does not support aggregate reads")
+
+// Storage persists playback reports and events.
+type Storage interface {
+	// Name identifies the storage type in logs.
+	Name() string
+	WriteReport(r *reporter.PlaybackReport, loc Location, ts string) error
+	// WriteEvent writes one of the reporter event payloads, see newEvent.
+	WriteEvent(e any, loc Location, ts string) error
+	// Close writes out pending data and releases resources.
+	Close() error
+}
+
+// AggregateReader is implemented by storages which can serve QoE aggregates.
+type AggregateReader interface {
+	Rebuffering(ctx context.Context, a Aggregate) ([]RebufferingRow, error)
+	Bitrate(ctx context.Context, a Aggregate) ([]BitrateRow, error)
+	Startup(ctx context.Context, a Aggregate) ([]StartupRow, error)
+}
+
+// store is used by package-level write and read functions, set by Open or Connect.
+var store Storage
+
+// Open creates the storage selected by the Storage.Type key of the watchman config, ClickHouse by default,
+// and makes package-level functions use it. Data is kept for as long as the Retention section says.
+func Open(cfg *viper.Viper) (Storage, error) {
+	var (
+		s   Storage
+		err error
+	)
+	err = SetRetention(Retention{
+		Raw:    cfg.GetDuration("retention.raw"),
+		Hourly: cfg.GetDuration("retention.hourly"),
+		Daily:  cfg.GetDuration("retention.daily"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch t := strings.ToLower(cfg.GetString("storage.type")); t {
+	case "", StorageClickHouse:
+		err := Connect(
+			cfg.GetString("clickhouse.url"), "watchman",
+			WithQueueSize(cfg.GetInt("clickhouse.queuesize")),
+			WithSpillDir(cfg.GetString("clickhouse.spilldir")),
+			WithMaxSpillSize(cfg.GetInt64("clickhouse.maxspillsize")),
+			WithMaxBackoff(cfg.GetDuration("clickhouse.maxbackoff")),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case StorageSQLite:
+		s, err = NewSQLite(cfg.GetString("storage.path"))
+	case StorageNDJSON:
+		s, err = NewNDJSON(
+			cfg.GetString("storage.path"),
+			WithMaxFileSize(cfg.GetInt64("storage.maxfilesize")),
+			WithMaxFileAge(cfg.GetDuration("storage.maxfileage")),
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	Use(s)
+	return s, nil
+}
+
+// Use makes package-level functions use the storage.
+func Use(s Storage) {
+	store = s
+}
+
+// WriteReport writes a playback report into the current storage.
+func WriteReport(r *reporter.PlaybackReport, loc Location, ts string) error {
+	return store.WriteReport(r, loc, ts)
+}
+
+// WriteEvent writes one of the reporter event payloads into the current storage.
+func WriteEvent(e any, loc Location, ts string) error {
+	return store.WriteEvent(e, loc, ts)
+}
+
+func reader() (AggregateReader, error) {
+	r, ok := store.(AggregateReader)
+	if !ok {
+		return nil, ErrAggregatesUnsupported
+	}
+	return r, nil
+}
+
+// Rebuffering sums up playback and rebuffering time.
+func Rebuffering(ctx context.Context, a Aggregate) ([]RebufferingRow, error) {
+	r, err := reader()
+	if err != nil {
+		return nil, err
+	}
+	return r.Rebuffering(ctx, a)
+}
+
+// Bitrate averages media bitrate and client bandwidth.
+func Bitrate(ctx context.Context, a Aggregate) ([]BitrateRow, error) {
+	r, err := reader()
+	if err != nil {
+		return nil, err
+	}
+	return r.Bitrate(ctx, a)
+}
+
+// Startup aggregates reports covering the beginning of a playback.
+func Startup(ctx context.Context, a Aggregate) ([]StartupRow, error) {
+	r, err := reader()
+	if err != nil {
+		return nil, err
+	}
+	return r.Startup(ctx, a)
+}
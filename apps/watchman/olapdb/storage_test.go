@@ -0,0 +1,310 @@
+package olapdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+	"github.com/Pallinder/go-randomdata"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storageHarness wraps a storage under test with a way to count rows it has persisted.
+type storageHarness struct {
+	storage Storage
+	// count returns the number of rows in the table with the URL.
+	count func(table, url string) int
+}
+
+// testStorageConformance checks behaviour every storage must have.
+// Aggregate reads are checked for storages implementing AggregateReader.
+func testStorageConformance(t *testing.T, h storageHarness) {
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+
+	ctx := context.Background()
+	player := "conformance-" + randomdata.Alphanumeric(12)
+	url := "lbry://" + player
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	loc := Locate("81.2.69.142")
+
+	rate := func(v int32) *int32 { return &v }
+	reports := []struct {
+		at time.Duration
+		r  reporter.PlaybackReport
+	}{
+		{time.Minute, reporter.PlaybackReport{
+			Duration: 10000, RebufCount: 1, RebufDuration: 1000, Position: 5000,
+			Bitrate: rate(1_000_000), Bandwidth: rate(5_000_000), Protocol: "hls",
+		}},
+		{2 * time.Minute, reporter.PlaybackReport{
+			Duration: 10000, Position: 20000,
+			Bitrate: rate(2_000_000), Bandwidth: rate(3_000_000), Protocol: "hls",
+		}},
+		{61 * time.Minute, reporter.PlaybackReport{
+			Duration: 20000, RebufCount: 2, RebufDuration: 2000, Position: 10000,
+			Bitrate: rate(3_000_000), Bandwidth: rate(4_000_000), Protocol: "stb",
+		}},
+	}
+	for _, r := range reports {
+		r.r.URL, r.r.Player, r.r.UserID, r.r.Device = url, player, "1", "web"
+		require.NoError(t, h.storage.WriteReport(&r.r, loc, base.Add(r.at).Format(time.RFC1123Z)))
+	}
+
+	ev := reporter.StartupEvent{URL: url, Protocol: "hls", Player: player, UserID: "1", Device: "web"}
+	events := []any{
+		&reporter.StartupEvent{URL: ev.URL, Protocol: ev.Protocol, Player: ev.Player, UserID: ev.UserID, Device: ev.Device, Ttff: 800},
+		&reporter.ErrorEvent{URL: ev.URL, Protocol: ev.Protocol, Player: ev.Player, UserID: ev.UserID, Device: ev.Device, Code: "MEDIA_ERR_DECODE", Fatal: true},
+		&reporter.SeekEvent{URL: ev.URL, Protocol: ev.Protocol, Player: ev.Player, UserID: ev.UserID, Device: ev.Device, From: 1000, To: 5000},
+		&reporter.QualitySwitchEvent{URL: ev.URL, Protocol: ev.Protocol, Player: ev.Player, UserID: ev.UserID, Device: ev.Device, FromBitrate: 1, ToBitrate: 2, Reason: "auto"},
+		&reporter.SessionEndEvent{URL: ev.URL, Protocol: ev.Protocol, Player: ev.Player, UserID: ev.UserID, Device: ev.Device, Position: 9000, Watched: 9000, Reason: "closed"},
+	}
+	for _, e := range events {
+		require.NoError(t, h.storage.WriteEvent(e, loc, base.Format(time.RFC1123Z)))
+	}
+	require.Error(t, h.storage.WriteEvent(&reporter.PlaybackReport{}, loc, base.Format(time.RFC1123Z)))
+	require.Error(t, h.storage.WriteReport(&reporter.PlaybackReport{URL: url}, loc, "yesterday"))
+
+	assert.Eventually(t, func() bool {
+		return h.count(tablePlayback, url) == len(reports) && h.count(tableEvents, url) == len(events)
+	}, 10*time.Second, 100*time.Millisecond, "rows weren't written")
+
+	r, ok := h.storage.(AggregateReader)
+	if !ok {
+		return
+	}
+	agg := Aggregate{From: base, To: base.Add(3 * time.Hour), Player: player}
+
+	rebuf, err := r.Rebuffering(ctx, agg)
+	require.NoError(t, err)
+	require.Len(t, rebuf, 1)
+	assert.EqualValues(t, 3, rebuf[0].Reports)
+	assert.EqualValues(t, 40000, rebuf[0].PlaybackMs)
+	assert.EqualValues(t, 3, rebuf[0].RebufCount)
+	assert.EqualValues(t, 3000, rebuf[0].RebufMs)
+
+	byHour := agg
+	byHour.GroupBy = []string{GroupTime, GroupArea}
+	rebuf, err = r.Rebuffering(ctx, byHour)
+	require.NoError(t, err)
+	require.Len(t, rebuf, 2)
+	assert.True(t, base.Equal(rebuf[0].Time), "got %v", rebuf[0].Time)
+	assert.Equal(t, "gb", rebuf[0].Area)
+	assert.EqualValues(t, 2, rebuf[0].Reports)
+	assert.EqualValues(t, 20000, rebuf[0].PlaybackMs)
+	assert.EqualValues(t, 1, rebuf[0].RebufCount)
+	assert.EqualValues(t, 1000, rebuf[0].RebufMs)
+	assert.True(t, base.Add(time.Hour).Equal(rebuf[1].Time), "got %v", rebuf[1].Time)
+	assert.EqualValues(t, 1, rebuf[1].Reports)
+	assert.EqualValues(t, 20000, rebuf[1].PlaybackMs)
+	assert.EqualValues(t, 2, rebuf[1].RebufCount)
+	assert.EqualValues(t, 2000, rebuf[1].RebufMs)
+
+	byProtocol := agg
+	byProtocol.GroupBy = []string{GroupProtocol}
+	byProtocol.Area = "GB"
+	byProtocol.Limit, byProtocol.Offset = 1, 1
+	rebuf, err = r.Rebuffering(ctx, byProtocol)
+	require.NoError(t, err)
+	require.Len(t, rebuf, 1)
+	assert.Equal(t, "stb", rebuf[0].Protocol)
+
+	byProtocol.Area = "us"
+	rebuf, err = r.Rebuffering(ctx, byProtocol)
+	require.NoError(t, err)
+	assert.Empty(t, rebuf)
+
+	bitrate, err := r.Bitrate(ctx, agg)
+	require.NoError(t, err)
+	require.Len(t, bitrate, 1)
+	assert.InDelta(t, 2_000_000, bitrate[0].AvgBitrate, 1)
+	assert.InDelta(t, 2_000_000, bitrate[0].MedianBitrate, 1)
+	assert.InDelta(t, 4_000_000, bitrate[0].AvgBandwidth, 1)
+
+	startup, err := r.Startup(ctx, agg)
+	require.NoError(t, err)
+	require.Len(t, startup, 1)
+	assert.EqualValues(t, 2, startup[0].Reports)
+	assert.EqualValues(t, 2, startup[0].RebufferedStarts)
+	assert.InDelta(t, 1500, startup[0].AvgRebufMs, 0.1)
+
+	// Without groups there's always a single row, same as SQL aggregates over an empty set.
+	empty := agg
+	empty.Player = "nonexistent"
+	bitrate, err = r.Bitrate(ctx, empty)
+	require.NoError(t, err)
+	require.Len(t, bitrate, 1)
+	assert.Zero(t, bitrate[0].Reports)
+	assert.Zero(t, bitrate[0].MedianBitrate)
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	s, err := NewSQLite(filepath.Join(t.TempDir(), "watchman.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	testStorageConformance(t, storageHarness{
+		storage: s,
+		count: func(table, url string) int {
+			var n int
+			require.NoError(t, s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s WHERE URL = ?", table), url).Scan(&n))
+			return n
+		},
+	})
+}
+
+func TestSQLiteRetention(t *testing.T) {
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+	path := filepath.Join(t.TempDir(), "watchman.db")
+	s, err := NewSQLite(path)
+	require.NoError(t, err)
+
+	count := func() int {
+		var n int
+		require.NoError(t, s.db.QueryRow("SELECT count(*) FROM playback").Scan(&n))
+		return n
+	}
+	r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+	require.NoError(t, s.WriteReport(r, Locate("81.2.69.142"), time.Now().Add(-48*time.Hour).Format(time.RFC1123Z)))
+	require.NoError(t, s.WriteReport(r, Locate("81.2.69.142"), time.Now().Format(time.RFC1123Z)))
+	require.Equal(t, 2, count())
+	require.NoError(t, s.deleteBefore(time.Now().Add(-24*time.Hour)))
+	assert.Equal(t, 1, count())
+
+	// Databases created before ASNs were stored get the column added, client IPs are dropped.
+	_, err = s.db.Exec("ALTER TABLE playback DROP COLUMN ASN")
+	require.NoError(t, err)
+	_, err = s.db.Exec("ALTER TABLE playback ADD COLUMN IP TEXT NOT NULL DEFAULT '81.2.69.0'")
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	s, err = NewSQLite(path)
+	require.NoError(t, err)
+	defer s.Close()
+	var asn uint32
+	require.NoError(t, s.db.QueryRow("SELECT ASN FROM playback").Scan(&asn))
+	assert.Zero(t, asn)
+	hasIP, err := s.hasColumn(tablePlayback, "IP")
+	require.NoError(t, err)
+	assert.False(t, hasIP)
+	require.NoError(t, s.WriteReport(r, Locate("81.2.69.142"), time.Now().Format(time.RFC1123Z)))
+	assert.Equal(t, 2, count())
+}
+
+func TestNDJSONConformance(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewNDJSON(dir)
+	require.NoError(t, err)
+	defer s.Close()
+
+	testStorageConformance(t, storageHarness{
+		storage: s,
+		count: func(table, url string) int {
+			return len(readNDJSON(t, dir, table, url))
+		},
+	})
+}
+
+// readNDJSON returns rows of the table with the URL from all files in dir.
+func readNDJSON(t *testing.T, dir, table, url string) []map[string]any {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, table+"-*.ndjson"))
+	require.NoError(t, err)
+	var rows []map[string]any
+	for _, name := range files {
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			row := map[string]any{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+			if row["URL"] == url {
+				rows = append(rows, row)
+			}
+		}
+		f.Close()
+		require.NoError(t, scanner.Err())
+	}
+	return rows
+}
+
+func TestNDJSONRotation(t *testing.T) {
+	p, _ := filepath.Abs(filepath.Join("./testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, OpenGeoDB(p))
+
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s, err := NewNDJSON(dir, WithMaxFileSize(500), WithMaxFileAge(time.Minute))
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+
+	r := &reporter.PlaybackReport{URL: "lbry://rotation", Player: "p1", Duration: 1000}
+	ts := now.Format(time.RFC1123Z)
+	for range 3 {
+		require.NoError(t, s.WriteReport(r, Location{}, ts))
+	}
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, s.WriteReport(r, Location{}, ts))
+	require.NoError(t, s.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "playback-*.ndjson"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "playback-20261001T120000Z-1.ndjson"),
+		filepath.Join(dir, "playback-20261001T120000Z.ndjson"),
+		filepath.Join(dir, "playback-20261001T120200Z.ndjson"),
+	}, files)
+
+	rows := readNDJSON(t, dir, tablePlayback, r.URL)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "p1", rows[0]["Player"])
+	assert.Equal(t, now.Add(-2*time.Minute).Format(time.RFC3339), rows[0]["Timestamp"])
+	assert.EqualValues(t, 1000, rows[0]["Duration"])
+}
+
+func TestOpen(t *testing.T) {
+	defer Use(nil)
+
+	cfg := viper.New()
+	cfg.Set("storage.type", "SQLite")
+	cfg.Set("storage.path", ":memory:")
+	s, err := Open(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, StorageSQLite, s.Name())
+	_, err = Rebuffering(context.Background(), Aggregate{From: time.Now().Add(-time.Hour), To: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	cfg.Set("storage.type", "ndjson")
+	cfg.Set("storage.path", t.TempDir())
+	s, err = Open(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, StorageNDJSON, s.Name())
+	_, err = Startup(context.Background(), Aggregate{From: time.Now().Add(-time.Hour), To: time.Now()})
+	require.ErrorIs(t, err, ErrAggregatesUnsupported)
+	require.NoError(t, s.Close())
+
+	cfg.Set("storage.type", "parquet")
+	_, err = Open(cfg)
+	require.ErrorContains(t, err, "unknown storage type")
+}
+
+func (s *olapdbSuite) TestStorageConformance() {
+	testStorageConformance(s.T(), storageHarness{
+		storage: ClickHouse{},
+		count: func(table, url string) int {
+			var n int
+			q := fmt.Sprintf("SELECT count() FROM %s.%s WHERE URL = ?", database, table)
+			s.Require().NoError(conn.QueryRow(q, url).Scan(&n))
+			return n
+		},
+	})
+}
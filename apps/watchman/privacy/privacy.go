@@ -0,0 +1,115 @@
+// Package privacy anonymizes personal data in playback reports before it's looked up or stored.
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"time"
+)
+
+const (
+	DefaultIPv4Prefix = 24
+	DefaultIPv6Prefix = 48
+	DefaultRotation   = 24 * time.Hour
+)
+
+// Anonymizer truncates client IPs and replaces user IDs with salted hashes.
+// A nil Anonymizer leaves data as it is.
+type Anonymizer struct {
+	v4Mask   net.IPMask
+	v6Mask   net.IPMask
+	secret   []byte
+	rotation time.Duration
+	now      func() time.Time
+}
+
+type AnonymizerOption func(a *Anonymizer)
+
+// WithIPPrefixes sets the number of leading bits kept in IPv4 and IPv6 addresses. Zero keeps the default.
+func WithIPPrefixes(v4, v6 int) AnonymizerOption {
+	return func(a *Anonymizer) {
+		if v4 > 0 && v4 <= 32 {
+			a.v4Mask = net.CIDRMask(v4, 32)
+		}
+		if v6 > 0 && v6 <= 128 {
+			a.v6Mask = net.CIDRMask(v6, 128)
+		}
+	}
+}
+
+// WithUserIDSecret sets the secret user ID hash salts are derived from.
+// Instances sharing the secret produce the same hashes. Without it a random secret is generated,
+// so hashes differ between instances and restarts.
+func WithUserIDSecret(secret string) AnonymizerOption {
+	return func(a *Anonymizer) {
+		if secret != "" {
+			a.secret = []byte(secret)
+		}
+	}
+}
+
+// WithRotation sets how often the user ID hash salt changes. Zero keeps the default.
+func WithRotation(d time.Duration) AnonymizerOption {
+	return func(a *Anonymizer) {
+		if d > 0 {
+			a.rotation = d
+		}
+	}
+}
+
+func NewAnonymizer(options ...AnonymizerOption) *Anonymizer {
+	a := &Anonymizer{
+		v4Mask:   net.CIDRMask(DefaultIPv4Prefix, 32),
+		v6Mask:   net.CIDRMask(DefaultIPv6Prefix, 128),
+		rotation: DefaultRotation,
+		now:      time.Now,
+	}
+	for _, o := range options {
+		o(a)
+	}
+	if a.secret == nil {
+		a.secret = make([]byte, 32)
+		rand.Read(a.secret)
+	}
+	return a
+}
+
+// IP returns the address with host bits zeroed, empty if it can't be parsed.
+func (a *Anonymizer) IP(addr string) string {
+	if a == nil {
+		return addr
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(a.v4Mask).String()
+	}
+	return ip.Mask(a.v6Mask).String()
+}
+
+// UserID returns a hash of the user ID keyed with the salt for the current rotation period.
+// The same user gets the same hash within a period, but hashes can't be linked across periods
+// without the secret. Empty IDs stay empty.
+func (a *Anonymizer) UserID(id string) string {
+	if a == nil || id == "" {
+		return id
+	}
+	mac := hmac.New(sha256.New, a.salt(a.now()))
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// salt derives the salt for the rotation period t falls into.
+func (a *Anonymizer) salt(t time.Time) []byte {
+	var period [8]byte
+	binary.BigEndian.PutUint64(period[:], uint64(t.UnixNano()/int64(a.rotation)))
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(period[:])
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package privacy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIP(t *testing.T) {
+	cases := []struct {
+		options []AnonymizerOption
+		in, out string
+	}{
+		{nil, "81.2.69.142", "81.2.69.0"},
+		{nil, "::ffff:81.2.69.142", "81.2.69.0"},
+		{nil, "2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"},
+		{nil, "not an ip", ""},
+		{nil, "", ""},
+		{[]AnonymizerOption{WithIPPrefixes(16, 32)}, "81.2.69.142", "81.2.0.0"},
+		{[]AnonymizerOption{WithIPPrefixes(16, 32)}, "2001:db8:85a3:8d3::1", "2001:db8::"},
+		{[]AnonymizerOption{WithIPPrefixes(0, 200)}, "81.2.69.142", "81.2.69.0"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.out, NewAnonymizer(c.options...).IP(c.in), c.in)
+	}
+}
+
+func TestUserID(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAnonymizer(WithUserIDSecret("secret"), WithRotation(24*time.Hour))
+	a.now = func() time.Time { return now }
+
+	h := a.UserID("432521")
+	assert.Len(t, h, 32)
+	assert.NotContains(t, h, "432521")
+	assert.Equal(t, h, a.UserID("432521"))
+	assert.NotEqual(t, h, a.UserID("432522"))
+	assert.Empty(t, a.UserID(""))
+
+	// Instances sharing the secret agree.
+	b := NewAnonymizer(WithUserIDSecret("secret"))
+	b.now = a.now
+	assert.Equal(t, h, b.UserID("432521"))
+
+	// Hashes change with the rotation period.
+	now = now.Add(11 * time.Hour)
+	assert.Equal(t, h, a.UserID("432521"))
+	now = now.Add(time.Hour)
+	assert.NotEqual(t, h, a.UserID("432521"))
+
+	c := NewAnonymizer(WithUserIDSecret("another"))
+	c.now = a.now
+	assert.NotEqual(t, a.UserID("432521"), c.UserID("432521"))
+
+	// Without a secret a random one is used.
+	assert.NotEqual(t, NewAnonymizer().UserID("432521"), NewAnonymizer().UserID("432521"))
+}
+
+func TestNil(t *testing.T) {
+	var a *Anonymizer
+	assert.Equal(t, "81.2.69.142", a.IP("81.2.69.142"))
+	assert.Equal(t, "432521", a.UserID("432521"))
+}
@@ -0,0 +1,207 @@
+package watchman
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	qoe "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/olapdb"
+
+	"go.uber.org/zap"
+	"goa.design/goa/v3/security"
+)
+
+// defaultRange is the time range aggregated when the query doesn't set its start.
+const defaultRange = 24 * time.Hour
+
+// qoe service implementation, reads aggregates over playback reports.
+type qoesrvc struct {
+	apiKeys [][]byte
+	logger  *zap.SugaredLogger
+}
+
+// NewQoE returns the qoe service implementation. Requests are only authorized with one of apiKeys,
+// so all of them are rejected if none are configured.
+func NewQoE(apiKeys []string, logger *zap.SugaredLogger) qoe.Service {
+	svc := &qoesrvc{logger: logger}
+	for _, k := range apiKeys {
+		if k != "" {
+			svc.apiKeys = append(svc.apiKeys, []byte(k))
+		}
+	}
+	return svc
+}
+
+// APIKeyAuth implements the authorization logic for the api_key security scheme.
+func (s *qoesrvc) APIKeyAuth(ctx context.Context, key string, schema *security.APIKeyScheme) (context.Context, error) {
+	for _, k := range s.apiKeys {
+		if subtle.ConstantTimeCompare(k, []byte(key)) == 1 {
+			return ctx, nil
+		}
+	}
+	return ctx, qoe.Unauthorized("invalid API key")
+}
+
+// readError maps storage errors onto service errors.
+func readError(err error) error {
+	if errors.Is(err, olapdb.ErrAggregatesUnsupported) {
+		return qoe.NotImplemented(err.Error())
+	}
+	return err
+}
+
+// Rebuffering implements rebuffering.
+func (s *qoesrvc) Rebuffering(ctx context.Context, p *qoe.AggregateQuery) (*qoe.RebufferingPage, error) {
+	s.logger.Debug("qoe.rebuffering")
+	agg, err := aggregateFromQuery(p)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := olapdb.Rebuffering(ctx, agg)
+	if err != nil {
+		return nil, readError(err)
+	}
+	rows, hasMore := paginate(rows, p.PageSize)
+	res := &qoe.RebufferingPage{Items: []*qoe.RebufferingStats{}, Page: p.Page, PageSize: p.PageSize, HasMore: hasMore}
+	for _, r := range rows {
+		item := &qoe.RebufferingStats{
+			PlaybackMs: r.PlaybackMs,
+			RebufCount: r.RebufCount,
+			RebufMs:    r.RebufMs,
+			RebufRatio: ratio(r.RebufMs, r.PlaybackMs),
+			Reports:    r.Reports,
+		}
+		item.Player, item.Area, item.Device, item.Protocol, item.Time = groupValues(p, r.AggregateGroup)
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+// Bitrate implements bitrate.
+func (s *qoesrvc) Bitrate(ctx context.Context, p *qoe.AggregateQuery) (*qoe.BitratePage, error) {
+	s.logger.Debug("qoe.bitrate")
+	agg, err := aggregateFromQuery(p)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := olapdb.Bitrate(ctx, agg)
+	if err != nil {
+		return nil, readError(err)
+	}
+	rows, hasMore := paginate(rows, p.PageSize)
+	res := &qoe.BitratePage{Items: []*qoe.BitrateStats{}, Page: p.Page, PageSize: p.PageSize, HasMore: hasMore}
+	for _, r := range rows {
+		item := &qoe.BitrateStats{
+			AvgBitrate:    r.AvgBitrate,
+			MedianBitrate: r.MedianBitrate,
+			AvgBandwidth:  r.AvgBandwidth,
+			Reports:       r.Reports,
+		}
+		item.Player, item.Area, item.Device, item.Protocol, item.Time = groupValues(p, r.AggregateGroup)
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+// Startup implements startup.
+func (s *qoesrvc) Startup(ctx context.Context, p *qoe.AggregateQuery) (*qoe.StartupPage, error) {
+	s.logger.Debug("qoe.startup")
+	agg, err := aggregateFromQuery(p)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := olapdb.Startup(ctx, agg)
+	if err != nil {
+		return nil, readError(err)
+	}
+	rows, hasMore := paginate(rows, p.PageSize)
+	res := &qoe.StartupPage{Items: []*qoe.StartupStats{}, Page: p.Page, PageSize: p.PageSize, HasMore: hasMore}
+	for _, r := range rows {
+		item := &qoe.StartupStats{
+			Starts:           r.Reports,
+			RebufferedStarts: r.RebufferedStarts,
+			RebufferedRatio:  ratio(r.RebufferedStarts, r.Reports),
+			AvgRebufMs:       r.AvgRebufMs,
+			Reports:          r.Reports,
+		}
+		item.Player, item.Area, item.Device, item.Protocol, item.Time = groupValues(p, r.AggregateGroup)
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+// aggregateFromQuery validates the time range and converts the payload into an aggregate query.
+// One extra group is requested to tell whether there are more pages.
+func aggregateFromQuery(p *qoe.AggregateQuery) (olapdb.Aggregate, error) {
+	agg := olapdb.Aggregate{
+		GroupBy: p.GroupBy,
+		Bucket:  p.Bucket,
+		To:      time.Now(),
+		Limit:   p.PageSize + 1,
+		Offset:  (p.Page - 1) * p.PageSize,
+	}
+	if p.To != nil {
+		t, err := time.Parse(time.RFC3339, *p.To)
+		if err != nil {
+			return agg, &qoe.MultiFieldError{Message: "to: " + err.Error()}
+		}
+		agg.To = t
+	}
+	agg.From = agg.To.Add(-defaultRange)
+	if p.From != nil {
+		t, err := time.Parse(time.RFC3339, *p.From)
+		if err != nil {
+			return agg, &qoe.MultiFieldError{Message: "from: " + err.Error()}
+		}
+		agg.From = t
+	}
+	if !agg.From.Before(agg.To) {
+		return agg, &qoe.MultiFieldError{Message: "from must be before to"}
+	}
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{{&agg.Player, p.Player}, {&agg.Area, p.Area}, {&agg.Device, p.Device}, {&agg.Protocol, p.Protocol}} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return agg, nil
+}
+
+// paginate trims the extra row requested by aggregateFromQuery.
+func paginate[T any](rows []T, pageSize int) ([]T, bool) {
+	if len(rows) > pageSize {
+		return rows[:pageSize], true
+	}
+	return rows, false
+}
+
+// groupValues returns pointers to values of the fields the query groups by, nil for the rest.
+func groupValues(p *qoe.AggregateQuery, g olapdb.AggregateGroup) (player, area, device, protocol, ts *string) {
+	for _, f := range p.GroupBy {
+		switch f {
+		case olapdb.GroupPlayer:
+			player = &g.Player
+		case olapdb.GroupArea:
+			area = &g.Area
+		case olapdb.GroupDevice:
+			device = &g.Device
+		case olapdb.GroupProtocol:
+			protocol = &g.Protocol
+		case olapdb.GroupTime:
+			t := g.Time.UTC().Format(time.RFC3339)
+			ts = &t
+		}
+	}
+	return
+}
+
+func ratio(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
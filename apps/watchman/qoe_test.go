@@ -0,0 +1,68 @@
+package watchman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qoesvr "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/http/qoe/server"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/qoe"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/olapdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goahttp "goa.design/goa/v3/http"
+)
+
+func TestQoERejectsRequests(t *testing.T) {
+	log.Configure(log.LevelDebug, log.EncodingConsole)
+	storage, err := olapdb.NewNDJSON(t.TempDir())
+	require.NoError(t, err)
+	olapdb.Use(storage)
+	defer olapdb.Use(nil)
+
+	mux := goahttp.NewMuxer()
+	qoeServer := qoesvr.New(
+		qoe.NewEndpoints(NewQoE([]string{"", "secret"}, log.Log)),
+		mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil)
+	qoesvr.Mount(mux, qoeServer)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cases := []struct {
+		name, path, key string
+		status          int
+	}{
+		{"no key", "/qoe/rebuffering", "", http.StatusBadRequest},
+		{"wrong key", "/qoe/bitrate", "secre", http.StatusUnauthorized},
+		{"unknown group", "/qoe/startup?group_by=url", "secret", http.StatusBadRequest},
+		{"page size too large", "/qoe/rebuffering?page_size=1001", "secret", http.StatusBadRequest},
+		{"bad time", "/qoe/rebuffering?from=yesterday", "secret", http.StatusBadRequest},
+		{
+			"reversed range",
+			"/qoe/bitrate?from=2026-10-02T00:00:00Z&to=2026-10-01T00:00:00Z",
+			"secret", http.StatusBadRequest,
+		},
+		{"storage without aggregates", "/qoe/startup", "secret", http.StatusNotImplemented},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+c.path, nil)
+			require.NoError(t, err)
+			if c.key != "" {
+				req.Header.Set("X-Api-Key", c.key)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, c.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestQoENoKeysConfigured(t *testing.T) {
+	svc := NewQoE(nil, log.Log).(qoe.Auther)
+	_, err := svc.APIKeyAuth(t.Context(), "", nil)
+	assert.Error(t, err)
+}
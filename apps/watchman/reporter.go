@@ -3,9 +3,12 @@ package watchman
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	reporter "github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/olapdb"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/privacy"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/rolling"
 
 	"go.uber.org/zap"
 )
@@ -13,15 +16,21 @@ import (
 // reporter service example implementation.
 // The example methods log the requests and return zero values.
 type reportersrvc struct {
-	db     *sql.DB
-	logger *zap.SugaredLogger
+	db         *sql.DB
+	logger     *zap.SugaredLogger
+	aggregator *rolling.Aggregator
+	anonymizer *privacy.Anonymizer
 }
 
 // NewReporter returns the reporter service implementation.
-func NewReporter(db *sql.DB, logger *zap.SugaredLogger) reporter.Service {
+// Reports and events are also fed into the aggregator if it's not nil.
+// Client IPs and user IDs are anonymized before anything else if anonymizer is not nil.
+func NewReporter(db *sql.DB, logger *zap.SugaredLogger, aggregator *rolling.Aggregator, anonymizer *privacy.Anonymizer) reporter.Service {
 	svc := &reportersrvc{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		aggregator: aggregator,
+		anonymizer: anonymizer,
 	}
 	return svc
 }
@@ -33,10 +42,89 @@ func (s *reportersrvc) Add(ctx context.Context, p *reporter.PlaybackReport) erro
 	if p.RebufDuration > p.Duration {
 		return &reporter.MultiFieldError{Message: "rebufferung duration cannot be larger than duration"}
 	}
-	addr := ctx.Value(RemoteAddressKey).(string)
-	err := olapdb.BatchWrite(p, addr, "")
+	loc := olapdb.Locate(s.anonymize(ctx, p))
+	err := olapdb.WriteReport(p, loc, "")
 	if err != nil {
-		return err
+		return writeError(err)
+	}
+	if s.aggregator != nil {
+		s.aggregator.ObserveReport(p, loc.Area)
+	}
+	return nil
+}
+
+// Startup implements startup.
+func (s *reportersrvc) Startup(ctx context.Context, p *reporter.StartupEvent) error {
+	s.logger.Debug("reporter.startup")
+	return s.writeEvent(ctx, p)
+}
+
+// PlaybackError implements playback_error.
+func (s *reportersrvc) PlaybackError(ctx context.Context, p *reporter.ErrorEvent) error {
+	s.logger.Debug("reporter.playback_error")
+	return s.writeEvent(ctx, p)
+}
+
+// Seek implements seek.
+func (s *reportersrvc) Seek(ctx context.Context, p *reporter.SeekEvent) error {
+	s.logger.Debug("reporter.seek")
+
+	if p.From == p.To {
+		return &reporter.MultiFieldError{Message: "seek start and end positions cannot be the same"}
+	}
+	return s.writeEvent(ctx, p)
+}
+
+// QualitySwitch implements quality_switch.
+func (s *reportersrvc) QualitySwitch(ctx context.Context, p *reporter.QualitySwitchEvent) error {
+	s.logger.Debug("reporter.quality_switch")
+
+	if p.FromBitrate == p.ToBitrate {
+		return &reporter.MultiFieldError{Message: "bitrate before and after the switch cannot be the same"}
+	}
+	return s.writeEvent(ctx, p)
+}
+
+// SessionEnd implements session_end.
+func (s *reportersrvc) SessionEnd(ctx context.Context, p *reporter.SessionEndEvent) error {
+	s.logger.Debug("reporter.session_end")
+	return s.writeEvent(ctx, p)
+}
+
+// writeError maps storage errors onto service errors.
+func writeError(err error) error {
+	if errors.Is(err, olapdb.ErrQueueFull) {
+		return reporter.Unavailable(err.Error())
+	}
+	return err
+}
+
+// anonymize replaces the user ID in the payload with its hash and returns the truncated client address.
+func (s *reportersrvc) anonymize(ctx context.Context, payload any) string {
+	switch p := payload.(type) {
+	case *reporter.PlaybackReport:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	case *reporter.StartupEvent:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	case *reporter.ErrorEvent:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	case *reporter.SeekEvent:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	case *reporter.QualitySwitchEvent:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	case *reporter.SessionEndEvent:
+		p.UserID = s.anonymizer.UserID(p.UserID)
+	}
+	return s.anonymizer.IP(ctx.Value(RemoteAddressKey).(string))
+}
+
+func (s *reportersrvc) writeEvent(ctx context.Context, e any) error {
+	loc := olapdb.Locate(s.anonymize(ctx, e))
+	if err := olapdb.WriteEvent(e, loc, ""); err != nil {
+		return writeError(err)
+	}
+	if s.aggregator != nil {
+		s.aggregator.ObserveEvent(e, loc.Area)
 	}
 	return nil
 }
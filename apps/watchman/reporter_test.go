@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"path/filepath"
 	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/config"
@@ -17,8 +18,11 @@ import (
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/log"
 	"github.com/OdyseeTeam/odysee-api/apps/watchman/olapdb"
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/privacy"
 
 	"github.com/Pallinder/go-randomdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	goahttp "goa.design/goa/v3/http"
 )
@@ -51,7 +55,7 @@ func (s *reporterSuite) SetupSuite() {
 	err = olapdb.OpenGeoDB(p)
 	s.Require().NoError(err)
 
-	reporterSvc := NewReporter(nil, log.Log)
+	reporterSvc := NewReporter(nil, log.Log, nil, nil)
 	reporterEndpoints := reporter.NewEndpoints(reporterSvc)
 
 	var (
@@ -124,6 +128,146 @@ func (s *reporterSuite) TestAdd() {
 
 }
 
+func (s *reporterSuite) TestEvents() {
+	base := `"url": "what#1", "protocol": "hls", "player": "sg-p2", "user_id": "123", "device": "web"`
+	cases := []struct {
+		name, path, body string
+		respCode         int
+		respBodyRegex    string
+	}{
+		{"Startup", reporterclt.StartupReporterPath(), `{"ttff": 1200, ` + base + `}`, http.StatusCreated, "^$"},
+		{"StartupMissingTTFF", reporterclt.StartupReporterPath(), `{` + base + `}`, http.StatusBadRequest, `ttff.+ is missing from body`},
+		{"Error", reporterclt.PlaybackErrorReporterPath(), `{"position": 0, "code": "MEDIA_ERR_NETWORK", "fatal": true, ` + base + `}`, http.StatusCreated, "^$"},
+		{"Seek", reporterclt.SeekReporterPath(), `{"from": 1000, "to": 60000, ` + base + `}`, http.StatusCreated, "^$"},
+		{"SeekSamePosition", reporterclt.SeekReporterPath(), `{"from": 1000, "to": 1000, ` + base + `}`, http.StatusBadRequest, `"message":"seek start and end positions cannot be the same"`},
+		{"QualitySwitch", reporterclt.QualitySwitchReporterPath(), `{"position": 0, "from_bitrate": 800000, "to_bitrate": 2400000, "reason": "auto", ` + base + `}`, http.StatusCreated, "^$"},
+		{"QualitySwitchBadReason", reporterclt.QualitySwitchReporterPath(), `{"position": 0, "from_bitrate": 800000, "to_bitrate": 2400000, "reason": "bored", ` + base + `}`, http.StatusBadRequest, `reason`},
+		{"SessionEnd", reporterclt.SessionEndReporterPath(), `{"position": 90000, "watched": 85000, "reason": "closed", ` + base + `}`, http.StatusCreated, "^$"},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			r, err := http.NewRequest(http.MethodPost, s.ts.URL+c.path, bytes.NewBufferString(c.body))
+			s.Require().NoError(err)
+			r.Header.Add("content-type", "application/json")
+
+			resp, err := http.DefaultClient.Do(r)
+			s.Require().NoError(err)
+			b, err := ioutil.ReadAll(resp.Body)
+			s.NoError(err)
+			s.Equal(c.respCode, resp.StatusCode, string(b))
+			s.Regexp(regexp.MustCompile(c.respBodyRegex), string(b))
+		})
+	}
+}
+
 func (s *reporterSuite) TearDownSuite() {
 	s.cleanup()
 }
+
+// fullStorage rejects all writes as if its queue was full.
+type fullStorage struct{}
+
+func (fullStorage) Name() string { return "full" }
+func (fullStorage) WriteReport(r *reporter.PlaybackReport, loc olapdb.Location, ts string) error {
+	return olapdb.ErrQueueFull
+}
+func (fullStorage) WriteEvent(e any, loc olapdb.Location, ts string) error {
+	return olapdb.ErrQueueFull
+}
+func (fullStorage) Close() error { return nil }
+
+func TestReporterQueueFull(t *testing.T) {
+	log.Configure(log.LevelDebug, log.EncodingConsole)
+	olapdb.Use(fullStorage{})
+	defer olapdb.Use(nil)
+
+	mux := goahttp.NewMuxer()
+	reporterServer := reportersvr.New(
+		reporter.NewEndpoints(NewReporter(nil, log.Log, nil, nil)),
+		mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil)
+	reporterServer.Use(RemoteAddressMiddleware())
+	reportersvr.Mount(mux, reporterServer)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	report, err := json.Marshal(olapdb.PlaybackReportAddRequestFactory.MustCreate().(*client.AddRequestBody))
+	require.NoError(t, err)
+	for path, body := range map[string][]byte{
+		"/reports/playback": report,
+		"/reports/startup":  []byte(`{"url": "what#1", "protocol": "hls", "player": "sg-p2", "user_id": "1", "device": "web", "ttff": 800}`),
+	} {
+		resp, err := http.Post(ts.URL+path, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, path)
+	}
+}
+
+// recordingStorage keeps client locations and user IDs it was asked to write.
+type recordingStorage struct {
+	mu      sync.Mutex
+	locs    []olapdb.Location
+	userIDs []string
+}
+
+func (s *recordingStorage) Name() string { return "recording" }
+func (s *recordingStorage) WriteReport(r *reporter.PlaybackReport, loc olapdb.Location, ts string) error {
+	return s.record(r.UserID, loc)
+}
+func (s *recordingStorage) WriteEvent(e any, loc olapdb.Location, ts string) error {
+	return s.record(e.(*reporter.StartupEvent).UserID, loc)
+}
+func (s *recordingStorage) Close() error { return nil }
+
+func (s *recordingStorage) record(userID string, loc olapdb.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userIDs = append(s.userIDs, userID)
+	s.locs = append(s.locs, loc)
+	return nil
+}
+
+func TestReporterAnonymizes(t *testing.T) {
+	log.Configure(log.LevelDebug, log.EncodingConsole)
+	storage := &recordingStorage{}
+	olapdb.Use(storage)
+	defer olapdb.Use(nil)
+	p, _ := filepath.Abs(filepath.Join("./olapdb/testdata", "GeoIP2-City-Test.mmdb"))
+	require.NoError(t, olapdb.OpenGeoDB(p))
+	p, _ = filepath.Abs(filepath.Join("./olapdb/testdata", "GeoLite2-ASN-Test.mmdb"))
+	require.NoError(t, olapdb.OpenASNDB(p))
+
+	anonymizer := privacy.NewAnonymizer(privacy.WithUserIDSecret("secret"))
+	mux := goahttp.NewMuxer()
+	reporterServer := reportersvr.New(
+		reporter.NewEndpoints(NewReporter(nil, log.Log, nil, anonymizer)),
+		mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil)
+	reporterServer.Use(RemoteAddressMiddleware())
+	reportersvr.Mount(mux, reporterServer)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	report := olapdb.PlaybackReportAddRequestFactory.MustCreate().(*client.AddRequestBody)
+	report.UserID = "432521"
+	body, err := json.Marshal(report)
+	require.NoError(t, err)
+	for path, body := range map[string][]byte{
+		"/reports/playback": body,
+		"/reports/startup":  []byte(`{"url": "what#1", "protocol": "hls", "player": "sg-p2", "user_id": "432521", "device": "web", "ttff": 800}`),
+	} {
+		r, err := http.NewRequest(http.MethodPost, ts.URL+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		r.Header.Set("X-Forwarded-For", "81.2.69.142, 10.0.0.1")
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Less(t, resp.StatusCode, 300, path)
+	}
+
+	// Locations are looked up for truncated addresses, the test city database has no data for 81.2.69.0.
+	loc := olapdb.Location{ASN: 20712}
+	assert.Equal(t, []olapdb.Location{loc, loc}, storage.locs)
+	hash := anonymizer.UserID("432521")
+	assert.Equal(t, []string{hash, hash}, storage.userIDs)
+}
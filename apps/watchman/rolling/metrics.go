@@ -0,0 +1,80 @@
+package rolling
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const ns = "watchman"
+
+// descs are metrics exported for a single dimension.
+type descs struct {
+	reports, playback, rebuffer, rebufferRatio    *prometheus.Desc
+	starts, fatalErrors, fatalErrorRatio, bitrate *prometheus.Desc
+}
+
+func newDescs(label string) descs {
+	labels := []string{label}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(ns, label, name), help, labels, nil)
+	}
+	return descs{
+		reports:         desc("window_reports", "Playback reports received during the rolling window"),
+		playback:        desc("window_playback_seconds", "Playback time reported during the rolling window"),
+		rebuffer:        desc("window_rebuffer_seconds", "Rebuffering time reported during the rolling window"),
+		rebufferRatio:   desc("rebuffer_ratio", "Share of playback time spent rebuffering during the rolling window"),
+		starts:          desc("window_starts", "Playback startups during the rolling window"),
+		fatalErrors:     desc("window_fatal_errors", "Fatal player errors during the rolling window"),
+		fatalErrorRatio: desc("fatal_error_ratio", "Fatal player errors per playback startup during the rolling window"),
+		bitrate:         desc("median_bitrate_bps", "Median media bitrate reported during the rolling window, bit/s"),
+	}
+}
+
+var (
+	playerDescs = newDescs("player")
+	areaDescs   = newDescs("area")
+)
+
+func (d descs) all() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		d.reports, d.playback, d.rebuffer, d.rebufferRatio,
+		d.starts, d.fatalErrors, d.fatalErrorRatio, d.bitrate,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (a *Aggregator) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range append(playerDescs.all(), areaDescs.all()...) {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. Ratios are only exported when their denominators are non-zero.
+func (a *Aggregator) Collect(ch chan<- prometheus.Metric) {
+	for _, dim := range []struct {
+		dimension *dimension
+		descs     descs
+	}{{a.players, playerDescs}, {a.areas, areaDescs}} {
+		d := dim.descs
+		snapshot := a.snapshot(dim.dimension)
+		for _, value := range values(snapshot) {
+			s := snapshot[value]
+			gauge := func(desc *prometheus.Desc, v float64) {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, value)
+			}
+			gauge(d.reports, float64(s.reports))
+			gauge(d.playback, float64(s.playbackMs)/1000)
+			gauge(d.rebuffer, float64(s.rebufMs)/1000)
+			if s.playbackMs > 0 {
+				gauge(d.rebufferRatio, float64(s.rebufMs)/float64(s.playbackMs))
+			}
+			gauge(d.starts, float64(s.starts))
+			gauge(d.fatalErrors, float64(s.fatalErrors))
+			if s.starts > 0 {
+				gauge(d.fatalErrorRatio, float64(s.fatalErrors)/float64(s.starts))
+			}
+			if bitrate, ok := s.medianBitrate(); ok {
+				gauge(d.bitrate, bitrate)
+			}
+		}
+	}
+}
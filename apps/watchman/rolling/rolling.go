@@ -0,0 +1,275 @@
+// Package rolling keeps QoE stats of recent playback reports and events in memory
+// and exposes them as Prometheus metrics, so alerting doesn't need to query ClickHouse.
+package rolling
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+)
+
+const (
+	// slotCount is the number of slots the window is divided into, stats expire one slot at a time.
+	slotCount = 10
+
+	// Bitrates are counted in bitrateStep wide buckets up to bitrateBuckets*bitrateStep,
+	// higher ones go into the last bucket.
+	bitrateStep    = 100_000
+	bitrateBuckets = 200
+
+	// LabelOther replaces label values above the series limit and ones that don't look like player names.
+	LabelOther = "other"
+	// LabelUnknown is used for reports from IP addresses without a known area.
+	LabelUnknown = "unknown"
+
+	DefaultWindow    = 5 * time.Minute
+	DefaultMaxSeries = 100
+)
+
+var (
+	playerRe = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+	areaRe   = regexp.MustCompile(`^[a-z]{2}$`)
+	// playerSuffixRe matches what proxies append to the player header, same as in olapdb.
+	playerSuffixRe = regexp.MustCompile(`,.*$`)
+)
+
+// stats are sums over a single slot.
+type stats struct {
+	reports     uint64
+	playbackMs  uint64
+	rebufMs     uint64
+	starts      uint64
+	fatalErrors uint64
+	bitrates    [bitrateBuckets]uint32
+}
+
+func (s *stats) add(o *stats) {
+	s.reports += o.reports
+	s.playbackMs += o.playbackMs
+	s.rebufMs += o.rebufMs
+	s.starts += o.starts
+	s.fatalErrors += o.fatalErrors
+	for i, c := range o.bitrates {
+		s.bitrates[i] += c
+	}
+}
+
+func (s *stats) empty() bool {
+	return s.reports == 0 && s.starts == 0 && s.fatalErrors == 0
+}
+
+// medianBitrate returns the midpoint of the bucket containing the median and false if no bitrates were reported.
+func (s *stats) medianBitrate() (float64, bool) {
+	var total uint64
+	for _, c := range s.bitrates {
+		total += uint64(c)
+	}
+	if total == 0 {
+		return 0, false
+	}
+	var seen uint64
+	for i, c := range s.bitrates {
+		seen += uint64(c)
+		if seen*2 >= total {
+			return (float64(i) + 0.5) * bitrateStep, true
+		}
+	}
+	return 0, false
+}
+
+// series is a ring of slots for one label value.
+type series struct {
+	slots [slotCount]stats
+	// ids are absolute slot numbers the ring entries hold stats for.
+	ids [slotCount]int64
+}
+
+// slot returns stats for the absolute slot number, resetting the ring entry if it held an older one.
+func (s *series) slot(id int64) *stats {
+	i := id % slotCount
+	if s.ids[i] != id {
+		s.ids[i] = id
+		s.slots[i] = stats{}
+	}
+	return &s.slots[i]
+}
+
+// sum adds up slots which are still inside the window ending at the current slot.
+func (s *series) sum(current int64) *stats {
+	total := &stats{}
+	for i := range s.slots {
+		if s.ids[i] > current-slotCount && s.ids[i] <= current {
+			total.add(&s.slots[i])
+		}
+	}
+	return total
+}
+
+// dimension holds series for values of a single label, limited to maxSeries of them plus LabelOther.
+type dimension struct {
+	label  string
+	series map[string]*series
+}
+
+func (d *dimension) get(value string, maxSeries int) *series {
+	if s, ok := d.series[value]; ok {
+		return s
+	}
+	if len(d.series) >= maxSeries {
+		value = LabelOther
+		if s, ok := d.series[value]; ok {
+			return s
+		}
+	}
+	s := &series{}
+	for i := range s.ids {
+		s.ids[i] = -1
+	}
+	d.series[value] = s
+	return s
+}
+
+// Aggregator sums up playback reports and events per player server and per area over a sliding window.
+// It implements prometheus.Collector, values are computed when metrics are collected.
+type Aggregator struct {
+	mu        sync.Mutex
+	slotLen   time.Duration
+	maxSeries int
+	players   *dimension
+	areas     *dimension
+	now       func() time.Time
+}
+
+type AggregatorOption func(a *Aggregator)
+
+// WithWindow sets the window length, DefaultWindow by default.
+func WithWindow(window time.Duration) AggregatorOption {
+	return func(a *Aggregator) {
+		if window > 0 {
+			a.slotLen = max(window/slotCount, time.Second)
+		}
+	}
+}
+
+// WithMaxSeries limits the number of distinct players and areas tracked at once, DefaultMaxSeries by default.
+// Reports for values over the limit are counted under LabelOther until some of the tracked ones expire.
+func WithMaxSeries(n int) AggregatorOption {
+	return func(a *Aggregator) {
+		if n > 0 {
+			a.maxSeries = n
+		}
+	}
+}
+
+func NewAggregator(options ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
+		slotLen:   DefaultWindow / slotCount,
+		maxSeries: DefaultMaxSeries,
+		players:   &dimension{label: "player", series: map[string]*series{}},
+		areas:     &dimension{label: "area", series: map[string]*series{}},
+		now:       time.Now,
+	}
+	for _, opt := range options {
+		opt(a)
+	}
+	return a
+}
+
+// Window returns the length of time stats are kept for.
+func (a *Aggregator) Window() time.Duration {
+	return a.slotLen * slotCount
+}
+
+// ObserveReport adds a playback report, area is the country code of the client.
+func (a *Aggregator) ObserveReport(r *reporter.PlaybackReport, area string) {
+	if a == nil {
+		return
+	}
+	a.observe(r.Player, area, func(s *stats) {
+		s.reports++
+		s.playbackMs += uint64(max(r.Duration, 0))
+		s.rebufMs += uint64(max(r.RebufDuration, 0))
+		if r.Bitrate != nil && *r.Bitrate > 0 {
+			s.bitrates[min(int(*r.Bitrate/bitrateStep), bitrateBuckets-1)]++
+		}
+	})
+}
+
+// ObserveEvent adds a playback event, only startups and fatal errors are counted.
+func (a *Aggregator) ObserveEvent(e any, area string) {
+	if a == nil {
+		return
+	}
+	switch e := e.(type) {
+	case *reporter.StartupEvent:
+		a.observe(e.Player, area, func(s *stats) { s.starts++ })
+	case *reporter.ErrorEvent:
+		if e.Fatal {
+			a.observe(e.Player, area, func(s *stats) { s.fatalErrors++ })
+		}
+	}
+}
+
+func (a *Aggregator) observe(player, area string, update func(*stats)) {
+	id := a.slotID()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	update(a.players.get(playerLabel(player), a.maxSeries).slot(id))
+	update(a.areas.get(areaLabel(area), a.maxSeries).slot(id))
+}
+
+func (a *Aggregator) slotID() int64 {
+	return a.now().UnixNano() / int64(a.slotLen)
+}
+
+// snapshot sums up the window for every tracked value of the dimension, dropping values without recent data.
+func (a *Aggregator) snapshot(d *dimension) map[string]*stats {
+	current := a.slotID()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	res := map[string]*stats{}
+	for value, s := range d.series {
+		total := s.sum(current)
+		if total.empty() {
+			delete(d.series, value)
+			continue
+		}
+		res[value] = total
+	}
+	return res
+}
+
+// values returns snapshot keys in a stable order.
+func values(snapshot map[string]*stats) []string {
+	keys := make([]string, 0, len(snapshot))
+	for k := range snapshot {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// playerLabel normalizes player server names the same way they're stored,
+// replacing anything that doesn't look like a host name with LabelOther.
+func playerLabel(player string) string {
+	player = strings.ToLower(playerSuffixRe.ReplaceAllLiteralString(player, ""))
+	if len(player) > 16 {
+		player = player[:16]
+	}
+	if !playerRe.MatchString(player) {
+		return LabelOther
+	}
+	return player
+}
+
+func areaLabel(area string) string {
+	area = strings.ToLower(area)
+	if !areaRe.MatchString(area) {
+		return LabelUnknown
+	}
+	return area
+}
@@ -0,0 +1,229 @@
+package rolling
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/watchman/gen/reporter"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// gather returns metric values keyed by metric name and label value.
+func gather(t *testing.T, a *Aggregator) map[string]float64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(a))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	res := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			res[key(f, m)] = m.GetGauge().GetValue()
+		}
+	}
+	return res
+}
+
+func key(f *dto.MetricFamily, m *dto.Metric) string {
+	return fmt.Sprintf("%s{%s}", f.GetName(), m.GetLabel()[0].GetValue())
+}
+
+func report(player string, duration, rebuf, bitrate int32) *reporter.PlaybackReport {
+	return &reporter.PlaybackReport{Player: player, Duration: duration, RebufDuration: rebuf, Bitrate: &bitrate}
+}
+
+func TestAggregator(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAggregator(WithWindow(time.Minute))
+	a.now = func() time.Time { return now }
+	assert.Equal(t, time.Minute, a.Window())
+
+	a.ObserveReport(report("sg-p1,proxy", 30000, 3000, 1_000_000), "us")
+	a.ObserveReport(report("SG-P1", 10000, 1000, 2_000_000), "US")
+	a.ObserveReport(report("sg-p1", 20000, 0, 2_050_000), "")
+	a.ObserveReport(report("use-p2", 30000, 0, 0), "gb")
+	a.ObserveEvent(&reporter.StartupEvent{Player: "sg-p1"}, "us")
+	a.ObserveEvent(&reporter.StartupEvent{Player: "sg-p1"}, "us")
+	a.ObserveEvent(&reporter.ErrorEvent{Player: "sg-p1", Fatal: true}, "us")
+	a.ObserveEvent(&reporter.ErrorEvent{Player: "sg-p1"}, "us")
+	a.ObserveEvent(&reporter.SeekEvent{Player: "sg-p1"}, "us")
+
+	m := gather(t, a)
+	assert.Equal(t, 3.0, m["watchman_player_window_reports{sg-p1}"])
+	assert.Equal(t, 60.0, m["watchman_player_window_playback_seconds{sg-p1}"])
+	assert.Equal(t, 4.0, m["watchman_player_window_rebuffer_seconds{sg-p1}"])
+	assert.InDelta(t, 4.0/60, m["watchman_player_rebuffer_ratio{sg-p1}"], 1e-9)
+	assert.Equal(t, 2.0, m["watchman_player_window_starts{sg-p1}"])
+	assert.Equal(t, 1.0, m["watchman_player_window_fatal_errors{sg-p1}"])
+	assert.Equal(t, 0.5, m["watchman_player_fatal_error_ratio{sg-p1}"])
+	assert.Equal(t, 2_050_000.0, m["watchman_player_median_bitrate_bps{sg-p1}"])
+
+	assert.Equal(t, 0.0, m["watchman_player_rebuffer_ratio{use-p2}"])
+	assert.NotContains(t, m, "watchman_player_fatal_error_ratio{use-p2}")
+	assert.NotContains(t, m, "watchman_player_median_bitrate_bps{use-p2}")
+
+	assert.Equal(t, 2.0, m["watchman_area_window_reports{us}"])
+	assert.Equal(t, 1.0, m["watchman_area_window_reports{unknown}"])
+	assert.Equal(t, 1.0, m["watchman_area_window_reports{gb}"])
+
+	// Stats expire one slot at a time.
+	now = now.Add(50 * time.Second)
+	a.ObserveReport(report("use-p2", 5000, 0, 0), "gb")
+	m = gather(t, a)
+	assert.Equal(t, 3.0, m["watchman_player_window_reports{sg-p1}"])
+	assert.Equal(t, 2.0, m["watchman_player_window_reports{use-p2}"])
+
+	now = now.Add(20 * time.Second)
+	m = gather(t, a)
+	assert.NotContains(t, m, "watchman_player_window_reports{sg-p1}")
+	assert.NotContains(t, m, "watchman_area_window_reports{us}")
+	assert.Equal(t, 1.0, m["watchman_player_window_reports{use-p2}"])
+	assert.Equal(t, 5.0, m["watchman_player_window_playback_seconds{use-p2}"])
+}
+
+func TestAggregatorCardinality(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAggregator(WithWindow(time.Minute), WithMaxSeries(3))
+	a.now = func() time.Time { return now }
+
+	for i := range 10 {
+		a.ObserveReport(report(fmt.Sprintf("p%d", i), 1000, 0, 0), "us")
+	}
+	a.ObserveReport(report(`"><script>`, 1000, 0, 0), "United States")
+	a.ObserveReport(report("a-very-long-player-server-name", 1000, 0, 0), "us")
+
+	m := gather(t, a)
+	assert.Equal(t, 1.0, m["watchman_player_window_reports{p0}"])
+	assert.Equal(t, 1.0, m["watchman_player_window_reports{p2}"])
+	assert.NotContains(t, m, "watchman_player_window_reports{p3}")
+	assert.Equal(t, 9.0, m["watchman_player_window_reports{other}"])
+	assert.Equal(t, 1.0, m["watchman_area_window_reports{unknown}"])
+
+	// Expired series free up room for new values.
+	now = now.Add(2 * time.Minute)
+	gather(t, a)
+	a.ObserveReport(report("p9", 1000, 0, 0), "us")
+	m = gather(t, a)
+	assert.Equal(t, 1.0, m["watchman_player_window_reports{p9}"])
+	assert.NotContains(t, m, "watchman_player_window_reports{other}")
+}
+
+func TestAggregatorNil(t *testing.T) {
+	var a *Aggregator
+	a.ObserveReport(report("p1", 1000, 0, 0), "us")
+	a.ObserveEvent(&reporter.StartupEvent{}, "us")
+}
+
+func TestPlayerLabel(t *testing.T) {
+	cases := map[string]string{
+		"sg-p2":                   "sg-p2",
+		"use-p1, cloudflare":      "use-p1",
+		"Player-12.Odysee":        "player-12.odysee",
+		"player-server-with-long": "player-server-wi",
+		"":                        LabelOther,
+		"p1 p2":                   LabelOther,
+		"-p1":                     LabelOther,
+	}
+	for in, out := range cases {
+		assert.Equal(t, out, playerLabel(in), in)
+	}
+}
+
+type ruleFile struct {
+	Groups []struct {
+		Name  string
+		Rules []struct {
+			Record string
+			Alert  string
+			Expr   string
+		}
+	}
+}
+
+type ruleTestFile struct {
+	RuleFiles []string `yaml:"rule_files"`
+	Tests     []struct {
+		InputSeries []struct {
+			Series string
+		} `yaml:"input_series"`
+		PromqlExprTest []struct {
+			Expr string
+		} `yaml:"promql_expr_test"`
+		AlertRuleTest []struct {
+			Alertname string
+		} `yaml:"alert_rule_test"`
+	}
+}
+
+// TestRules checks that bundled rules and their promtool fixtures only refer to metrics the aggregator exports
+// and that every alert is covered by a fixture. Rule evaluation itself is tested by promtool.
+func TestRules(t *testing.T) {
+	exported := map[string]bool{}
+	descCh := make(chan *prometheus.Desc, 100)
+	NewAggregator().Describe(descCh)
+	close(descCh)
+	fqNameRe := regexp.MustCompile(`fqName: "([^"]+)"`)
+	for d := range descCh {
+		exported[fqNameRe.FindStringSubmatch(d.String())[1]] = true
+	}
+
+	var rules ruleFile
+	data, err := os.ReadFile("../prometheus/rules.yml")
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &rules))
+
+	var tests ruleTestFile
+	data, err = os.ReadFile("../prometheus/rules_test.yml")
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &tests))
+	assert.Equal(t, []string{"rules.yml"}, tests.RuleFiles)
+
+	metricRe := regexp.MustCompile(`\bwatchman_(?:player|area)_[a-z_]+`)
+	recordRe := regexp.MustCompile(`\b(?:player|area):watchman_[a-z_]+:window\b`)
+	records, alerts := map[string]bool{}, map[string]bool{}
+	for _, g := range rules.Groups {
+		for _, r := range g.Rules {
+			for _, m := range metricRe.FindAllString(r.Expr, -1) {
+				assert.True(t, exported[m], "%s%s refers to %s, which is not exported", r.Record, r.Alert, m)
+			}
+			for _, m := range recordRe.FindAllString(r.Expr, -1) {
+				assert.True(t, records[m], "%s refers to %s, which is not recorded before it", r.Alert, m)
+			}
+			if r.Record != "" {
+				records[r.Record] = true
+			}
+			if r.Alert != "" {
+				alerts[r.Alert] = true
+			}
+		}
+	}
+	require.NotEmpty(t, records)
+	require.NotEmpty(t, alerts)
+
+	tested := map[string]bool{}
+	for _, tc := range tests.Tests {
+		for _, s := range tc.InputSeries {
+			for _, m := range metricRe.FindAllString(s.Series, -1) {
+				assert.True(t, exported[m], "fixture series %s is not exported", s.Series)
+			}
+		}
+		for _, e := range tc.PromqlExprTest {
+			assert.True(t, records[e.Expr], "fixture expression %s is not recorded", e.Expr)
+		}
+		for _, a := range tc.AlertRuleTest {
+			assert.True(t, alerts[a.Alertname], "fixture alert %s is not defined", a.Alertname)
+			tested[a.Alertname] = true
+		}
+	}
+	for a := range alerts {
+		assert.True(t, tested[a], "alert %s has no fixture", a)
+	}
+}
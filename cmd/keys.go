@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/keybox"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyringFile       string
+	keysActivateAfter time.Duration
+	keysPrune         bool
+)
+
+func init() {
+	keys.PersistentFlags().StringVar(&keyringFile, "keyring", "", "keyring file, UploadTokenKeyring.File by default")
+	keysAdd.Flags().DurationVar(&keysActivateAfter, "activate-after", 24*time.Hour,
+		"delay before the key starts signing tokens, which gives validators time to fetch it")
+	keysAdd.Flags().BoolVar(&keysPrune, "prune", true, "remove keys retired for longer than the grace period")
+	keys.AddCommand(keysGenerate, keysAdd, keysList)
+	rootCmd.AddCommand(keys)
+}
+
+var keys = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage keys upload tokens are signed with",
+}
+
+var keysGenerate = &cobra.Command{
+	Use:   "generate",
+	Short: "Print a new private key usable as UploadTokenPrivateKey",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := keybox.GenerateKeyringEntry(time.Now())
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		fmt.Println(e.PrivateKey)
+	},
+}
+
+var keysAdd = &cobra.Command{
+	Use:   "add",
+	Short: "Generate a key and schedule rotation to it in the keyring",
+	Long: "Generate a key and add it to the keyring, creating the keyring if it doesn't exist. " +
+		"The current key retires when the new one activates, servers have to be restarted with the updated keyring before that.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := keyringPath()
+		f, err := keybox.ReadKeyringFile(path)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		activatesAt := time.Now().Add(keysActivateAfter)
+		if len(f.Keys) == 0 {
+			activatesAt = time.Now()
+		}
+		e, err := keybox.GenerateKeyringEntry(activatesAt.Truncate(time.Second))
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		f.AddKey(e)
+		if keysPrune {
+			for _, k := range f.Prune(time.Now(), keyringGracePeriod()) {
+				fmt.Printf("removed %s retired at %s\n", k.ID, k.RetiresAt.Format(time.RFC3339))
+			}
+		}
+		if err := keybox.WriteKeyringFile(path, f); err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		fmt.Printf("added %s activating at %s\n", e.ID, e.ActivatesAt.Format(time.RFC3339))
+	},
+}
+
+var keysList = &cobra.Command{
+	Use:   "list",
+	Short: "List keys in the keyring with their rotation schedule",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := keybox.ReadKeyringFile(keyringPath())
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		now := time.Now()
+		grace := keyringGracePeriod()
+		for _, k := range f.Keys {
+			status := "active"
+			switch {
+			case k.ActivatesAt.After(now):
+				status = "scheduled"
+			case !k.RetiresAt.IsZero() && !k.RetiresAt.Add(grace).After(now):
+				status = "expired"
+			case !k.RetiresAt.IsZero() && !k.RetiresAt.After(now):
+				status = "retired"
+			}
+			retires := "-"
+			if !k.RetiresAt.IsZero() {
+				retires = k.RetiresAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", k.ID, status, k.ActivatesAt.Format(time.RFC3339), retires)
+		}
+	},
+}
+
+func keyringPath() string {
+	if keyringFile != "" {
+		return keyringFile
+	}
+	path := config.GetUploadTokenKeyringConfig().File
+	if path == "" {
+		log.Error("keyring file is not set, pass --keyring or set UploadTokenKeyring.File")
+		os.Exit(1)
+	}
+	return path
+}
+
+func keyringGracePeriod() time.Duration {
+	if grace := config.GetUploadTokenKeyringConfig().GracePeriod; grace > 0 {
+		return grace
+	}
+	return keybox.DefaultGracePeriod
+}
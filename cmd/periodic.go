@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/app/wallet/tracker"
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+
+	"github.com/hibiken/asynq"
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+const (
+	taskUnloadWallets = "wallets:unload"
+
+	// periodicQueue is only served by API instances, asynquery requests share the same Redis database.
+	periodicQueue = "api_periodic"
+)
+
+type unloadWalletsPayload struct {
+	OlderThanMinutes int `json:"older_than_minutes"`
+}
+
+// periodicTasks creates a queue for maintenance jobs which only need to run on one of API instances.
+// Publish reaper is not among them as publish source directories are local to each instance.
+func periodicTasks(cfg config.PeriodicTasksConfig) (*queue.Queue, error) {
+	opts, err := config.GetAsynqueryRequestsConnOpts()
+	if err != nil {
+		return nil, err
+	}
+	q, err := queue.New(
+		queue.WithRequestsConnOpts(opts),
+		queue.WithLogger(zapadapter.NewKV(nil)),
+		queue.WithConcurrency(1),
+		queue.WithQueues(map[string]int{periodicQueue: 1}),
+		queue.WithSchedulerName("api"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	q.AddHandler(taskUnloadWallets, handleUnloadWallets)
+	err = q.AddPeriodicTask(taskUnloadWallets, cfg.UnloadWalletsSchedule,
+		unloadWalletsPayload{OlderThanMinutes: int(cfg.UnloadWalletsAfter.Minutes())},
+		queue.WithRequestQueue(periodicQueue))
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func handleUnloadWallets(_ context.Context, task *asynq.Task) error {
+	var payload unloadWalletsPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+	_, err := tracker.Unload(boil.GetDB(), time.Duration(payload.OlderThanMinutes)*time.Minute)
+	return err
+}
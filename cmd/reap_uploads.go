@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var reapUploadsDryRun bool
+
+func init() {
+	reapUploads.Flags().BoolVar(&reapUploadsDryRun, "dry-run", false, "only report abandoned uploads without removing them")
+	rootCmd.AddCommand(reapUploads)
+}
+
+var reapUploads = &cobra.Command{
+	Use:   "reap_uploads",
+	Short: "Remove abandoned uploads from publish source directories once",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := publishReaper(reapUploadsDryRun).RunOnce(context.Background())
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// publishReaper creates a reaper for tus filestores of v2 and v3 publish handlers.
+func publishReaper(dryRun bool) *reaper.Reaper {
+	cfg := config.GetPublishReaperConfig()
+	cfg.DryRun = cfg.DryRun || dryRun
+	options := append(cfg.Options(),
+		reaper.WithLogger(zapadapter.NewNamedKV("reaper", config.GetLoggingOpts())),
+		reaper.WithSweepers(reaper.NewFileStoreSweeper("publish", config.GetPublishSourceDir())),
+	)
+	if dir := config.GetGeoPublishSourceDir(); dir != "" {
+		options = append(options, reaper.WithSweepers(reaper.NewFileStoreSweeper("geopublish", dir)))
+	}
+	return reaper.New(options...)
+}
@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 
 	"github.com/OdyseeTeam/odysee-api/api"
+	"github.com/OdyseeTeam/odysee-api/app/query"
 	"github.com/OdyseeTeam/odysee-api/app/sdkrouter"
 	"github.com/OdyseeTeam/odysee-api/app/wallet"
 	"github.com/OdyseeTeam/odysee-api/apps/lbrytv/config"
+	"github.com/OdyseeTeam/odysee-api/pkg/chainquery"
+	"github.com/OdyseeTeam/odysee-api/pkg/reaper"
 	"github.com/OdyseeTeam/odysee-api/server"
 	"github.com/OdyseeTeam/player-server/pkg/paid"
 
@@ -20,6 +24,22 @@ var rootCmd = &cobra.Command{
 	Use:   "oapi",
 	Short: "backend server for Odysee frontend",
 	Run: func(_ *cobra.Command, _ []string) {
+		key, err := ioutil.ReadFile(config.GetPaidTokenPrivKey())
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = paid.InitPrivateKey(key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		paidKeyring, err := query.NewPaidTokenKeyring()
+		if err != nil {
+			log.Fatalf("unable to load paid token keys: %s", err)
+		}
+		query.SetPaidTokenKeyring(paidKeyring)
+
+		chainquery.SetDefault(chainquery.NewClient(chainquery.WithBaseURL(config.GetChainqueryURL())))
+
 		sdkRouter := sdkrouter.New(config.GetLbrynetServers())
 		go sdkRouter.WatchLoad()
 
@@ -27,22 +47,37 @@ var rootCmd = &cobra.Command{
 			EnableProfiling: config.GetProfiling(),
 			EnableV3Publish: false,
 		})
-		err := s.Start()
+		err = s.Start()
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		key, err := ioutil.ReadFile(config.GetPaidTokenPrivKey())
-		if err != nil {
-			log.Fatal(err)
-		}
-		err = paid.InitPrivateKey(key)
+		signers, err := query.NewURLSigners()
 		if err != nil {
-			log.Fatal(err)
+			log.Fatalf("invalid streaming URL signers config: %s", err)
 		}
+		query.SetURLSigners(signers)
+
 		c := wallet.NewTokenCache()
 		wallet.SetTokenCache(c)
 
+		if pcfg := config.GetPeriodicTasksConfig(); pcfg.Enabled {
+			q, err := periodicTasks(pcfg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			go func() {
+				if err := q.ServeUntilShutdown(); err != nil {
+					log.Fatal(err)
+				}
+			}()
+		}
+
+		if config.GetPublishReaperEnabled() {
+			reaper.RegisterMetrics(nil)
+			go publishReaper(false).Start(context.Background())
+		}
+
 		// ServeUntilShutdown is blocking, should be last
 		s.ServeUntilShutdown()
 	},
@@ -20,6 +20,7 @@ func init() {
 var unloadWallets = &cobra.Command{
 	Use:   "unload_wallets MIN",
 	Short: "Unload wallets that have not been used in the last MIN minutes",
+	Long:  "Unload wallets that have not been used in the last MIN minutes. The server does it on schedule when PeriodicTasks are enabled.",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		min, err := strconv.Atoi(args[0])
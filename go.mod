@@ -38,6 +38,7 @@ require (
 	github.com/lbryio/types v0.0.0-20220224142228-73610f6654a6
 	github.com/lestrrat-go/jwx/v2 v2.1.6
 	github.com/lib/pq v1.12.3
+	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/nsf/jsondiff v0.0.0-20190712045011-8443391ee9b6
 	github.com/ory/dockertest/v3 v3.12.0
@@ -69,6 +70,7 @@ require (
 	golang.org/x/sync v0.20.0
 	golang.org/x/time v0.15.0
 	gopkg.in/vansante/go-ffprobe.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 	logur.dev/logur v0.17.0
 )
 
@@ -224,7 +226,6 @@ require (
 	gopkg.in/ini.v1 v1.67.1 // indirect
 	gopkg.in/nullbio/null.v6 v6.0.0-20161116030900-40264a2e6b79 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/floostack/transcoder => github.com/OdyseeTeam/transcoder v0.19.3-0.20260123013255-b07fca87b389
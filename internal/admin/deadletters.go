@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/OdyseeTeam/odysee-api/internal/responses"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+
+	"github.com/gorilla/mux"
+)
+
+// DeadLetters exposes archived tasks of named queues to operators.
+type DeadLetters struct {
+	queues map[string]*queue.Queue
+}
+
+type editedTask struct {
+	ID string `json:"id"`
+}
+
+// InstallDeadLetterRoutes adds dead letter inspection endpoints for the supplied queues, keyed by queue name.
+// Routes should be installed on a router already protected by SimpleAdminAuthMiddleware.
+func InstallDeadLetterRoutes(r *mux.Router, queues map[string]*queue.Queue) {
+	h := &DeadLetters{queues: queues}
+	r.HandleFunc("/deadletters/{queue}", h.List).Methods(http.MethodGet)
+	r.HandleFunc("/deadletters/{queue}/{id}", h.Get).Methods(http.MethodGet)
+	r.HandleFunc("/deadletters/{queue}/{id}", h.Discard).Methods(http.MethodDelete)
+	r.HandleFunc("/deadletters/{queue}/{id}/requeue", h.Requeue).Methods(http.MethodPost)
+	r.HandleFunc("/deadletters/{queue}/{id}/payload", h.Edit).Methods(http.MethodPut)
+}
+
+// List returns archived tasks, optionally filtered by the type query parameter.
+func (h *DeadLetters) List(w http.ResponseWriter, r *http.Request) {
+	q := h.queue(w, r)
+	if q == nil {
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	tasks, err := q.ListDeadTasks(r.URL.Query().Get("type"), page, pageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if tasks == nil {
+		tasks = []*queue.DeadTask{}
+	}
+	responses.WriteJSON(w, tasks)
+}
+
+func (h *DeadLetters) Get(w http.ResponseWriter, r *http.Request) {
+	q := h.queue(w, r)
+	if q == nil {
+		return
+	}
+	task, err := q.GetDeadTask(mux.Vars(r)["id"])
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	responses.WriteJSON(w, task)
+}
+
+// Requeue puts the task back to the queue as is.
+func (h *DeadLetters) Requeue(w http.ResponseWriter, r *http.Request) {
+	q := h.queue(w, r)
+	if q == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := q.RequeueDeadTask(id); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	logger.Log().Infof("dead task %s requeued", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Edit replaces the task payload with the request body and puts it back to the queue.
+func (h *DeadLetters) Edit(w http.ResponseWriter, r *http.Request) {
+	q := h.queue(w, r)
+	if q == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !json.Valid(payload) {
+		http.Error(w, "payload is not valid json", http.StatusBadRequest)
+		return
+	}
+	newID, err := q.EditDeadTask(id, payload)
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	logger.Log().Infof("dead task %s edited and requeued as %s", id, newID)
+	responses.WriteJSON(w, editedTask{ID: newID})
+}
+
+// Discard permanently deletes the task.
+func (h *DeadLetters) Discard(w http.ResponseWriter, r *http.Request) {
+	q := h.queue(w, r)
+	if q == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := q.DiscardDeadTask(id); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	logger.Log().Infof("dead task %s discarded", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DeadLetters) queue(w http.ResponseWriter, r *http.Request) *queue.Queue {
+	q, ok := h.queues[mux.Vars(r)["queue"]]
+	if !ok {
+		http.Error(w, "queue not found", http.StatusNotFound)
+		return nil
+	}
+	return q
+}
+
+func writeDeadLetterError(w http.ResponseWriter, err error) {
+	if errors.Is(err, queue.ErrDeadTaskNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+	"github.com/OdyseeTeam/odysee-api/pkg/queue"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/mux"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := queue.New(
+		queue.WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		queue.WithLogger(logging.NoopKVLogger{}),
+	)
+	require.NoError(t, err)
+	defer q.Shutdown()
+
+	dead := make(chan struct{}, 2)
+	q.AddHandler("test:task", func(context.Context, *asynq.Task) error {
+		return asynq.SkipRetry
+	})
+	q.AddDeadLetterHandler("test:task", func(context.Context, *asynq.Task, error) {
+		dead <- struct{}{}
+	})
+	go q.ServeUntilShutdown()
+
+	require.NoError(t, q.SendRequest("test:task", map[string]string{"upload_id": "abc"}))
+	require.NoError(t, q.SendRequest("test:task", map[string]string{"upload_id": "def"}))
+	for range 2 {
+		select {
+		case <-dead:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for task to fail")
+		}
+	}
+
+	router := mux.NewRouter()
+	InstallDeadLetterRoutes(router, map[string]*queue.Queue{"forklift": q})
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	var tasks []*queue.DeadTask
+	require.Eventually(t, func() bool {
+		rr := call(http.MethodGet, "/deadletters/forklift?type=test:task", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tasks))
+		return len(tasks) == 2
+	}, 5*time.Second, 100*time.Millisecond)
+
+	rr := call(http.MethodGet, "/deadletters/uploads", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = call(http.MethodGet, "/deadletters/forklift/"+tasks[0].ID, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), tasks[0].ID)
+
+	rr = call(http.MethodPut, "/deadletters/forklift/"+tasks[0].ID+"/payload", `{"upload_id"`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = call(http.MethodPut, "/deadletters/forklift/"+tasks[0].ID+"/payload", `{"upload_id": "xyz"}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id"`)
+
+	rr = call(http.MethodDelete, "/deadletters/forklift/"+tasks[1].ID, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = call(http.MethodPost, "/deadletters/forklift/"+tasks[1].ID+"/requeue", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -12,6 +12,10 @@ import (
 
 var Logger = monitor.NewModuleLogger("metrics")
 
+// TrackUIMetric records metrics sent by the frontend.
+//
+// Deprecated: time_to_start is reported to watchman as a startup playback event (POST /reports/startup),
+// which also carries the user, device and stream. This handler is kept until clients stop sending it.
 func TrackUIMetric(w http.ResponseWriter, req *http.Request) {
 	responses.AddJSONContentType(w)
 	resp := make(map[string]string)
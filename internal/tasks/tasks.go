@@ -9,6 +9,30 @@ const (
 	ForkliftUploadIncoming = "forklift:upload:incoming"
 	ForkliftURLIncoming    = "forklift:url:incoming"
 	ForkliftUploadDone     = "forklift:upload:done"
+	ForkliftUploadFailed   = "forklift:upload:failed"
+)
+
+// Queue names. Upload processing is kept apart from queries, so a burst of large uploads doesn't hold up short queries.
+const (
+	QueueDefault = "default"
+	QueueQueries = "queries"
+	QueueUploads = "uploads"
+)
+
+// Routes maps request types to queues they are sent to. Senders and servers have to share it.
+var Routes = map[string]string{
+	AsynqueryIncomingQuery: QueueQueries,
+	ForkliftUploadDone:     QueueUploads,
+	ForkliftUploadFailed:   QueueUploads,
+	ForkliftUploadIncoming: QueueUploads,
+	ForkliftURLIncoming:    QueueUploads,
+}
+
+// AsynqueryQueues and ForkliftQueues are default queue weights for respective servers.
+// Default queue is processed to drain requests sent before routing was introduced.
+var (
+	AsynqueryQueues = map[string]int{QueueQueries: 6, QueueUploads: 3, QueueDefault: 1}
+	ForkliftQueues  = map[string]int{QueueUploads: 9, QueueDefault: 1}
 )
 
 type AsynqueryIncomingQueryPayload struct {
@@ -22,11 +46,20 @@ type ForkliftUploadDonePayload struct {
 	Meta     UploadMeta
 }
 
+// ForkliftUploadFailedPayload is sent when forklift gives up on processing an upload.
+type ForkliftUploadFailedPayload struct {
+	UploadID string `json:"upload_id"`
+	UserID   int32  `json:"user_id"`
+	Error    string `json:"error"`
+}
+
 type ForkliftUploadIncomingPayload struct {
 	UserID       int32          `json:"user_id"`
 	UploadID     string         `json:"upload_id"`
 	FileName     string         `json:"file_name"`
 	FileLocation FileLocationS3 `json:"file_location"`
+	// KeepMetadata is set when the publisher opted out of metadata sanitization.
+	KeepMetadata bool `json:"keep_metadata,omitempty"`
 }
 
 type ForkliftURLIncomingPayload struct {
@@ -34,6 +67,7 @@ type ForkliftURLIncomingPayload struct {
 	UploadID     string           `json:"upload_id"`
 	FileName     string           `json:"file_name"`
 	FileLocation FileLocationHTTP `json:"file_location"`
+	KeepMetadata bool             `json:"keep_metadata,omitempty"`
 }
 
 type FileLocationS3 struct {
@@ -55,6 +89,8 @@ type UploadMeta struct {
 	Duration  int `json:",omitempty"`
 	Width     int `json:",omitempty"`
 	Height    int `json:",omitempty"`
+	// Sanitized lists kinds of metadata removed from the file before it was turned into a stream.
+	Sanitized []string `json:"sanitized,omitempty"`
 }
 
 func (p AsynqueryIncomingQueryPayload) GetTraceData() map[string]string {
@@ -71,6 +107,13 @@ func (p ForkliftUploadDonePayload) GetTraceData() map[string]string {
 	}
 }
 
+func (p ForkliftUploadFailedPayload) GetTraceData() map[string]string {
+	return map[string]string{
+		"user_id":   strconv.Itoa(int(p.UserID)),
+		"upload_id": p.UploadID,
+	}
+}
+
 func (p ForkliftUploadIncomingPayload) GetTraceData() map[string]string {
 	return map[string]string{
 		"user_id":   strconv.Itoa(int(p.UserID)),
@@ -1,15 +1,14 @@
 package blobs
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path"
 	"sort"
 
+	lbryerrors "github.com/lbryio/lbry.go/v2/extras/errors"
 	"github.com/lbryio/lbry.go/v3/stream"
 	"github.com/lbryio/reflector.go/db"
-	"github.com/lbryio/reflector.go/reflector"
 	"github.com/lbryio/reflector.go/store"
 	pb "github.com/lbryio/types/v2/go"
 	"github.com/spf13/viper"
@@ -34,6 +33,8 @@ type Source struct {
 	blobsManifest   []string
 }
 
+// Store is the reflector blob destination: blobs are written to all S3 stores
+// and recorded in the reflector MySQL database.
 type Store struct {
 	db         *db.SQL
 	mainStore  *store.DBBackedStore
@@ -41,10 +42,6 @@ type Store struct {
 	workers    int
 }
 
-type Uploader struct {
-	uploader *reflector.Uploader
-}
-
 // NewSource initializes a blob splitter, takes source file and blobs destination path as arguments.
 func NewSource(filePath, blobPath, encodedFileName string) *Source {
 	s := Source{
@@ -127,17 +124,45 @@ func (s *Store) SetWorkers(workers int) {
 }
 
 // Uploader returns blob file uploader instance for the pre-configured store.
-// Can only be used for one stream upload and discarded afterwards.
-func (s *Store) Uploader() *Uploader {
-	return &Uploader{
-		uploader: reflector.NewUploader(s.db, s.mainStore, s.workers, true, false),
-	}
+func (s *Store) Uploader(options ...UploaderOption) *Uploader {
+	return NewUploader(s, append([]UploaderOption{WithWorkers(s.workers)}, options...)...)
 }
 
 func (s *Store) BlobStores() []store.BlobStore {
 	return s.blobStores
 }
 
+func (s *Store) Name() string {
+	return DestinationReflector
+}
+
+// Has checks the reflector database for the blob.
+func (s *Store) Has(hash string) (bool, error) {
+	return s.mainStore.Has(hash)
+}
+
+// Get returns the blob from the first S3 store which has it.
+func (s *Store) Get(hash string) ([]byte, error) {
+	for _, bs := range s.blobStores {
+		blob, _, err := bs.Get(hash)
+		if lbryerrors.Is(err, store.ErrBlobNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot get blob from %s: %w", bs.Name(), err)
+		}
+		return blob, nil
+	}
+	return nil, ErrBlobNotFound
+}
+
+func (s *Store) Put(hash string, blob []byte) error {
+	return s.mainStore.Put(hash, blob)
+}
+
+func (s *Store) PutSD(hash string, blob []byte) error {
+	return s.mainStore.PutSD(hash, blob)
+}
+
 func (s *Source) Split() (*pb.Stream, error) {
 	file, err := os.Open(s.filePath)
 	if err != nil {
@@ -172,26 +197,27 @@ func (s *Source) Split() (*pb.Stream, error) {
 	return s.stream, nil
 }
 
+// RestoreSource recreates a source which has been split earlier from its blobs directory,
+// so it can be uploaded without splitting the original file again.
+func RestoreSource(blobPath string, manifest []string, stream *pb.Stream) *Source {
+	return &Source{
+		blobPath:      blobPath,
+		finalPath:     blobPath,
+		stream:        stream,
+		blobsManifest: manifest,
+	}
+}
+
 func (s *Source) Stream() *pb.Stream {
 	return s.stream
 }
 
-// Upload is a wrapper for uploading sreams to reflector.
-// Split() should be called on the source first.
-func (u *Uploader) Upload(source *Source) (*reflector.Summary, error) {
-	if source.finalPath == "" || source.Stream() == nil {
-		return nil, errors.New("source is not split to blobs")
-	}
-	fi, err := os.Stat(source.finalPath)
-	if err != nil {
-		return nil, fmt.Errorf("cannot stat source blobs: %w", err)
-	} else if !fi.IsDir() {
-		return nil, fmt.Errorf("blob source %s is not a directory", source.finalPath)
-	}
-	err = u.uploader.Upload(source.finalPath)
-	summary := u.uploader.GetSummary()
-	if err != nil {
-		return nil, fmt.Errorf("cannot upload blobs: %w", err)
-	}
-	return &summary, nil
+// Manifest returns hashes of blobs produced by Split.
+func (s *Source) Manifest() []string {
+	return s.blobsManifest
+}
+
+// BlobPath returns the directory containing blobs produced by Split.
+func (s *Source) BlobPath() string {
+	return s.finalPath
 }
@@ -0,0 +1,68 @@
+package blobs
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	DestinationReflector = "reflector"
+	DestinationLocal     = "local"
+	DestinationMemory    = "memory"
+)
+
+// ErrBlobNotFound is returned by destinations for blobs they don't have.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Destination is a place blobs of split sources are uploaded to, see Uploader.
+type Destination interface {
+	// Name identifies the destination in logs and metrics.
+	Name() string
+	Has(hash string) (bool, error)
+	// Get returns blob contents, ErrBlobNotFound if the destination doesn't have it.
+	Get(hash string) ([]byte, error)
+	Put(hash string, blob []byte) error
+	// PutSD stores a stream descriptor blob, destinations may index the stream it describes.
+	PutSD(hash string, blob []byte) error
+}
+
+// NewDestinationFromConfig creates a blob destination of the type set by the Type key, reflector by default.
+// The reflector destination needs DatabaseDSN and Destinations S3 stores, local one needs Path to a directory.
+func NewDestinationFromConfig(cfg *viper.Viper) (Destination, error) {
+	if cfg == nil {
+		return nil, errors.New("blob destination config is missing")
+	}
+	switch t := strings.ToLower(cfg.GetString("type")); t {
+	case "", DestinationReflector:
+		destinations, err := CreateStoresFromConfig(cfg, "destinations")
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize reflector destination stores: %w", err)
+		}
+		store, err := NewStore(cfg.GetString("databasedsn"), destinations)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize reflector store: %w", err)
+		}
+		return store, nil
+	case DestinationLocal:
+		local, err := NewLocalDestination(cfg.GetString("path"))
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	case DestinationMemory:
+		return NewMemoryDestination(), nil
+	default:
+		return nil, fmt.Errorf("unknown blob destination type: %s", t)
+	}
+}
+
+// BlobHash returns the hex-encoded hash blobs are addressed by.
+func BlobHash(blob []byte) string {
+	h := sha512.Sum384(blob)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,141 @@
+package blobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/lbryio/types/v2/go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestinations(t *testing.T) {
+	local, err := NewLocalDestination(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+
+	for _, dest := range []Destination{local, NewMemoryDestination()} {
+		t.Run(dest.Name(), func(t *testing.T) {
+			blob := randomBlob(t, 1000)
+			hash := BlobHash(blob)
+
+			ok, err := dest.Has(hash)
+			require.NoError(t, err)
+			assert.False(t, ok)
+			_, err = dest.Get(hash)
+			assert.ErrorIs(t, err, ErrBlobNotFound)
+
+			require.NoError(t, dest.Put(hash, blob))
+			ok, err = dest.Has(hash)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			stored, err := dest.Get(hash)
+			require.NoError(t, err)
+			assert.Equal(t, blob, stored)
+
+			sd := []byte(`{"stream_hash": "abc"}`)
+			require.NoError(t, dest.PutSD(BlobHash(sd), sd))
+			stored, err = dest.Get(BlobHash(sd))
+			require.NoError(t, err)
+			assert.Equal(t, sd, stored)
+		})
+	}
+}
+
+func TestLocalDestinationInvalidHash(t *testing.T) {
+	dest, err := NewLocalDestination(t.TempDir())
+	require.NoError(t, err)
+
+	for _, hash := range []string{"", "../escape", ".tmp-abc", "a/b"} {
+		assert.Error(t, dest.Put(hash, []byte("blob")), hash)
+		_, err := dest.Get(hash)
+		assert.Error(t, err, hash)
+	}
+}
+
+func TestNewDestinationFromConfig(t *testing.T) {
+	v := viper.New()
+	v.Set("Type", "memory")
+	dest, err := NewDestinationFromConfig(v)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryDestination{}, dest)
+
+	dir := filepath.Join(t.TempDir(), "blobs")
+	v = viper.New()
+	v.Set("Type", "Local")
+	v.Set("Path", dir)
+	dest, err = NewDestinationFromConfig(v)
+	require.NoError(t, err)
+	require.IsType(t, &LocalDestination{}, dest)
+	assert.Equal(t, dir, dest.(*LocalDestination).Dir())
+
+	v = viper.New()
+	v.Set("Type", "local")
+	_, err = NewDestinationFromConfig(v)
+	assert.Error(t, err)
+
+	v = viper.New()
+	v.Set("Type", "tape")
+	_, err = NewDestinationFromConfig(v)
+	assert.ErrorContains(t, err, "unknown blob destination type")
+}
+
+func TestUploader(t *testing.T) {
+	blobPath := t.TempDir()
+	sd := []byte(`{"stream_hash": "abc", "blobs": []}`)
+	sdHash := BlobHash(sd)
+	require.NoError(t, os.WriteFile(filepath.Join(blobPath, sdHash), sd, 0600))
+	hashes := []string{sdHash}
+	for range 9 {
+		blob := randomBlob(t, 2000)
+		hash := BlobHash(blob)
+		hashes = append(hashes, hash)
+		require.NoError(t, os.WriteFile(filepath.Join(blobPath, hash), blob, 0600))
+	}
+	sdHashBytes, err := hex.DecodeString(sdHash)
+	require.NoError(t, err)
+	src := RestoreSource(blobPath, hashes, &pb.Stream{Source: &pb.Source{SdHash: sdHashBytes}})
+
+	dest := NewMemoryDestination()
+	var reports []Progress
+	summary, err := NewUploader(dest, WithWorkers(3), WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	})).Upload(src)
+	require.NoError(t, err)
+	assert.Equal(t, 10, summary.Total)
+	assert.Equal(t, 1, summary.Sd)
+	assert.Equal(t, 9, summary.Blob)
+	assert.Zero(t, summary.Err)
+	assert.EqualValues(t, len(sd)+9*2000, summary.Bytes)
+	assert.Equal(t, 10, dest.Len())
+	require.Len(t, reports, 10)
+	for i, p := range reports {
+		assert.Equal(t, DestinationMemory, p.Destination)
+		assert.Equal(t, i+1, p.Done())
+	}
+
+	dest.Delete(hashes[1])
+	require.NoError(t, os.WriteFile(filepath.Join(blobPath, "corrupt"), []byte("corrupt"), 0600))
+	summary, err = NewUploader(dest, WithSkipExisting()).Upload(src)
+	require.NoError(t, err)
+	assert.Equal(t, 11, summary.Total)
+	assert.Equal(t, 9, summary.AlreadyStored)
+	assert.Equal(t, 1, summary.Blob)
+	assert.Equal(t, 1, summary.Err)
+	ok, err := dest.Has(hashes[1])
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = NewUploader(dest).Upload(NewSource("file", blobPath, "file"))
+	assert.Error(t, err)
+}
+
+func randomBlob(t *testing.T, size int) []byte {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,90 @@
+package blobs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalDestination keeps blobs as files named by their hashes in a single directory.
+type LocalDestination struct {
+	dir string
+}
+
+// NewLocalDestination creates a destination storing blobs in dir, which is created if it doesn't exist.
+func NewLocalDestination(dir string) (*LocalDestination, error) {
+	if dir == "" {
+		return nil, errors.New("local blob destination path is required")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("cannot create blob destination directory: %w", err)
+	}
+	return &LocalDestination{dir: dir}, nil
+}
+
+func (d *LocalDestination) Name() string {
+	return DestinationLocal
+}
+
+// Dir returns the directory blobs are stored in.
+func (d *LocalDestination) Dir() string {
+	return d.dir
+}
+
+func (d *LocalDestination) Has(hash string) (bool, error) {
+	p, err := d.path(hash)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (d *LocalDestination) Get(hash string) ([]byte, error) {
+	p, err := d.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return blob, err
+}
+
+// Put writes the blob to a temporary file first so partially written blobs are never visible.
+func (d *LocalDestination) Put(hash string, blob []byte) error {
+	p, err := d.path(hash)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(d.dir, ".tmp-"+hash)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(blob)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (d *LocalDestination) PutSD(hash string, blob []byte) error {
+	return d.Put(hash, blob)
+}
+
+// path makes sure hashes can't point outside of the destination directory.
+func (d *LocalDestination) path(hash string) (string, error) {
+	if hash == "" || filepath.Base(hash) != hash || hash[0] == '.' {
+		return "", fmt.Errorf("invalid blob hash: %q", hash)
+	}
+	return filepath.Join(d.dir, hash), nil
+}
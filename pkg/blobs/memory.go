@@ -0,0 +1,63 @@
+package blobs
+
+import (
+	"sync"
+)
+
+// MemoryDestination keeps blobs in memory, it is meant for tests and local development.
+type MemoryDestination struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+func NewMemoryDestination() *MemoryDestination {
+	return &MemoryDestination{
+		blobs: map[string][]byte{},
+	}
+}
+
+func (d *MemoryDestination) Name() string {
+	return DestinationMemory
+}
+
+func (d *MemoryDestination) Has(hash string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.blobs[hash]
+	return ok, nil
+}
+
+func (d *MemoryDestination) Get(hash string) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	blob, ok := d.blobs[hash]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return append([]byte(nil), blob...), nil
+}
+
+func (d *MemoryDestination) Put(hash string, blob []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.blobs[hash] = append([]byte(nil), blob...)
+	return nil
+}
+
+func (d *MemoryDestination) PutSD(hash string, blob []byte) error {
+	return d.Put(hash, blob)
+}
+
+// Delete removes the blob, so tests can simulate blobs going missing.
+func (d *MemoryDestination) Delete(hash string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.blobs, hash)
+}
+
+// Len returns the number of blobs stored.
+func (d *MemoryDestination) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.blobs)
+}
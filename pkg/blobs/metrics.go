@@ -0,0 +1,73 @@
+package blobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const ns = "blobs"
+
+var (
+	blobsUploaded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "uploaded_total",
+		Help:      "Blobs uploaded by destination and kind, sd or content",
+	}, []string{"destination", "kind"})
+	bytesUploaded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "uploaded_bytes_total",
+	}, []string{"destination"})
+	uploadErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "upload_errors_total",
+	}, []string{"destination"})
+	blobUploadSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Name:      "blob_upload_seconds",
+		Help:      "Time taken to put a single blob into the destination",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"destination"})
+	streamUploadSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Name:      "stream_upload_seconds",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	}, []string{"destination"})
+
+	verifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "verifications_total",
+		Help:      "Streams verified by result: ok, broken or error",
+	}, []string{"destination", "result"})
+	blobsBroken = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "broken_total",
+		Help:      "Blobs found missing or corrupt by verification",
+	}, []string{"destination", "state"})
+	blobsReuploaded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "reuploaded_total",
+	}, []string{"destination"})
+)
+
+// RegisterMetrics registers blob upload metrics with the registry, the default registerer is used if it's nil.
+func RegisterMetrics(registry prometheus.Registerer) {
+	if registry == nil {
+		registry = prometheus.DefaultRegisterer
+	}
+	registry.MustRegister(
+		blobsUploaded, bytesUploaded, uploadErrors, blobUploadSeconds, streamUploadSeconds,
+		verifications, blobsBroken, blobsReuploaded,
+	)
+}
+
+func observeVerification(destination string, report *Report) {
+	result := "ok"
+	switch {
+	case len(report.Unchecked) > 0:
+		result = "error"
+	case !report.OK():
+		result = "broken"
+	}
+	verifications.WithLabelValues(destination, result).Inc()
+	blobsBroken.WithLabelValues(destination, "missing").Add(float64(len(report.Missing)))
+	blobsBroken.WithLabelValues(destination, "corrupt").Add(float64(len(report.Corrupt)))
+}
@@ -0,0 +1,200 @@
+package blobs
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+)
+
+// Summary counts blobs processed by an upload.
+type Summary struct {
+	Total, AlreadyStored, Sd, Blob, Err int
+	Bytes                               int64
+}
+
+// Progress is reported after each blob of a stream is processed.
+type Progress struct {
+	Destination string
+	Summary
+}
+
+// Done returns the number of blobs processed so far, including failed and skipped ones.
+func (p Progress) Done() int {
+	return p.AlreadyStored + p.Sd + p.Blob + p.Err
+}
+
+type ProgressFunc func(Progress)
+
+// Uploader puts blobs of split sources into a destination.
+type Uploader struct {
+	destination Destination
+	workers     int
+	skipExists  bool
+	progress    ProgressFunc
+	logger      logging.KVLogger
+}
+
+type UploaderOption func(*Uploader)
+
+// WithWorkers sets the number of blobs uploaded concurrently, 1 by default.
+func WithWorkers(workers int) UploaderOption {
+	return func(u *Uploader) {
+		if workers > 0 {
+			u.workers = workers
+		}
+	}
+}
+
+// WithSkipExisting makes the uploader check if each blob is already in the destination before uploading it.
+func WithSkipExisting() UploaderOption {
+	return func(u *Uploader) {
+		u.skipExists = true
+	}
+}
+
+// WithProgress sets a function called after each blob is processed. Calls are not concurrent.
+func WithProgress(fn ProgressFunc) UploaderOption {
+	return func(u *Uploader) {
+		u.progress = fn
+	}
+}
+
+func WithUploaderLogger(logger logging.KVLogger) UploaderOption {
+	return func(u *Uploader) {
+		u.logger = logger
+	}
+}
+
+func NewUploader(destination Destination, options ...UploaderOption) *Uploader {
+	u := &Uploader{
+		destination: destination,
+		workers:     1,
+		logger:      logging.NoopKVLogger{},
+	}
+	for _, o := range options {
+		o(u)
+	}
+	return u
+}
+
+// Upload uploads all blobs from the source directory. Split() should be called on the source first.
+// Blobs which fail to upload are logged and counted in Summary.Err, the error is only returned
+// if the source itself cannot be read.
+func (u *Uploader) Upload(source *Source) (*Summary, error) {
+	if source.finalPath == "" || source.Stream() == nil {
+		return nil, errors.New("source is not split to blobs")
+	}
+	fi, err := os.Stat(source.finalPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot stat source blobs: %w", err)
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("blob source %s is not a directory", source.finalPath)
+	}
+	entries, err := os.ReadDir(source.finalPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list source blobs: %w", err)
+	}
+	hashes := []string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			hashes = append(hashes, e.Name())
+		}
+	}
+
+	var (
+		name    = u.destination.Name()
+		sdHash  = hex.EncodeToString(source.Stream().GetSource().GetSdHash())
+		summary = Summary{Total: len(hashes)}
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		queue   = make(chan string)
+		start   = time.Now()
+	)
+	report := func(update func(*Summary)) {
+		mu.Lock()
+		defer mu.Unlock()
+		update(&summary)
+		if u.progress != nil {
+			u.progress(Progress{Destination: name, Summary: summary})
+		}
+	}
+
+	for range u.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range queue {
+				if u.skipExists {
+					if ok, err := u.destination.Has(hash); err == nil && ok {
+						report(func(s *Summary) { s.AlreadyStored++ })
+						continue
+					}
+				}
+				size, sd, err := u.uploadBlob(path.Join(source.finalPath, hash), hash, sdHash)
+				if err != nil {
+					uploadErrors.WithLabelValues(name).Inc()
+					u.logger.Warn("blob upload failed", "hash", hash, "destination", name, "err", err)
+					report(func(s *Summary) { s.Err++ })
+					continue
+				}
+				bytesUploaded.WithLabelValues(name).Add(float64(size))
+				report(func(s *Summary) {
+					s.Bytes += int64(size)
+					if sd {
+						s.Sd++
+					} else {
+						s.Blob++
+					}
+				})
+			}
+		}()
+	}
+	for _, hash := range hashes {
+		queue <- hash
+	}
+	close(queue)
+	wg.Wait()
+
+	streamUploadSeconds.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	u.logger.Debug(
+		"stream blobs uploaded", "destination", name, "total", summary.Total, "already_stored", summary.AlreadyStored,
+		"sd", summary.Sd, "content", summary.Blob, "errors", summary.Err, "seconds", time.Since(start).Seconds(),
+	)
+	return &summary, nil
+}
+
+func (u *Uploader) uploadBlob(blobPath, hash, sdHash string) (int, bool, error) {
+	blob, err := os.ReadFile(blobPath)
+	if err != nil {
+		return 0, false, err
+	}
+	if h := BlobHash(blob); h != hash {
+		return 0, false, fmt.Errorf("file name does not match blob hash (%s != %s)", hash, h)
+	}
+
+	name := u.destination.Name()
+	start := time.Now()
+	sd := hash == sdHash || json.Valid(blob)
+	if sd {
+		err = u.destination.PutSD(hash, blob)
+	} else {
+		err = u.destination.Put(hash, blob)
+	}
+	if err != nil {
+		return 0, sd, err
+	}
+	blobUploadSeconds.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	kind := "content"
+	if sd {
+		kind = "sd"
+	}
+	blobsUploaded.WithLabelValues(name, kind).Inc()
+	return len(blob), sd, nil
+}
@@ -0,0 +1,215 @@
+package blobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/lbryio/lbry.go/v3/stream"
+)
+
+// Report lists blobs of a stream which are not intact in the destination.
+type Report struct {
+	SDHash string `json:"sd_hash"`
+	// Blobs is the number of content blobs listed in the sd blob.
+	Blobs   int      `json:"blobs"`
+	Missing []string `json:"missing,omitempty"`
+	Corrupt []string `json:"corrupt,omitempty"`
+	// Unchecked blobs could not be fetched from the destination, mapped to the error.
+	Unchecked map[string]string `json:"unchecked,omitempty"`
+
+	sdBlob []byte
+}
+
+// OK is true when the sd blob and all content blobs are stored intact.
+func (r *Report) OK() bool {
+	return len(r.Missing) == 0 && len(r.Corrupt) == 0 && len(r.Unchecked) == 0
+}
+
+// Broken returns hashes of missing and corrupt blobs.
+func (r *Report) Broken() []string {
+	return append(slices.Clone(r.Missing), r.Corrupt...)
+}
+
+// ReuploadSource points to local copies of a stream blobs can be uploaded again from.
+type ReuploadSource struct {
+	// BlobPath is a directory with blobs produced by Source.Split, it is checked first.
+	BlobPath string
+	// FilePath is the original file, which gets encoded again with the key and IVs from the sd blob.
+	FilePath string
+}
+
+// Verifier checks that all blobs of a stream can be fetched from the destination and match their hashes and sizes.
+type Verifier struct {
+	destination Destination
+	workers     int
+}
+
+// sdBlob is the part of a stream descriptor needed to walk the stream.
+type sdBlob struct {
+	StreamHash string `json:"stream_hash"`
+	Blobs      []struct {
+		BlobHash string `json:"blob_hash"`
+		Length   int    `json:"length"`
+	} `json:"blobs"`
+}
+
+func NewVerifier(destination Destination, workers int) *Verifier {
+	return &Verifier{destination: destination, workers: max(workers, 1)}
+}
+
+// Verify fetches the sd blob and every content blob it lists.
+// Only failures to reach the destination are returned as errors, broken blobs are listed in the report.
+func (v *Verifier) Verify(sdHash string) (*Report, error) {
+	name := v.destination.Name()
+	report := &Report{SDHash: sdHash}
+	data, err := v.destination.Get(sdHash)
+	if errors.Is(err, ErrBlobNotFound) {
+		report.Missing = []string{sdHash}
+		observeVerification(name, report)
+		return report, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get sd blob: %w", err)
+	}
+	var sd sdBlob
+	if BlobHash(data) != sdHash || json.Unmarshal(data, &sd) != nil || sd.StreamHash == "" {
+		report.Corrupt = []string{sdHash}
+		observeVerification(name, report)
+		return report, nil
+	}
+	report.sdBlob = data
+
+	type check struct {
+		hash   string
+		length int
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		checks = make(chan check)
+	)
+	for range v.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range checks {
+				blob, err := v.destination.Get(c.hash)
+				mu.Lock()
+				switch {
+				case errors.Is(err, ErrBlobNotFound):
+					report.Missing = append(report.Missing, c.hash)
+				case err != nil:
+					if report.Unchecked == nil {
+						report.Unchecked = map[string]string{}
+					}
+					report.Unchecked[c.hash] = err.Error()
+				case len(blob) != c.length || BlobHash(blob) != c.hash:
+					report.Corrupt = append(report.Corrupt, c.hash)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, b := range sd.Blobs {
+		// The stream terminator has no hash and zero length.
+		if b.BlobHash == "" {
+			continue
+		}
+		report.Blobs++
+		checks <- check{b.BlobHash, b.Length}
+	}
+	close(checks)
+	wg.Wait()
+
+	slices.Sort(report.Missing)
+	slices.Sort(report.Corrupt)
+	observeVerification(name, report)
+	if len(report.Unchecked) > 0 {
+		return report, fmt.Errorf("cannot get %d blobs from %s", len(report.Unchecked), name)
+	}
+	return report, nil
+}
+
+// Reupload puts missing and corrupt blobs from the report back into the destination, reading them
+// from the source blob directory or encoding the original file again. Blobs which could not be restored
+// are counted in Summary.Err, the sd blob can only be restored from the blob directory.
+func (v *Verifier) Reupload(report *Report, source ReuploadSource) (*Summary, error) {
+	broken := report.Broken()
+	summary := &Summary{Total: len(broken)}
+	wanted := map[string]bool{}
+	put := func(hash string, blob []byte) error {
+		var err error
+		if hash == report.SDHash {
+			err = v.destination.PutSD(hash, blob)
+		} else {
+			err = v.destination.Put(hash, blob)
+		}
+		if err != nil {
+			return err
+		}
+		if hash == report.SDHash {
+			summary.Sd++
+		} else {
+			summary.Blob++
+		}
+		summary.Bytes += int64(len(blob))
+		blobsReuploaded.WithLabelValues(v.destination.Name()).Inc()
+		delete(wanted, hash)
+		return nil
+	}
+
+	for _, hash := range broken {
+		wanted[hash] = true
+		if source.BlobPath == "" {
+			continue
+		}
+		blob, err := os.ReadFile(filepath.Join(source.BlobPath, filepath.Base(hash)))
+		if err != nil || BlobHash(blob) != hash {
+			continue
+		}
+		if err := put(hash, blob); err != nil {
+			return summary, fmt.Errorf("cannot upload blob %s: %w", hash, err)
+		}
+	}
+
+	if len(wanted) > 0 && source.FilePath != "" && report.sdBlob != nil {
+		if err := v.reencode(report.sdBlob, source.FilePath, wanted, put); err != nil {
+			return summary, err
+		}
+	}
+	summary.Err = len(wanted)
+	return summary, nil
+}
+
+// reencode splits the original file again using the key and IVs of the existing stream,
+// which yields the same content blobs as long as the file has not changed.
+func (v *Verifier) reencode(sdData []byte, filePath string, wanted map[string]bool, put func(string, []byte) error) error {
+	sd := &stream.SDBlob{}
+	if err := sd.FromBlob(sdData); err != nil {
+		return fmt.Errorf("cannot parse sd blob: %w", err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot open source: %w", err)
+	}
+	defer file.Close()
+
+	enc := stream.NewEncoderFromSD(file, sd)
+	_, err = enc.Encode(func(h string, b []byte) error {
+		if !wanted[h] {
+			return nil
+		}
+		if err := put(h, b); err != nil {
+			return fmt.Errorf("cannot upload blob %s: %w", h, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot encode source: %w", err)
+	}
+	return nil
+}
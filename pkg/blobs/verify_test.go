@@ -0,0 +1,137 @@
+package blobs
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OdyseeTeam/odysee-api/internal/test"
+
+	pb "github.com/lbryio/types/v2/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStream writes an sd blob and content blobs to dir, returning the sd hash and content blob hashes.
+func fakeStream(t *testing.T, dir string, count int) (string, []string) {
+	type blobInfo struct {
+		BlobHash string `json:"blob_hash,omitempty"`
+		BlobNum  int    `json:"blob_num"`
+		IV       string `json:"iv"`
+		Length   int    `json:"length"`
+	}
+	var (
+		infos  []blobInfo
+		hashes []string
+	)
+	for i := range count {
+		blob := randomBlob(t, 1000+i)
+		hash := BlobHash(blob)
+		hashes = append(hashes, hash)
+		infos = append(infos, blobInfo{BlobHash: hash, BlobNum: i, IV: "00", Length: len(blob)})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, hash), blob, 0600))
+	}
+	infos = append(infos, blobInfo{BlobNum: count, IV: "00"})
+	sd, err := json.Marshal(map[string]any{
+		"blobs":       infos,
+		"key":         "00",
+		"stream_hash": "abc",
+		"stream_name": "66616b65",
+		"stream_type": "lbryfile",
+	})
+	require.NoError(t, err)
+	sdHash := BlobHash(sd)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sdHash), sd, 0600))
+	return sdHash, hashes
+}
+
+func TestVerifier(t *testing.T) {
+	blobPath := t.TempDir()
+	sdHash, hashes := fakeStream(t, blobPath, 5)
+	sdHashBytes, err := hex.DecodeString(sdHash)
+	require.NoError(t, err)
+
+	dest := NewMemoryDestination()
+	src := RestoreSource(blobPath, nil, &pb.Stream{Source: &pb.Source{SdHash: sdHashBytes}})
+	summary, err := NewUploader(dest).Upload(src)
+	require.NoError(t, err)
+	require.Zero(t, summary.Err)
+
+	v := NewVerifier(dest, 2)
+	report, err := v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+	assert.Equal(t, 5, report.Blobs)
+
+	dest.Delete(hashes[3])
+	require.NoError(t, dest.Put(hashes[1], []byte("corrupt")))
+	report, err = v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, []string{hashes[3]}, report.Missing)
+	assert.Equal(t, []string{hashes[1]}, report.Corrupt)
+
+	summary, err = v.Reupload(report, ReuploadSource{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Err)
+
+	summary, err = v.Reupload(report, ReuploadSource{BlobPath: blobPath})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Blob)
+	assert.Zero(t, summary.Err)
+
+	report, err = v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+
+	dest.Delete(sdHash)
+	report, err = v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{sdHash}, report.Missing)
+	summary, err = v.Reupload(report, ReuploadSource{BlobPath: blobPath})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Sd)
+
+	require.NoError(t, dest.PutSD(sdHash, []byte(`{"stream_hash": "abc"}`)))
+	report, err = v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{sdHash}, report.Corrupt)
+}
+
+func TestVerifierReuploadFromFile(t *testing.T) {
+	filePath := test.StaticAsset(t, "doc.pdf")
+	src := NewSource(filePath, t.TempDir(), "doc.pdf")
+	pbs, err := src.Split()
+	require.NoError(t, err)
+	sdHash := hex.EncodeToString(pbs.GetSource().GetSdHash())
+
+	dest := NewMemoryDestination()
+	summary, err := NewUploader(dest).Upload(src)
+	require.NoError(t, err)
+	require.Zero(t, summary.Err)
+
+	var removed []string
+	for _, h := range src.Manifest() {
+		if h != sdHash {
+			dest.Delete(h)
+			removed = append(removed, h)
+		}
+	}
+	require.NotEmpty(t, removed)
+
+	v := NewVerifier(dest, 1)
+	report, err := v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, removed, report.Missing)
+
+	summary, err = v.Reupload(report, ReuploadSource{FilePath: filePath})
+	require.NoError(t, err)
+	assert.Equal(t, len(removed), summary.Blob)
+	assert.Zero(t, summary.Err)
+
+	report, err = v.Verify(sdHash)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
@@ -1,21 +1,30 @@
 package chainquery
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/friendsofgo/errors"
 )
 
 const (
-	apiUrl       = "https://chainquery.odysee.tv/api/sql"
+	DefaultBaseURL = "https://chainquery.odysee.tv/api/sql"
+
 	queryTimeout = 15 * time.Second
+	retries      = 2
+	retryWait    = 250 * time.Millisecond
 )
 
+// ErrNotFound is returned when the requested claim or transaction isn't known to chainquery.
+var ErrNotFound = errors.New("not found in chainquery")
+
 type HttpDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
@@ -30,13 +39,89 @@ type HeightData struct {
 	Height int `json:"height"`
 }
 
-var client HttpDoer = &http.Client{
-	Timeout: queryTimeout,
+// Client runs queries against the chainquery SQL API.
+// Requests failing with network or server errors are retried, each attempt is limited by the timeout.
+type Client struct {
+	baseURL   string
+	client    HttpDoer
+	timeout   time.Duration
+	retries   int
+	retryWait time.Duration
 }
 
+type Option func(c *Client)
+
+// WithBaseURL sets the SQL API endpoint, empty value keeps the default.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+func WithHttpClient(client HttpDoer) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithTimeout limits the duration of a single request attempt, zero keeps the default.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithRetries sets how many times a failed request is retried, waiting wait times the attempt number in between.
+func WithRetries(n int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.retries = n
+		c.retryWait = wait
+	}
+}
+
+func NewClient(options ...Option) *Client {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		client:    &http.Client{},
+		timeout:   queryTimeout,
+		retries:   retries,
+		retryWait: retryWait,
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+var defaultClient atomic.Pointer[Client]
+
+func init() {
+	defaultClient.Store(NewClient())
+}
+
+// SetDefault sets the client used by package-level functions.
+func SetDefault(c *Client) {
+	defaultClient.Store(c)
+}
+
+// Default returns the client used by package-level functions.
+func Default() *Client {
+	return defaultClient.Load()
+}
+
+// GetHeight returns the latest block height using the default client.
 func GetHeight() (int, error) {
+	return Default().Height(context.Background())
+}
+
+// Height returns the latest block height.
+func (c *Client) Height(ctx context.Context) (int, error) {
 	r := HeightResponse{}
-	err := makeRequest(client, "select height from block order by id desc limit 1", &r)
+	err := c.query(ctx, queryHeight, &r)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving latest height")
 	}
@@ -46,8 +131,71 @@ func GetHeight() (int, error) {
 	return r.Data[0].Height, nil
 }
 
-func makeRequest(client HttpDoer, query string, target any) error {
-	baseUrl, err := url.Parse(apiUrl)
+// response is the SQL API response envelope with rows of type T.
+type response[T any] struct {
+	Success bool    `json:"success"`
+	Error   *string `json:"error"`
+	Data    []T     `json:"data"`
+}
+
+// rows runs the query and returns its rows.
+func rows[T any](ctx context.Context, c *Client, query string) ([]T, error) {
+	r := response[T]{}
+	if err := c.query(ctx, query, &r); err != nil {
+		return nil, err
+	}
+	if r.Error != nil {
+		return nil, fmt.Errorf("query failed: %s", *r.Error)
+	}
+	if !r.Success {
+		return nil, errors.New("query failed")
+	}
+	return r.Data, nil
+}
+
+// statusError is returned for unexpected response status codes.
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: got %v, want %v", e.code, http.StatusOK)
+}
+
+// query runs the query, retrying failed requests, and decodes the response into target.
+func (c *Client) query(ctx context.Context, query string, target any) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.makeRequest(ctx, query, target)
+		if err == nil || attempt >= c.retries || !retryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-time.After(time.Duration(attempt+1) * c.retryWait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// retryable is true for network errors, attempt timeouts and server errors.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var se statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError || se.code == http.StatusTooManyRequests
+	}
+	var ue *url.Error
+	return errors.As(err, &ue)
+}
+
+func (c *Client) makeRequest(ctx context.Context, query string, target any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	baseUrl, err := url.Parse(c.baseURL)
 	if err != nil {
 		return err
 	}
@@ -55,12 +203,12 @@ func makeRequest(client HttpDoer, query string, target any) error {
 	params.Add("query", query)
 	baseUrl.RawQuery = params.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, baseUrl.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseUrl.String(), nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error sending request: %w", err)
 	}
@@ -72,7 +220,7 @@ func makeRequest(client HttpDoer, query string, target any) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: got %v, want %v", resp.StatusCode, http.StatusOK)
+		return statusError{resp.StatusCode}
 	}
 
 	err = json.Unmarshal(body, target)
@@ -81,3 +229,11 @@ func makeRequest(client HttpDoer, query string, target any) error {
 	}
 	return nil
 }
+
+// quote returns s as a MySQL string literal.
+func quote(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`,
+	)
+	return "'" + r.Replace(s) + "'"
+}
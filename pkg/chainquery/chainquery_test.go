@@ -2,10 +2,12 @@ package chainquery
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,13 +44,138 @@ func TestGetHeight(t *testing.T) {
 func TestGetHeightFailure(t *testing.T) {
 	assert := assert.New(t)
 
-	origClient := client
-	client = failureTestClient{}
-	defer func() {
-		client = origClient
-	}()
+	origClient := Default()
+	SetDefault(NewClient(WithHttpClient(failureTestClient{})))
+	defer SetDefault(origClient)
 
 	height, err := GetHeight()
 	assert.ErrorContains(err, "error retrieving latest height, expected 1 items in response, got 0")
 	assert.Equal(0, height)
 }
+
+func TestClientClaims(t *testing.T) {
+	ctx := context.Background()
+	s := NewFakeServer(t)
+	channel := "0ac7fd4f2e0da0e2e6fa57e3e14e0ac53ae8dbb4"
+	s.AddClaims(
+		Claim{ClaimID: channel, Name: "@channel", ClaimType: ClaimTypeChannel, BidState: "Controlling"},
+		Claim{ClaimID: "5d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3c4b5a697", Name: "it's", ClaimType: ClaimTypeStream, PublisherID: channel, BidState: "Active", EffectiveAmount: 100},
+		Claim{ClaimID: "b5a6975d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3c4", Name: "it's", ClaimType: ClaimTypeStream, PublisherID: channel, BidState: "Controlling", EffectiveAmount: 500},
+		Claim{ClaimID: "c4b5a6975d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3", Name: "it's", ClaimType: ClaimTypeStream, PublisherID: channel, BidState: BidStateSpent, EffectiveAmount: 900},
+	)
+	c := s.Client()
+
+	claim, err := c.ClaimByID(ctx, channel)
+	require.NoError(t, err)
+	assert.Equal(t, "@channel", claim.Name)
+	assert.Equal(t, ClaimTypeChannel, claim.ClaimType)
+
+	_, err = c.ClaimByID(ctx, "ffffffffffffffffffffffffffffffffffffffff")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	claims, err := c.ClaimsByName(ctx, "it's")
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	assert.Equal(t, "b5a6975d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3c4", claims[0].ClaimID)
+	assert.Equal(t, "5d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3c4b5a697", claims[1].ClaimID)
+
+	claims, err = c.ClaimsByName(ctx, `x' or '1'='1`)
+	require.NoError(t, err)
+	assert.Empty(t, claims)
+
+	count, err := c.ChannelClaimCount(ctx, channel)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	for _, id := range []string{"", "5d6a2f1e", "' or 1=1 --", "5D6A2F1E7B1F4E6FB0F3C2A8A0A1E2D3C4B5A697"} {
+		_, err := c.ClaimByID(ctx, id)
+		assert.ErrorContains(t, err, "invalid claim id")
+	}
+	assert.Len(t, s.Queries(), 5, "invalid arguments shouldn't be sent")
+}
+
+func TestClientSupportsAndTransactions(t *testing.T) {
+	ctx := context.Background()
+	s := NewFakeServer(t)
+	claimID := "5d6a2f1e7b1f4e6fb0f3c2a8a0a1e2d3c4b5a697"
+	tx := "8d4b0f3e1a5c6b7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d"
+	s.SetHeight(1500100)
+	s.AddSupport(claimID, 10.5)
+	s.AddSupport(claimID, 0.25)
+	s.AddTx(tx, 1500095)
+	c := s.Client()
+
+	total, err := c.SupportTotal(ctx, claimID)
+	require.NoError(t, err)
+	assert.Equal(t, 10.75, total)
+	total, err = c.SupportTotal(ctx, "ffffffffffffffffffffffffffffffffffffffff")
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	status, err := c.TxStatus(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, status.Confirmations())
+	_, err = c.TxStatus(ctx, "ff"+tx[2:])
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.TxStatus(ctx, claimID)
+	assert.ErrorContains(t, err, "invalid transaction hash")
+
+	height, err := c.Height(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1500100, height)
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+	s := NewFakeServer(t)
+	s.SetHeight(1500100)
+
+	s.FailNext(2, http.StatusBadGateway)
+	height, err := s.Client().Height(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1500100, height)
+	assert.Len(t, s.Queries(), 3)
+
+	s.FailNext(3, http.StatusServiceUnavailable)
+	_, err = s.Client().Height(ctx)
+	assert.ErrorContains(t, err, "unexpected status code: got 503")
+	assert.Len(t, s.Queries(), 6)
+
+	// Client errors aren't retried.
+	s.FailNext(1, http.StatusBadRequest)
+	_, err = s.Client().Height(ctx)
+	assert.ErrorContains(t, err, "unexpected status code: got 400")
+	assert.Len(t, s.Queries(), 7)
+}
+
+func TestClientTimeout(t *testing.T) {
+	s := NewFakeServer(t)
+	s.SetHeight(1500100)
+	c := s.Client(WithTimeout(50*time.Millisecond), WithRetries(1, 0))
+
+	// Hold the fake server lock so that requests hang.
+	s.mu.Lock()
+	start := time.Now()
+	_, err := c.Height(context.Background())
+	s.mu.Unlock()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Height(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAmount(t *testing.T) {
+	var a struct {
+		Number Amount `json:"number"`
+		String Amount `json:"string"`
+		Null   Amount `json:"null"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"number": 1.5, "string": "2.25000000", "null": null}`), &a))
+	assert.Equal(t, Amount(1.5), a.Number)
+	assert.Equal(t, Amount(2.25), a.String)
+	assert.Zero(t, a.Null)
+	assert.Error(t, json.Unmarshal([]byte(`"abc"`), &a.Number))
+}
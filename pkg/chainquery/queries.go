@@ -0,0 +1,186 @@
+package chainquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/friendsofgo/errors"
+)
+
+const (
+	ClaimTypeStream  = 1
+	ClaimTypeChannel = 2
+
+	BidStateSpent = "Spent"
+
+	// maxClaimsByName limits the number of claims returned for a name.
+	maxClaimsByName = 100
+)
+
+// Query templates, string arguments are substituted as quoted literals. FakeServer matches requests against them.
+const (
+	queryHeight = "select height from block order by id desc limit 1"
+
+	claimColumns = "claim_id, name, claim_type, publisher_id, title, bid_state, effective_amount, height, " +
+		"release_time, transaction_hash_id, vout"
+
+	queryClaimByID = "select " + claimColumns + " from claim where claim_id = %s"
+
+	queryClaimsByName = "select " + claimColumns + " from claim where name = %s and bid_state <> 'Spent' " +
+		"order by effective_amount desc limit %d"
+
+	queryChannelClaimCount = "select count(*) as count from claim where publisher_id = %s and bid_state <> 'Spent'"
+
+	querySupportTotal = "select coalesce(sum(support_amount), 0) as total from support " +
+		"where supported_claim_id = %s and bid_state <> 'Spent'"
+
+	queryTxStatus = "select hash, height, (select height from block order by id desc limit 1) as best_height " +
+		"from transaction where hash = %s"
+)
+
+var (
+	reClaimID = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	reTxHash  = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+type Claim struct {
+	ClaimID     string `json:"claim_id"`
+	Name        string `json:"name"`
+	ClaimType   int    `json:"claim_type"`
+	PublisherID string `json:"publisher_id"`
+	Title       string `json:"title"`
+	BidState    string `json:"bid_state"`
+	// EffectiveAmount is the claim amount together with its supports, in dewies.
+	EffectiveAmount uint64 `json:"effective_amount"`
+	Height          int    `json:"height"`
+	ReleaseTime     int64  `json:"release_time"`
+	TxHash          string `json:"transaction_hash_id"`
+	Vout            int    `json:"vout"`
+}
+
+// TxStatus is the confirmation status of a transaction.
+type TxStatus struct {
+	Hash string `json:"hash"`
+	// Height is the block the transaction was included in.
+	Height     int `json:"height"`
+	BestHeight int `json:"best_height"`
+}
+
+// Confirmations returns the number of blocks on top of the transaction, including its own.
+func (s TxStatus) Confirmations() int {
+	if s.Height <= 0 || s.BestHeight < s.Height {
+		return 0
+	}
+	return s.BestHeight - s.Height + 1
+}
+
+// Amount is an LBC amount, which chainquery returns as either a number or a decimal string.
+type Amount float64
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*a = Amount(v)
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q: %w", v, err)
+		}
+		*a = Amount(f)
+	case nil:
+		*a = 0
+	default:
+		return fmt.Errorf("invalid amount %v", v)
+	}
+	return nil
+}
+
+func validateClaimID(claimID string) error {
+	if !reClaimID.MatchString(claimID) {
+		return fmt.Errorf("invalid claim id %q", claimID)
+	}
+	return nil
+}
+
+// ClaimByID returns the claim, ErrNotFound if it doesn't exist.
+func (c *Client) ClaimByID(ctx context.Context, claimID string) (*Claim, error) {
+	if err := validateClaimID(claimID); err != nil {
+		return nil, err
+	}
+	claims, err := rows[Claim](ctx, c, fmt.Sprintf(queryClaimByID, quote(claimID)))
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving claim")
+	}
+	if len(claims) == 0 {
+		return nil, ErrNotFound
+	}
+	return &claims[0], nil
+}
+
+// ClaimsByName returns unspent claims for the name, the one with the largest effective amount first.
+func (c *Client) ClaimsByName(ctx context.Context, name string) ([]Claim, error) {
+	if name == "" {
+		return nil, errors.New("claim name is empty")
+	}
+	claims, err := rows[Claim](ctx, c, fmt.Sprintf(queryClaimsByName, quote(name), maxClaimsByName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving claims")
+	}
+	return claims, nil
+}
+
+// ChannelClaimCount returns the number of unspent claims published in the channel.
+func (c *Client) ChannelClaimCount(ctx context.Context, channelID string) (int, error) {
+	if err := validateClaimID(channelID); err != nil {
+		return 0, err
+	}
+	r, err := rows[struct {
+		Count int `json:"count"`
+	}](ctx, c, fmt.Sprintf(queryChannelClaimCount, quote(channelID)))
+	if err != nil {
+		return 0, errors.Wrap(err, "error retrieving channel claim count")
+	}
+	if len(r) != 1 {
+		return 0, errors.Errorf("error retrieving channel claim count, expected 1 items in response, got %v", len(r))
+	}
+	return r[0].Count, nil
+}
+
+// SupportTotal returns the sum of active supports for the claim in LBC.
+func (c *Client) SupportTotal(ctx context.Context, claimID string) (float64, error) {
+	if err := validateClaimID(claimID); err != nil {
+		return 0, err
+	}
+	r, err := rows[struct {
+		Total Amount `json:"total"`
+	}](ctx, c, fmt.Sprintf(querySupportTotal, quote(claimID)))
+	if err != nil {
+		return 0, errors.Wrap(err, "error retrieving support total")
+	}
+	if len(r) != 1 {
+		return 0, errors.Errorf("error retrieving support total, expected 1 items in response, got %v", len(r))
+	}
+	return float64(r[0].Total), nil
+}
+
+// TxStatus returns the confirmation status of the transaction, ErrNotFound if it's not in a block yet.
+func (c *Client) TxStatus(ctx context.Context, txHash string) (*TxStatus, error) {
+	if !reTxHash.MatchString(txHash) {
+		return nil, fmt.Errorf("invalid transaction hash %q", txHash)
+	}
+	r, err := rows[TxStatus](ctx, c, fmt.Sprintf(queryTxStatus, quote(txHash)))
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving transaction")
+	}
+	if len(r) == 0 {
+		return nil, ErrNotFound
+	}
+	return &r[0], nil
+}
@@ -0,0 +1,188 @@
+package chainquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FakeServer stands in for the chainquery SQL API, answering queries made by Client from in-memory data.
+type FakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	height   int
+	claims   []Claim
+	supports map[string]float64
+	txs      map[string]int
+	failures int
+	status   int
+	queries  []string
+}
+
+type fakeHandler struct {
+	re     *regexp.Regexp
+	handle func(s *FakeServer, args []string) any
+}
+
+var fakeHandlers = []fakeHandler{
+	{templateRegexp(queryHeight), func(s *FakeServer, _ []string) any {
+		return []HeightData{{s.height}}
+	}},
+	{templateRegexp(queryClaimByID), func(s *FakeServer, args []string) any {
+		return s.findClaims(func(c Claim) bool { return c.ClaimID == args[0] })
+	}},
+	{templateRegexp(queryClaimsByName), func(s *FakeServer, args []string) any {
+		claims := s.findClaims(func(c Claim) bool { return c.Name == args[0] && c.BidState != BidStateSpent })
+		sort.SliceStable(claims, func(i, j int) bool { return claims[i].EffectiveAmount > claims[j].EffectiveAmount })
+		if limit, _ := strconv.Atoi(args[1]); len(claims) > limit {
+			claims = claims[:limit]
+		}
+		return claims
+	}},
+	{templateRegexp(queryChannelClaimCount), func(s *FakeServer, args []string) any {
+		claims := s.findClaims(func(c Claim) bool { return c.PublisherID == args[0] && c.BidState != BidStateSpent })
+		return []map[string]int{{"count": len(claims)}}
+	}},
+	{templateRegexp(querySupportTotal), func(s *FakeServer, args []string) any {
+		// MySQL decimals are returned as strings.
+		return []map[string]string{{"total": fmt.Sprintf("%.8f", s.supports[args[0]])}}
+	}},
+	{templateRegexp(queryTxStatus), func(s *FakeServer, args []string) any {
+		height, ok := s.txs[args[0]]
+		if !ok {
+			return []TxStatus{}
+		}
+		return []TxStatus{{Hash: args[0], Height: height, BestHeight: s.height}}
+	}},
+}
+
+// NewFakeServer starts a fake chainquery API which is stopped when the test ends.
+func NewFakeServer(t testing.TB) *FakeServer {
+	s := &FakeServer{supports: map[string]float64{}, txs: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a client for the fake server. Retries are made without waiting unless options say otherwise.
+func (s *FakeServer) Client(options ...Option) *Client {
+	return NewClient(append([]Option{WithBaseURL(s.URL + "/api/sql"), WithRetries(retries, 0)}, options...)...)
+}
+
+func (s *FakeServer) SetHeight(height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.height = height
+}
+
+func (s *FakeServer) AddClaims(claims ...Claim) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims = append(s.claims, claims...)
+}
+
+// AddSupport adds an active support of amount LBC to the claim.
+func (s *FakeServer) AddSupport(claimID string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.supports[claimID] += amount
+}
+
+// AddTx adds a transaction included in the block at height.
+func (s *FakeServer) AddTx(hash string, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txs[hash] = height
+}
+
+// FailNext makes the next n requests fail with the HTTP status.
+func (s *FakeServer) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures, s.status = n, status
+}
+
+// Queries returns SQL queries received so far, including failed ones.
+func (s *FakeServer) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.queries...)
+}
+
+func (s *FakeServer) findClaims(match func(Claim) bool) []Claim {
+	claims := []Claim{}
+	for _, c := range s.claims {
+		if match(c) {
+			claims = append(claims, c)
+		}
+	}
+	return claims
+}
+
+func (s *FakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query().Get("query")
+	s.queries = append(s.queries, query)
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(s.status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	for _, h := range fakeHandlers {
+		m := h.re.FindStringSubmatch(query)
+		if m == nil {
+			continue
+		}
+		args := make([]string, len(m)-1)
+		for i, a := range m[1:] {
+			args[i] = unquote(a)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "error": nil, "data": h.handle(s, args)})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"success": false, "error": "unsupported query: " + query, "data": nil})
+}
+
+// templateRegexp matches queries made from the template, capturing its arguments.
+func templateRegexp(template string) *regexp.Regexp {
+	re := regexp.QuoteMeta(template)
+	re = strings.ReplaceAll(re, "%s", `'((?:[^'\\]|\\.)*)'`)
+	re = strings.ReplaceAll(re, "%d", `(\d+)`)
+	return regexp.MustCompile("^" + re + "$")
+}
+
+// unquote reverses escaping done by quote.
+func unquote(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '0':
+			b.WriteByte(0)
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'Z':
+			b.WriteByte(0x1a)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
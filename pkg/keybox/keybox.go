@@ -16,17 +16,23 @@ import (
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
 type Keyfob struct {
 	issuer     string
+	keyID      string
 	publicKey  crypto.PublicKey
 	privateKey crypto.PrivateKey
 }
 
+// Validator checks tokens against a single public key or, if keys is set, against a key set
+// where keys are selected by the kid token header.
 type Validator struct {
 	publicKey crypto.PublicKey
+	keys      keySource
+	now       func() time.Time
 }
 
 // NewKeyfob creates a new Keyfob from an existing private key.
@@ -125,8 +131,20 @@ func (kf Keyfob) GenerateToken(userID int32, expiry time.Time, fields ...string)
 	if err != nil {
 		return "", fmt.Errorf("unable to build token: %w", err)
 	}
+	return kf.Sign(t)
+}
 
-	bt, err := jwt.Sign(t, jwt.WithKey(jwa.ES256, kf.privateKey))
+// Sign signs a token with the private key of the Keyfob, putting its key ID in the kid header if it's set.
+func (kf Keyfob) Sign(t jwt.Token) (string, error) {
+	var options []jwt.Option
+	if kf.keyID != "" {
+		h := jws.NewHeaders()
+		if err := h.Set(jws.KeyIDKey, kf.keyID); err != nil {
+			return "", fmt.Errorf("unable to set key id: %w", err)
+		}
+		options = append(options, jws.WithProtectedHeaders(h))
+	}
+	bt, err := jwt.Sign(t, jwt.WithKey(jwa.ES256, kf.privateKey, options...))
 	if err != nil {
 		return "", fmt.Errorf("unable to sign token: %w", err)
 	}
@@ -134,6 +152,11 @@ func (kf Keyfob) GenerateToken(userID int32, expiry time.Time, fields ...string)
 	return string(bt), nil
 }
 
+// KeyID returns ID of the key put in the kid header of generated tokens, it's empty for keys outside of a Keyring.
+func (kf Keyfob) KeyID() string {
+	return kf.keyID
+}
+
 func (kf Keyfob) PublicKey() crypto.PublicKey {
 	return kf.publicKey
 }
@@ -167,6 +190,9 @@ func (kf Keyfob) PublicKeyHandler(w http.ResponseWriter, r *http.Request) {
 // ParseToken validates and parses a JWT token using the public key of the Validator structure,
 // and returns the private claims as a map[string]interface{}.
 func (v Validator) ParseToken(token string) (jwt.Token, error) {
+	if v.keys != nil {
+		return v.parseWithKeySet(token)
+	}
 	t, err := jwt.Parse([]byte(token), jwt.WithKey(jwa.ES256, v.publicKey))
 	if t == nil {
 		return nil, fmt.Errorf("unable to parse token: %w", err)
@@ -186,6 +212,9 @@ func privateKeyFromString(key string) (any, error) {
 	}
 
 	privateKeyBlock, _ := pem.Decode(privateKeyBytes)
+	if privateKeyBlock == nil {
+		return nil, errors.New("invalid PEM-encoded private key")
+	}
 	privateKey, err := x509.ParseECPrivateKey(privateKeyBlock.Bytes)
 	if err != nil {
 		return nil, err
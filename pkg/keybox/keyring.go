@@ -0,0 +1,449 @@
+package keybox
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DefaultGracePeriod is how long tokens signed with a retired key are still accepted,
+// it exceeds the lifetime of upload tokens.
+const DefaultGracePeriod = 72 * time.Hour
+
+// DefaultJWKSRefreshInterval is how often remote key sets are fetched again.
+const DefaultJWKSRefreshInterval = 15 * time.Minute
+
+// jwkActivatesAtKey is the key set member holding activates_at of the keyring entry as Unix time.
+// It isn't a registered JWK parameter, validators of this package reject tokens of scheduled keys until then.
+const jwkActivatesAtKey = "activates_at"
+
+var ErrNoActiveKey = errors.New("keyring has no active key")
+
+// KeyringEntry is a key in the keyring file. Keys sign tokens from ActivatesAt until RetiresAt,
+// after which their tokens are accepted for the grace period of the Keyring.
+type KeyringEntry struct {
+	ID string `json:"kid"`
+	// PrivateKey is a base64-encoded PEM EC private key, same as accepted by KeyfobFromString.
+	PrivateKey  string    `json:"private_key"`
+	ActivatesAt time.Time `json:"activates_at"`
+	RetiresAt   time.Time `json:"retires_at,omitzero"`
+}
+
+// KeyringFile is the keyring file layout.
+type KeyringFile struct {
+	Keys []KeyringEntry `json:"keys"`
+}
+
+type ringKey struct {
+	*Keyfob
+	activatesAt, retiresAt time.Time
+}
+
+// Keyring holds keys rotated on schedule. Tokens are signed with the current key and carry its ID
+// in the kid header, validators select keys by it.
+type Keyring struct {
+	keys  []ringKey
+	grace time.Duration
+	now   func() time.Time
+}
+
+type KeyringOption func(*Keyring)
+
+// WithGracePeriod sets how long tokens signed with a retired key are still accepted.
+func WithGracePeriod(grace time.Duration) KeyringOption {
+	return func(kr *Keyring) {
+		if grace > 0 {
+			kr.grace = grace
+		}
+	}
+}
+
+func withClock(now func() time.Time) KeyringOption {
+	return func(kr *Keyring) {
+		kr.now = now
+	}
+}
+
+// NewKeyring creates a Keyring from entries, which can be in any order.
+func NewKeyring(entries []KeyringEntry, options ...KeyringOption) (*Keyring, error) {
+	kr := &Keyring{grace: DefaultGracePeriod, now: time.Now}
+	for _, o := range options {
+		o(kr)
+	}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.ID == "" {
+			return nil, errors.New("keyring entry is missing key id")
+		}
+		if seen[e.ID] {
+			return nil, fmt.Errorf("duplicate key id %s", e.ID)
+		}
+		seen[e.ID] = true
+		kf, err := KeyfobFromString(e.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", e.ID, err)
+		}
+		kf.keyID = e.ID
+		kr.keys = append(kr.keys, ringKey{Keyfob: kf, activatesAt: e.ActivatesAt, retiresAt: e.RetiresAt})
+	}
+	slices.SortStableFunc(kr.keys, func(a, b ringKey) int { return a.activatesAt.Compare(b.activatesAt) })
+	return kr, nil
+}
+
+// NewKeyringFromKeyfob creates a Keyring with a single key that's always active, its ID is the key thumbprint.
+func NewKeyringFromKeyfob(kf *Keyfob, options ...KeyringOption) (*Keyring, error) {
+	id, err := Thumbprint(kf.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	kr, err := NewKeyring(nil, options...)
+	if err != nil {
+		return nil, err
+	}
+	rkf := *kf
+	rkf.keyID = id
+	kr.keys = []ringKey{{Keyfob: &rkf}}
+	return kr, nil
+}
+
+// LoadKeyring creates a Keyring from a keyring file.
+func LoadKeyring(path string, options ...KeyringOption) (*Keyring, error) {
+	f, err := ReadKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Keys) == 0 {
+		return nil, fmt.Errorf("keyring %s has no keys", path)
+	}
+	return NewKeyring(f.Keys, options...)
+}
+
+// Current returns the key tokens are signed with, which is the most recently activated key that isn't retired.
+func (kr *Keyring) Current() (*Keyfob, error) {
+	now := kr.now()
+	for i := len(kr.keys) - 1; i >= 0; i-- {
+		k := kr.keys[i]
+		if !k.activatesAt.After(now) && (k.retiresAt.IsZero() || k.retiresAt.After(now)) {
+			return k.Keyfob, nil
+		}
+	}
+	return nil, ErrNoActiveKey
+}
+
+// GenerateToken generates a JWT token for a given user signed with the current key.
+func (kr *Keyring) GenerateToken(userID int32, expiry time.Time, fields ...string) (string, error) {
+	kf, err := kr.Current()
+	if err != nil {
+		return "", err
+	}
+	return kf.GenerateToken(userID, expiry, fields...)
+}
+
+// Sign signs a token with the current key.
+func (kr *Keyring) Sign(t jwt.Token) (string, error) {
+	kf, err := kr.Current()
+	if err != nil {
+		return "", err
+	}
+	return kf.Sign(t)
+}
+
+// JWKS returns public keys of the ring which are either scheduled, active or retired within the grace period.
+// Scheduled keys are published in advance so validators know them by the time tokens are signed with them,
+// keys carry their activation time in the activates_at member.
+func (kr *Keyring) JWKS() (jwk.Set, error) {
+	now := kr.now()
+	set := jwk.NewSet()
+	for _, k := range kr.keys {
+		if !k.retiresAt.IsZero() && !k.retiresAt.Add(kr.grace).After(now) {
+			continue
+		}
+		key, err := publicJWK(k.Keyfob)
+		if err != nil {
+			return nil, err
+		}
+		if !k.activatesAt.IsZero() {
+			if err := key.Set(jwkActivatesAtKey, k.activatesAt.Unix()); err != nil {
+				return nil, fmt.Errorf("unable to set %s: %w", jwkActivatesAtKey, err)
+			}
+		}
+		if err := set.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// JWKSHandler returns a HTTP handler that delivers public keys of the ring as a JSON Web Key Set.
+func (kr *Keyring) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	set, err := kr.JWKS()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error marshaling key set: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(set)
+}
+
+// PublicKeyHandler delivers the current public key PEM-encoded, for validators which don't support key sets.
+func (kr *Keyring) PublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	kf, err := kr.Current()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	kf.PublicKeyHandler(w, r)
+}
+
+// Validator creates a Validator accepting tokens signed by keys published in JWKS once they're activated.
+func (kr *Keyring) Validator() *Validator {
+	return &Validator{keys: func(bool) (jwk.Set, error) { return kr.JWKS() }, now: kr.now}
+}
+
+// keySource returns keys for validation, refresh is requested when a token has an unknown key ID.
+type keySource func(refresh bool) (jwk.Set, error)
+
+// NewValidatorFromJWKS creates a Validator accepting tokens signed by keys in a static key set.
+func NewValidatorFromJWKS(set jwk.Set) *Validator {
+	return &Validator{keys: func(bool) (jwk.Set, error) { return set, nil }}
+}
+
+// ValidatorFromJWKSURL creates a Validator for a key set published at keysURL, which is fetched again every refreshInterval
+// and when a token has an unknown key ID, but not more often than once a minute.
+func ValidatorFromJWKSURL(ctx context.Context, keysURL string, refreshInterval time.Duration) (*Validator, error) {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+	c := jwk.NewCache(ctx)
+	if err := c.Register(keysURL, jwk.WithRefreshInterval(refreshInterval)); err != nil {
+		return nil, fmt.Errorf("unable to register key set: %w", err)
+	}
+	if _, err := c.Refresh(ctx, keysURL); err != nil {
+		return nil, fmt.Errorf("unable to retrieve key set: %w", err)
+	}
+
+	var mu sync.Mutex
+	var lastRefresh time.Time
+	return &Validator{keys: func(refresh bool) (jwk.Set, error) {
+		if refresh {
+			mu.Lock()
+			allowed := time.Since(lastRefresh) > time.Minute
+			if allowed {
+				lastRefresh = time.Now()
+			}
+			mu.Unlock()
+			if allowed {
+				return c.Refresh(ctx, keysURL)
+			}
+		}
+		return c.Get(ctx, keysURL)
+	}}, nil
+}
+
+// parseWithKeySet validates a token with the key its kid header points to, rejecting keys which aren't activated yet.
+// Tokens without the header were signed before key IDs were introduced and are checked against all active keys.
+func (v Validator) parseWithKeySet(token string) (jwt.Token, error) {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse token: %w", err)
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, errors.New("unable to parse token: expected a single signature")
+	}
+	kid := msg.Signatures()[0].ProtectedHeaders().KeyID()
+
+	set, err := v.keys(false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve keys: %w", err)
+	}
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+	var option jwt.ParseOption
+	if kid == "" {
+		if set, err = activeKeys(set, now); err != nil {
+			return nil, fmt.Errorf("unable to retrieve keys: %w", err)
+		}
+		option = jwt.WithKeySet(set, jws.WithRequireKid(false))
+	} else {
+		key, ok := set.LookupKeyID(kid)
+		if !ok {
+			if set, err = v.keys(true); err != nil {
+				return nil, fmt.Errorf("unable to retrieve keys: %w", err)
+			}
+			if key, ok = set.LookupKeyID(kid); !ok {
+				return nil, fmt.Errorf("unable to parse token: unknown key id %s", kid)
+			}
+		}
+		if keyActivatesAt(key).After(now) {
+			return nil, fmt.Errorf("unable to parse token: key id %s is not active yet", kid)
+		}
+		option = jwt.WithKey(jwa.ES256, key)
+	}
+
+	t, err := jwt.Parse([]byte(token), option)
+	if t == nil {
+		return nil, fmt.Errorf("unable to parse token: %w", err)
+	}
+	return t, err
+}
+
+// activeKeys returns keys of the set which are activated by now.
+func activeKeys(set jwk.Set, now time.Time) (jwk.Set, error) {
+	active := jwk.NewSet()
+	for i := range set.Len() {
+		key, _ := set.Key(i)
+		if keyActivatesAt(key).After(now) {
+			continue
+		}
+		if err := active.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return active, nil
+}
+
+// keyActivatesAt returns the activation time published with the key, zero if the key has none.
+func keyActivatesAt(key jwk.Key) time.Time {
+	v, ok := key.Get(jwkActivatesAtKey)
+	if !ok {
+		return time.Time{}
+	}
+	switch at := v.(type) {
+	case int64:
+		return time.Unix(at, 0)
+	case float64:
+		return time.Unix(int64(at), 0)
+	case json.Number:
+		n, _ := at.Int64()
+		return time.Unix(n, 0)
+	}
+	return time.Time{}
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the public key, which is used as its key ID.
+func Thumbprint(publicKey crypto.PublicKey) (string, error) {
+	key, err := jwk.FromRaw(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to create jwk: %w", err)
+	}
+	tp, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("unable to calculate thumbprint: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(tp), nil
+}
+
+func publicJWK(kf *Keyfob) (jwk.Key, error) {
+	key, err := jwk.FromRaw(kf.PublicKey())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jwk: %w", err)
+	}
+	for k, v := range map[string]any{
+		jwk.KeyIDKey:     kf.KeyID(),
+		jwk.AlgorithmKey: jwa.ES256,
+		jwk.KeyUsageKey:  jwk.ForSignature,
+	} {
+		if err := key.Set(k, v); err != nil {
+			return nil, fmt.Errorf("unable to set %s: %w", k, err)
+		}
+	}
+	return key, nil
+}
+
+// GenerateKeyringEntry generates a new key activating at activatesAt.
+func GenerateKeyringEntry(activatesAt time.Time) (KeyringEntry, error) {
+	pvk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return KeyringEntry{}, fmt.Errorf("unable to generate private key: %w", err)
+	}
+	encoded, err := EncodePrivateKey(pvk)
+	if err != nil {
+		return KeyringEntry{}, err
+	}
+	id, err := Thumbprint(pvk.Public())
+	if err != nil {
+		return KeyringEntry{}, err
+	}
+	return KeyringEntry{ID: id, PrivateKey: encoded, ActivatesAt: activatesAt.UTC()}, nil
+}
+
+// EncodePrivateKey encodes the key in the base64 PEM form accepted by KeyfobFromString.
+func EncodePrivateKey(privateKey *ecdsa.PrivateKey) (string, error) {
+	b, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal private key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})), nil
+}
+
+// AddKey schedules rotation to the entry. Keys activated before it without a retirement time retire when it activates.
+func (f *KeyringFile) AddKey(e KeyringEntry) {
+	for i, k := range f.Keys {
+		if k.RetiresAt.IsZero() && k.ActivatesAt.Before(e.ActivatesAt) {
+			f.Keys[i].RetiresAt = e.ActivatesAt
+		}
+	}
+	f.Keys = append(f.Keys, e)
+}
+
+// Prune removes keys retired for longer than grace before now.
+func (f *KeyringFile) Prune(now time.Time, grace time.Duration) []KeyringEntry {
+	var pruned []KeyringEntry
+	f.Keys = slices.DeleteFunc(f.Keys, func(k KeyringEntry) bool {
+		expired := !k.RetiresAt.IsZero() && !k.RetiresAt.Add(grace).After(now)
+		if expired {
+			pruned = append(pruned, k)
+		}
+		return expired
+	})
+	return pruned
+}
+
+// ReadKeyringFile reads a keyring file, a file which doesn't exist is an empty keyring.
+func ReadKeyringFile(path string) (*KeyringFile, error) {
+	f := &KeyringFile{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read keyring: %w", err)
+	}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("unable to parse keyring: %w", err)
+	}
+	return f, nil
+}
+
+// WriteKeyringFile writes the keyring file readable only by its owner.
+func WriteKeyringFile(path string, f *KeyringFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("unable to write keyring: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,314 @@
+package keybox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func tokenKeyID(t *testing.T, token string) string {
+	msg, err := jws.Parse([]byte(token))
+	require.NoError(t, err)
+	return msg.Signatures()[0].ProtectedHeaders().KeyID()
+}
+
+func setKeyIDs(set jwk.Set) []string {
+	var ids []string
+	for i := range set.Len() {
+		k, _ := set.Key(i)
+		ids = append(ids, k.KeyID())
+	}
+	return ids
+}
+
+// rotatedRing returns a keyring file with the first key retired at start+24h by the second one.
+func rotatedRing(t *testing.T, start time.Time) *KeyringFile {
+	f := &KeyringFile{}
+	for _, at := range []time.Time{start, start.Add(24 * time.Hour)} {
+		e, err := GenerateKeyringEntry(at)
+		require.NoError(t, err)
+		f.AddKey(e)
+	}
+	return f
+}
+
+func TestKeyringRotation(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	c := &clock{start.Add(time.Hour)}
+	f := rotatedRing(t, start)
+	require.Equal(t, f.Keys[1].ActivatesAt, f.Keys[0].RetiresAt)
+	assert.True(t, f.Keys[1].RetiresAt.IsZero())
+	oldID, newID := f.Keys[0].ID, f.Keys[1].ID
+
+	kr, err := NewKeyring([]KeyringEntry{f.Keys[1], f.Keys[0]}, WithGracePeriod(48*time.Hour), withClock(c.now))
+	require.NoError(t, err)
+	v := kr.Validator()
+
+	cur, err := kr.Current()
+	require.NoError(t, err)
+	assert.Equal(t, oldID, cur.KeyID())
+	oldToken, err := kr.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, oldID, tokenKeyID(t, oldToken))
+
+	// The next key is published before it signs anything.
+	set, err := kr.JWKS()
+	require.NoError(t, err)
+	assert.Equal(t, []string{oldID, newID}, setKeyIDs(set))
+
+	c.t = start.Add(25 * time.Hour)
+	newToken, err := kr.GenerateToken(2, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, newID, tokenKeyID(t, newToken))
+
+	for _, token := range []string{oldToken, newToken} {
+		_, err := v.ParseToken(token)
+		require.NoError(t, err)
+	}
+
+	// Tokens of the retired key are rejected after the grace period.
+	c.t = start.Add(24*time.Hour + 48*time.Hour)
+	set, err = kr.JWKS()
+	require.NoError(t, err)
+	assert.Equal(t, []string{newID}, setKeyIDs(set))
+	_, err = v.ParseToken(oldToken)
+	require.ErrorContains(t, err, "unknown key id "+oldID)
+	_, err = v.ParseToken(newToken)
+	require.NoError(t, err)
+
+	c.t = start.Add(-time.Hour)
+	_, err = kr.GenerateToken(1, start)
+	require.ErrorIs(t, err, ErrNoActiveKey)
+}
+
+func TestKeyringInvalidEntries(t *testing.T) {
+	e, err := GenerateKeyringEntry(time.Now())
+	require.NoError(t, err)
+
+	_, err = NewKeyring([]KeyringEntry{e, e})
+	require.ErrorContains(t, err, "duplicate key id")
+	_, err = NewKeyring([]KeyringEntry{{PrivateKey: e.PrivateKey}})
+	require.ErrorContains(t, err, "missing key id")
+	_, err = NewKeyring([]KeyringEntry{{ID: "a", PrivateKey: "bm90IGEga2V5"}})
+	require.ErrorContains(t, err, "invalid key a")
+}
+
+func TestValidatorSelectsKeyByID(t *testing.T) {
+	f := rotatedRing(t, time.Now().Add(-time.Hour))
+	kr, err := NewKeyring(f.Keys)
+	require.NoError(t, err)
+	other, err := GenerateKeyfob()
+	require.NoError(t, err)
+
+	// A token claiming a known key ID but signed by another key.
+	forged := *other
+	forged.keyID = f.Keys[0].ID
+	token, err := forged.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = kr.Validator().ParseToken(token)
+	require.Error(t, err)
+
+	// Tokens issued before key IDs are checked against all keys.
+	legacy, err := KeyfobFromString(f.Keys[0].PrivateKey)
+	require.NoError(t, err)
+	token, err = legacy.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, tokenKeyID(t, token))
+	pt, err := kr.Validator().ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "1", pt.Subject())
+
+	token, err = other.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = kr.Validator().ParseToken(token)
+	require.Error(t, err)
+
+	expired, err := kr.GenerateToken(1, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = kr.Validator().ParseToken(expired)
+	require.Error(t, err)
+}
+
+func TestValidatorRejectsScheduledKey(t *testing.T) {
+	start := time.Now().Truncate(time.Second).Add(-time.Hour)
+	c := &clock{time.Now()}
+	f := rotatedRing(t, start)
+	kr, err := NewKeyring(f.Keys, withClock(c.now))
+	require.NoError(t, err)
+
+	scheduled, err := KeyfobFromString(f.Keys[1].PrivateKey)
+	require.NoError(t, err)
+	legacyToken, err := scheduled.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	scheduled.keyID = f.Keys[1].ID
+	token, err := scheduled.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = kr.Validator().ParseToken(token)
+	require.ErrorContains(t, err, "key id "+f.Keys[1].ID+" is not active yet")
+	_, err = kr.Validator().ParseToken(legacyToken)
+	require.Error(t, err)
+
+	rr := httptest.NewRecorder()
+	kr.JWKSHandler(rr, httptest.NewRequest(http.MethodGet, "/jwks", nil))
+	set, err := jwk.Parse(rr.Body.Bytes())
+	require.NoError(t, err)
+	_, err = NewValidatorFromJWKS(set).ParseToken(token)
+	require.ErrorContains(t, err, "key id "+f.Keys[1].ID+" is not active yet")
+
+	c.t = start.Add(24 * time.Hour)
+	_, err = kr.Validator().ParseToken(token)
+	require.NoError(t, err)
+	_, err = kr.Validator().ParseToken(legacyToken)
+	require.NoError(t, err)
+}
+
+func TestNewKeyringFromKeyfob(t *testing.T) {
+	kf, err := KeyfobFromString(testPrivKey)
+	require.NoError(t, err)
+	kr, err := NewKeyringFromKeyfob(kf)
+	require.NoError(t, err)
+
+	cur, err := kr.Current()
+	require.NoError(t, err)
+	id, err := Thumbprint(kf.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, id, cur.KeyID())
+	assert.Empty(t, kf.KeyID())
+
+	token, err := kr.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = kf.Validator().ParseToken(token)
+	require.NoError(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	f := rotatedRing(t, time.Now().Add(-time.Hour))
+	kr, err := NewKeyring(f.Keys)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	kr.JWKSHandler(rr, httptest.NewRequest(http.MethodGet, "/jwks", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/jwk-set+json", rr.Header().Get("Content-Type"))
+
+	var body struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Keys, 2)
+	for i, k := range body.Keys {
+		assert.Equal(t, f.Keys[i].ID, k["kid"])
+		assert.Equal(t, "EC", k["kty"])
+		assert.Equal(t, "P-256", k["crv"])
+		assert.Equal(t, "ES256", k["alg"])
+		assert.Equal(t, "sig", k["use"])
+		assert.NotContains(t, k, "d")
+		assert.EqualValues(t, f.Keys[i].ActivatesAt.Unix(), k["activates_at"])
+	}
+
+	set, err := jwk.Parse(rr.Body.Bytes())
+	require.NoError(t, err)
+	token, err := kr.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = NewValidatorFromJWKS(set).ParseToken(token)
+	require.NoError(t, err)
+
+	rr = httptest.NewRecorder()
+	kr.PublicKeyHandler(rr, httptest.NewRequest(http.MethodGet, "/pubkey", nil))
+	assert.Equal(t, "application/x-pem-file", rr.Header().Get("Content-Type"))
+}
+
+func TestValidatorFromJWKSURL(t *testing.T) {
+	// The second key has been current for an hour, the first one is served until the ring is updated.
+	start := time.Now().Add(-25 * time.Hour)
+	f := rotatedRing(t, start)
+	c := &clock{start.Add(time.Minute)}
+	kr, err := NewKeyring(f.Keys[:1], withClock(c.now))
+	require.NoError(t, err)
+
+	var ring atomic.Pointer[Keyring]
+	ring.Store(kr)
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		ring.Load().JWKSHandler(w, r)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v, err := ValidatorFromJWKSURL(ctx, ts.URL, time.Hour)
+	require.NoError(t, err)
+
+	token, err := kr.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = v.ParseToken(token)
+	require.NoError(t, err)
+	fetched := fetches.Load()
+
+	// A key added to the ring later is fetched when a token signed with it comes in.
+	rotated, err := NewKeyring(f.Keys)
+	require.NoError(t, err)
+	ring.Store(rotated)
+	token, err = rotated.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = v.ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, fetched+1, fetches.Load())
+
+	// Unknown key IDs don't trigger more fetches right away.
+	other, err := GenerateKeyfob()
+	require.NoError(t, err)
+	other.keyID = "unknown"
+	token, err = other.GenerateToken(1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = v.ParseToken(token)
+	require.ErrorContains(t, err, "unknown key id unknown")
+	assert.Equal(t, fetched+1, fetches.Load())
+
+	_, err = ValidatorFromJWKSURL(ctx, ts.URL+"/missing\x7f", time.Hour)
+	require.Error(t, err)
+}
+
+func TestKeyringFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	_, err := LoadKeyring(path)
+	require.ErrorContains(t, err, "has no keys")
+
+	f, err := ReadKeyringFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, f.Keys)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	f = rotatedRing(t, start)
+	require.NoError(t, WriteKeyringFile(path, f))
+
+	read, err := ReadKeyringFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, f, read)
+	kr, err := LoadKeyring(path)
+	require.NoError(t, err)
+	cur, err := kr.Current()
+	require.NoError(t, err)
+	assert.Equal(t, f.Keys[1].ID, cur.KeyID())
+
+	pruned := read.Prune(start.Add(24*time.Hour+time.Hour), time.Hour)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, f.Keys[0].ID, pruned[0].ID)
+	assert.Equal(t, f.Keys[1:], read.Keys)
+}
@@ -0,0 +1,218 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const defaultQueue = "default"
+
+// ErrDeadTaskNotFound is returned when there is no archived task with the requested ID.
+var ErrDeadTaskNotFound = errors.New("dead task not found")
+
+// DeadLetterHandler is called once a request has failed for the last time and is about to be archived.
+// Context passed to it is detached from the request deadline.
+type DeadLetterHandler func(ctx context.Context, task *asynq.Task, err error)
+
+// RequeueHandler is called before a dead request is put back to the queue, so that state left behind
+// by its dead letter handler can be reverted. Returning an error cancels the requeue.
+type RequeueHandler func(ctx context.Context, task *asynq.Task) error
+
+// DeadTask is a request that has run out of retries and got archived.
+type DeadTask struct {
+	ID       string          `json:"id"`
+	Queue    string          `json:"queue"`
+	Type     string          `json:"type"`
+	Payload  json.RawMessage `json:"payload"`
+	Error    string          `json:"error"`
+	Retried  int             `json:"retried"`
+	MaxRetry int             `json:"max_retry"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// AddDeadLetterHandler adds a handler for requests of the specified type that have exhausted their retries
+// or were rejected with asynq.SkipRetry. Must be called before ServeUntilShutdown.
+func (q *Queue) AddDeadLetterHandler(requestType string, handler DeadLetterHandler) {
+	q.logger.Info("adding dead letter handler", "type", requestType)
+	q.deadLetterHandlers[requestType] = handler
+}
+
+// AddRequeueHandler adds a handler for archived requests of the specified type that are about to be
+// requeued or edited.
+func (q *Queue) AddRequeueHandler(requestType string, handler RequeueHandler) {
+	q.logger.Info("adding requeue handler", "type", requestType)
+	q.requeueHandlers[requestType] = handler
+}
+
+// handleError is invoked by asynq on every failed request attempt.
+func (q *Queue) handleError(ctx context.Context, task *asynq.Task, err error) {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	if errors.Is(err, errUserBusy) || (retried < maxRetry && !errors.Is(err, asynq.SkipRetry)) {
+		return
+	}
+	deadLetters.WithLabelValues(task.Type()).Inc()
+	q.logger.Warn("request failed permanently", "type", task.Type(), "retried", retried, "err", err)
+	if h, ok := q.deadLetterHandlers[task.Type()]; ok {
+		h(context.WithoutCancel(ctx), task, err)
+	}
+}
+
+// ListDeadTasks returns a page of archived requests from all queues, optionally filtered by request type.
+// Pages are numbered starting from 1.
+func (q *Queue) ListDeadTasks(requestType string, page, pageSize int) ([]*DeadTask, error) {
+	if q.asynqInspector == nil {
+		return nil, errors.New("requests connection options must be provided")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 30
+	}
+	// Filtering happens after retrieval, so keep fetching until the requested page is full.
+	var (
+		tasks []*DeadTask
+		skip  = (page - 1) * pageSize
+	)
+	for _, queue := range q.queueNames() {
+		for current := 1; len(tasks) < pageSize; current++ {
+			infos, err := q.asynqInspector.ListArchivedTasks(queue, asynq.Page(current), asynq.PageSize(pageSize))
+			if errors.Is(err, asynq.ErrQueueNotFound) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to list archived tasks: %w", err)
+			}
+			for _, info := range infos {
+				if requestType != "" && info.Type != requestType {
+					continue
+				}
+				if skip > 0 {
+					skip--
+					continue
+				}
+				if len(tasks) < pageSize {
+					tasks = append(tasks, newDeadTask(info))
+				}
+			}
+			if len(infos) < pageSize {
+				break
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// GetDeadTask returns the archived request with the given ID.
+func (q *Queue) GetDeadTask(id string) (*DeadTask, error) {
+	info, err := q.getArchivedTask(id)
+	if err != nil {
+		return nil, err
+	}
+	return newDeadTask(info), nil
+}
+
+// RequeueDeadTask moves the archived request back to the queue, retry counter is kept intact.
+func (q *Queue) RequeueDeadTask(id string) error {
+	info, err := q.getArchivedTask(id)
+	if err != nil {
+		return err
+	}
+	if err := q.handleRequeue(asynq.NewTask(info.Type, info.Payload)); err != nil {
+		return fmt.Errorf("failed to requeue task %s: %w", id, err)
+	}
+	if err := q.asynqInspector.RunTask(info.Queue, id); err != nil {
+		return fmt.Errorf("failed to requeue task %s: %w", id, err)
+	}
+	q.logger.Info("dead task requeued", "id", id)
+	return nil
+}
+
+// EditDeadTask replaces the archived request with a new one that has the supplied payload
+// and the same type and options. ID of the new request is returned.
+func (q *Queue) EditDeadTask(id string, payload json.RawMessage) (string, error) {
+	info, err := q.getArchivedTask(id)
+	if err != nil {
+		return "", err
+	}
+	if !json.Valid(payload) {
+		return "", errors.New("payload is not valid json")
+	}
+	if err := q.handleRequeue(asynq.NewTask(info.Type, payload)); err != nil {
+		return "", fmt.Errorf("failed to requeue task %s: %w", id, err)
+	}
+	opts := []asynq.Option{asynq.Queue(info.Queue), asynq.MaxRetry(info.MaxRetry)}
+	if info.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(info.Timeout))
+	}
+	if info.Retention > 0 {
+		opts = append(opts, asynq.Retention(info.Retention))
+	}
+	newInfo, err := q.requestsClient.Enqueue(asynq.NewTask(info.Type, payload), opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue edited task: %w", err)
+	}
+	if err := q.asynqInspector.DeleteTask(info.Queue, id); err != nil {
+		q.logger.Warn("failed to delete edited dead task", "id", id, "err", err)
+	}
+	q.logger.Info("dead task edited and requeued", "id", id, "new_id", newInfo.ID)
+	return newInfo.ID, nil
+}
+
+// DiscardDeadTask permanently deletes the archived request.
+func (q *Queue) DiscardDeadTask(id string) error {
+	info, err := q.getArchivedTask(id)
+	if err != nil {
+		return err
+	}
+	if err := q.asynqInspector.DeleteTask(info.Queue, id); err != nil {
+		return fmt.Errorf("failed to delete task %s: %w", id, err)
+	}
+	q.logger.Info("dead task discarded", "id", id)
+	return nil
+}
+
+func (q *Queue) handleRequeue(task *asynq.Task) error {
+	h, ok := q.requeueHandlers[task.Type()]
+	if !ok {
+		return nil
+	}
+	return h(context.Background(), task)
+}
+
+func (q *Queue) getArchivedTask(id string) (*asynq.TaskInfo, error) {
+	if q.asynqInspector == nil {
+		return nil, errors.New("requests connection options must be provided")
+	}
+	for _, queue := range q.queueNames() {
+		info, err := q.asynqInspector.GetTaskInfo(queue, id)
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if info.State != asynq.TaskStateArchived {
+			return nil, ErrDeadTaskNotFound
+		}
+		return info, nil
+	}
+	return nil, ErrDeadTaskNotFound
+}
+
+func newDeadTask(info *asynq.TaskInfo) *DeadTask {
+	return &DeadTask{
+		ID:       info.ID,
+		Queue:    info.Queue,
+		Type:     info.Type,
+		Payload:  json.RawMessage(info.Payload),
+		Error:    info.LastErr,
+		Retried:  info.Retried,
+		MaxRetry: info.MaxRetry,
+		FailedAt: info.LastFailedAt,
+	}
+}
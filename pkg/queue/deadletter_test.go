@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := New(
+		WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		WithLogger(logging.NoopKVLogger{}),
+	)
+	require.NoError(t, err)
+	defer q.Shutdown()
+
+	processed := make(chan map[string]any, 1)
+	dead := make(chan error, 1)
+	q.AddHandler(queueRequest, func(ctx context.Context, task *asynq.Task) error {
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(task.Payload(), &payload))
+		if payload["fail"] == true {
+			return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+		}
+		processed <- payload
+		return nil
+	})
+	q.AddDeadLetterHandler(queueRequest, func(ctx context.Context, task *asynq.Task, err error) {
+		dead <- err
+	})
+	requeued := make(chan string, 2)
+	q.AddRequeueHandler(queueRequest, func(ctx context.Context, task *asynq.Task) error {
+		if string(task.Payload()) == `{"fail": "forever"}` {
+			return errors.New("cannot be requeued")
+		}
+		requeued <- string(task.Payload())
+		return nil
+	})
+	go q.ServeUntilShutdown()
+
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"fail": true}))
+	select {
+	case err := <-dead:
+		assert.True(t, errors.Is(err, asynq.SkipRetry))
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for dead letter handler")
+	}
+
+	var tasks []*DeadTask
+	require.Eventually(t, func() bool {
+		tasks, err = q.ListDeadTasks(queueRequest, 1, 10)
+		return err == nil && len(tasks) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, queueRequest, tasks[0].Type)
+	assert.JSONEq(t, `{"fail": true}`, string(tasks[0].Payload))
+	assert.Contains(t, tasks[0].Error, "bad payload")
+
+	other, err := q.ListDeadTasks("test:other", 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	_, err = q.EditDeadTask(tasks[0].ID, json.RawMessage(`{"fail"`))
+	require.Error(t, err)
+	_, err = q.EditDeadTask(tasks[0].ID, json.RawMessage(`{"fail": "forever"}`))
+	require.ErrorContains(t, err, "cannot be requeued")
+	newID, err := q.EditDeadTask(tasks[0].ID, json.RawMessage(`{"fail": false}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"fail": false}`, <-requeued)
+	assert.NotEqual(t, tasks[0].ID, newID)
+	select {
+	case payload := <-processed:
+		assert.Equal(t, map[string]any{"fail": false}, payload)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for edited task to be processed")
+	}
+
+	_, err = q.GetDeadTask(tasks[0].ID)
+	assert.ErrorIs(t, err, ErrDeadTaskNotFound)
+	assert.ErrorIs(t, q.RequeueDeadTask(tasks[0].ID), ErrDeadTaskNotFound)
+
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"fail": true}))
+	<-dead
+	require.Eventually(t, func() bool {
+		tasks, err = q.ListDeadTasks("", 1, 10)
+		return err == nil && len(tasks) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.NoError(t, q.RequeueDeadTask(tasks[0].ID))
+	assert.JSONEq(t, `{"fail": true}`, <-requeued)
+	select {
+	case <-dead:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for requeued task to fail")
+	}
+	require.Eventually(t, func() bool {
+		_, err := q.GetDeadTask(tasks[0].ID)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	require.NoError(t, q.DiscardDeadTask(tasks[0].ID))
+	tasks, err = q.ListDeadTasks("", 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const fairKeyPrefix = "queue:fair:"
+
+// errUserBusy is returned instead of processing a request when its user already has too many requests in progress.
+var errUserBusy = errors.New("user has too many requests in progress")
+
+// fairScheduler keeps track of requests in progress per user in a Redis sorted set,
+// so the limit is shared by all servers processing the same queues.
+// Entries of requests that got lost, for example due to a server crash, expire after the request timeout.
+type fairScheduler struct {
+	client    redis.UniversalClient
+	maxActive int
+	userKey   func(*asynq.Task) string
+}
+
+func newFairScheduler(client redis.UniversalClient, maxActive int, userKey func(*asynq.Task) string) *fairScheduler {
+	return &fairScheduler{client: client, maxActive: maxActive, userKey: userKey}
+}
+
+func (s *fairScheduler) middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		user := s.userKey(task)
+		id, _ := asynq.GetTaskID(ctx)
+		if user == "" || id == "" {
+			return next.ProcessTask(ctx, task)
+		}
+		// Requests on their last attempt would get archived if postponed.
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			return next.ProcessTask(ctx, task)
+		}
+
+		queue, _ := asynq.GetQueueName(ctx)
+		ok, err := s.acquire(ctx, user, id, deadline(ctx))
+		if err != nil {
+			// Fairness is best-effort, Redis hiccups should not stop processing.
+			return next.ProcessTask(ctx, task)
+		}
+		if !ok {
+			fairDeferrals.WithLabelValues(queue).Inc()
+			return fmt.Errorf("%w: %s", errUserBusy, user)
+		}
+		defer s.release(context.WithoutCancel(ctx), user, id)
+		return next.ProcessTask(ctx, task)
+	})
+}
+
+// acquire registers the request as active for the user, returning false if the user is over the limit.
+func (s *fairScheduler) acquire(ctx context.Context, user, id string, expiresAt time.Time) (bool, error) {
+	key := fairKeyPrefix + user
+	now := time.Now()
+	var card *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+		p.ZAdd(ctx, key, redis.Z{Score: float64(expiresAt.UnixMilli()), Member: id})
+		card = p.ZCard(ctx, key)
+		p.ExpireGT(ctx, key, time.Until(expiresAt))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if card.Val() > int64(s.maxActive) {
+		s.release(ctx, user, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *fairScheduler) release(ctx context.Context, user, id string) {
+	s.client.ZRem(ctx, fairKeyPrefix+user, id)
+}
+
+func deadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(time.Hour)
+}
+
+// userIDFromPayload attributes requests to users by the "user_id" field of their JSON payload.
+func userIDFromPayload(task *asynq.Task) string {
+	var payload struct {
+		UserID json.Number `json:"user_id"`
+	}
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return ""
+	}
+	return payload.UserID.String()
+}
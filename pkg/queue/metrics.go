@@ -10,14 +10,56 @@ var (
 	queueTasks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: ns,
 		Name:      "queue_tasks",
+	}, []string{"queue", "status"})
+	queueSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "queue_size",
+		Help:      "Requests in the queue, excluding completed ones",
+	}, []string{"queue"})
+	queueLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "queue_latency_seconds",
+		Help:      "Time the oldest pending request has been waiting for",
+	}, []string{"queue"})
+	queueProcessed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "queue_processed",
+		Help:      "Requests processed since the queue was created, including failed ones",
+	}, []string{"queue"})
+	queueFailed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "queue_failed",
+		Help:      "Request attempts failed since the queue was created",
+	}, []string{"queue"})
+	fairDeferrals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "fair_deferrals_total",
+		Help:      "Requests put back to the queue because their user had too many requests in progress",
+	}, []string{"queue"})
+	periodicRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "periodic_requests_total",
+		Help:      "Periodic requests by the outcome of sending them: sent, skipped or failed",
 	}, []string{"status"})
+	schedulerLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "scheduler_leader",
+		Help:      "Set to 1 when this server is the one sending periodic requests",
+	})
+	deadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "dead_letters_total",
+		Help:      "Requests that failed permanently and got archived",
+	}, []string{"type"})
 )
 
-func registerMetrics(registry prometheus.Registerer) {
+// RegisterMetrics registers queue metrics with the registry, the default registerer is used if it's nil.
+func RegisterMetrics(registry prometheus.Registerer) {
 	if registry == nil {
 		registry = prometheus.DefaultRegisterer
 	}
 	registry.MustRegister(
-		queueTasks,
+		queueTasks, queueSize, queueLatency, queueProcessed, queueFailed, fairDeferrals,
+		periodicRequests, schedulerLeader, deadLetters,
 	)
 }
@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	scheduleOff           = "off"
+	schedulerKeyPrefix    = "queue:scheduler:"
+	schedulerLease        = 15 * time.Second
+	schedulerLeaseRenewal = 5 * time.Second
+)
+
+// holdLease takes the lease if it's free or extends it if it's already held by the caller.
+var holdLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+var releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type periodicTask struct {
+	requestType string
+	schedule    string
+	payload     []byte
+	options     *MessageOptions
+}
+
+// AddPeriodicTask sends a request of the given type on schedule, which is a cron expression (in UTC)
+// or "@every <duration>". Schedules can be overridden with WithSchedules.
+// All servers sharing the requests connection and scheduler name elect one of them to send periodic requests,
+// so each request is sent once per schedule tick across the cluster. A request is not sent
+// while the previous one of the same type is still waiting or being processed.
+// Must be called before ServeUntilShutdown.
+func (q *Queue) AddPeriodicTask(requestType, schedule string, payload any, optionFuncs ...func(*MessageOptions)) error {
+	if s, ok := q.options.schedules[requestType]; ok {
+		schedule = s
+	}
+	if schedule == "" || schedule == scheduleOff {
+		q.logger.Info("periodic request disabled", "type", requestType)
+		return nil
+	}
+	options := &MessageOptions{
+		retry:     0,
+		timeout:   1 * time.Hour,
+		retention: 24 * time.Hour,
+	}
+	for _, optionFunc := range optionFuncs {
+		optionFunc(options)
+	}
+	pb, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	q.logger.Info("adding periodic request", "type", requestType, "schedule", schedule)
+	q.periodicTasks = append(q.periodicTasks, &periodicTask{
+		requestType: requestType,
+		schedule:    schedule,
+		payload:     pb,
+		options:     options,
+	})
+	return nil
+}
+
+// newScheduler creates a scheduler with all periodic requests registered.
+// Schedulers which are not going to be started can share the queue Redis client,
+// started ones need their own as asynq closes it on shutdown.
+func (q *Queue) newScheduler(shared bool) (*asynq.Scheduler, error) {
+	opts := &asynq.SchedulerOpts{
+		Logger: zapadapter.New(nil),
+		PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+			switch {
+			case errors.Is(err, asynq.ErrDuplicateTask):
+				periodicRequests.WithLabelValues("skipped").Inc()
+				q.logger.Info("periodic request skipped as the previous one is not done yet")
+			case err != nil:
+				periodicRequests.WithLabelValues("failed").Inc()
+				q.logger.Warn("failed to send periodic request", "err", err)
+			default:
+				periodicRequests.WithLabelValues("sent").Inc()
+				q.logger.Debug("periodic request sent", "type", info.Type, "id", info.ID)
+			}
+		},
+	}
+	var scheduler *asynq.Scheduler
+	if shared {
+		scheduler = asynq.NewSchedulerFromRedisClient(q.redis, opts)
+	} else {
+		scheduler = asynq.NewScheduler(q.options.requestsConnOpts, opts)
+	}
+	for _, t := range q.periodicTasks {
+		queue := t.options.queue
+		if queue == "" {
+			queue = q.route(t.requestType)
+		}
+		_, err := scheduler.Register(
+			t.schedule, asynq.NewTask(t.requestType, t.payload),
+			asynq.Queue(queue), asynq.MaxRetry(t.options.retry),
+			asynq.Timeout(t.options.timeout), asynq.Retention(t.options.retention),
+			asynq.Unique(t.options.timeout),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q for %s: %w", t.schedule, t.requestType, err)
+		}
+	}
+	return scheduler, nil
+}
+
+// runScheduler runs the scheduler while this server holds the scheduler lease, until the queue is shut down.
+func (q *Queue) runScheduler() {
+	var (
+		scheduler *asynq.Scheduler
+		key       = schedulerKeyPrefix + q.options.schedulerName
+		id        = schedulerID()
+		ctx       = context.Background()
+		t         = time.NewTicker(schedulerLeaseRenewal)
+	)
+	defer t.Stop()
+	stop := func() {
+		if scheduler != nil {
+			scheduler.Shutdown()
+			scheduler = nil
+			schedulerLeader.Set(0)
+		}
+	}
+	for {
+		held, err := holdLease.Run(ctx, q.redis, []string{key}, id, schedulerLease.Milliseconds()).Int()
+		switch {
+		case err != nil:
+			// Another server takes over if the lease can't be renewed in time.
+			q.logger.Warn("failed to renew scheduler lease", "err", err)
+		case held == 1 && scheduler == nil:
+			scheduler, err = q.newScheduler(false)
+			if err == nil {
+				err = scheduler.Start()
+			}
+			if err != nil {
+				q.logger.Error("failed to start scheduler", "err", err)
+				scheduler = nil
+				break
+			}
+			schedulerLeader.Set(1)
+			q.logger.Info("started sending periodic requests", "scheduler", q.options.schedulerName)
+		case held == 0 && scheduler != nil:
+			stop()
+			q.logger.Info("scheduler lease lost, stopped sending periodic requests", "scheduler", q.options.schedulerName)
+		}
+
+		select {
+		case <-t.C:
+		case <-q.handlerStopChan:
+			stop()
+			releaseLease.Run(ctx, q.redis, []string{key}, id)
+			return
+		}
+	}
+}
+
+func schedulerID() string {
+	host, _ := os.Hostname()
+	return strings.Join([]string{host, fmt.Sprint(os.Getpid()), fmt.Sprint(time.Now().UnixNano())}, ":")
+}
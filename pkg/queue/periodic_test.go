@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodicTasks(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	var runs atomic.Int32
+
+	servers := []*Queue{}
+	for range 3 {
+		q, err := New(
+			WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+			WithLogger(logging.NoopKVLogger{}),
+			WithSchedulerName("test"),
+			WithSchedules(map[string]string{"test:disabled": scheduleOff}),
+		)
+		require.NoError(t, err)
+		q.AddHandler(queueRequest, func(context.Context, *asynq.Task) error {
+			runs.Add(1)
+			return nil
+		})
+		require.NoError(t, q.AddPeriodicTask(queueRequest, "@every 1s", map[string]any{"periodic": true}))
+		require.NoError(t, q.AddPeriodicTask("test:disabled", "@every 1s", nil))
+		go q.ServeUntilShutdown()
+		servers = append(servers, q)
+	}
+	for _, q := range servers {
+		assert.Len(t, q.periodicTasks, 1)
+	}
+
+	time.Sleep(3500 * time.Millisecond)
+	n := runs.Load()
+	assert.GreaterOrEqual(t, n, int32(2))
+	assert.LessOrEqual(t, n, int32(4))
+
+	leader, err := redisServer.Get(schedulerKeyPrefix + "test")
+	require.NoError(t, err)
+	assert.NotEmpty(t, leader)
+	for _, q := range servers {
+		q.Shutdown()
+	}
+	assert.False(t, redisServer.Exists(schedulerKeyPrefix+"test"))
+}
+
+func TestPeriodicTaskInvalidSchedule(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := New(
+		WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		WithLogger(logging.NoopKVLogger{}),
+	)
+	require.NoError(t, err)
+	q.AddHandler(queueRequest, func(context.Context, *asynq.Task) error { return nil })
+	require.NoError(t, q.AddPeriodicTask(queueRequest, "every day", nil))
+	assert.ErrorContains(t, q.ServeUntilShutdown(), "invalid schedule")
+	q.Shutdown()
+}
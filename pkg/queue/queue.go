@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/pkg/logging"
@@ -17,9 +19,20 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrDuplicateRequest is returned when a request with the same ID is already queued, running or retained.
+var ErrDuplicateRequest = errors.New("request with the same id already exists")
+
 type Options struct {
 	concurrency       int
 	delayFunc         asynq.RetryDelayFunc
+	queues            map[string]int
+	strictPriority    bool
+	routes            map[string]string
+	fairMaxActive     int
+	fairRetryDelay    time.Duration
+	userKeyFunc       func(*asynq.Task) string
+	schedules         map[string]string
+	schedulerName     string
 	logger            logging.KVLogger
 	requestsConnOpts  asynq.RedisConnOpt
 	requestsConnURL   string
@@ -27,7 +40,19 @@ type Options struct {
 	responsesConnURL  string
 }
 
+// Config holds queue scheduling settings which are usually read from a config file.
+type Config struct {
+	// Queues maps queue names to their weights.
+	Queues           map[string]int
+	StrictPriority   bool
+	MaxActivePerUser int
+	// Schedules override schedules of periodic requests by request type, "off" disables a request.
+	Schedules map[string]string
+}
+
 type MessageOptions struct {
+	queue              string
+	id                 string
 	retry              int
 	timeout, retention time.Duration
 }
@@ -41,6 +66,13 @@ type Queue struct {
 	handlerStopChan chan struct{}
 	handlers        map[string]asynq.HandlerFunc
 	logger          logging.KVLogger
+	redis           redis.UniversalClient
+	fair            *fairScheduler
+	periodicTasks   []*periodicTask
+	schedulerDone   chan struct{}
+
+	deadLetterHandlers map[string]DeadLetterHandler
+	requeueHandlers    map[string]RequeueHandler
 }
 
 func WithRequestsConnOpts(opts asynq.RedisConnOpt) func(options *Options) {
@@ -95,6 +127,91 @@ func WithLogger(logger logging.KVLogger) func(options *Options) {
 	}
 }
 
+// WithQueues sets named queues to process requests from along with their weights.
+// Queues with higher weight get proportionally more processing slots, see WithStrictPriority for an alternative.
+// Only the "default" queue is processed if none are specified.
+func WithQueues(queues map[string]int) func(options *Options) {
+	return func(options *Options) {
+		options.queues = queues
+	}
+}
+
+// WithStrictPriority makes the server always drain queues with higher weight first,
+// so requests from lower weight queues are only processed when higher ones are empty.
+func WithStrictPriority(strict bool) func(options *Options) {
+	return func(options *Options) {
+		options.strictPriority = strict
+	}
+}
+
+// WithRoutes sets queues that requests and responses of a given type are sent to.
+// Types without a route go to the "default" queue.
+func WithRoutes(routes map[string]string) func(options *Options) {
+	return func(options *Options) {
+		if options.routes == nil {
+			options.routes = map[string]string{}
+		}
+		for t, q := range routes {
+			options.routes[t] = q
+		}
+	}
+}
+
+// WithFairScheduling limits the number of requests processed concurrently for the same user across all servers
+// sharing the requests connection. Requests over the limit are put back to the queue and retried after a short delay
+// without counting towards their retry limit, letting requests of other users through.
+// Requests are attributed to users by the "user_id" payload field unless WithUserKeyFunc is supplied.
+func WithFairScheduling(maxActivePerUser int) func(options *Options) {
+	return func(options *Options) {
+		options.fairMaxActive = maxActivePerUser
+	}
+}
+
+// WithUserKeyFunc sets a function which returns an identifier of the user a request belongs to, for fair scheduling.
+// Requests for which it returns an empty string are not limited.
+func WithUserKeyFunc(f func(*asynq.Task) string) func(options *Options) {
+	return func(options *Options) {
+		options.userKeyFunc = f
+	}
+}
+
+// WithSchedules overrides schedules of periodic requests added with AddPeriodicTask, keys are request types.
+// Setting a schedule to "off" disables the periodic request.
+func WithSchedules(schedules map[string]string) func(options *Options) {
+	return func(options *Options) {
+		if options.schedules == nil {
+			options.schedules = map[string]string{}
+		}
+		for t, s := range schedules {
+			options.schedules[t] = s
+		}
+	}
+}
+
+// WithSchedulerName sets the name periodic request schedulers coordinate under,
+// so that only one server out of those with the same name sends periodic requests.
+// Services sharing a requests connection but scheduling different requests should use different names.
+func WithSchedulerName(name string) func(options *Options) {
+	return func(options *Options) {
+		options.schedulerName = name
+	}
+}
+
+// WithRequestQueue sends the message to the specified queue, overriding the route configured for its type.
+func WithRequestQueue(queue string) func(options *MessageOptions) {
+	return func(options *MessageOptions) {
+		options.queue = queue
+	}
+}
+
+// WithRequestID sets ID of the message, sending is rejected with ErrDuplicateRequest
+// while a message with the same ID is in the queue, including running and retained ones.
+func WithRequestID(id string) func(options *MessageOptions) {
+	return func(options *MessageOptions) {
+		options.id = id
+	}
+}
+
 func WithRequestRetry(retry int) func(options *MessageOptions) {
 	return func(options *MessageOptions) {
 		options.retry = retry
@@ -113,6 +230,21 @@ func WithRequestRetention(retention time.Duration) func(options *MessageOptions)
 	}
 }
 
+// Options converts config to queue options.
+func (c Config) Options() []func(*Options) {
+	options := []func(*Options){WithStrictPriority(c.StrictPriority)}
+	if len(c.Queues) > 0 {
+		options = append(options, WithQueues(c.Queues))
+	}
+	if c.MaxActivePerUser > 0 {
+		options = append(options, WithFairScheduling(c.MaxActivePerUser))
+	}
+	if len(c.Schedules) > 0 {
+		options = append(options, WithSchedules(c.Schedules))
+	}
+	return options
+}
+
 // New creates a new Queue instance with Redis request and response connections.
 // If supplied WithRequestsConnOpts, the handler will be able to receive requests.
 // If supplied WithResponsesConnOpts, the handler will be able to send responses.
@@ -125,6 +257,9 @@ func New(optionFuncs ...func(*Options)) (*Queue, error) {
 		delayFunc: func(n int, err error, t *asynq.Task) time.Duration {
 			return 10 * time.Second
 		},
+		fairRetryDelay: 5 * time.Second,
+		userKeyFunc:    userIDFromPayload,
+		schedulerName:  "default",
 	}
 	for _, optionFunc := range optionFuncs {
 		optionFunc(options)
@@ -137,6 +272,9 @@ func New(optionFuncs ...func(*Options)) (*Queue, error) {
 		handlerStopChan: make(chan struct{}),
 		handlers:        map[string]asynq.HandlerFunc{},
 		logger:          options.logger,
+
+		deadLetterHandlers: map[string]DeadLetterHandler{},
+		requeueHandlers:    map[string]RequeueHandler{},
 	}
 
 	if options.responsesConnOpts != nil {
@@ -148,13 +286,18 @@ func New(optionFuncs ...func(*Options)) (*Queue, error) {
 		conn = true
 	}
 	if options.requestsConnOpts != nil {
-		err = options.requestsConnOpts.MakeRedisClient().(redis.UniversalClient).Ping(context.Background()).Err()
+		queue.redis = options.requestsConnOpts.MakeRedisClient().(redis.UniversalClient)
+		err = queue.redis.Ping(context.Background()).Err()
 		if err != nil {
+			queue.redis.Close()
 			return nil, fmt.Errorf("redis requests connection failed: %w", err)
 		}
 		queue.asynqInspector = asynq.NewInspector(options.requestsConnOpts)
 		queue.requestsClient = asynq.NewClient(options.requestsConnOpts)
 		conn = true
+		if options.fairMaxActive > 0 {
+			queue.fair = newFairScheduler(queue.redis, options.fairMaxActive, options.userKeyFunc)
+		}
 	}
 	if !conn {
 		return nil, errors.New("either requests or responses connection options must be provided")
@@ -187,39 +330,54 @@ func (q *Queue) ServeUntilShutdown() error {
 	if len(q.handlers) == 0 {
 		return errors.New("no request handlers registered")
 	}
+	queues := q.options.queues
+	if len(queues) == 0 {
+		queues = map[string]int{defaultQueue: 1}
+	}
 	q.asynqServer = asynq.NewServer(
 		q.options.requestsConnOpts,
 		asynq.Config{
 			Concurrency:    q.options.concurrency,
-			RetryDelayFunc: q.options.delayFunc,
+			Queues:         queues,
+			StrictPriority: q.options.strictPriority,
+			RetryDelayFunc: q.retryDelay,
+			IsFailure:      isFailure,
+			ErrorHandler:   asynq.ErrorHandlerFunc(q.handleError),
 			Logger:         zapadapter.New(nil),
 		},
 	)
 	mux := asynq.NewServeMux()
+	if q.fair != nil {
+		mux.Use(q.fair.middleware)
+	}
 	for k, v := range q.handlers {
 		q.logger.Info("initializing request handler", "type", k)
 		mux.HandleFunc(k, v)
 	}
-	q.logger.Info("started queue handlers")
+	q.logger.Info("started queue handlers", "queues", queues, "strict_priority", q.options.strictPriority)
 
 	go func() {
 		t := time.NewTicker(1 * time.Second)
 		for {
 			select {
 			case <-t.C:
-				q, err := q.asynqInspector.GetQueueInfo("default")
-				if err != nil {
-					continue
-				}
-				queueTasks.WithLabelValues("active").Set(float64(q.Active))
-				queueTasks.WithLabelValues("completed").Set(float64(q.Completed))
-				queueTasks.WithLabelValues("pending").Set(float64(q.Pending))
-				queueTasks.WithLabelValues("failed").Set(float64(q.Failed))
+				q.updateMetrics()
 			case <-q.handlerStopChan:
 				return
 			}
 		}
 	}()
+	if len(q.periodicTasks) > 0 {
+		// Validate schedules upfront, the actual scheduler is only started by the elected server.
+		if _, err := q.newScheduler(true); err != nil {
+			return err
+		}
+		q.schedulerDone = make(chan struct{})
+		go func() {
+			defer close(q.schedulerDone)
+			q.runScheduler()
+		}()
+	}
 	err := q.asynqServer.Run(mux)
 	if err != nil {
 		q.logger.Error("error starting queue", "error", err)
@@ -230,6 +388,9 @@ func (q *Queue) ServeUntilShutdown() error {
 func (q *Queue) Shutdown() {
 	q.logger.Info("stopping queue")
 	close(q.handlerStopChan)
+	if q.schedulerDone != nil {
+		<-q.schedulerDone
+	}
 	if q.responsesClient != nil {
 		q.responsesClient.Close()
 	}
@@ -239,6 +400,9 @@ func (q *Queue) Shutdown() {
 	if q.asynqInspector != nil {
 		q.asynqInspector.Close()
 	}
+	if q.redis != nil {
+		q.redis.Close()
+	}
 	if q.asynqServer != nil {
 		q.asynqServer.Shutdown()
 	}
@@ -273,14 +437,83 @@ func (q *Queue) sendMessage(client *asynq.Client, messageType string, payload an
 	if err != nil {
 		return err
 	}
+	queue := options.queue
+	if queue == "" {
+		queue = q.route(messageType)
+	}
 	t := asynq.NewTask(messageType, pb, asynq.MaxRetry(options.retry))
 	q.logger.Debug(
-		"sending message", "type", messageType,
+		"sending message", "type", messageType, "queue", queue,
 		"payload", string(pb), "retries", options.retry,
 		"timeout", options.timeout, "retention", options.retention)
-	_, err = client.Enqueue(t, asynq.Timeout(options.timeout), asynq.Retention(options.retention))
-	if err != nil {
+	enqueueOptions := []asynq.Option{asynq.Queue(queue), asynq.Timeout(options.timeout), asynq.Retention(options.retention)}
+	if options.id != "" {
+		enqueueOptions = append(enqueueOptions, asynq.TaskID(options.id))
+	}
+	_, err = client.Enqueue(t, enqueueOptions...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("%w: %s", ErrDuplicateRequest, options.id)
+	} else if err != nil {
 		return fmt.Errorf("failed to enqueue %s request: %w", messageType, err)
 	}
 	return nil
 }
+
+// route returns the queue messages of the given type should be sent to.
+func (q *Queue) route(messageType string) string {
+	if queue, ok := q.options.routes[messageType]; ok && queue != "" {
+		return queue
+	}
+	return defaultQueue
+}
+
+func (q *Queue) retryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if errors.Is(err, errUserBusy) {
+		return q.options.fairRetryDelay
+	}
+	return q.options.delayFunc(n, err, t)
+}
+
+// isFailure excludes requests postponed by the fair scheduler from failures, so they don't use up retries.
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, errUserBusy)
+}
+
+// queueNames returns all queues known to the requests connection, including the configured ones
+// which may not exist yet.
+func (q *Queue) queueNames() []string {
+	names, err := q.asynqInspector.Queues()
+	if err != nil {
+		q.logger.Warn("failed to list queues", "err", err)
+	}
+	for name := range q.options.queues {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if !slices.Contains(names, defaultQueue) {
+		names = append(names, defaultQueue)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (q *Queue) updateMetrics() {
+	for _, name := range q.queueNames() {
+		info, err := q.asynqInspector.GetQueueInfo(name)
+		if err != nil {
+			continue
+		}
+		queueTasks.WithLabelValues(name, "active").Set(float64(info.Active))
+		queueTasks.WithLabelValues(name, "completed").Set(float64(info.Completed))
+		queueTasks.WithLabelValues(name, "pending").Set(float64(info.Pending))
+		queueTasks.WithLabelValues(name, "scheduled").Set(float64(info.Scheduled))
+		queueTasks.WithLabelValues(name, "retry").Set(float64(info.Retry))
+		queueTasks.WithLabelValues(name, "failed").Set(float64(info.Failed))
+		queueTasks.WithLabelValues(name, "archived").Set(float64(info.Archived))
+		queueSize.WithLabelValues(name).Set(float64(info.Size))
+		queueLatency.WithLabelValues(name).Set(info.Latency.Seconds())
+		queueProcessed.WithLabelValues(name).Set(float64(info.ProcessedTotal))
+		queueFailed.WithLabelValues(name).Set(float64(info.FailedTotal))
+	}
+}
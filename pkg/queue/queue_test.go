@@ -3,13 +3,19 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/OdyseeTeam/odysee-api/internal/testdeps"
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
 	"github.com/OdyseeTeam/odysee-api/pkg/logging/zapadapter"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -97,3 +103,118 @@ func TestQueueIntegration(t *testing.T) {
 		t.Fatal("timeout waiting for response to be sent")
 	}
 }
+
+func TestQueueRouting(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := New(
+		WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		WithLogger(logging.NoopKVLogger{}),
+		WithQueues(map[string]int{"queries": 6, "uploads": 3, defaultQueue: 1}),
+		WithRoutes(map[string]string{queueRequest: "queries"}),
+	)
+	require.NoError(t, err)
+	defer q.Shutdown()
+
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 1}))
+	require.NoError(t, q.SendRequest("test:other", map[string]any{"user_id": 1}))
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 1}, WithRequestQueue("uploads")))
+
+	for queue, size := range map[string]int{"queries": 1, "uploads": 1, defaultQueue: 1} {
+		info, err := q.asynqInspector.GetQueueInfo(queue)
+		require.NoError(t, err)
+		assert.Equal(t, size, info.Pending, queue)
+	}
+	assert.Equal(t, []string{defaultQueue, "queries", "uploads"}, q.queueNames())
+
+	q.updateMetrics()
+	assert.Equal(t, 1.0, gaugeValue(t, queueSize.WithLabelValues("queries")))
+	assert.Equal(t, 1.0, gaugeValue(t, queueTasks.WithLabelValues("uploads", "pending")))
+}
+
+func TestSendRequestWithID(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := New(
+		WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		WithLogger(logging.NoopKVLogger{}),
+	)
+	require.NoError(t, err)
+	defer q.Shutdown()
+
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 1}, WithRequestID("upload:1")))
+	err = q.SendRequest(queueRequest, map[string]any{"user_id": 1}, WithRequestID("upload:1"))
+	require.ErrorIs(t, err, ErrDuplicateRequest)
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 1}, WithRequestID("upload:2")))
+
+	info, err := q.asynqInspector.GetQueueInfo(defaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Pending)
+}
+
+func TestFairScheduling(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	q, err := New(
+		WithRequestsConnOpts(asynq.RedisClientOpt{Addr: redisServer.Addr()}),
+		WithLogger(logging.NoopKVLogger{}),
+		WithConcurrency(3),
+		WithFairScheduling(1),
+	)
+	require.NoError(t, err)
+	defer q.Shutdown()
+	q.options.fairRetryDelay = 100 * time.Millisecond
+
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	q.AddHandler(queueRequest, func(ctx context.Context, task *asynq.Task) error {
+		retried, _ := asynq.GetRetryCount(ctx)
+		assert.Equal(t, 0, retried)
+		started <- userIDFromPayload(task)
+		<-release
+		return nil
+	})
+	go q.ServeUntilShutdown()
+
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 10}))
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 10}))
+	require.NoError(t, q.SendRequest(queueRequest, map[string]any{"user_id": 20}))
+
+	received := []string{}
+	for range 2 {
+		select {
+		case u := <-started:
+			received = append(received, u)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for requests to start")
+		}
+	}
+	assert.ElementsMatch(t, []string{"10", "20"}, received)
+	require.Eventually(t, func() bool {
+		m := &dto.Metric{}
+		require.NoError(t, fairDeferrals.WithLabelValues(defaultQueue).Write(m))
+		return m.GetCounter().GetValue() > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	close(release)
+	select {
+	case u := <-started:
+		assert.Equal(t, "10", u)
+	case <-time.After(15 * time.Second):
+		t.Fatal("timeout waiting for deferred request")
+	}
+	dead, err := q.ListDeadTasks("", 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestUserIDFromPayload(t *testing.T) {
+	assert.Equal(t, "10", userIDFromPayload(asynq.NewTask(queueRequest, []byte(`{"user_id": 10}`))))
+	assert.Equal(t, "", userIDFromPayload(asynq.NewTask(queueRequest, []byte(`{"query_id": "abc"}`))))
+	assert.Equal(t, "", userIDFromPayload(asynq.NewTask(queueRequest, []byte(`not json`))))
+	assert.False(t, isFailure(fmt.Errorf("%w: 10", errUserBusy)))
+	assert.True(t, isFailure(errors.New("boom")))
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, g.Write(m))
+	return m.GetGauge().GetValue()
+}
@@ -0,0 +1,81 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DirSweeper removes files that haven't been modified since the cutoff from a working directory tree,
+// along with directories left empty. It suits directories where each file is owned by a single task,
+// like local copies of uploads and their blobs.
+type DirSweeper struct {
+	name string
+	path string
+}
+
+// NewDirSweeper creates a sweeper for the directory at path, name is used to tell sweepers apart.
+func NewDirSweeper(name, path string) *DirSweeper {
+	return &DirSweeper{name: name, path: path}
+}
+
+func (s *DirSweeper) Name() string {
+	return s.name
+}
+
+func (s *DirSweeper) Find(ctx context.Context, cutoff time.Time) ([]Item, error) {
+	var items []Item
+	err := filepath.WalkDir(s.path, func(p string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if p == s.path {
+				return err
+			}
+			return nil
+		}
+		if p == s.path {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil || !fi.ModTime().Before(cutoff) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.path, p)
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			// Directories are only removed once empty, it may take a few passes for nested ones.
+			if entries, err := os.ReadDir(p); err == nil && len(entries) == 0 {
+				items = append(items, Item{ID: rel, LastActive: fi.ModTime()})
+			}
+			return nil
+		}
+		if fi.Mode().IsRegular() {
+			items = append(items, Item{ID: rel, Size: fi.Size(), LastActive: fi.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Reap removes a file or an empty directory, item ID is relative to the sweeper path.
+func (s *DirSweeper) Reap(_ context.Context, item Item) error {
+	p := filepath.Join(s.path, item.ID)
+	if !strings.HasPrefix(p, filepath.Clean(s.path)+string(filepath.Separator)) {
+		return errors.New("item is outside of the directory")
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const infoExt = ".info"
+
+// FileStoreSweeper removes abandoned uploads from a tus filestore directory, as used by publish handlers:
+//   - <id>.info and <id> binary files of unfinished uploads,
+//   - binary files without a matching .info,
+//   - <user_id>/<id> directories left after failed publish calls.
+//
+// Files of an upload are considered active as long as any of them keeps getting modified.
+type FileStoreSweeper struct {
+	name string
+	path string
+}
+
+// NewFileStoreSweeper creates a sweeper for tus filestore at path, name is used to tell sweepers apart.
+func NewFileStoreSweeper(name, path string) *FileStoreSweeper {
+	return &FileStoreSweeper{name: name, path: path}
+}
+
+func (s *FileStoreSweeper) Name() string {
+	return s.name
+}
+
+func (s *FileStoreSweeper) Find(ctx context.Context, cutoff time.Time) ([]Item, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := map[string]*Item{}
+	var items []Item
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if e.IsDir() {
+			if _, err := strconv.Atoi(e.Name()); err != nil {
+				continue
+			}
+			items = append(items, s.findUserDirs(filepath.Join(s.path, e.Name()), cutoff)...)
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), infoExt)
+		u, ok := uploads[id]
+		if !ok {
+			u = &Item{ID: id}
+			uploads[id] = u
+		}
+		u.Size += fi.Size()
+		if fi.ModTime().After(u.LastActive) {
+			u.LastActive = fi.ModTime()
+		}
+	}
+	for _, u := range uploads {
+		if u.LastActive.Before(cutoff) {
+			items = append(items, *u)
+		}
+	}
+	return items, nil
+}
+
+// Reap removes upload files or a leftover directory, item ID is relative to the filestore path.
+func (s *FileStoreSweeper) Reap(_ context.Context, item Item) error {
+	p := filepath.Join(s.path, item.ID)
+	if !strings.HasPrefix(p, filepath.Clean(s.path)+string(filepath.Separator)) {
+		return errors.New("item is outside of the filestore")
+	}
+	if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+		return os.RemoveAll(p)
+	}
+	var errs []error
+	for _, f := range []string{p + infoExt, p} {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *FileStoreSweeper) findUserDirs(userDir string, cutoff time.Time) []Item {
+	var items []Item
+	entries, err := os.ReadDir(userDir)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(userDir, e.Name())
+		item := Item{ID: filepath.Join(filepath.Base(userDir), e.Name())}
+		// Directory modification times are bumped by file moves, so only files count unless there are none.
+		if fi, err := e.Info(); err == nil {
+			item.LastActive = fi.ModTime()
+		}
+		var files int
+		_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if files == 0 || fi.ModTime().After(item.LastActive) {
+				item.LastActive = fi.ModTime()
+			}
+			files++
+			item.Size += fi.Size()
+			return nil
+		})
+		if item.LastActive.Before(cutoff) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,46 @@
+package reaper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const ns = "reaper"
+
+var (
+	itemsFound = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "items_found",
+		Help:      "Expired items found, including ones only reported in dry-run mode",
+	}, []string{"sweeper"})
+	itemsReaped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "items_reaped",
+	}, []string{"sweeper"})
+	bytesReclaimed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "bytes_reclaimed",
+	}, []string{"sweeper"})
+	sweepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Name:      "sweep_seconds",
+		Buckets:   []float64{1, 5, 15, 60, 300, 900, 3600},
+	}, []string{"sweeper"})
+
+	sweepErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: "errors",
+		Name:      "sweep",
+	}, []string{"sweeper"})
+	reapErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: "errors",
+		Name:      "reap",
+	}, []string{"sweeper"})
+)
+
+func RegisterMetrics(registry prometheus.Registerer) {
+	if registry == nil {
+		registry = prometheus.DefaultRegisterer
+	}
+	registry.MustRegister(itemsFound, itemsReaped, bytesReclaimed, sweepDuration, sweepErrors, reapErrors)
+}
@@ -0,0 +1,223 @@
+// Package reaper periodically cleans up resources left behind by abandoned uploads.
+//
+// Each kind of resource is handled by a Sweeper, which finds expired items and removes them.
+// Reaper runs sweepers on schedule, limits the rate of removals and reports metrics.
+package reaper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OdyseeTeam/odysee-api/pkg/logging"
+
+	"golang.org/x/time/rate"
+)
+
+// Item is a single expired resource found by a sweeper.
+type Item struct {
+	// ID identifies the item for the sweeper that found it, e.g. upload ID or file path.
+	ID string
+	// Size is the number of bytes reclaimed after removing the item, if known.
+	Size int64
+	// LastActive is the last time the item was touched by its owner.
+	LastActive time.Time
+}
+
+// Sweeper finds and removes expired items of one kind.
+type Sweeper interface {
+	// Name is used in logs and metrics labels.
+	Name() string
+	// Find returns items that have been inactive since before the cutoff.
+	Find(ctx context.Context, cutoff time.Time) ([]Item, error)
+	// Reap removes a single item.
+	Reap(ctx context.Context, item Item) error
+}
+
+// Result summarizes a single sweep.
+type Result struct {
+	Sweeper string
+	Found   int
+	Reaped  int
+	Failed  int
+	Bytes   int64
+}
+
+// Config holds reaper settings as read from a config file, zero values are left at defaults.
+type Config struct {
+	Interval  time.Duration
+	MaxAge    time.Duration
+	MaxItems  int
+	RateLimit float64
+	DryRun    bool
+}
+
+type Reaper struct {
+	sweepers []Sweeper
+	interval time.Duration
+	maxAge   time.Duration
+	maxItems int
+	dryRun   bool
+	limiter  *rate.Limiter
+	logger   logging.KVLogger
+}
+
+type Option func(*Reaper)
+
+// WithSweepers adds sweepers to be run on each pass.
+func WithSweepers(sweepers ...Sweeper) Option {
+	return func(r *Reaper) {
+		r.sweepers = append(r.sweepers, sweepers...)
+	}
+}
+
+// WithInterval sets how often sweepers are run by Start.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Reaper) {
+		r.interval = interval
+	}
+}
+
+// WithMaxAge sets how long an item has to be inactive to be considered abandoned.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(r *Reaper) {
+		r.maxAge = maxAge
+	}
+}
+
+// WithMaxItems limits the number of items removed by each sweeper per pass, the rest is left for the next one.
+func WithMaxItems(n int) Option {
+	return func(r *Reaper) {
+		r.maxItems = n
+	}
+}
+
+// WithRateLimit limits the number of removals per second across all sweepers.
+func WithRateLimit(perSecond float64) Option {
+	return func(r *Reaper) {
+		r.limiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+	}
+}
+
+// WithDryRun makes reaper only log and count expired items without removing them.
+func WithDryRun(dryRun bool) Option {
+	return func(r *Reaper) {
+		r.dryRun = dryRun
+	}
+}
+
+func WithLogger(logger logging.KVLogger) Option {
+	return func(r *Reaper) {
+		r.logger = logger
+	}
+}
+
+// Options converts config values into reaper options.
+func (c Config) Options() []Option {
+	options := []Option{WithDryRun(c.DryRun)}
+	if c.Interval > 0 {
+		options = append(options, WithInterval(c.Interval))
+	}
+	if c.MaxAge > 0 {
+		options = append(options, WithMaxAge(c.MaxAge))
+	}
+	if c.MaxItems > 0 {
+		options = append(options, WithMaxItems(c.MaxItems))
+	}
+	if c.RateLimit > 0 {
+		options = append(options, WithRateLimit(c.RateLimit))
+	}
+	return options
+}
+
+func New(options ...Option) *Reaper {
+	r := &Reaper{
+		interval: time.Hour,
+		maxAge:   48 * time.Hour,
+		maxItems: 1000,
+		limiter:  rate.NewLimiter(rate.Limit(10), 1),
+		logger:   logging.NoopKVLogger{},
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Start runs all sweepers every interval until the context is canceled.
+func (r *Reaper) Start(ctx context.Context) {
+	r.logger.Info("reaper started", "interval", r.interval, "max_age", r.maxAge, "dry_run", r.dryRun)
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Warn("reaper pass failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			r.logger.Info("reaper stopped")
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce runs all sweepers a single time.
+// A failing sweeper does not prevent others from running, all errors are returned joined.
+func (r *Reaper) RunOnce(ctx context.Context) ([]Result, error) {
+	var errs []error
+	results := make([]Result, 0, len(r.sweepers))
+	for _, s := range r.sweepers {
+		res, err := r.sweep(ctx, s)
+		results = append(results, res)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (r *Reaper) sweep(ctx context.Context, s Sweeper) (Result, error) {
+	res := Result{Sweeper: s.Name()}
+	log := r.logger.With("sweeper", s.Name(), "dry_run", r.dryRun)
+	start := time.Now()
+	defer func() {
+		sweepDuration.WithLabelValues(s.Name()).Observe(time.Since(start).Seconds())
+	}()
+
+	items, err := s.Find(ctx, time.Now().Add(-r.maxAge))
+	if err != nil {
+		sweepErrors.WithLabelValues(s.Name()).Inc()
+		return res, fmt.Errorf("failed to find expired items: %w", err)
+	}
+	res.Found = len(items)
+	itemsFound.WithLabelValues(s.Name()).Add(float64(len(items)))
+	if len(items) > r.maxItems {
+		log.Info("too many expired items, the rest will be reaped on the next pass", "found", len(items), "max_items", r.maxItems)
+		items = items[:r.maxItems]
+	}
+
+	for _, item := range items {
+		if r.dryRun {
+			log.Info("would reap item", "id", item.ID, "size", item.Size, "last_active", item.LastActive)
+			continue
+		}
+		if err := r.limiter.Wait(ctx); err != nil {
+			return res, err
+		}
+		if err := s.Reap(ctx, item); err != nil {
+			res.Failed++
+			reapErrors.WithLabelValues(s.Name()).Inc()
+			log.Warn("failed to reap item", "id", item.ID, "err", err)
+			continue
+		}
+		res.Reaped++
+		res.Bytes += item.Size
+		itemsReaped.WithLabelValues(s.Name()).Inc()
+		bytesReclaimed.WithLabelValues(s.Name()).Add(float64(item.Size))
+		log.Debug("item reaped", "id", item.ID, "size", item.Size, "last_active", item.LastActive)
+	}
+	log.Info("sweep done", "found", res.Found, "reaped", res.Reaped, "failed", res.Failed, "bytes", res.Bytes)
+	return res, nil
+}
@@ -0,0 +1,179 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSweeper struct {
+	items   []Item
+	reaped  []string
+	findErr error
+	reapErr map[string]error
+}
+
+func (s *fakeSweeper) Name() string { return "fake" }
+
+func (s *fakeSweeper) Find(_ context.Context, cutoff time.Time) ([]Item, error) {
+	var items []Item
+	for _, i := range s.items {
+		if i.LastActive.Before(cutoff) {
+			items = append(items, i)
+		}
+	}
+	return items, s.findErr
+}
+
+func (s *fakeSweeper) Reap(_ context.Context, item Item) error {
+	if err := s.reapErr[item.ID]; err != nil {
+		return err
+	}
+	s.reaped = append(s.reaped, item.ID)
+	return nil
+}
+
+func TestReaperRunOnce(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	newSweeper := func() *fakeSweeper {
+		return &fakeSweeper{items: []Item{
+			{ID: "a", Size: 10, LastActive: old},
+			{ID: "b", Size: 20, LastActive: old},
+			{ID: "c", Size: 30, LastActive: old},
+			{ID: "fresh", Size: 40, LastActive: time.Now()},
+		}}
+	}
+
+	t.Run("reaps expired items", func(t *testing.T) {
+		s := newSweeper()
+		s.reapErr = map[string]error{"b": errors.New("no")}
+		res, err := New(WithSweepers(s), WithRateLimit(1000)).RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []Result{{Sweeper: "fake", Found: 3, Reaped: 2, Failed: 1, Bytes: 40}}, res)
+		assert.Equal(t, []string{"a", "c"}, s.reaped)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		s := newSweeper()
+		res, err := New(WithSweepers(s), WithDryRun(true)).RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, res[0].Found)
+		assert.Empty(t, s.reaped)
+	})
+
+	t.Run("max items", func(t *testing.T) {
+		s := newSweeper()
+		_, err := New(WithSweepers(s), WithMaxItems(1), WithRateLimit(1000)).RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, s.reaped)
+	})
+
+	t.Run("find error does not stop other sweepers", func(t *testing.T) {
+		failing, ok := newSweeper(), newSweeper()
+		failing.findErr = errors.New("db is down")
+		_, err := New(WithSweepers(failing, ok), WithRateLimit(1000)).RunOnce(context.Background())
+		require.ErrorContains(t, err, "db is down")
+		assert.Len(t, ok.reaped, 3)
+	})
+}
+
+func TestFileStoreSweeper(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour)
+	write := func(name string, mtime time.Time) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0o644))
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+	}
+	write("abandoned.info", old)
+	write("abandoned", old)
+	write("active.info", old)
+	write("active", time.Now())
+	write("orphan", old)
+	write("123/leftover/file.mp4", old)
+	write("123/published/file.mp4", time.Now())
+	write("uploads/file.mp4", old)
+
+	s := NewFileStoreSweeper("publish", dir)
+	items, err := s.Find(context.Background(), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	ids := []string{}
+	for _, i := range items {
+		ids = append(ids, i.ID)
+		require.NoError(t, s.Reap(context.Background(), i))
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{filepath.Join("123", "leftover"), "abandoned", "orphan"}, ids)
+
+	for _, f := range []string{"active.info", "active", "123/published/file.mp4", "uploads/file.mp4"} {
+		assert.FileExists(t, filepath.Join(dir, f))
+	}
+	for _, f := range []string{"abandoned.info", "abandoned", "orphan", "123/leftover"} {
+		assert.NoFileExists(t, filepath.Join(dir, f))
+	}
+	assert.Error(t, s.Reap(context.Background(), Item{ID: "../outside"}))
+}
+
+func TestDirSweeper(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour)
+	write := func(name string, mtime time.Time) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0o644))
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+	}
+	write("orphan", old)
+	write("active", time.Now())
+	write("tmp/uploads/orphan/blob1", old)
+	write("tmp/uploads/orphan/blob2", old)
+	write("tmp/uploads/active/blob1", old)
+	write("tmp/uploads/active/blob2", time.Now())
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "empty"), os.ModePerm))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "empty"), old, old))
+
+	s := NewDirSweeper("blobs", dir)
+	items, err := s.Find(context.Background(), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	ids := []string{}
+	for _, i := range items {
+		ids = append(ids, i.ID)
+		require.NoError(t, s.Reap(context.Background(), i))
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{
+		"empty", "orphan",
+		filepath.Join("tmp", "uploads", "active", "blob1"),
+		filepath.Join("tmp", "uploads", "orphan", "blob1"),
+		filepath.Join("tmp", "uploads", "orphan", "blob2"),
+	}, ids)
+
+	for _, f := range []string{"active", "tmp/uploads/active/blob2"} {
+		assert.FileExists(t, filepath.Join(dir, f))
+	}
+	for _, f := range []string{"orphan", "empty", "tmp/uploads/orphan/blob1", "tmp/uploads/active/blob1"} {
+		assert.NoFileExists(t, filepath.Join(dir, f))
+		assert.NoDirExists(t, filepath.Join(dir, f))
+	}
+
+	// Emptied directories are removed once they are old enough.
+	orphanDir := filepath.Join(dir, "tmp", "uploads", "orphan")
+	require.NoError(t, os.Chtimes(orphanDir, old, old))
+	items, err = s.Find(context.Background(), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NoError(t, s.Reap(context.Background(), items[0]))
+	assert.NoDirExists(t, orphanDir)
+
+	assert.Error(t, s.Reap(context.Background(), Item{ID: "../outside"}))
+	_, err = NewDirSweeper("missing", filepath.Join(dir, "missing")).Find(context.Background(), time.Now())
+	assert.Error(t, err)
+}
@@ -16,6 +16,39 @@ var (
 		Name:      "unlocked",
 	})
 
+	lockContentions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "contentions",
+		Help:      "Lock attempts which found the lock already taken",
+	})
+	lockWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: ns,
+		Name:      "wait_seconds",
+		Help:      "Time it took to acquire a lock",
+		Buckets:   []float64{.005, .01, .05, .1, .5, 1, 2.5, 5, 10, 30},
+	})
+	lockHeldSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: ns,
+		Name:      "held_seconds",
+		Help:      "Time locks were held for",
+		Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 120, 300, 600, 1800},
+	})
+	lockExtensions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "extended",
+		Help:      "Successful lock lease extensions",
+	})
+	lostLocks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "lost",
+		Help:      "Locks which expired before they could be extended",
+	})
+	releaseRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Name:      "release_requests",
+		Help:      "Requests to release a lock, sent to other nodes or received and passed to a local holder",
+	}, []string{"direction"})
+
 	fileLockedErrors = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: ns,
 		Subsystem: "errors",
@@ -26,11 +59,19 @@ var (
 		Subsystem: "errors",
 		Name:      "unlock",
 	})
+	extendErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: "errors",
+		Name:      "extend",
+	})
 )
 
 func RegisterMetrics(registry prometheus.Registerer) {
 	if registry == nil {
 		registry = prometheus.DefaultRegisterer
 	}
-	registry.MustRegister(locked, unlocked, fileLockedErrors, unlockErrors)
+	registry.MustRegister(
+		locked, unlocked, lockContentions, lockWaitSeconds, lockHeldSeconds, lockExtensions, lostLocks, releaseRequests,
+		fileLockedErrors, unlockErrors, extendErrors,
+	)
 }
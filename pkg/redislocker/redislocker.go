@@ -2,7 +2,12 @@ package redislocker
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,39 +17,100 @@ import (
 	"github.com/tus/tusd/v2/pkg/handler"
 )
 
+const releaseChannel = "redislocker:release"
+
 var (
 	lockTimeout = 100 * time.Second
+	// lockRetries and lockRetryDelay bound waiting for locks taken with contexts without a deadline.
+	lockRetries    = 32
+	lockRetryDelay = 100 * time.Millisecond
 )
 
 // Locker implements tusd's handler.Locker using Redis via redsync.
-// The requestRelease callback (tusd v2) is tracked in-process only.
-// Cross-process contention falls back to retry/timeout via redsync.
+// Held locks are kept alive by a heartbeat which extends their expiry, so long requests don't lose them.
+// When a lock is taken, the holder is asked to release it through Redis pub/sub,
+// so the requestRelease callback (tusd v2) gets invoked on whichever node holds the lock.
 type Locker struct {
-	rs       *redsync.Redsync
-	holders  map[string]func()
-	holderMu sync.Mutex
+	id        string
+	client    *goredislib.Client
+	rs        *redsync.Redsync
+	expiry    time.Duration
+	heartbeat time.Duration
+	holders   map[string]func()
+	holderMu  sync.Mutex
+	pubsub    *goredislib.PubSub
 }
 
 type lock struct {
-	locker *Locker
-	name   string
-	mutex  *redsync.Mutex
+	locker   *Locker
+	name     string
+	mutex    *redsync.Mutex
+	lockedAt time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type Option func(*Locker)
+
+// WithExpiry sets how long a lock stays valid without being extended, 100s by default.
+func WithExpiry(expiry time.Duration) Option {
+	return func(l *Locker) {
+		l.expiry = expiry
+	}
 }
 
-func New(redisOpts *goredislib.Options) (*Locker, error) {
+// WithHeartbeat sets how often held locks get extended, a third of the expiry by default.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(l *Locker) {
+		l.heartbeat = interval
+	}
+}
+
+func New(redisOpts *goredislib.Options, options ...Option) (*Locker, error) {
 	client := goredislib.NewClient(redisOpts)
 	err := client.Ping(context.Background()).Err()
 	if err != nil {
 		return nil, err
 	}
-	pool := goredis.NewPool(client)
-	rs := redsync.New(pool)
-	return &Locker{rs: rs, holders: make(map[string]func())}, nil
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	locker := &Locker{
+		id:      hex.EncodeToString(id),
+		client:  client,
+		rs:      redsync.New(goredis.NewPool(client)),
+		expiry:  lockTimeout,
+		holders: make(map[string]func()),
+	}
+	for _, o := range options {
+		o(locker)
+	}
+	if locker.heartbeat <= 0 {
+		locker.heartbeat = locker.expiry / 3
+	}
+
+	locker.pubsub = client.Subscribe(context.Background(), releaseChannel)
+	// Make sure the subscription is active before any locks are taken.
+	if _, err := locker.pubsub.Receive(context.Background()); err != nil {
+		return nil, fmt.Errorf("cannot subscribe to release requests: %w", err)
+	}
+	go locker.listen()
+	return locker, nil
 }
 
 func (locker *Locker) NewLock(name string) (handler.Lock, error) {
-	m := locker.rs.NewMutex(name, redsync.WithExpiry(lockTimeout))
-	return &lock{locker: locker, name: name, mutex: m}, nil
+	return &lock{locker: locker, name: name}, nil
+}
+
+// newMutex creates a mutex retrying until ctx is done if it has a deadline, or lockRetries times otherwise.
+func (locker *Locker) newMutex(ctx context.Context, name string) *redsync.Mutex {
+	tries := lockRetries
+	if _, ok := ctx.Deadline(); ok {
+		tries = math.MaxInt32
+	}
+	return locker.rs.NewMutex(name,
+		redsync.WithExpiry(locker.expiry), redsync.WithTries(tries), redsync.WithRetryDelay(lockRetryDelay))
 }
 
 // UseIn adds this locker to the passed composer.
@@ -52,34 +118,117 @@ func (locker *Locker) UseIn(composer *handler.StoreComposer) {
 	composer.UseLocker(locker)
 }
 
-func (l *lock) Lock(ctx context.Context, requestRelease func()) error {
-	l.locker.holderMu.Lock()
-	if existing, ok := l.locker.holders[l.name]; ok {
-		existing()
+// Close stops listening for release requests and closes the Redis connection.
+func (locker *Locker) Close() error {
+	return errors.Join(locker.pubsub.Close(), locker.client.Close())
+}
+
+// listen invokes requestRelease callbacks of local holders when other nodes ask for their locks.
+// Messages are "<locker id> <lock name>", requests sent by this locker are handled directly in Lock.
+func (locker *Locker) listen() {
+	for msg := range locker.pubsub.Channel() {
+		sender, name, ok := strings.Cut(msg.Payload, " ")
+		if !ok || sender == locker.id {
+			continue
+		}
+		if locker.requestRelease(name) {
+			releaseRequests.WithLabelValues("received").Inc()
+		}
 	}
-	l.locker.holderMu.Unlock()
+}
 
-	if err := l.mutex.LockContext(ctx); err != nil {
+func (locker *Locker) requestRelease(name string) bool {
+	locker.holderMu.Lock()
+	release, ok := locker.holders[name]
+	locker.holderMu.Unlock()
+	if ok {
+		release()
+	}
+	return ok
+}
+
+func (l *lock) Lock(ctx context.Context, requestRelease func()) error {
+	start := time.Now()
+	var taken *redsync.ErrTaken
+	m := l.locker.newMutex(ctx, l.name)
+	err := m.TryLockContext(ctx)
+	if errors.As(err, &taken) || errors.Is(err, redsync.ErrFailed) {
+		// Someone holds the lock, ask them to let go and keep trying until the context is done.
+		// Without a deadline on the context, the lock is given up after lockRetries attempts.
+		lockContentions.Inc()
+		l.locker.requestRelease(l.name)
+		pubErr := l.locker.client.Publish(ctx, releaseChannel, l.locker.id+" "+l.name).Err()
+		if pubErr == nil {
+			releaseRequests.WithLabelValues("sent").Inc()
+		}
+		err = m.LockContext(ctx)
+	}
+	if err != nil {
 		fileLockedErrors.Inc()
 		if ctx.Err() != nil {
 			return fmt.Errorf("%w: file %s: %s", handler.ErrLockTimeout, l.name, err)
 		}
 		return fmt.Errorf("%w: file %s: %s", handler.ErrFileLocked, l.name, err)
 	}
+	lockWaitSeconds.Observe(time.Since(start).Seconds())
+	l.mutex = m
 
 	l.locker.holderMu.Lock()
 	l.locker.holders[l.name] = requestRelease
 	l.locker.holderMu.Unlock()
 
+	l.lockedAt = time.Now()
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	go l.keepAlive(requestRelease)
+
 	locked.Inc()
 	return nil
 }
 
+// keepAlive extends the lock until it's unlocked. If the lock can't be extended before it expires,
+// the holder is asked to release it as another request could have taken it by then.
+func (l *lock) keepAlive(requestRelease func()) {
+	defer close(l.done)
+	t := time.NewTicker(l.locker.heartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), l.locker.heartbeat)
+		ok, err := l.mutex.ExtendContext(ctx)
+		cancel()
+		if ok && err == nil {
+			lockExtensions.Inc()
+			continue
+		}
+		extendErrors.Inc()
+		if time.Now().After(l.mutex.Until()) {
+			lostLocks.Inc()
+			requestRelease()
+			return
+		}
+	}
+}
+
 func (l *lock) Unlock() error {
 	l.locker.holderMu.Lock()
 	delete(l.locker.holders, l.name)
 	l.locker.holderMu.Unlock()
 
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+		l.stop = nil
+		lockHeldSeconds.Observe(time.Since(l.lockedAt).Seconds())
+	}
+
+	if l.mutex == nil {
+		unlockErrors.Inc()
+		return fmt.Errorf("cannot unlock file %s: it's not locked", l.name)
+	}
 	if ok, err := l.mutex.Unlock(); !ok || err != nil {
 		unlockErrors.Inc()
 		return fmt.Errorf("cannot unlock file %s: %w", l.name, err)
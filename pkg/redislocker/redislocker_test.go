@@ -72,6 +72,31 @@ func TestLockerTimeout(t *testing.T) {
 	a.NoError(lock1.Unlock())
 }
 
+func TestLockerWaitsForContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	locker, err := New(redisOpts)
+	r.NoError(err)
+
+	lock1, err := locker.NewLock("wait-test")
+	r.NoError(err)
+	r.NoError(lock1.Lock(context.Background(), func() {}))
+	// The lock is released after more time than lockRetries attempts take.
+	go func() {
+		time.Sleep(time.Duration(lockRetries+10) * lockRetryDelay)
+		lock1.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	lock2, err := locker.NewLock("wait-test")
+	r.NoError(err)
+	r.NoError(lock2.Lock(ctx, func() {}))
+	a.NoError(lock2.Unlock())
+	a.Error(lock2.Unlock())
+}
+
 func TestLockerRequestRelease(t *testing.T) {
 	a := assert.New(t)
 	r := require.New(t)
@@ -98,3 +123,57 @@ func TestLockerRequestRelease(t *testing.T) {
 
 	a.NoError(lock1.Unlock())
 }
+
+func TestLockerHeartbeat(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	locker, err := New(redisOpts, WithExpiry(time.Second), WithHeartbeat(200*time.Millisecond))
+	r.NoError(err)
+	defer locker.Close()
+
+	lock1, err := locker.NewLock("heartbeat-test")
+	r.NoError(err)
+	r.NoError(lock1.Lock(context.Background(), func() {}))
+
+	// Held well past the expiry.
+	time.Sleep(2500 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	lock2, err := locker.NewLock("heartbeat-test")
+	r.NoError(err)
+	a.ErrorIs(lock2.Lock(ctx, func() {}), handler.ErrLockTimeout)
+
+	a.NoError(lock1.Unlock())
+}
+
+func TestLockerCrossProcessRelease(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	holder, err := New(redisOpts)
+	r.NoError(err)
+	defer holder.Close()
+	contender, err := New(redisOpts)
+	r.NoError(err)
+	defer contender.Close()
+
+	var released atomic.Bool
+	lock1, err := holder.NewLock("cross-release-test")
+	r.NoError(err)
+	r.NoError(lock1.Lock(context.Background(), func() {
+		if released.CompareAndSwap(false, true) {
+			go lock1.Unlock()
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lock2, err := contender.NewLock("cross-release-test")
+	r.NoError(err)
+	r.NoError(lock2.Lock(ctx, func() {}))
+	a.True(released.Load(), "holder on another node should have been asked to release the lock")
+
+	a.NoError(lock2.Unlock())
+}
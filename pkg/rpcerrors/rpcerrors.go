@@ -36,12 +36,23 @@ func (e RPCError) Error() string {
 	return e.err.Error()
 }
 
+// DataError is implemented by errors carrying details for the client, which are returned in the error data.
+type DataError interface {
+	error
+	ErrorData() any
+}
+
 func (e RPCError) JSON() []byte {
+	rpcErr := &jsonrpc.RPCError{
+		Code:    e.Code(),
+		Message: e.Error(),
+	}
+	var de DataError
+	if errors.As(e.err, &de) {
+		rpcErr.Data = de.ErrorData()
+	}
 	b, err := json.MarshalIndent(jsonrpc.RPCResponse{
-		Error: &jsonrpc.RPCError{
-			Code:    e.Code(),
-			Message: e.Error(),
-		},
+		Error:   rpcErr,
 		JSONRPC: "2.0",
 	}, "", "  ")
 	if err != nil {
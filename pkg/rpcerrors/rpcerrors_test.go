@@ -23,3 +23,19 @@ func TestWrite(t *testing.T) {
 	}, "", "  ")
 	require.Equal(t, b, w.Bytes())
 }
+
+type dataError struct{}
+
+func (dataError) Error() string  { return "denied" }
+func (dataError) ErrorData() any { return map[string]string{"reason": "test"} }
+
+func TestWriteData(t *testing.T) {
+	w := new(bytes.Buffer)
+	Write(w, NewSDKError(dataError{}))
+
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(w.Bytes(), &res))
+	require.Equal(t, rpcErrorCodeSDK, res.Error.Code)
+	require.Equal(t, "denied", res.Error.Message)
+	require.Equal(t, map[string]any{"reason": "test"}, res.Error.Data)
+}
@@ -0,0 +1,456 @@
+package sanitizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+const maxBoxReadSize = 4 << 20
+
+var (
+	errMalformedBox = errors.New("malformed iso bmff box")
+
+	// Atoms in udta boxes carrying location and recording device info.
+	udtaLocationAtoms = map[string]bool{"\xa9xyz": true, "loci": true}
+	udtaDeviceAtoms   = map[string]bool{"\xa9mak": true, "\xa9mod": true}
+
+	qtLocationKeys = [][]byte{[]byte("com.apple.quicktime.location")}
+	qtDeviceKeys   = [][]byte{[]byte("com.apple.quicktime.make"), []byte("com.apple.quicktime.model")}
+)
+
+type box struct {
+	typ        string
+	offset     int64
+	headerSize int64
+	size       int64
+}
+
+func (b box) contentOffset() int64 {
+	return b.offset + b.headerSize
+}
+
+func (b box) contentSize() int64 {
+	return b.size - b.headerSize
+}
+
+// readBoxes lists boxes located between start and end offsets.
+func readBoxes(r io.ReaderAt, start, end int64) ([]box, error) {
+	var boxes []box
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return nil, err
+		}
+		b := box{
+			typ:        string(header[4:8]),
+			offset:     offset,
+			headerSize: 8,
+			size:       int64(binary.BigEndian.Uint32(header)),
+		}
+		switch b.size {
+		case 0:
+			b.size = end - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return nil, err
+			}
+			b.headerSize = 16
+			b.size = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if b.size < b.headerSize || offset+b.size > end {
+			return nil, errMalformedBox
+		}
+		boxes = append(boxes, b)
+		offset += b.size
+	}
+	return boxes, nil
+}
+
+func readBoxContent(r io.ReaderAt, b box) ([]byte, error) {
+	if b.contentSize() > maxBoxReadSize {
+		return nil, errMalformedBox
+	}
+	buf := make([]byte, b.contentSize())
+	_, err := r.ReadAt(buf, b.contentOffset())
+	return buf, err
+}
+
+func findBox(boxes []box, typ string) (box, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return box{}, false
+}
+
+func fileSize(f *os.File) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// stripHEIC overwrites Exif and XMP items with zeros, keeping all offsets intact.
+func stripHEIC(f *os.File, report *Report) error {
+	size, err := fileSize(f)
+	if err != nil {
+		return err
+	}
+	top, err := readBoxes(f, 0, size)
+	if err != nil {
+		return err
+	}
+	meta, ok := findBox(top, "meta")
+	if !ok {
+		return nil
+	}
+	// meta is a full box, children follow version and flags
+	children, err := readBoxes(f, meta.contentOffset()+4, meta.offset+meta.size)
+	if err != nil {
+		return err
+	}
+	iinf, ok := findBox(children, "iinf")
+	if !ok {
+		return nil
+	}
+	iloc, ok := findBox(children, "iloc")
+	if !ok {
+		return nil
+	}
+
+	items, err := parseIinf(f, iinf)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	ilocData, err := readBoxContent(f, iloc)
+	if err != nil {
+		return err
+	}
+	locations, err := parseIloc(ilocData)
+	if err != nil {
+		return err
+	}
+
+	var idatOffset int64 = -1
+	if idat, ok := findBox(children, "idat"); ok {
+		idatOffset = idat.contentOffset()
+	}
+	for id, label := range items {
+		loc, ok := locations[id]
+		if !ok {
+			continue
+		}
+		var base int64
+		switch loc.constructionMethod {
+		case 0:
+			base = loc.baseOffset
+		case 1:
+			if idatOffset < 0 {
+				continue
+			}
+			base = idatOffset + loc.baseOffset
+		default:
+			continue
+		}
+		for _, e := range loc.extents {
+			if e.length == 0 || base+e.offset+e.length > size {
+				continue
+			}
+			if err := zeroRange(f, base+e.offset, e.length); err != nil {
+				return err
+			}
+		}
+		report.add(label)
+	}
+	return nil
+}
+
+// parseIinf returns IDs of Exif and XMP items mapped to report labels.
+func parseIinf(r io.ReaderAt, iinf box) (map[uint32]string, error) {
+	data, err := readBoxContent(r, iinf)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 6 {
+		return nil, errMalformedBox
+	}
+	childrenStart := int64(6)
+	if data[0] > 0 {
+		childrenStart = 8
+	}
+	entries, err := readBoxes(bytes.NewReader(data), childrenStart, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	items := map[uint32]string{}
+	for _, e := range entries {
+		if e.typ != "infe" {
+			continue
+		}
+		c := data[e.contentOffset() : e.offset+e.size]
+		if len(c) < 4 || c[0] < 2 {
+			continue
+		}
+		var id uint32
+		p := 4
+		if c[0] == 2 {
+			if len(c) < p+2 {
+				continue
+			}
+			id = uint32(binary.BigEndian.Uint16(c[p:]))
+			p += 2
+		} else {
+			if len(c) < p+4 {
+				continue
+			}
+			id = binary.BigEndian.Uint32(c[p:])
+			p += 4
+		}
+		p += 2 // item_protection_index
+		if len(c) < p+4 {
+			continue
+		}
+		itemType := string(c[p : p+4])
+		p += 4
+		switch itemType {
+		case "Exif":
+			items[id] = RemovedEXIF
+		case "mime":
+			// item_name, then content_type
+			rest := c[p:]
+			if n := bytes.IndexByte(rest, 0); n >= 0 {
+				rest = rest[n+1:]
+			}
+			if bytes.HasPrefix(rest, []byte("application/rdf+xml")) {
+				items[id] = RemovedXMP
+			}
+		}
+	}
+	return items, nil
+}
+
+type itemExtent struct {
+	offset, length int64
+}
+
+type itemLocation struct {
+	constructionMethod int
+	baseOffset         int64
+	extents            []itemExtent
+}
+
+func parseIloc(data []byte) (map[uint32]itemLocation, error) {
+	if len(data) < 8 {
+		return nil, errMalformedBox
+	}
+	version := data[0]
+	offsetSize := int(data[4] >> 4)
+	lengthSize := int(data[4] & 0x0F)
+	baseOffsetSize := int(data[5] >> 4)
+	var indexSize int
+	if version == 1 || version == 2 {
+		indexSize = int(data[5] & 0x0F)
+	}
+	p := 6
+
+	readN := func(n int) (int64, error) {
+		if p+n > len(data) {
+			return 0, errMalformedBox
+		}
+		var v uint64
+		for i := 0; i < n; i++ {
+			v = v<<8 | uint64(data[p+i])
+		}
+		p += n
+		return int64(v), nil
+	}
+
+	var itemCount int64
+	var err error
+	if version < 2 {
+		itemCount, err = readN(2)
+	} else {
+		itemCount, err = readN(4)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locations := map[uint32]itemLocation{}
+	for i := int64(0); i < itemCount; i++ {
+		var id int64
+		if version < 2 {
+			id, err = readN(2)
+		} else {
+			id, err = readN(4)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loc := itemLocation{}
+		if version == 1 || version == 2 {
+			cm, err := readN(2)
+			if err != nil {
+				return nil, err
+			}
+			loc.constructionMethod = int(cm & 0x0F)
+		}
+		if _, err := readN(2); err != nil { // data_reference_index
+			return nil, err
+		}
+		if loc.baseOffset, err = readN(baseOffsetSize); err != nil {
+			return nil, err
+		}
+		extentCount, err := readN(2)
+		if err != nil {
+			return nil, err
+		}
+		for j := int64(0); j < extentCount; j++ {
+			if _, err := readN(indexSize); err != nil {
+				return nil, err
+			}
+			e := itemExtent{}
+			if e.offset, err = readN(offsetSize); err != nil {
+				return nil, err
+			}
+			if e.length, err = readN(lengthSize); err != nil {
+				return nil, err
+			}
+			loc.extents = append(loc.extents, e)
+		}
+		locations[uint32(id)] = loc
+	}
+	return locations, nil
+}
+
+func zeroRange(f *os.File, offset, length int64) error {
+	zeros := make([]byte, min(length, 32<<10))
+	for length > 0 {
+		n := min(length, int64(len(zeros)))
+		if _, err := f.WriteAt(zeros[:n], offset); err != nil {
+			return err
+		}
+		offset += n
+		length -= n
+	}
+	return nil
+}
+
+// stripMP4 turns location and device atoms into zeroed free space atoms, which players skip.
+func stripMP4(f *os.File, report *Report) error {
+	size, err := fileSize(f)
+	if err != nil {
+		return err
+	}
+	top, err := readBoxes(f, 0, size)
+	if err != nil {
+		return err
+	}
+	moov, ok := findBox(top, "moov")
+	if !ok {
+		return nil
+	}
+	children, err := readBoxes(f, moov.contentOffset(), moov.offset+moov.size)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range children {
+		switch b.typ {
+		case "udta":
+			if err := stripUdta(f, b, report); err != nil {
+				return err
+			}
+		case "trak":
+			trakChildren, err := readBoxes(f, b.contentOffset(), b.offset+b.size)
+			if err != nil {
+				return err
+			}
+			if udta, ok := findBox(trakChildren, "udta"); ok {
+				if err := stripUdta(f, udta, report); err != nil {
+					return err
+				}
+			}
+		case "meta":
+			if err := stripQuickTimeMeta(f, b, report); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func stripUdta(f *os.File, udta box, report *Report) error {
+	children, err := readBoxes(f, udta.contentOffset(), udta.offset+udta.size)
+	if err != nil {
+		return err
+	}
+	for _, b := range children {
+		var label string
+		switch {
+		case udtaLocationAtoms[b.typ]:
+			label = RemovedLocation
+		case udtaDeviceAtoms[b.typ]:
+			label = RemovedDevice
+		default:
+			continue
+		}
+		if err := freeBox(f, b); err != nil {
+			return err
+		}
+		report.add(label)
+	}
+	return nil
+}
+
+// stripQuickTimeMeta frees the whole moov-level metadata box written by Apple devices
+// if it contains location or device keys.
+func stripQuickTimeMeta(f *os.File, meta box, report *Report) error {
+	if meta.contentSize() > maxBoxReadSize {
+		return nil
+	}
+	data, err := readBoxContent(f, meta)
+	if err != nil {
+		return err
+	}
+	var labels []string
+	for _, k := range qtLocationKeys {
+		if bytes.Contains(data, k) {
+			labels = append(labels, RemovedLocation)
+			break
+		}
+	}
+	for _, k := range qtDeviceKeys {
+		if bytes.Contains(data, k) {
+			labels = append(labels, RemovedDevice)
+			break
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	if err := freeBox(f, meta); err != nil {
+		return err
+	}
+	for _, l := range labels {
+		report.add(l)
+	}
+	return nil
+}
+
+// freeBox renames the box to free and wipes its content.
+func freeBox(f *os.File, b box) error {
+	if _, err := f.WriteAt([]byte("free"), b.offset+4); err != nil {
+		return err
+	}
+	return zeroRange(f, b.contentOffset(), b.contentSize())
+}
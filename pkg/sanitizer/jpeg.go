@@ -0,0 +1,151 @@
+package sanitizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+)
+
+const (
+	markerSOS  = 0xDA
+	markerEOI  = 0xD9
+	markerAPP1 = 0xE1
+	markerAPPD = 0xED
+	markerCOM  = 0xFE
+
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+var (
+	exifHeader        = []byte("Exif\x00\x00")
+	xmpHeader         = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtendedHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	photoshopHeader   = []byte("Photoshop 3.0\x00")
+
+	errMalformedJPEG = errors.New("malformed jpeg")
+)
+
+// stripJPEG removes EXIF, XMP, IPTC and comment segments.
+// EXIF orientation is preserved in a minimal replacement EXIF segment, put in place of the original one,
+// so the image is displayed the same way.
+func stripJPEG(data []byte, report *Report) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errMalformedJPEG
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	var orientation uint16
+	exifPos := -1
+
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, errMalformedJPEG
+		}
+		// Skip fill bytes
+		for i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) {
+			return nil, errMalformedJPEG
+		}
+		marker := data[i+1]
+		if marker == markerEOI || marker == markerSOS {
+			out = append(out, data[i:]...)
+			if orientation > 1 && exifPos >= 0 {
+				out = slices.Insert(out, exifPos, orientationSegment(orientation)...)
+			}
+			return out, nil
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return nil, errMalformedJPEG
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return nil, errMalformedJPEG
+		}
+		payload := data[i+4 : end]
+
+		switch {
+		case marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader):
+			report.add(RemovedEXIF)
+			if exifPos < 0 {
+				exifPos = len(out)
+			}
+			o, gps := parseEXIF(payload[len(exifHeader):])
+			if o > 0 {
+				orientation = o
+			}
+			if gps {
+				report.add(RemovedGPS)
+			}
+		case marker == markerAPP1 && (bytes.HasPrefix(payload, xmpHeader) || bytes.HasPrefix(payload, xmpExtendedHeader)):
+			report.add(RemovedXMP)
+		case marker == markerAPPD && bytes.HasPrefix(payload, photoshopHeader):
+			report.add(RemovedIPTC)
+		case marker == markerCOM:
+			report.add(RemovedComment)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return nil, errMalformedJPEG
+}
+
+// parseEXIF looks up orientation value and GPS IFD pointer in the IFD0 of a TIFF structure.
+func parseEXIF(tiff []byte) (orientation uint16, hasGPS bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 0, false
+	}
+	offset := int(bo.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(bo.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		switch bo.Uint16(tiff[entry:]) {
+		case tagOrientation:
+			orientation = bo.Uint16(tiff[entry+8:])
+		case tagGPSInfo:
+			hasGPS = true
+		}
+	}
+	return orientation, hasGPS
+}
+
+// orientationSegment builds an APP1 segment containing only the orientation tag.
+func orientationSegment(orientation uint16) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, // byte order, magic
+		0x00, 0x00, 0x00, 0x08, // IFD0 offset
+		0x00, 0x01, // entry count
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, // orientation, SHORT, count 1
+		byte(orientation >> 8), byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // next IFD offset
+	}
+	seg := []byte{0xFF, markerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(2+len(exifHeader)+len(tiff)))
+	seg = append(seg, exifHeader...)
+	return append(seg, tiff...)
+}
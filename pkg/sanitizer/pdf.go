@@ -0,0 +1,81 @@
+package sanitizer
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// reInfoKeys matches document information dictionary entries identifying the author and authoring software.
+var reInfoKeys = regexp.MustCompile(`/(Author|Creator|Producer)\s*[(<]`)
+
+// stripPDF blanks author, creator and producer strings in the document information dictionary.
+// Values are overwritten with the same number of bytes so cross-reference offsets stay valid.
+// Entries inside compressed object streams are not reachable and are left as is.
+func stripPDF(data []byte, report *Report) ([]byte, error) {
+	out := bytes.Clone(data)
+	for _, m := range reInfoKeys.FindAllIndex(out, -1) {
+		start := m[1] - 1
+		var blanked bool
+		if out[start] == '(' {
+			blanked = blankLiteralString(out, start)
+		} else {
+			blanked = blankHexString(out, start)
+		}
+		if blanked {
+			report.add(RemovedAuthor)
+		}
+	}
+	return out, nil
+}
+
+// blankLiteralString replaces content of a (...) string starting at pos with spaces.
+func blankLiteralString(data []byte, pos int) bool {
+	depth := 0
+	for i := pos; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			data[i] = ' '
+			if i+1 < len(data) {
+				i++
+				data[i] = ' '
+			}
+		case '(':
+			depth++
+			if depth > 1 {
+				data[i] = ' '
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return i > pos+1
+			}
+			data[i] = ' '
+		default:
+			data[i] = ' '
+		}
+	}
+	return false
+}
+
+// blankHexString replaces content of a <...> string starting at pos with encoded spaces.
+func blankHexString(data []byte, pos int) bool {
+	if pos+1 < len(data) && data[pos+1] == '<' {
+		// A dictionary, not a string
+		return false
+	}
+	digits := 0
+	for i := pos + 1; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '>':
+			return digits > 0
+		case (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			if digits%2 == 0 {
+				data[i] = '2'
+			} else {
+				data[i] = '0'
+			}
+			digits++
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package sanitizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	pngXMPKey    = []byte("XML:com.adobe.xmp\x00")
+
+	errMalformedPNG = errors.New("malformed png")
+)
+
+// stripPNG removes eXIf, textual and timestamp chunks.
+func stripPNG(data []byte, report *Report) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformedPNG
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, errMalformedPNG
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformedPNG
+		}
+		chunkType := string(data[i+4 : i+8])
+		switch chunkType {
+		case "eXIf":
+			report.add(RemovedEXIF)
+		case "iTXt":
+			if bytes.HasPrefix(data[i+8:end], pngXMPKey) {
+				report.add(RemovedXMP)
+			} else {
+				report.add(RemovedText)
+			}
+		case "tEXt", "zTXt":
+			report.add(RemovedText)
+		case "tIME":
+			report.add(RemovedTime)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,212 @@
+// Package sanitizer removes privacy-sensitive metadata (location, device, authoring info)
+// from uploaded files while leaving the actual content untouched.
+package sanitizer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/h2non/filetype"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+	FormatHEIC = "heic"
+	FormatPDF  = "pdf"
+	FormatMP4  = "mp4"
+
+	RemovedEXIF     = "exif"
+	RemovedGPS      = "gps"
+	RemovedXMP      = "xmp"
+	RemovedIPTC     = "iptc"
+	RemovedComment  = "comment"
+	RemovedText     = "text"
+	RemovedTime     = "time"
+	RemovedLocation = "location"
+	RemovedDevice   = "device"
+	RemovedAuthor   = "author"
+
+	defaultMaxInMemorySize = 256 << 20
+)
+
+var (
+	ErrTooLarge = errors.New("file is too large to be sanitized in memory")
+	// ErrMetadataRemains is returned when metadata was found in the file but sanitization failed before it was removed.
+	ErrMetadataRemains = errors.New("metadata found in the file could not be removed")
+)
+
+// Sanitizer strips metadata from supported file formats.
+// JPEG, PNG, WebP and HEIC images are always processed, PDF and MP4 are opt-in.
+type Sanitizer struct {
+	documents       bool
+	videos          bool
+	maxInMemorySize int64
+}
+
+// Report describes what was removed from a file.
+type Report struct {
+	Format  string
+	Removed []string
+}
+
+type Option func(s *Sanitizer)
+
+// WithDocuments enables blanking of author and producer metadata in PDF documents.
+func WithDocuments(enabled bool) Option {
+	return func(s *Sanitizer) {
+		s.documents = enabled
+	}
+}
+
+// WithVideos enables removal of location and device atoms from MP4 and QuickTime files.
+func WithVideos(enabled bool) Option {
+	return func(s *Sanitizer) {
+		s.videos = enabled
+	}
+}
+
+// WithMaxInMemorySize sets the maximum size of files which need to be fully read into memory (images and documents).
+func WithMaxInMemorySize(size int64) Option {
+	return func(s *Sanitizer) {
+		s.maxInMemorySize = size
+	}
+}
+
+func New(options ...Option) *Sanitizer {
+	s := &Sanitizer{
+		maxInMemorySize: defaultMaxInMemorySize,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// Sanitize detects file format and strips metadata from it, modifying the file in place.
+// Unsupported formats are left untouched and an empty report is returned.
+// Errors wrap ErrMetadataRemains if metadata was found in the file before sanitization failed.
+func (s *Sanitizer) Sanitize(filePath string) (*Report, error) {
+	header, err := readHeader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	kind, _ := filetype.Match(header)
+
+	report := &Report{}
+	switch kind.MIME.Value {
+	case "image/jpeg":
+		report.Format = FormatJPEG
+		err = s.rewrite(filePath, report, stripJPEG)
+	case "image/png":
+		report.Format = FormatPNG
+		err = s.rewrite(filePath, report, stripPNG)
+	case "image/webp":
+		report.Format = FormatWebP
+		err = s.rewrite(filePath, report, stripWebP)
+	case "image/heif":
+		report.Format = FormatHEIC
+		err = inPlace(filePath, report, stripHEIC)
+	case "application/pdf":
+		if !s.documents {
+			return report, nil
+		}
+		report.Format = FormatPDF
+		err = s.rewrite(filePath, report, stripPDF)
+	case "video/mp4", "video/x-m4v", "video/quicktime":
+		if !s.videos {
+			return report, nil
+		}
+		report.Format = FormatMP4
+		err = inPlace(filePath, report, stripMP4)
+	}
+	if err != nil {
+		if !report.Empty() {
+			err = fmt.Errorf("%w (%s): %w", ErrMetadataRemains, strings.Join(report.Removed, ", "), err)
+		}
+		return nil, fmt.Errorf("error sanitizing %s: %w", report.Format, err)
+	}
+	return report, nil
+}
+
+// Empty returns true if nothing was removed.
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Removed) == 0
+}
+
+func (r *Report) add(item string) {
+	if !slices.Contains(r.Removed, item) {
+		r.Removed = append(r.Removed, item)
+	}
+}
+
+// rewrite reads the whole file into memory, passes it through strip function
+// and atomically replaces the original file if anything was removed.
+func (s *Sanitizer) rewrite(filePath string, report *Report, strip func([]byte, *Report) ([]byte, error)) error {
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if fi.Size() > s.maxInMemorySize {
+		return ErrTooLarge
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	out, err := strip(data, report)
+	if err != nil {
+		return err
+	}
+	if report.Empty() {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".sanitized-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// inPlace opens the file for in-place editing, used for formats where metadata
+// can be neutralized without shifting any offsets.
+func inPlace(filePath string, report *Report, strip func(*os.File, *Report) error) error {
+	f, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := strip(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readHeader(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	header := make([]byte, 261)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return header[:n], nil
+}
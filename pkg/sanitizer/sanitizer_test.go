@@ -0,0 +1,290 @@
+package sanitizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			img.Set(x, y, color.RGBA{uint8(x * 16), uint8(y * 16), 128, 255})
+		}
+	}
+	return img
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, data, 0o644))
+	return p
+}
+
+// exifSegment builds a little-endian EXIF APP1 segment with orientation and GPS pointer tags.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00, 0x02, 0x00}
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], tagOrientation)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	binary.LittleEndian.PutUint16(entry[0:], tagGPSInfo)
+	binary.LittleEndian.PutUint16(entry[2:], 4)
+	binary.LittleEndian.PutUint32(entry[8:], 0)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPS 37.7749N 122.4194W")...)
+
+	seg := []byte{0xFF, markerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(2+len(exifHeader)+len(tiff)))
+	seg = append(seg, exifHeader...)
+	return append(seg, tiff...)
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	c := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(c, uint32(len(data)))
+	c = append(c, typ...)
+	c = append(c, data...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+func isoBox(typ string, content ...[]byte) []byte {
+	b := make([]byte, 4)
+	b = append(b, typ...)
+	for _, c := range content {
+		b = append(b, c...)
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+func TestSanitizeJPEG(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(buf, testImage(), nil))
+	orig := buf.Bytes()
+	withExif := append([]byte{}, orig[:2]...)
+	withExif = append(withExif, exifSegment(6)...)
+	withExif = append(withExif, 0xFF, markerCOM, 0x00, 0x0A)
+	withExif = append(withExif, []byte("iPhone 7")...)
+	withExif = append(withExif, orig[2:]...)
+	p := writeFile(t, "image.jpg", withExif)
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, report.Format)
+	assert.ElementsMatch(t, []string{RemovedEXIF, RemovedGPS, RemovedComment}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "GPS 37.7749N")
+	assert.NotContains(t, string(out), "iPhone")
+	assert.True(t, bytes.HasSuffix(out, orig[2:]), "image data must be left untouched")
+
+	i := bytes.Index(out, exifHeader)
+	require.Greater(t, i, 0, "orientation must be preserved")
+	o, gps := parseEXIF(out[i+len(exifHeader):])
+	assert.EqualValues(t, 6, o)
+	assert.False(t, gps)
+
+	_, err = jpeg.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+}
+
+func TestSanitizeJPEGClean(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(buf, testImage(), nil))
+	p := writeFile(t, "image.jpg", buf.Bytes())
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.True(t, report.Empty())
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), out)
+}
+
+func TestSanitizePNG(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, testImage()))
+	orig := buf.Bytes()
+	// Signature (8) + IHDR (25)
+	withMeta := append([]byte{}, orig[:33]...)
+	withMeta = append(withMeta, pngChunk("eXIf", []byte("MM\x00\x2aGPS"))...)
+	withMeta = append(withMeta, pngChunk("tEXt", []byte("Author\x00Jane"))...)
+	withMeta = append(withMeta, pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>"))...)
+	withMeta = append(withMeta, pngChunk("tIME", []byte{0x07, 0xE8, 1, 1, 0, 0, 0})...)
+	withMeta = append(withMeta, orig[33:]...)
+	p := writeFile(t, "image.png", withMeta)
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatPNG, report.Format)
+	assert.ElementsMatch(t, []string{RemovedEXIF, RemovedText, RemovedXMP, RemovedTime}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.Equal(t, orig, out)
+}
+
+func TestSanitizeWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = vp8xFlagEXIF | vp8xFlagXMP
+	image := []byte("VP8L-bitstream")
+	riff := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range []struct {
+		typ  string
+		data []byte
+	}{{"VP8X", vp8x}, {"VP8L", image}, {"EXIF", []byte("MM\x00\x2aGPS!")}, {"XMP ", []byte("<x:xmpmeta/>")}} {
+		riff = append(riff, c.typ...)
+		riff = binary.LittleEndian.AppendUint32(riff, uint32(len(c.data)))
+		riff = append(riff, c.data...)
+		if len(c.data)%2 == 1 {
+			riff = append(riff, 0)
+		}
+	}
+	binary.LittleEndian.PutUint32(riff[4:], uint32(len(riff)-8))
+	p := writeFile(t, "image.webp", riff)
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatWebP, report.Format)
+	assert.ElementsMatch(t, []string{RemovedEXIF, RemovedXMP}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.EqualValues(t, len(out)-8, binary.LittleEndian.Uint32(out[4:]))
+	assert.Zero(t, out[20]&(vp8xFlagEXIF|vp8xFlagXMP))
+	assert.Contains(t, string(out), "VP8L-bitstream")
+	assert.NotContains(t, string(out), "GPS!")
+	assert.NotContains(t, string(out), "xmpmeta")
+}
+
+func TestSanitizeHEIC(t *testing.T) {
+	exifPayload := []byte("\x00\x00\x00\x06Exif\x00\x00MM GPS 37.7749N")
+	imagePayload := []byte("hevc-image-data")
+
+	infe := func(id uint16, typ string) []byte {
+		c := []byte{2, 0, 0, 0}
+		c = binary.BigEndian.AppendUint16(c, id)
+		c = append(c, 0, 0)
+		c = append(c, typ...)
+		c = append(c, 0)
+		return isoBox("infe", c)
+	}
+	iinf := isoBox("iinf", []byte{0, 0, 0, 0, 0, 2}, infe(1, "hvc1"), infe(2, "Exif"))
+
+	ftyp := isoBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	// iloc v0, offset/length size 4, base offset size 0, two items with one extent each
+	buildMeta := func(imageOffset, exifOffset uint32) []byte {
+		iloc := []byte{0, 0, 0, 0, 0x44, 0x00, 0x00, 0x02}
+		for _, item := range []struct {
+			id             uint16
+			offset, length uint32
+		}{{1, imageOffset, uint32(len(imagePayload))}, {2, exifOffset, uint32(len(exifPayload))}} {
+			iloc = binary.BigEndian.AppendUint16(iloc, item.id)
+			iloc = append(iloc, 0, 0, 0, 1)
+			iloc = binary.BigEndian.AppendUint32(iloc, item.offset)
+			iloc = binary.BigEndian.AppendUint32(iloc, item.length)
+		}
+		return isoBox("meta", []byte{0, 0, 0, 0}, isoBox("hdlr", make([]byte, 25)), iinf, isoBox("iloc", iloc))
+	}
+	dataStart := uint32(len(ftyp) + len(buildMeta(0, 0)) + 8)
+	file := append(ftyp, buildMeta(dataStart, dataStart+uint32(len(imagePayload)))...)
+	file = append(file, isoBox("mdat", imagePayload, exifPayload)...)
+	p := writeFile(t, "image.heic", file)
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatHEIC, report.Format)
+	assert.Equal(t, []string{RemovedEXIF}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Len(t, out, len(file))
+	assert.Contains(t, string(out), "hevc-image-data")
+	assert.NotContains(t, string(out), "GPS 37.7749N")
+}
+
+func TestSanitizeMP4(t *testing.T) {
+	xyz := isoBox("\xa9xyz", []byte("\x00\x12\x15\xc7+37.7749-122.4194/"))
+	mak := isoBox("\xa9mak", []byte("\x00\x05\x15\xc7Apple"))
+	nam := isoBox("\xa9nam", []byte("\x00\x05\x15\xc7Title"))
+	file := isoBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	file = append(file, isoBox("mdat", []byte("video-data"))...)
+	file = append(file, isoBox("moov", isoBox("mvhd", make([]byte, 100)), isoBox("udta", xyz, mak, nam))...)
+	p := writeFile(t, "video.mp4", file)
+
+	report, err := New().Sanitize(p)
+	require.NoError(t, err)
+	assert.Empty(t, report.Format, "videos are not processed by default")
+	assert.True(t, report.Empty())
+
+	report, err = New(WithVideos(true)).Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatMP4, report.Format)
+	assert.ElementsMatch(t, []string{RemovedLocation, RemovedDevice}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Len(t, out, len(file))
+	assert.NotContains(t, string(out), "+37.7749-122.4194")
+	assert.NotContains(t, string(out), "Apple")
+	assert.Contains(t, string(out), "Title")
+	assert.Contains(t, string(out), "video-data")
+}
+
+func TestSanitizePDF(t *testing.T) {
+	doc := []byte("%PDF-1.4\n1 0 obj\n<< /Title (Report) /Author (Jane \\(JD\\) Doe) /Producer <FEFF0041> /Creator (x) >>\nendobj\n%%EOF\n")
+	p := writeFile(t, "doc.pdf", doc)
+
+	report, err := New(WithDocuments(true)).Sanitize(p)
+	require.NoError(t, err)
+	assert.Equal(t, FormatPDF, report.Format)
+	assert.Equal(t, []string{RemovedAuthor}, report.Removed)
+
+	out, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Len(t, out, len(doc))
+	assert.Contains(t, string(out), "/Title (Report)")
+	assert.Contains(t, string(out), "/Author (               )")
+	assert.Contains(t, string(out), "/Producer <20202020>")
+	assert.Contains(t, string(out), "/Creator ( )")
+}
+
+func TestSanitizeUnsupported(t *testing.T) {
+	p := writeFile(t, "file.txt", []byte("plain text"))
+	report, err := New(WithDocuments(true), WithVideos(true)).Sanitize(p)
+	require.NoError(t, err)
+	assert.True(t, report.Empty())
+}
+
+func TestSanitizeMalformed(t *testing.T) {
+	s := New()
+
+	jfif := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00}
+	p := writeFile(t, "a.jpg", append(jfif, make([]byte, 64)...))
+	_, err := s.Sanitize(p)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMetadataRemains)
+
+	data := append([]byte{0xFF, 0xD8}, exifSegment(6)...)
+	p = writeFile(t, "b.jpg", append(data, make([]byte, 64)...))
+	_, err = s.Sanitize(p)
+	require.ErrorIs(t, err, ErrMetadataRemains)
+	assert.ErrorContains(t, err, RemovedGPS)
+}
@@ -0,0 +1,53 @@
+package sanitizer
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	vp8xFlagXMP  = 0x04
+	vp8xFlagEXIF = 0x08
+)
+
+var errMalformedWebP = errors.New("malformed webp")
+
+// stripWebP removes EXIF and XMP chunks from the RIFF container and clears the corresponding VP8X flags.
+func stripWebP(data []byte, report *Report) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedWebP
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	vp8x := -1
+
+	i := 12
+	for i+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			// Tolerate a missing padding byte on the last chunk
+			if i+8+size != len(data) {
+				return nil, errMalformedWebP
+			}
+			end = len(data)
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF":
+			report.add(RemovedEXIF)
+		case "XMP ":
+			report.add(RemovedXMP)
+		case "VP8X":
+			vp8x = len(out) + 8
+			out = append(out, data[i:end]...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if vp8x >= 0 && vp8x < len(out) {
+		out[vp8x] &^= vp8xFlagEXIF | vp8xFlagXMP
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
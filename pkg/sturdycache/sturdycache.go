@@ -15,6 +15,9 @@ const ReplicatedCacheType = "redis"
 type ReplicatedCache struct {
 	masterCache   *cache.Cache[any]
 	replicaCaches []*cache.Cache[any]
+
+	masterClient   *redis.Client
+	replicaClients []*redis.Client
 }
 
 // NewReplicatedCache creates a new gocache store instance for redis master-replica setups.
@@ -37,6 +40,7 @@ func NewReplicatedCache(
 	masterCache := cache.New[any](masterStore)
 
 	replicaCaches := []*cache.Cache[any]{}
+	replicaClients := []*redis.Client{}
 
 	for _, addr := range replicaAddrs {
 		replicaClient := redis.NewClient(&redis.Options{
@@ -49,11 +53,14 @@ func NewReplicatedCache(
 
 		replicaStore := redis_store.NewRedis(replicaClient)
 		replicaCaches = append(replicaCaches, cache.New[any](replicaStore))
+		replicaClients = append(replicaClients, replicaClient)
 	}
 
 	baseStore := &ReplicatedCache{
-		masterCache:   masterCache,
-		replicaCaches: replicaCaches,
+		masterCache:    masterCache,
+		replicaCaches:  replicaCaches,
+		masterClient:   masterClient,
+		replicaClients: replicaClients,
 	}
 
 	return baseStore, nil
@@ -76,6 +83,47 @@ func (rc *ReplicatedCache) Get(ctx context.Context, key any) (any, error) {
 	return rc.masterCache.Get(ctx, key)
 }
 
+// MGet reads many keys with a single request per server, falling back from replicas to master
+// for keys they miss the same way Get does. Values of keys missing everywhere are nil.
+func (rc *ReplicatedCache) MGet(ctx context.Context, keys ...string) ([]any, error) {
+	values := make([]any, len(keys))
+	missing := make([]int, len(keys))
+	for i := range keys {
+		missing[i] = i
+	}
+	clients := make([]*redis.Client, 0, len(rc.replicaClients)+1)
+	clients = append(append(clients, rc.replicaClients...), rc.masterClient)
+	var err error
+	for _, client := range clients {
+		missingKeys := make([]string, len(missing))
+		for i, idx := range missing {
+			missingKeys[i] = keys[idx]
+		}
+		var found []any
+		found, err = client.MGet(ctx, missingKeys...).Result()
+		if err != nil {
+			continue
+		}
+		stillMissing := missing[:0]
+		for i, idx := range missing {
+			if found[i] == nil {
+				stillMissing = append(stillMissing, idx)
+				continue
+			}
+			values[idx] = found[i]
+		}
+		missing = stillMissing
+		if len(missing) == 0 {
+			return values, nil
+		}
+	}
+	// Master error only matters when nothing was found elsewhere.
+	if err != nil && len(missing) == len(keys) {
+		return nil, err
+	}
+	return values, nil
+}
+
 // Get reads from master and replica caches.
 func (rc *ReplicatedCache) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
 	for _, cache := range rc.replicaCaches {
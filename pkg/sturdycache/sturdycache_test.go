@@ -98,6 +98,18 @@ func (s *ReplicatedCacheTestSuite) TestGet() {
 	s.Require().Equal(testValue, value)
 }
 
+func (s *ReplicatedCacheTestSuite) TestMGet() {
+	s.Require().NoError(s.cache.Set(s.ctx, "key1", "value1"))
+	s.Require().NoError(s.cache.Set(s.ctx, "key2", "value2"))
+	// Only the first replica has caught up on key1, the rest of keys are read from master.
+	s.replicas[0].Set("key1", "value1")
+	s.replicas[1].Close()
+
+	values, err := s.replicatedCache.MGet(s.ctx, "key1", "key2", "key3")
+	s.Require().NoError(err)
+	s.Equal([]any{"value1", "value2", nil}, values)
+}
+
 func (s *ReplicatedCacheTestSuite) TestGetWithReplicaFailures() {
 	testKey := "test-key"
 	testValue := "test-value"
@@ -0,0 +1,53 @@
+package urlsigner
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// CDN77 signs URLs with CDN77 secure tokens, which are put in front of the file path.
+type CDN77 struct {
+	token     string
+	hashParam string
+}
+
+// NewCDN77 creates a signer using the CDN resource secure token.
+// The signature is repeated in the hashParam query parameter unless it's empty.
+func NewCDN77(token, hashParam string) *CDN77 {
+	return &CDN77{token: token, hashParam: hashParam}
+}
+
+// Hash returns the secure token hash for the file, followed by the expiry timestamp if it's positive.
+func (s *CDN77) Hash(filePath string, expiryTimestamp int64) string {
+	hash := path.Dir(filePath) + s.token
+	if expiryTimestamp > 0 {
+		hash = fmt.Sprintf("%d%s", expiryTimestamp, hash)
+	}
+
+	sum := md5.Sum([]byte(hash))
+	encoded := base64.StdEncoding.EncodeToString(sum[:])
+	encoded = strings.NewReplacer("+", "-", "/", "_").Replace(encoded)
+
+	if expiryTimestamp > 0 {
+		return fmt.Sprintf("%s,%d", encoded, expiryTimestamp)
+	}
+	return encoded
+}
+
+// Sign returns a URL valid until expiry, or without expiration if it's zero.
+func (s *CDN77) Sign(host, filePath string, expiry time.Time) (string, error) {
+	var ts int64
+	if !expiry.IsZero() {
+		ts = expiry.Unix()
+	}
+	hash := s.Hash(filePath, ts)
+	u := fmt.Sprintf("https://%s/%s%s", host, hash, filePath)
+	if s.hashParam != "" {
+		u += fmt.Sprintf("?%s=%s", s.hashParam, hash)
+	}
+	return u, nil
+}
@@ -0,0 +1,94 @@
+package urlsigner
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// cloudFrontEncoding is base64 with characters invalid in query strings replaced the way CloudFront expects.
+var cloudFrontEncoding = strings.NewReplacer("+", "-", "=", "_", "/", "~")
+
+// CloudFront signs URLs with CloudFront custom policies, allowing access to the file directory until expiry.
+type CloudFront struct {
+	keyPairID string
+	key       *rsa.PrivateKey
+}
+
+type cloudFrontPolicy struct {
+	Statement []cloudFrontStatement `json:"Statement"`
+}
+
+type cloudFrontStatement struct {
+	Resource  string `json:"Resource"`
+	Condition struct {
+		DateLessThan struct {
+			EpochTime int64 `json:"AWS:EpochTime"`
+		} `json:"DateLessThan"`
+	} `json:"Condition"`
+}
+
+// NewCloudFront creates a signer using the RSA key of the CloudFront key pair, PEM-encoded in PKCS #1 or PKCS #8 form.
+func NewCloudFront(keyPairID string, privateKeyPEM []byte) (*CloudFront, error) {
+	if keyPairID == "" {
+		return nil, errors.New("cloudfront signer requires a key pair id")
+	}
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("cloudfront private key is not PEM-encoded")
+	}
+	var key *rsa.PrivateKey
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		key = k
+	} else {
+		pk, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse cloudfront private key: %w", err)
+		}
+		var ok bool
+		if key, ok = pk.(*rsa.PrivateKey); !ok {
+			return nil, errors.New("cloudfront private key is not an RSA key")
+		}
+	}
+	return &CloudFront{keyPairID: keyPairID, key: key}, nil
+}
+
+// Policy returns the policy allowing access to files in the directory of filePath until expiry.
+func (s *CloudFront) Policy(host, filePath string, expiry time.Time) ([]byte, error) {
+	st := cloudFrontStatement{Resource: fmt.Sprintf("https://%s%s/*", host, strings.TrimSuffix(path.Dir(filePath), "/"))}
+	st.Condition.DateLessThan.EpochTime = expiry.Unix()
+	return json.Marshal(cloudFrontPolicy{Statement: []cloudFrontStatement{st}})
+}
+
+// Sign returns a URL valid until expiry, which is required.
+func (s *CloudFront) Sign(host, filePath string, expiry time.Time) (string, error) {
+	if expiry.IsZero() {
+		return "", errors.New("expiry is required")
+	}
+	policy, err := s.Policy(host, filePath, expiry)
+	if err != nil {
+		return "", err
+	}
+	digest := sha1.Sum(policy)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA1, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("cannot sign cloudfront policy: %w", err)
+	}
+	q := fmt.Sprintf("Policy=%s&Signature=%s&Key-Pair-Id=%s",
+		cloudFrontEncoding.Replace(base64.StdEncoding.EncodeToString(policy)),
+		cloudFrontEncoding.Replace(base64.StdEncoding.EncodeToString(sig)),
+		url.QueryEscape(s.keyPairID),
+	)
+	return fmt.Sprintf("https://%s%s?%s", host, filePath, q), nil
+}
@@ -0,0 +1,92 @@
+// Package urlsigner signs streaming URLs, so that CDNs only serve files to clients authorized by the API.
+//
+// Each CDN token scheme is implemented by a URLSigner, Signers selects one by player host.
+// Signatures cover the directory of the signed file, so HLS segments next to a playlist
+// can be fetched with the same token.
+package urlsigner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SchemeCDN77      = "cdn77"
+	SchemeHMACSHA256 = "hmac-sha256"
+	SchemeCloudFront = "cloudfront"
+)
+
+// URLSigner signs URLs of files on a CDN host.
+type URLSigner interface {
+	// Sign returns a signed https URL for the file at filePath on host, valid until expiry.
+	Sign(host, filePath string, expiry time.Time) (string, error)
+}
+
+// Config selects and sets up a signer, as read from a config file.
+type Config struct {
+	// Scheme is one of cdn77, hmac-sha256 and cloudfront.
+	Scheme string
+	// Secret is the CDN77 secure token or the HMAC key.
+	Secret string
+	// HashParam is a query parameter CDN77 signature is repeated in, it's omitted if empty.
+	HashParam string
+	// KeyPairID identifies the CloudFront public key PrivateKey, a PEM-encoded RSA key, corresponds to.
+	KeyPairID  string
+	PrivateKey string
+}
+
+// New creates a signer for the scheme set in cfg.
+func New(cfg Config) (URLSigner, error) {
+	switch cfg.Scheme {
+	case SchemeCDN77:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("%s signer requires a secret", cfg.Scheme)
+		}
+		return NewCDN77(cfg.Secret, cfg.HashParam), nil
+	case SchemeHMACSHA256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("%s signer requires a secret", cfg.Scheme)
+		}
+		return NewHMACSHA256([]byte(cfg.Secret)), nil
+	case SchemeCloudFront:
+		return NewCloudFront(cfg.KeyPairID, []byte(cfg.PrivateKey))
+	default:
+		return nil, fmt.Errorf("unknown url signing scheme %q", cfg.Scheme)
+	}
+}
+
+// Signers signs URLs with a signer set up for their host.
+type Signers struct {
+	hosts    map[string]URLSigner
+	fallback URLSigner
+}
+
+// NewSigners creates signers for hosts in cfgs. URLs for other hosts are signed by fallback, which can be nil.
+func NewSigners(cfgs map[string]Config, fallback URLSigner) (*Signers, error) {
+	s := &Signers{hosts: map[string]URLSigner{}, fallback: fallback}
+	for host, cfg := range cfgs {
+		signer, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer for %s: %w", host, err)
+		}
+		s.hosts[strings.ToLower(host)] = signer
+	}
+	return s, nil
+}
+
+// For returns the signer for host, nil if there's none.
+func (s *Signers) For(host string) URLSigner {
+	if signer, ok := s.hosts[strings.ToLower(host)]; ok {
+		return signer
+	}
+	return s.fallback
+}
+
+func (s *Signers) Sign(host, filePath string, expiry time.Time) (string, error) {
+	signer := s.For(host)
+	if signer == nil {
+		return "", fmt.Errorf("no url signer for %s", host)
+	}
+	return signer.Sign(host, filePath, expiry)
+}
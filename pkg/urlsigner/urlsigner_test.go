@@ -0,0 +1,150 @@
+package urlsigner
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret = "aiphaechiSietee3heiKaezosaitip0i"
+	testPath   = "/v6/streams/abc/sdhash.mp4"
+)
+
+var testExpiry = time.Unix(1695977338, 0)
+
+func TestCDN77(t *testing.T) {
+	filePath := "/api/v4/streams/tc/all-the-times-we-nearly-blew-up-the" +
+		"/ac809d68d201e2f58dcd241b5aaeefe817634dda" +
+		"/2f562bd1dd318db726014d255c3c7f4e5cae3e746f77647e00ad7e9b272d193bcad634b515bf0a2bc471719cfdde0c00" +
+		"/master.m3u8"
+	s := NewCDN77(testSecret, "")
+	assert.Equal(t, "Syc1EWOyivHWw9L4aquM1g==,1695977338", s.Hash(filePath, testExpiry.Unix()))
+
+	u, err := s.Sign("player.odycdn.com", filePath, testExpiry)
+	require.NoError(t, err)
+	assert.Equal(t, "https://player.odycdn.com/Syc1EWOyivHWw9L4aquM1g==,1695977338"+filePath, u)
+
+	u, err = NewCDN77(testSecret, "hash77").Sign("secure.odycdn.com", filePath, testExpiry)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"https://secure.odycdn.com/Syc1EWOyivHWw9L4aquM1g==,1695977338"+filePath+"?hash77=Syc1EWOyivHWw9L4aquM1g==,1695977338", u)
+
+	u, err = s.Sign("player.odycdn.com", filePath, time.Time{})
+	require.NoError(t, err)
+	assert.NotContains(t, u, ",")
+}
+
+func TestHMACSHA256(t *testing.T) {
+	s := NewHMACSHA256([]byte(testSecret))
+	u, err := s.Sign("cdn.example.com", testPath, testExpiry)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/5VDsKfh_v4t4MvsoV_Xf5AY-0Zn31J_duHXdUekQED0,1695977338"+testPath, u)
+
+	require.NoError(t, s.Verify(u, testExpiry.Add(-time.Second)))
+	require.NoError(t, s.Verify(strings.Replace(u, "sdhash.mp4", "seg1.ts", 1), testExpiry.Add(-time.Second)))
+	assert.ErrorIs(t, s.Verify(u, testExpiry), ErrExpired)
+	assert.ErrorIs(t, s.Verify(strings.Replace(u, "/abc/", "/abd/", 1), testExpiry.Add(-time.Second)), ErrInvalidSignature)
+	assert.ErrorIs(t, s.Verify(strings.Replace(u, "1695977338", "1695977339", 1), testExpiry.Add(-time.Second)), ErrInvalidSignature)
+	assert.ErrorIs(t, NewHMACSHA256([]byte("other")).Verify(u, testExpiry.Add(-time.Second)), ErrInvalidSignature)
+
+	_, err = s.Sign("cdn.example.com", testPath, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestCloudFront(t *testing.T) {
+	keyPEM, err := os.ReadFile("testdata/cloudfront_key.pem")
+	require.NoError(t, err)
+	s, err := NewCloudFront("K2JCJMDEHXQW5F", keyPEM)
+	require.NoError(t, err)
+
+	u, err := s.Sign("cdn.example.com", testPath, testExpiry)
+	require.NoError(t, err)
+	// Signature made with openssl dgst -sha1 -sign.
+	assert.Equal(t, "https://cdn.example.com"+testPath+
+		"?Policy=eyJTdGF0ZW1lbnQiOlt7IlJlc291cmNlIjoiaHR0cHM6Ly9jZG4uZXhhbXBsZS5jb20vdjYvc3RyZWFtcy9hYmMvKiIsIkNvbmRpdGlvbiI6eyJEYXRlTGVzc1RoYW4iOnsiQVdTOkVwb2NoVGltZSI6MTY5NTk3NzMzOH19fV19"+
+		"&Signature=Q~S9~2YKs9R41lWaGZth3FbpAf8dgSqWdak39CAwwYQrUuFMK6otQt92y~lbnsRFJV7-qazx8Rqwn-AeAZ0NokP-BFwWUmolySRB8~XaMUWTuvG7JCVXpc4fb03gzbv-jhFsryMMH0GE9DZxcETjPA2jlhczJUg47PACGQcTqONHqtYgylS2qutS7bBWeDzMlZmsFjAcBYnZgTboFEYJoSoT1LJAkB2Z2mEcY0xbcVu-EMT8568-ogQUsYaVl1ct5KSv4SmLgFzgD~l8hNfkUzaV-66bc7WO~3hN9QPdTuRAqwgTsdvhoHfkHJUCqTH5VZomQL7s9n8EdUyDxfozdw__"+
+		"&Key-Pair-Id=K2JCJMDEHXQW5F", u)
+
+	parsed, err := url.Parse(u)
+	require.NoError(t, err)
+	decode := func(v string) []byte {
+		b, err := base64.StdEncoding.DecodeString(strings.NewReplacer("-", "+", "_", "=", "~", "/").Replace(v))
+		require.NoError(t, err)
+		return b
+	}
+	policy := decode(parsed.Query().Get("Policy"))
+	assert.JSONEq(t,
+		`{"Statement":[{"Resource":"https://cdn.example.com/v6/streams/abc/*","Condition":{"DateLessThan":{"AWS:EpochTime":1695977338}}}]}`,
+		string(policy))
+	digest := sha1.Sum(policy)
+	require.NoError(t, rsa.VerifyPKCS1v15(&s.key.PublicKey, crypto.SHA1, digest[:], decode(parsed.Query().Get("Signature"))))
+
+	// PKCS #8 keys are accepted too.
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(s.key)
+	require.NoError(t, err)
+	s8, err := NewCloudFront("K2JCJMDEHXQW5F", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	require.NoError(t, err)
+	u8, err := s8.Sign("cdn.example.com", testPath, testExpiry)
+	require.NoError(t, err)
+	assert.Equal(t, u, u8)
+
+	_, err = NewCloudFront("", keyPEM)
+	assert.Error(t, err)
+	_, err = NewCloudFront("K2JCJMDEHXQW5F", []byte("not a key"))
+	assert.Error(t, err)
+	_, err = s.Sign("cdn.example.com", testPath, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestSigners(t *testing.T) {
+	keyPEM, err := os.ReadFile("testdata/cloudfront_key.pem")
+	require.NoError(t, err)
+	signers, err := NewSigners(map[string]Config{
+		"Secure.odycdn.com": {Scheme: SchemeCDN77, Secret: testSecret, HashParam: "hash77"},
+		"hmac.example.com":  {Scheme: SchemeHMACSHA256, Secret: testSecret},
+		"cf.example.com":    {Scheme: SchemeCloudFront, KeyPairID: "K2JCJMDEHXQW5F", PrivateKey: string(keyPEM)},
+	}, NewCDN77(testSecret, ""))
+	require.NoError(t, err)
+
+	assert.IsType(t, &CDN77{}, signers.For("secure.odycdn.com"))
+	assert.IsType(t, &HMACSHA256{}, signers.For("hmac.example.com"))
+	assert.IsType(t, &CloudFront{}, signers.For("CF.example.com"))
+
+	u, err := signers.Sign("secure.odycdn.com", testPath, testExpiry)
+	require.NoError(t, err)
+	assert.Contains(t, u, "?hash77=")
+	u, err = signers.Sign("live.example.com", testPath, testExpiry)
+	require.NoError(t, err)
+	assert.Equal(t, "https://live.example.com/"+NewCDN77(testSecret, "").Hash(testPath, testExpiry.Unix())+testPath, u)
+
+	noFallback, err := NewSigners(nil, nil)
+	require.NoError(t, err)
+	_, err = noFallback.Sign("live.example.com", testPath, testExpiry)
+	assert.EqualError(t, err, "no url signer for live.example.com")
+
+	cases := []struct {
+		cfg Config
+		err string
+	}{
+		{Config{Scheme: "md5"}, `invalid signer for cdn: unknown url signing scheme "md5"`},
+		{Config{Scheme: SchemeCDN77}, "invalid signer for cdn: cdn77 signer requires a secret"},
+		{Config{Scheme: SchemeHMACSHA256}, "invalid signer for cdn: hmac-sha256 signer requires a secret"},
+		{Config{Scheme: SchemeCloudFront, PrivateKey: string(keyPEM)}, "invalid signer for cdn: cloudfront signer requires a key pair id"},
+	}
+	for _, tc := range cases {
+		_, err := NewSigners(map[string]Config{"cdn": tc.cfg}, nil)
+		assert.EqualError(t, err, tc.err)
+	}
+}